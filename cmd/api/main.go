@@ -10,16 +10,21 @@ import (
 	"time"
 
 	"newsletter-assignment/internal/config"
+	"newsletter-assignment/internal/constants"
 	"newsletter-assignment/internal/db"
+	"newsletter-assignment/internal/email"
 	"newsletter-assignment/internal/handler"
 	httphandler "newsletter-assignment/internal/http"
+	"newsletter-assignment/internal/links"
 	"newsletter-assignment/internal/log"
 	"newsletter-assignment/internal/queue"
 	"newsletter-assignment/internal/repo"
 	"newsletter-assignment/internal/scheduler"
 	"newsletter-assignment/internal/service"
+	"newsletter-assignment/internal/token"
 	"newsletter-assignment/internal/version"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -57,18 +62,13 @@ func main() {
 	subscriptionRepo := repo.NewSubscriptionRepository(database)
 	contentRepo := repo.NewContentRepository(database)
 	jobRepo := repo.NewJobRepository(database)
-
-	// Initialize services
-	topicService := service.NewTopicService(topicRepo, logger)
-	subscriberService := service.NewSubscriberService(subscriberRepo, logger)
-	subscriptionService := service.NewSubscriptionService(subscriptionRepo, subscriberRepo, topicRepo, logger)
-	contentService := service.NewContentService(contentRepo, topicRepo, jobRepo, database, logger)
-
-	// Initialize handlers
-	topicHandler := handler.NewTopicHandler(topicService, logger)
-	subscriberHandler := handler.NewSubscriberHandler(subscriberService, logger)
-	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionService, logger)
-	contentHandler := handler.NewContentHandler(contentService, logger)
+	scheduleRepo := repo.NewRecurringScheduleRepository(database)
+	apiKeyRepo := repo.NewAPIKeyRepository(database)
+	workspaceRepo := repo.NewWorkspaceRepository(database)
+	suppressionRepo := repo.NewSuppressionRepository(database)
+	deliveryRepo := repo.NewDeliveryRepository(database)
+	deliveryEventRepo := repo.NewDeliveryEventRepository(database)
+	jobAuditRepo := repo.NewJobAuditRepository(database)
 
 	// Initialize queue
 	jobQueue := queue.NewAsynqQueue(
@@ -80,17 +80,126 @@ func main() {
 	)
 	defer jobQueue.Close()
 
+	// Initialize email sender for double opt-in confirmation emails
+	emailSender, err := email.NewUnifiedEmailSender(&email.UnifiedConfig{
+		Provider:                cfg.Email.Provider,
+		FailoverProviders:       cfg.Email.FailoverProviders,
+		Weights:                 cfg.Email.ProviderWeights,
+		CircuitFailureThreshold: cfg.Email.CircuitFailureThreshold,
+		CircuitCooldown:         cfg.Email.CircuitCooldown,
+		SMTP: &email.SMTPConfig{
+			Host:                     cfg.SMTP.Host,
+			Port:                     cfg.SMTP.Port,
+			Username:                 cfg.SMTP.Username,
+			Password:                 cfg.SMTP.Password,
+			FromEmail:                cfg.SMTP.FromEmail,
+			FromName:                 cfg.SMTP.FromName,
+			TLSMode:                  cfg.SMTP.TLSMode,
+			AuthMechanism:            cfg.SMTP.AuthMechanism,
+			PoolSize:                 cfg.SMTP.PoolSize,
+			PoolIdleTimeout:          cfg.SMTP.PoolIdleTimeout,
+			MaxMessagesPerConnection: cfg.SMTP.MaxMessagesPerConnection,
+			DKIMDomain:               cfg.DKIM.Domain,
+			DKIMSelector:             cfg.DKIM.Selector,
+			DKIMPrivateKey:           cfg.DKIM.PrivateKey,
+		},
+		HTTP: &email.HTTPConfig{
+			APIKey:    cfg.Email.APIKey,
+			FromEmail: cfg.Email.FromEmail,
+			FromName:  cfg.Email.FromName,
+			BaseURL:   cfg.Email.BaseURL,
+		},
+		SES: &email.SESConfig{
+			Region:          cfg.SES.Region,
+			AccessKeyID:     cfg.SES.AccessKeyID,
+			SecretAccessKey: cfg.SES.SecretAccessKey,
+			FromEmail:       cfg.Email.FromEmail,
+			FromName:        cfg.Email.FromName,
+			BaseURL:         cfg.SES.BaseURL,
+		},
+		SendGrid: &email.SendGridConfig{
+			APIKey:    cfg.SendGrid.APIKey,
+			FromEmail: cfg.Email.FromEmail,
+			FromName:  cfg.Email.FromName,
+			BaseURL:   cfg.SendGrid.BaseURL,
+		},
+		Mailgun: &email.MailgunConfig{
+			APIKey:    cfg.Mailgun.APIKey,
+			Domain:    cfg.Mailgun.Domain,
+			FromEmail: cfg.Email.FromEmail,
+			FromName:  cfg.Email.FromName,
+			BaseURL:   cfg.Mailgun.BaseURL,
+		},
+		Postmark: &email.PostmarkConfig{
+			ServerToken:   cfg.Postmark.ServerToken,
+			MessageStream: cfg.Postmark.MessageStream,
+			FromEmail:     cfg.Email.FromEmail,
+			FromName:      cfg.Email.FromName,
+			BaseURL:       cfg.Postmark.BaseURL,
+		},
+	}, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize email sender", zap.Error(err))
+	}
+
+	// Initialize signed links and tokens
+	signer := token.NewSigner(cfg.Links.TokenSecret)
+	linkBuilder := links.NewBuilder(cfg.Links.PublicBaseURL, signer)
+
+	// Initialize services
+	topicService := service.NewTopicService(topicRepo, logger)
+	subscriberService := service.NewSubscriberService(subscriberRepo, logger)
+	subscriptionService := service.NewSubscriptionService(subscriptionRepo, subscriberRepo, topicRepo, workspaceRepo, suppressionRepo, emailSender, linkBuilder, cfg.Subscriptions.ConfirmationTTL, logger)
+	contentService := service.NewContentService(contentRepo, topicRepo, subscriberRepo, jobRepo, jobQueue, database, logger)
+	scheduleService := service.NewRecurringScheduleService(scheduleRepo, contentRepo, topicRepo, jobRepo, database, logger)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, logger)
+	workspaceService := service.NewWorkspaceService(workspaceRepo, apiKeyService, logger)
+	suppressionService := service.NewSuppressionService(suppressionRepo, logger)
+	deliveryService := service.NewDeliveryService(contentRepo, topicRepo, deliveryRepo, deliveryEventRepo, logger)
+	jobService := service.NewJobService(jobRepo, jobAuditRepo, contentRepo, deliveryRepo, jobQueue, database, logger)
+	deliveryEventService := service.NewDeliveryEventService(deliveryRepo, deliveryEventRepo, contentRepo, suppressionRepo, subscriptionService, logger)
+
+	// Store the configured admin key in the default workspace so that the first keys
+	// and workspaces can be created through the API
+	if cfg.Auth.AdminAPIKey != "" {
+		defaultWorkspaceID := uuid.MustParse(constants.DefaultWorkspaceID)
+		if err := apiKeyService.EnsureAPIKey(context.Background(), defaultWorkspaceID, "bootstrap admin", cfg.Auth.AdminAPIKey, constants.RoleAdmin); err != nil {
+			logger.Fatal("Failed to store admin API key", zap.Error(err))
+		}
+	}
+
+	// Initialize handlers
+	topicHandler := handler.NewTopicHandler(topicService, logger)
+	subscriberHandler := handler.NewSubscriberHandler(subscriberService, logger)
+	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionService, logger)
+	contentHandler := handler.NewContentHandler(contentService, logger)
+	deliveryHandler := handler.NewDeliveryHandler(deliveryService, logger)
+	scheduleHandler := handler.NewScheduleHandler(scheduleService, logger)
+	jobHandler := handler.NewJobHandler(jobService, logger)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService, logger)
+	workspaceHandler := handler.NewWorkspaceHandler(workspaceService, logger)
+	suppressionHandler := handler.NewSuppressionHandler(suppressionService, logger)
+	unsubscribeHandler := handler.NewUnsubscribeHandler(subscriptionService, signer, logger)
+	confirmHandler := handler.NewConfirmHandler(subscriptionService, signer, logger)
+	emailStatusHandler := handler.NewEmailStatusHandler(emailSender, logger)
+	webhookHandler := handler.NewWebhookHandler(deliveryEventService, cfg.Email.WebhookSecret, logger)
+	trackingHandler := handler.NewTrackingHandler(deliveryEventService, signer, logger)
+	if cfg.Email.WebhookSecret == "" {
+		logger.Warn("EMAIL_WEBHOOK_SECRET is not set; provider webhooks will be rejected")
+	}
+
 	// Parse scheduler interval
 	schedulerInterval, err := time.ParseDuration(cfg.Scheduler.Interval)
 	if err != nil {
 		logger.Fatal("Invalid scheduler interval", zap.String("interval", cfg.Scheduler.Interval), zap.Error(err))
 	}
 
-	// Initialize scheduler
-	jobScheduler := scheduler.NewScheduler(jobRepo, jobQueue, logger, schedulerInterval, cfg.Scheduler.BatchSize)
+	// Initialize scheduler and the reaper of stuck enqueued jobs
+	reaper := scheduler.NewReaper(jobRepo, jobService, jobQueue, logger, cfg.Scheduler.VisibilityTimeout, cfg.Scheduler.BatchSize)
+	jobScheduler := scheduler.NewScheduler(jobRepo, scheduleService, subscriptionService, reaper, jobQueue, logger, schedulerInterval, cfg.Scheduler.BatchSize, cfg.Scheduler.Lookahead)
 
 	// Initialize HTTP handler with dependencies
-	httpHandler := httphandler.NewHandler(topicHandler, subscriberHandler, subscriptionHandler, contentHandler)
+	httpHandler := httphandler.NewHandler(topicHandler, subscriberHandler, subscriptionHandler, contentHandler, deliveryHandler, unsubscribeHandler, confirmHandler, scheduleHandler, jobHandler, suppressionHandler, webhookHandler, trackingHandler, emailStatusHandler, apiKeyHandler, workspaceHandler, apiKeyService, logger)
 	router := httpHandler.SetupRoutes()
 
 	srv := &http.Server{
@@ -126,6 +235,9 @@ func main() {
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		logger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
+	if err := emailSender.Close(); err != nil {
+		logger.Error("Failed to close email providers", zap.Error(err))
+	}
 
 	logger.Info("Server exited")
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -11,9 +12,11 @@ import (
 	"newsletter-assignment/internal/constants"
 	"newsletter-assignment/internal/db"
 	"newsletter-assignment/internal/email"
+	"newsletter-assignment/internal/links"
 	"newsletter-assignment/internal/log"
 	"newsletter-assignment/internal/queue"
 	"newsletter-assignment/internal/repo"
+	"newsletter-assignment/internal/token"
 	"newsletter-assignment/internal/version"
 	"newsletter-assignment/internal/worker"
 
@@ -49,19 +52,29 @@ func main() {
 
 	// Initialize repositories
 	contentRepo := repo.NewContentRepository(database)
-	subscriptionRepo := repo.NewSubscriptionRepository(database)
+	topicRepo := repo.NewTopicRepository(database)
 	subscriberRepo := repo.NewSubscriberRepository(database)
 	jobRepo := repo.NewJobRepository(database)
 	deliveryRepo := repo.NewDeliveryRepository(database)
+	workspaceRepo := repo.NewWorkspaceRepository(database)
+	suppressionRepo := repo.NewSuppressionRepository(database)
 
-	// Initialize unified email sender (supports both SMTP and HTTP API)
+	// Initialize email sender for the configured provider
 	smtpConfig := &email.SMTPConfig{
-		Host:      cfg.SMTP.Host,
-		Port:      cfg.SMTP.Port,
-		Username:  cfg.SMTP.Username,
-		Password:  cfg.SMTP.Password,
-		FromEmail: cfg.SMTP.FromEmail,
-		FromName:  cfg.SMTP.FromName,
+		Host:                     cfg.SMTP.Host,
+		Port:                     cfg.SMTP.Port,
+		Username:                 cfg.SMTP.Username,
+		Password:                 cfg.SMTP.Password,
+		FromEmail:                cfg.SMTP.FromEmail,
+		FromName:                 cfg.SMTP.FromName,
+		TLSMode:                  cfg.SMTP.TLSMode,
+		AuthMechanism:            cfg.SMTP.AuthMechanism,
+		PoolSize:                 cfg.SMTP.PoolSize,
+		PoolIdleTimeout:          cfg.SMTP.PoolIdleTimeout,
+		MaxMessagesPerConnection: cfg.SMTP.MaxMessagesPerConnection,
+		DKIMDomain:               cfg.DKIM.Domain,
+		DKIMSelector:             cfg.DKIM.Selector,
+		DKIMPrivateKey:           cfg.DKIM.PrivateKey,
 	}
 
 	httpConfig := &email.HTTPConfig{
@@ -71,22 +84,80 @@ func main() {
 		BaseURL:   cfg.Email.BaseURL,
 	}
 
+	sesConfig := &email.SESConfig{
+		Region:          cfg.SES.Region,
+		AccessKeyID:     cfg.SES.AccessKeyID,
+		SecretAccessKey: cfg.SES.SecretAccessKey,
+		FromEmail:       cfg.Email.FromEmail,
+		FromName:        cfg.Email.FromName,
+		BaseURL:         cfg.SES.BaseURL,
+	}
+
+	sendGridConfig := &email.SendGridConfig{
+		APIKey:    cfg.SendGrid.APIKey,
+		FromEmail: cfg.Email.FromEmail,
+		FromName:  cfg.Email.FromName,
+		BaseURL:   cfg.SendGrid.BaseURL,
+	}
+
+	mailgunConfig := &email.MailgunConfig{
+		APIKey:    cfg.Mailgun.APIKey,
+		Domain:    cfg.Mailgun.Domain,
+		FromEmail: cfg.Email.FromEmail,
+		FromName:  cfg.Email.FromName,
+		BaseURL:   cfg.Mailgun.BaseURL,
+	}
+
+	postmarkConfig := &email.PostmarkConfig{
+		ServerToken:   cfg.Postmark.ServerToken,
+		MessageStream: cfg.Postmark.MessageStream,
+		FromEmail:     cfg.Email.FromEmail,
+		FromName:      cfg.Email.FromName,
+		BaseURL:       cfg.Postmark.BaseURL,
+	}
+
 	unifiedConfig := &email.UnifiedConfig{
-		SMTP:    smtpConfig,
-		HTTP:    httpConfig,
-		UseHTTP: cfg.Email.UseHTTP,
+		Provider:                cfg.Email.Provider,
+		FailoverProviders:       cfg.Email.FailoverProviders,
+		Weights:                 cfg.Email.ProviderWeights,
+		CircuitFailureThreshold: cfg.Email.CircuitFailureThreshold,
+		CircuitCooldown:         cfg.Email.CircuitCooldown,
+		SMTP:                    smtpConfig,
+		HTTP:                    httpConfig,
+		SES:                     sesConfig,
+		SendGrid:                sendGridConfig,
+		Mailgun:                 mailgunConfig,
+		Postmark:                postmarkConfig,
+	}
+
+	emailSender, err := email.NewUnifiedEmailSender(unifiedConfig, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize email sender", zap.Error(err))
 	}
 
-	emailSender := email.NewUnifiedEmailSender(unifiedConfig, logger)
+	// Initialize signed link builder for unsubscribe links
+	linkBuilder := links.NewBuilder(cfg.Links.PublicBaseURL, token.NewSigner(cfg.Links.TokenSecret))
 
 	// Initialize worker
 	sendContentWorker := worker.NewSendContentWorker(
+		workspaceRepo,
 		contentRepo,
-		subscriptionRepo,
+		topicRepo,
 		subscriberRepo,
 		jobRepo,
 		deliveryRepo,
+		suppressionRepo,
 		emailSender,
+		linkBuilder,
+		worker.SendContentOptions{
+			Concurrency:       cfg.Worker.Concurrency,
+			BatchSize:         cfg.Worker.AudienceBatchSize,
+			FailureThreshold:  cfg.Worker.SendFailureThreshold,
+			RetryMaxAttempts:  cfg.DeliveryRetry.MaxAttempts,
+			RetryBaseDelay:    cfg.DeliveryRetry.BaseDelay,
+			RetryMaxDelay:     cfg.DeliveryRetry.MaxDelay,
+			HeartbeatInterval: cfg.Worker.HeartbeatInterval,
+		},
 		logger,
 	)
 
@@ -101,6 +172,7 @@ func main() {
 
 	// Register task handlers
 	jobQueue.RegisterHandler(constants.JobTypeSendNewsletter, sendContentWorker.HandleSendContent)
+	jobQueue.RegisterHandler(constants.JobTypeRetryDelivery, sendContentWorker.HandleRetryDelivery)
 
 	// Start health check server for Render
 	go func() {
@@ -108,6 +180,11 @@ func main() {
 			w.WriteHeader(http.StatusOK)
 			w.Write([]byte("Worker is healthy"))
 		})
+		// Circuit breaker state of the email providers this worker sends through
+		http.HandleFunc("/status/email-providers", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"providers": emailSender.Status()})
+		})
 		logger.Info("Starting health check server", zap.String("port", "8081"))
 		if err := http.ListenAndServe(":8081", nil); err != nil {
 			logger.Error("Health check server failed", zap.Error(err))
@@ -130,5 +207,8 @@ func main() {
 
 	logger.Info("Shutting down worker...")
 	jobQueue.Shutdown()
+	if err := emailSender.Close(); err != nil {
+		logger.Error("Failed to close email providers", zap.Error(err))
+	}
 	logger.Info("Worker exited")
 }
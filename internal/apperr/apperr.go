@@ -0,0 +1,101 @@
+package apperr
+
+import "errors"
+
+// Kind classifies a domain error so that transports can map it to a response
+type Kind string
+
+const (
+	KindNotFound           Kind = "not_found"
+	KindConflict           Kind = "conflict"
+	KindValidation         Kind = "validation"
+	KindPreconditionFailed Kind = "precondition_failed"
+	KindUnauthorized       Kind = "unauthorized"
+	KindForbidden          Kind = "forbidden"
+)
+
+// Sentinels for matching on the kind alone, e.g. errors.Is(err, apperr.ErrNotFound)
+var (
+	ErrNotFound           = &Error{Kind: KindNotFound}
+	ErrConflict           = &Error{Kind: KindConflict}
+	ErrValidation         = &Error{Kind: KindValidation}
+	ErrPreconditionFailed = &Error{Kind: KindPreconditionFailed}
+	ErrUnauthorized       = &Error{Kind: KindUnauthorized}
+	ErrForbidden          = &Error{Kind: KindForbidden}
+)
+
+// FieldError describes a problem with a single input field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a domain error whose message is safe to show to API clients
+type Error struct {
+	Kind    Kind
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the kind sentinels against any error of the same kind
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok || t.Message != "" {
+		return false
+	}
+	return t.Kind == e.Kind
+}
+
+// Wrap returns a copy of the error with err as its cause
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+func NotFound(message string) *Error {
+	return &Error{Kind: KindNotFound, Message: message}
+}
+
+func Conflict(message string) *Error {
+	return &Error{Kind: KindConflict, Message: message}
+}
+
+func Validation(message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Message: message, Fields: fields}
+}
+
+func PreconditionFailed(message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindPreconditionFailed, Message: message, Fields: fields}
+}
+
+func Unauthorized(message string) *Error {
+	return &Error{Kind: KindUnauthorized, Message: message}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Kind: KindForbidden, Message: message}
+}
+
+// Field builds a FieldError
+func Field(field, message string) FieldError {
+	return FieldError{Field: field, Message: message}
+}
+
+// As returns the domain error in err's chain, if any
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"newsletter-assignment/internal/constants"
 
@@ -36,19 +39,94 @@ type Config struct {
 		Password  string
 		FromEmail string
 		FromName  string
+
+		TLSMode                  string
+		AuthMechanism            string
+		PoolSize                 int
+		PoolIdleTimeout          time.Duration
+		MaxMessagesPerConnection int
+	}
+
+	// DKIM signs outbound SMTP mail when a domain is set; PrivateKey is PEM encoded
+	DKIM struct {
+		Domain     string
+		Selector   string
+		PrivateKey string
 	}
 
 	Email struct {
+		Provider  string
 		APIKey    string
 		BaseURL   string
 		UseHTTP   bool
 		FromEmail string
 		FromName  string
+
+		WebhookSecret string
+
+		FailoverProviders       []string
+		ProviderWeights         map[string]int
+		CircuitFailureThreshold int
+		CircuitCooldown         time.Duration
+	}
+
+	SES struct {
+		Region          string
+		AccessKeyID     string
+		SecretAccessKey string
+		BaseURL         string
+	}
+
+	SendGrid struct {
+		APIKey  string
+		BaseURL string
+	}
+
+	Mailgun struct {
+		APIKey  string
+		Domain  string
+		BaseURL string
+	}
+
+	Postmark struct {
+		ServerToken   string
+		MessageStream string
+		BaseURL       string
+	}
+
+	Links struct {
+		PublicBaseURL string
+		TokenSecret   string
+	}
+
+	Auth struct {
+		AdminAPIKey string
+	}
+
+	Subscriptions struct {
+		ConfirmationTTL time.Duration
 	}
 
 	Scheduler struct {
 		Interval  string
 		BatchSize int
+		Lookahead time.Duration
+		// VisibilityTimeout is how long an enqueued job may go without progress
+		// before its task is checked
+		VisibilityTimeout time.Duration
+	}
+
+	Worker struct {
+		Concurrency          int
+		AudienceBatchSize    int
+		SendFailureThreshold float64
+		HeartbeatInterval    time.Duration
+	}
+
+	DeliveryRetry struct {
+		MaxAttempts int
+		BaseDelay   time.Duration
+		MaxDelay    time.Duration
 	}
 
 	Asynq struct {
@@ -87,15 +165,91 @@ func Load() (*Config, error) {
 	cfg.SMTP.Password = getEnv(constants.EnvKeySMTPPassword, "")
 	cfg.SMTP.FromEmail = getEnv(constants.EnvKeySMTPFromEmail, constants.DefaultSMTPFromEmail)
 	cfg.SMTP.FromName = getEnv(constants.EnvKeySMTPFromName, constants.DefaultSMTPFromName)
+	cfg.SMTP.TLSMode = strings.ToLower(getEnv(constants.EnvKeySMTPTLSMode, constants.DefaultSMTPTLSMode))
+	cfg.SMTP.AuthMechanism = strings.ToLower(getEnv(constants.EnvKeySMTPAuthMechanism, constants.DefaultSMTPAuthMechanism))
+	cfg.SMTP.PoolSize = getEnvInt(constants.EnvKeySMTPPoolSize, constants.DefaultSMTPPoolSize)
+	cfg.SMTP.PoolIdleTimeout = getEnvDuration(constants.EnvKeySMTPPoolIdleTimeout, constants.DefaultSMTPPoolIdleTimeout)
+	cfg.SMTP.MaxMessagesPerConnection = getEnvInt(constants.EnvKeySMTPMaxMessagesPerConnection, constants.DefaultSMTPMaxMessagesPerConnection)
+
+	cfg.DKIM.Domain = getEnv(constants.EnvKeyDKIMDomain, "")
+	cfg.DKIM.Selector = getEnv(constants.EnvKeyDKIMSelector, "")
+	// Keys passed inline in .env files usually have their newlines escaped
+	cfg.DKIM.PrivateKey = strings.ReplaceAll(getEnv(constants.EnvKeyDKIMPrivateKey, ""), `\n`, "\n")
+	if path := getEnv(constants.EnvKeyDKIMPrivateKeyFile, ""); path != "" {
+		key, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", constants.EnvKeyDKIMPrivateKeyFile, err)
+		}
+		cfg.DKIM.PrivateKey = string(key)
+	}
+	if cfg.DKIM.Domain != "" && (cfg.DKIM.Selector == "" || cfg.DKIM.PrivateKey == "") {
+		return nil, fmt.Errorf("%s requires %s and a private key", constants.EnvKeyDKIMDomain, constants.EnvKeyDKIMSelector)
+	}
 
 	cfg.Email.APIKey = getEnv(constants.EnvKeyEmailAPIKey, "")
 	cfg.Email.BaseURL = getEnv(constants.EnvKeyEmailAPIBaseURL, constants.DefaultEmailAPIBaseURL)
 	cfg.Email.UseHTTP = getEnvBool(constants.EnvKeyEmailUseHTTP, constants.DefaultEmailUseHTTP)
 	cfg.Email.FromEmail = getEnv(constants.EnvKeySMTPFromEmail, constants.DefaultSMTPFromEmail) // Reuse SMTP from email
 	cfg.Email.FromName = getEnv(constants.EnvKeySMTPFromName, constants.DefaultSMTPFromName)    // Reuse SMTP from name
+	cfg.Email.WebhookSecret = getEnv(constants.EnvKeyEmailWebhookSecret, "")
+
+	// EMAIL_USE_HTTP predates EMAIL_PROVIDER and still picks between Brevo and SMTP
+	cfg.Email.Provider = strings.ToLower(getEnv(constants.EnvKeyEmailProvider, ""))
+	if cfg.Email.Provider == "" {
+		cfg.Email.Provider = constants.EmailProviderSMTP
+		if cfg.Email.UseHTTP {
+			cfg.Email.Provider = constants.EmailProviderBrevo
+		}
+	}
+
+	cfg.Email.FailoverProviders = getEnvList(constants.EnvKeyEmailFailoverProviders)
+	weights, err := parseProviderWeights(getEnv(constants.EnvKeyEmailProviderWeights, ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", constants.EnvKeyEmailProviderWeights, err)
+	}
+	cfg.Email.ProviderWeights = weights
+	cfg.Email.CircuitFailureThreshold = getEnvInt(constants.EnvKeyEmailCircuitFailureThreshold, constants.DefaultEmailCircuitFailureThreshold)
+	cfg.Email.CircuitCooldown = getEnvDuration(constants.EnvKeyEmailCircuitCooldown, constants.DefaultEmailCircuitCooldown)
+
+	cfg.SES.Region = getEnv(constants.EnvKeySESRegion, constants.DefaultSESRegion)
+	cfg.SES.AccessKeyID = getEnv(constants.EnvKeySESAccessKeyID, "")
+	cfg.SES.SecretAccessKey = getEnv(constants.EnvKeySESSecretAccessKey, "")
+	cfg.SES.BaseURL = getEnv(constants.EnvKeySESBaseURL, "")
+
+	cfg.SendGrid.APIKey = getEnv(constants.EnvKeySendGridAPIKey, "")
+	cfg.SendGrid.BaseURL = getEnv(constants.EnvKeySendGridBaseURL, constants.DefaultSendGridBaseURL)
+
+	cfg.Mailgun.APIKey = getEnv(constants.EnvKeyMailgunAPIKey, "")
+	cfg.Mailgun.Domain = getEnv(constants.EnvKeyMailgunDomain, "")
+	cfg.Mailgun.BaseURL = getEnv(constants.EnvKeyMailgunBaseURL, constants.DefaultMailgunBaseURL)
+
+	cfg.Postmark.ServerToken = getEnv(constants.EnvKeyPostmarkServerToken, "")
+	cfg.Postmark.MessageStream = getEnv(constants.EnvKeyPostmarkMessageStream, constants.DefaultPostmarkMessageStream)
+	cfg.Postmark.BaseURL = getEnv(constants.EnvKeyPostmarkBaseURL, constants.DefaultPostmarkBaseURL)
+
+	cfg.Links.PublicBaseURL = strings.TrimRight(getEnv(constants.EnvKeyPublicBaseURL, constants.DefaultPublicBaseURL), "/")
+	cfg.Links.TokenSecret = getEnv(constants.EnvKeyTokenSecret, "")
+	if cfg.Links.TokenSecret == "" {
+		return nil, fmt.Errorf("%s is required", constants.EnvKeyTokenSecret)
+	}
+
+	cfg.Auth.AdminAPIKey = getEnv(constants.EnvKeyAdminAPIKey, "")
+
+	cfg.Subscriptions.ConfirmationTTL = getEnvDuration(constants.EnvKeySubscriptionConfirmationTTL, constants.DefaultSubscriptionConfirmationTTL)
 
 	cfg.Scheduler.Interval = getEnv(constants.EnvKeySchedulerInterval, constants.DefaultSchedulerInterval)
 	cfg.Scheduler.BatchSize = getEnvInt(constants.EnvKeySchedulerBatchSize, constants.DefaultSchedulerBatchSize)
+	cfg.Scheduler.Lookahead = getEnvDuration(constants.EnvKeySchedulerLookahead, constants.DefaultSchedulerLookahead)
+	cfg.Scheduler.VisibilityTimeout = getEnvDuration(constants.EnvKeySchedulerVisibilityTimeout, constants.DefaultSchedulerVisibilityTimeout)
+
+	cfg.Worker.Concurrency = getEnvInt(constants.EnvKeyWorkerConcurrency, constants.DefaultWorkerConcurrency)
+	cfg.Worker.AudienceBatchSize = getEnvInt(constants.EnvKeyWorkerAudienceBatchSize, constants.DefaultWorkerAudienceBatchSize)
+	cfg.Worker.SendFailureThreshold = getEnvFloat(constants.EnvKeySendFailureThreshold, constants.DefaultSendFailureThreshold)
+	cfg.Worker.HeartbeatInterval = getEnvDuration(constants.EnvKeyWorkerHeartbeatInterval, constants.DefaultWorkerHeartbeatInterval)
+
+	cfg.DeliveryRetry.MaxAttempts = getEnvInt(constants.EnvKeyDeliveryRetryMaxAttempts, constants.DefaultDeliveryRetryMaxAttempts)
+	cfg.DeliveryRetry.BaseDelay = getEnvDuration(constants.EnvKeyDeliveryRetryBaseDelay, constants.DefaultDeliveryRetryBaseDelay)
+	cfg.DeliveryRetry.MaxDelay = getEnvDuration(constants.EnvKeyDeliveryRetryMaxDelay, constants.DefaultDeliveryRetryMaxDelay)
 
 	cfg.Asynq.RedisAddr = getEnv(constants.EnvKeyAsynqRedisAddr, constants.DefaultRedisHost+":"+constants.DefaultRedisPort)
 	cfg.Asynq.RedisPassword = getEnv(constants.EnvKeyAsynqRedisPassword, "")
@@ -121,6 +275,56 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if durationValue, err := time.ParseDuration(value); err == nil {
+			return durationValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList reads a comma-separated list, lowercasing entries and dropping blank ones
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// parseProviderWeights parses "name=weight" pairs such as "brevo=80,ses=20"
+func parseProviderWeights(value string) (map[string]int, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	weights := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		name, weight, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("expected name=weight, got %q", pair)
+		}
+		weightValue, err := strconv.Atoi(strings.TrimSpace(weight))
+		if err != nil || weightValue < 0 {
+			return nil, fmt.Errorf("weight of %q must be a non-negative integer", name)
+		}
+		weights[strings.ToLower(strings.TrimSpace(name))] = weightValue
+	}
+	return weights, nil
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
@@ -1,5 +1,7 @@
 package constants
 
+import "time"
+
 // Environment constants
 const (
 	EnvDevelopment = "development"
@@ -34,6 +36,33 @@ const (
 	DefaultSMTPPort      = "587"
 	DefaultSMTPFromEmail = "noreply@yourapp.com"
 	DefaultSMTPFromName  = "Newsletter App"
+
+	DefaultSMTPTLSMode                  = SMTPTLSModeStartTLS
+	DefaultSMTPAuthMechanism            = SMTPAuthPlain
+	DefaultSMTPPoolSize                 = 5
+	DefaultSMTPPoolIdleTimeout          = 30 * time.Second
+	DefaultSMTPMaxMessagesPerConnection = 100
+	DefaultSMTPTimeout                  = 60 * time.Second
+)
+
+// SMTP TLS modes
+const (
+	SMTPTLSModeStartTLS = "starttls"
+	SMTPTLSModeImplicit = "implicit"
+	SMTPTLSModeNone     = "none"
+)
+
+// SMTP authentication mechanisms
+const (
+	SMTPAuthPlain   = "plain"
+	SMTPAuthLogin   = "login"
+	SMTPAuthCRAMMD5 = "cram-md5"
+)
+
+// DKIM signing algorithms (RFC 6376, RFC 8463)
+const (
+	DKIMAlgorithmRSASHA256     = "rsa-sha256"
+	DKIMAlgorithmEd25519SHA256 = "ed25519-sha256"
 )
 
 // Email HTTP API configuration defaults
@@ -42,36 +71,199 @@ const (
 	DefaultEmailUseHTTP    = true
 )
 
+// Email providers
+const (
+	EmailProviderSMTP     = "smtp"
+	EmailProviderBrevo    = "brevo"
+	EmailProviderSES      = "ses"
+	EmailProviderSendGrid = "sendgrid"
+	EmailProviderMailgun  = "mailgun"
+	EmailProviderPostmark = "postmark"
+)
+
+// Email provider API defaults
+const (
+	DefaultSESRegion             = "us-east-1"
+	DefaultSendGridBaseURL       = "https://api.sendgrid.com"
+	DefaultMailgunBaseURL        = "https://api.mailgun.net"
+	DefaultPostmarkBaseURL       = "https://api.postmarkapp.com"
+	DefaultPostmarkMessageStream = "outbound"
+
+	// BrevoMaxMessageVersions is the number of personalised versions Brevo accepts per request
+	BrevoMaxMessageVersions = 1000
+)
+
+// Email provider circuit breaker defaults
+const (
+	DefaultEmailCircuitFailureThreshold = 5
+	DefaultEmailCircuitCooldown         = 30 * time.Second
+)
+
+// Email provider circuit breaker states
+const (
+	CircuitStateClosed   = "closed"
+	CircuitStateOpen     = "open"
+	CircuitStateHalfOpen = "half_open"
+)
+
+// Public link defaults
+const (
+	DefaultPublicBaseURL = "http://localhost:8080"
+)
+
 // Content status constants
 const (
-	ContentStatusScheduled = "scheduled"
-	ContentStatusSent      = "sent"
-	ContentStatusFailed    = "failed"
-	ContentStatusCancelled = "cancelled"
+	ContentStatusScheduled     = "scheduled"
+	ContentStatusSent          = "sent"
+	ContentStatusPartiallySent = "partially_sent"
+	ContentStatusFailed        = "failed"
+	ContentStatusCancelled     = "cancelled"
+)
+
+// Content delivery modes
+const (
+	DeliveryModeAbsolute  = "absolute"
+	DeliveryModeLocalTime = "local_time"
 )
 
 // Delivery status constants
 const (
-	DeliveryStatusPending = "pending"
-	DeliveryStatusSent    = "sent"
-	DeliveryStatusFailed  = "failed"
-	DeliveryStatusBounced = "bounced"
+	DeliveryStatusPending       = "pending"
+	DeliveryStatusSent          = "sent"
+	DeliveryStatusFailed        = "failed"
+	DeliveryStatusUndeliverable = "undeliverable"
+	DeliveryStatusBounced       = "bounced"
+	DeliveryStatusSuppressed    = "suppressed"
+)
+
+// DeliveryStatuses lists every delivery status, in lifecycle order
+var DeliveryStatuses = []string{
+	DeliveryStatusPending,
+	DeliveryStatusSent,
+	DeliveryStatusFailed,
+	DeliveryStatusUndeliverable,
+	DeliveryStatusBounced,
+	DeliveryStatusSuppressed,
+}
+
+// Delivery report and listing limits
+const (
+	DefaultDeliveryPageSize         = 50
+	MaxDeliveryPageSize             = 500
+	DeliveryReportMaxFailureReasons = 20
+)
+
+// Delivery engagement event types
+const (
+	DeliveryEventOpen  = "open"
+	DeliveryEventClick = "click"
 )
 
+// MaxTrackedUserAgentLength caps the user agent stored with opens and clicks
+const MaxTrackedUserAgentLength = 512
+
 // Job status constants
 const (
 	JobStatusPending   = "pending"
 	JobStatusEnqueued  = "enqueued"
 	JobStatusCompleted = "completed"
 	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+// JobStatuses lists every job status
+var JobStatuses = []string{
+	JobStatusPending,
+	JobStatusEnqueued,
+	JobStatusCompleted,
+	JobStatusFailed,
+	JobStatusCancelled,
+}
+
+// Job audit actions
+const (
+	JobAuditActionRetry  = "retry"
+	JobAuditActionFail   = "fail"
+	JobAuditActionDelete = "delete"
+)
+
+// Subscription status constants
+const (
+	SubscriptionStatusActive              = "active"
+	SubscriptionStatusPendingConfirmation = "pending_confirmation"
+	SubscriptionStatusExpired             = "expired"
+	SubscriptionStatusUnsubscribed        = "unsubscribed"
+)
+
+// Suppression reasons
+const (
+	SuppressionReasonHardBounce  = "hard_bounce"
+	SuppressionReasonComplaint   = "complaint"
+	SuppressionReasonUnsubscribe = "unsubscribe"
+	SuppressionReasonManual      = "manual"
+)
+
+// Suppression sources
+const (
+	SuppressionSourceAPI      = "api"
+	SuppressionSourceImport   = "import"
+	SuppressionSourceProvider = "provider"
+)
+
+// Brevo transactional webhook events
+const (
+	BrevoEventDelivered    = "delivered"
+	BrevoEventHardBounce   = "hard_bounce"
+	BrevoEventSoftBounce   = "soft_bounce"
+	BrevoEventSpam         = "spam"
+	BrevoEventUnsubscribed = "unsubscribed"
+	BrevoEventOpened       = "opened"
+	BrevoEventClick        = "click"
+)
+
+// Recurring schedule status constants
+const (
+	RecurringScheduleStatusActive = "active"
+	RecurringScheduleStatusPaused = "paused"
+)
+
+// API key roles, from least to most privileged
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+// API key settings
+const (
+	APIKeyPrefix        = "nlk_"
+	APIKeyDisplayLength = 12
+	APIKeyBytes         = 32
+	MinAPIKeyLength     = 32
+	ContextKeyAPIKey    = "api_key"
+)
+
+// Workspace settings. The default workspace holds data created before workspaces existed.
+const (
+	DefaultWorkspaceID     = "00000000-0000-0000-0000-000000000001"
+	WorkspaceAdminKeyName  = "workspace admin"
+	MaxWorkspaceNameLength = 255
+	MaxWorkspaceSlugLength = 64
 )
 
 // Job types
 const (
 	JobTypeSendNewsletter = "send_newsletter"
+	JobTypeRetryDelivery  = "retry_delivery"
 	JobTypeCleanupOldJobs = "cleanup_old_jobs"
 )
 
+// JobTypes lists the job types stored in the job scheduler
+var JobTypes = []string{
+	JobTypeSendNewsletter,
+	JobTypeRetryDelivery,
+}
+
 // Pagination defaults
 const (
 	DefaultLimit  = 10
@@ -100,10 +292,50 @@ const (
 	DefaultJobLimit    = 100
 )
 
+// Worker settings
+const (
+	DefaultWorkerConcurrency       = 20
+	DefaultWorkerAudienceBatchSize = 1000
+	DefaultSendFailureThreshold    = 0.5
+	DefaultWorkerHeartbeatInterval = 30 * time.Second
+)
+
+// Delivery retry settings
+const (
+	DefaultDeliveryRetryMaxAttempts = 5
+	DefaultDeliveryRetryBaseDelay   = time.Minute
+	DefaultDeliveryRetryMaxDelay    = time.Hour
+	// DeliveryClaimLease is how long a claimed delivery stays reserved for the run
+	// that claimed it before another run may take it over
+	DeliveryClaimLease = 5 * time.Minute
+)
+
 // Scheduler settings
 const (
 	DefaultSchedulerInterval  = "30s"
 	DefaultSchedulerBatchSize = 100
+	DefaultSchedulerLookahead = time.Hour
+
+	// DefaultSchedulerVisibilityTimeout is how long an enqueued job may go without
+	// progress or heartbeat before the scheduler checks its task
+	DefaultSchedulerVisibilityTimeout = 15 * time.Minute
+)
+
+// Suppression settings
+const (
+	MaxSuppressionImportSize = 1000
+)
+
+// Subscription confirmation settings
+const (
+	DefaultSubscriptionConfirmationTTL = 48 * time.Hour
+)
+
+// Recurring schedule settings
+const (
+	DefaultScheduleTimezone     = "UTC"
+	DefaultSchedulePreviewCount = 5
+	MaxSchedulePreviewCount     = 50
 )
 
 // Environment variable keys
@@ -138,6 +370,20 @@ const (
 	EnvKeySMTPPassword  = "SMTP_PASSWORD"
 	EnvKeySMTPFromEmail = "SMTP_FROM_EMAIL"
 	EnvKeySMTPFromName  = "SMTP_FROM_NAME"
+
+	EnvKeySMTPTLSMode                  = "SMTP_TLS_MODE"
+	EnvKeySMTPAuthMechanism            = "SMTP_AUTH_MECHANISM"
+	EnvKeySMTPPoolSize                 = "SMTP_POOL_SIZE"
+	EnvKeySMTPPoolIdleTimeout          = "SMTP_POOL_IDLE_TIMEOUT"
+	EnvKeySMTPMaxMessagesPerConnection = "SMTP_MAX_MESSAGES_PER_CONNECTION"
+)
+
+// DKIM environment variable keys
+const (
+	EnvKeyDKIMDomain         = "DKIM_DOMAIN"
+	EnvKeyDKIMSelector       = "DKIM_SELECTOR"
+	EnvKeyDKIMPrivateKey     = "DKIM_PRIVATE_KEY"
+	EnvKeyDKIMPrivateKeyFile = "DKIM_PRIVATE_KEY_FILE"
 )
 
 // Email HTTP API environment variable keys
@@ -145,12 +391,73 @@ const (
 	EnvKeyEmailAPIKey     = "EMAIL_API_KEY"
 	EnvKeyEmailAPIBaseURL = "EMAIL_API_BASE_URL"
 	EnvKeyEmailUseHTTP    = "EMAIL_USE_HTTP"
+
+	EnvKeyEmailWebhookSecret = "EMAIL_WEBHOOK_SECRET"
+	EnvKeyEmailProvider      = "EMAIL_PROVIDER"
+
+	EnvKeyEmailFailoverProviders       = "EMAIL_FAILOVER_PROVIDERS"
+	EnvKeyEmailProviderWeights         = "EMAIL_PROVIDER_WEIGHTS"
+	EnvKeyEmailCircuitFailureThreshold = "EMAIL_CIRCUIT_FAILURE_THRESHOLD"
+	EnvKeyEmailCircuitCooldown         = "EMAIL_CIRCUIT_COOLDOWN"
+)
+
+// Email provider API environment variable keys
+const (
+	EnvKeySESRegion          = "SES_REGION"
+	EnvKeySESAccessKeyID     = "SES_ACCESS_KEY_ID"
+	EnvKeySESSecretAccessKey = "SES_SECRET_ACCESS_KEY"
+	EnvKeySESBaseURL         = "SES_BASE_URL"
+
+	EnvKeySendGridAPIKey  = "SENDGRID_API_KEY"
+	EnvKeySendGridBaseURL = "SENDGRID_BASE_URL"
+
+	EnvKeyMailgunAPIKey  = "MAILGUN_API_KEY"
+	EnvKeyMailgunDomain  = "MAILGUN_DOMAIN"
+	EnvKeyMailgunBaseURL = "MAILGUN_BASE_URL"
+
+	EnvKeyPostmarkServerToken   = "POSTMARK_SERVER_TOKEN"
+	EnvKeyPostmarkMessageStream = "POSTMARK_MESSAGE_STREAM"
+	EnvKeyPostmarkBaseURL       = "POSTMARK_BASE_URL"
+)
+
+// Public link environment variable keys
+const (
+	EnvKeyPublicBaseURL = "PUBLIC_BASE_URL"
+	EnvKeyTokenSecret   = "TOKEN_SECRET"
+)
+
+// Auth environment variable keys
+const (
+	EnvKeyAdminAPIKey = "ADMIN_API_KEY"
+)
+
+// Subscription environment variable keys
+const (
+	EnvKeySubscriptionConfirmationTTL = "SUBSCRIPTION_CONFIRMATION_TTL"
 )
 
 // Scheduler environment variable keys
 const (
 	EnvKeySchedulerInterval  = "SCHEDULER_INTERVAL"
 	EnvKeySchedulerBatchSize = "SCHEDULER_BATCH_SIZE"
+	EnvKeySchedulerLookahead = "SCHEDULER_LOOKAHEAD"
+
+	EnvKeySchedulerVisibilityTimeout = "SCHEDULER_VISIBILITY_TIMEOUT"
+)
+
+// Worker environment variable keys
+const (
+	EnvKeyWorkerConcurrency       = "WORKER_CONCURRENCY"
+	EnvKeyWorkerAudienceBatchSize = "WORKER_AUDIENCE_BATCH_SIZE"
+	EnvKeySendFailureThreshold    = "SEND_FAILURE_THRESHOLD"
+	EnvKeyWorkerHeartbeatInterval = "WORKER_HEARTBEAT_INTERVAL"
+)
+
+// Delivery retry environment variable keys
+const (
+	EnvKeyDeliveryRetryMaxAttempts = "DELIVERY_RETRY_MAX_ATTEMPTS"
+	EnvKeyDeliveryRetryBaseDelay   = "DELIVERY_RETRY_BASE_DELAY"
+	EnvKeyDeliveryRetryMaxDelay    = "DELIVERY_RETRY_MAX_DELAY"
 )
 
 // Asynq environment variable keys
@@ -0,0 +1,21 @@
+package email
+
+// BatchSender is implemented by senders that can mail many personalised messages in
+// one provider call. All requests of a batch must share the same sender identity.
+type BatchSender interface {
+	EmailSender
+
+	// MaxBatchSize is the largest number of requests SendBatch accepts; values below 2
+	// mean batching is not available
+	MaxBatchSize() int
+
+	// SendBatch sends the requests and returns one result per request, in request order.
+	// An error means the whole batch failed and none of the emails were accepted.
+	SendBatch(reqs []*EmailRequest) ([]BatchResult, error)
+}
+
+// BatchResult is the outcome of one request of a batch
+type BatchResult struct {
+	Result *SendResult
+	Err    error
+}
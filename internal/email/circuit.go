@@ -0,0 +1,111 @@
+package email
+
+import (
+	"sync"
+	"time"
+
+	"newsletter-assignment/internal/constants"
+)
+
+// circuitBreaker stops traffic to a provider after consecutive transient failures.
+// Once the cooldown has passed it lets a single trial send through (half-open):
+// success closes the circuit again, failure reopens it.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+
+	state    string
+	failures int
+	openedAt time.Time
+	trialing bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		threshold = constants.DefaultEmailCircuitFailureThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = constants.DefaultEmailCircuitCooldown
+	}
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     constants.CircuitStateClosed,
+	}
+}
+
+// available reports whether allow would currently let a send through, without claiming it
+func (b *circuitBreaker) available(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case constants.CircuitStateOpen:
+		return now.Sub(b.openedAt) >= b.cooldown
+	case constants.CircuitStateHalfOpen:
+		return !b.trialing
+	default:
+		return true
+	}
+}
+
+// allow reports whether a send may go through, claiming the trial send of a half-open circuit
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case constants.CircuitStateOpen:
+		if now.Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = constants.CircuitStateHalfOpen
+		b.trialing = true
+		return true
+	case constants.CircuitStateHalfOpen:
+		if b.trialing {
+			return false
+		}
+		b.trialing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// recordSuccess closes the circuit; the provider answered, even if it rejected the email
+func (b *circuitBreaker) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = constants.CircuitStateClosed
+	b.failures = 0
+	b.trialing = false
+}
+
+// recordFailure counts a transient failure and opens the circuit once the threshold is
+// reached, or immediately when the half-open trial failed
+func (b *circuitBreaker) recordFailure(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trialing = false
+	if b.state == constants.CircuitStateHalfOpen || b.failures >= b.threshold {
+		b.state = constants.CircuitStateOpen
+		b.openedAt = now
+	}
+}
+
+// snapshot returns the state, consecutive failure count and, when open, the time the circuit opened
+func (b *circuitBreaker) snapshot() (string, int, *time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == constants.CircuitStateClosed {
+		return b.state, b.failures, nil
+	}
+	openedAt := b.openedAt
+	return b.state, b.failures, &openedAt
+}
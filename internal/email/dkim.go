@@ -0,0 +1,202 @@
+package email
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"newsletter-assignment/internal/constants"
+)
+
+// dkimSignedHeaders are the header fields covered by the signature when present
+var dkimSignedHeaders = []string{
+	"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type",
+	"List-Unsubscribe", "List-Unsubscribe-Post",
+}
+
+// dkimSigner adds DKIM-Signature headers (RFC 6376) using relaxed/relaxed
+// canonicalisation and either rsa-sha256 or ed25519-sha256 (RFC 8463)
+type dkimSigner struct {
+	domain    string
+	selector  string
+	algorithm string
+	key       crypto.Signer
+}
+
+// newDKIMSigner parses a PEM encoded RSA or Ed25519 private key
+func newDKIMSigner(domain, selector, privateKey string) (*dkimSigner, error) {
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return nil, fmt.Errorf("DKIM private key is not PEM encoded")
+	}
+
+	var parsed any
+	var err error
+	if block.Type == "RSA PRIVATE KEY" {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DKIM private key: %w", err)
+	}
+
+	signer := &dkimSigner{domain: domain, selector: selector}
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		signer.algorithm = constants.DKIMAlgorithmRSASHA256
+		signer.key = key
+	case ed25519.PrivateKey:
+		signer.algorithm = constants.DKIMAlgorithmEd25519SHA256
+		signer.key = key
+	default:
+		return nil, fmt.Errorf("unsupported DKIM private key type %T", parsed)
+	}
+	return signer, nil
+}
+
+// sign returns the message with a DKIM-Signature header prepended
+func (s *dkimSigner) sign(message []byte, now time.Time) ([]byte, error) {
+	headerBlock, body, found := bytes.Cut(message, []byte("\r\n\r\n"))
+	if !found {
+		return nil, fmt.Errorf("message has no header/body separator")
+	}
+	fields := splitHeaderFields(string(headerBlock) + "\r\n")
+
+	bodyHash := sha256.Sum256(relaxedBody(body))
+
+	// Each signed header is looked up once, bottom-up, as verifiers do
+	var signed []string
+	var canonical strings.Builder
+	for _, name := range dkimSignedHeaders {
+		for i := len(fields) - 1; i >= 0; i-- {
+			if strings.EqualFold(headerFieldName(fields[i]), name) {
+				signed = append(signed, strings.ToLower(name))
+				canonical.WriteString(relaxedHeader(fields[i]))
+				break
+			}
+		}
+	}
+
+	value := fmt.Sprintf("v=1; a=%s; c=relaxed/relaxed; d=%s; s=%s; t=%d; h=%s; bh=%s; b=",
+		s.algorithm, s.domain, s.selector, now.Unix(), strings.Join(signed, ":"),
+		base64.StdEncoding.EncodeToString(bodyHash[:]))
+	// The signature header itself is hashed with an empty b= and no trailing CRLF
+	canonical.WriteString(strings.TrimSuffix(relaxedHeader("DKIM-Signature: "+value), "\r\n"))
+
+	digest := sha256.Sum256([]byte(canonical.String()))
+	var signature []byte
+	var err error
+	if s.algorithm == constants.DKIMAlgorithmEd25519SHA256 {
+		signature, err = s.key.Sign(rand.Reader, digest[:], crypto.Hash(0))
+	} else {
+		signature, err = s.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DKIM signature: %w", err)
+	}
+
+	var out bytes.Buffer
+	out.WriteString(foldDKIMHeader(value, base64.StdEncoding.EncodeToString(signature)))
+	out.Write(message)
+	return out.Bytes(), nil
+}
+
+// splitHeaderFields splits a CRLF terminated header block into fields, keeping
+// folded continuation lines with the field they belong to
+func splitHeaderFields(headerBlock string) []string {
+	var fields []string
+	for _, line := range strings.SplitAfter(headerBlock, "\r\n") {
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(fields) > 0 {
+			fields[len(fields)-1] += line
+			continue
+		}
+		fields = append(fields, line)
+	}
+	return fields
+}
+
+func headerFieldName(field string) string {
+	name, _, _ := strings.Cut(field, ":")
+	return strings.TrimSpace(name)
+}
+
+// relaxedHeader canonicalises a header field (RFC 6376 section 3.4.2)
+func relaxedHeader(field string) string {
+	name, value, _ := strings.Cut(field, ":")
+	value = strings.NewReplacer("\r\n", "", "\r", "", "\n", "").Replace(value)
+	return strings.ToLower(strings.TrimSpace(name)) + ":" + strings.TrimSpace(collapseWhitespace(value)) + "\r\n"
+}
+
+// relaxedBody canonicalises a message body (RFC 6376 section 3.4.4)
+func relaxedBody(body []byte) []byte {
+	lines := strings.Split(string(body), "\r\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(collapseWhitespace(line), " ")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+// collapseWhitespace reduces runs of spaces and tabs to a single space
+func collapseWhitespace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+// foldDKIMHeader renders the DKIM-Signature header, folding between tags and
+// splitting the signature, whose whitespace verifiers ignore
+func foldDKIMHeader(value, signature string) string {
+	var b strings.Builder
+	lineLen := len("DKIM-Signature:")
+	b.WriteString("DKIM-Signature:")
+	for _, tag := range strings.Split(value, " ") {
+		if lineLen+1+len(tag) > maxHeaderLineLength {
+			b.WriteString("\r\n")
+			lineLen = 0
+		}
+		b.WriteString(" " + tag)
+		lineLen += 1 + len(tag)
+	}
+
+	const chunk = 72
+	for len(signature) > 0 {
+		n := min(chunk, len(signature))
+		b.WriteString("\r\n " + signature[:n])
+		signature = signature[n:]
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
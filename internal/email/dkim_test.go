@@ -0,0 +1,356 @@
+package email
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The verifier below follows RFC 6376 section 6 on its own and shares no code with
+// the signer, so that a canonicalisation mistake cannot cancel itself out.
+
+var (
+	wspRun         = regexp.MustCompile(`[ \t]+`)
+	trailingWSP    = regexp.MustCompile(`[ \t]+$`)
+	signatureValue = regexp.MustCompile(`(^|;)([ \t\r\n]*b[ \t\r\n]*=)[^;]*`)
+)
+
+// verifyDKIM checks the first DKIM-Signature of a message against the public key
+func verifyDKIM(message []byte, publicKey crypto.PublicKey) error {
+	head, body, ok := bytes.Cut(message, []byte("\r\n\r\n"))
+	if !ok {
+		return fmt.Errorf("no end of headers")
+	}
+
+	// Collect raw header fields, continuation lines included
+	var fields []string
+	for _, line := range strings.Split(string(head), "\r\n") {
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			fields[len(fields)-1] += "\r\n" + line
+			continue
+		}
+		fields = append(fields, line)
+	}
+
+	sigIndex := -1
+	for i, field := range fields {
+		if strings.HasPrefix(strings.ToLower(field), "dkim-signature:") {
+			sigIndex = i
+			break
+		}
+	}
+	if sigIndex < 0 {
+		return fmt.Errorf("no DKIM-Signature header")
+	}
+	sigField := fields[sigIndex]
+	tags, err := parseTagList(sigField[len("DKIM-Signature:"):])
+	if err != nil {
+		return err
+	}
+
+	if tags["v"] != "1" || tags["c"] != "relaxed/relaxed" {
+		return fmt.Errorf("unexpected v=%q c=%q", tags["v"], tags["c"])
+	}
+
+	bodyHash := sha256.Sum256(canonicalBody(body))
+	if got := base64.StdEncoding.EncodeToString(bodyHash[:]); got != tags["bh"] {
+		return fmt.Errorf("body hash mismatch: computed %s, signed %s", got, tags["bh"])
+	}
+
+	// Header fields are taken bottom-up; a name listed twice takes the next one up
+	var data strings.Builder
+	used := make(map[int]bool)
+	for _, name := range strings.Split(tags["h"], ":") {
+		for i := len(fields) - 1; i >= 0; i-- {
+			fieldName, _, _ := strings.Cut(fields[i], ":")
+			if i != sigIndex && !used[i] && strings.EqualFold(strings.TrimSpace(fieldName), strings.TrimSpace(name)) {
+				used[i] = true
+				data.WriteString(canonicalHeader(fields[i]) + "\r\n")
+				break
+			}
+		}
+	}
+	data.WriteString(canonicalHeader(signatureValue.ReplaceAllString(sigField, "${1}${2}")))
+
+	signature, err := base64.StdEncoding.DecodeString(tags["b"])
+	if err != nil {
+		return fmt.Errorf("bad b= tag: %w", err)
+	}
+	digest := sha256.Sum256([]byte(data.String()))
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		if tags["a"] != "rsa-sha256" {
+			return fmt.Errorf("a=%q for an RSA key", tags["a"])
+		}
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+	case ed25519.PublicKey:
+		if tags["a"] != "ed25519-sha256" {
+			return fmt.Errorf("a=%q for an Ed25519 key", tags["a"])
+		}
+		// RFC 8463: the Ed25519 signature is over the SHA-256 hash
+		if !ed25519.Verify(key, digest[:], signature) {
+			return fmt.Errorf("ed25519 signature does not verify")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported key %T", publicKey)
+}
+
+// parseTagList parses a DKIM tag list, dropping whitespace from the base64 tags
+func parseTagList(value string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, spec := range strings.Split(value, ";") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		name, tagValue, ok := strings.Cut(spec, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed tag %q", spec)
+		}
+		name = strings.TrimSpace(name)
+		tagValue = strings.Join(strings.Fields(tagValue), "")
+		tags[name] = tagValue
+	}
+	return tags, nil
+}
+
+// canonicalHeader applies the relaxed header algorithm (RFC 6376 section 3.4.2)
+// to a field without its terminating CRLF
+func canonicalHeader(field string) string {
+	name, value, _ := strings.Cut(field, ":")
+	value = strings.ReplaceAll(value, "\r\n", "")
+	value = wspRun.ReplaceAllString(value, " ")
+	return strings.ToLower(strings.TrimRight(name, " \t")) + ":" + strings.Trim(value, " ")
+}
+
+// canonicalBody applies the relaxed body algorithm (RFC 6376 section 3.4.4)
+func canonicalBody(body []byte) []byte {
+	lines := strings.Split(string(body), "\r\n")
+	for i, line := range lines {
+		lines[i] = trailingWSP.ReplaceAllString(wspRun.ReplaceAllString(line, " "), "")
+	}
+	end := len(lines)
+	for end > 0 && lines[end-1] == "" {
+		end--
+	}
+	if end == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines[:end], "\r\n") + "\r\n")
+}
+
+func pemEncode(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+	return string(pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}))
+}
+
+// testSigners returns a signer for each supported key encoding with its public key
+func testSigners(t *testing.T) map[string]struct {
+	signer *dkimSigner
+	public crypto.PublicKey
+} {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPKCS8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPKCS8, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := map[string]struct {
+		pem    string
+		public crypto.PublicKey
+	}{
+		"rsa-pkcs1": {pemEncode(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)), &rsaKey.PublicKey},
+		"rsa-pkcs8": {pemEncode(t, "PRIVATE KEY", rsaPKCS8), &rsaKey.PublicKey},
+		"ed25519":   {pemEncode(t, "PRIVATE KEY", edPKCS8), edPublic},
+	}
+
+	signers := make(map[string]struct {
+		signer *dkimSigner
+		public crypto.PublicKey
+	})
+	for name, key := range keys {
+		signer, err := newDKIMSigner("example.com", "mail", key.pem)
+		if err != nil {
+			t.Fatalf("%s: newDKIMSigner: %v", name, err)
+		}
+		signers[name] = struct {
+			signer *dkimSigner
+			public crypto.PublicKey
+		}{signer, key.public}
+	}
+	return signers
+}
+
+func testMessage(t *testing.T) []byte {
+	t.Helper()
+	message, _, err := buildMIMEMessage(&EmailRequest{
+		To:                 "jane@example.org",
+		Subject:            "Weekly digest: " + strings.Repeat("a long subject that has to be folded ", 4),
+		TextBody:           "Hello Jane,\r\n\r\nHere is the news.  \r\n",
+		HTMLBody:           "<p>Hello Jane,</p>\n<p>Here is the news.</p>",
+		ListUnsubscribeURL: "https://newsletter.example.com/unsubscribe?token=abc",
+	}, "news@example.com", "Example News", time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return message
+}
+
+func TestDKIMSignVerifies(t *testing.T) {
+	message := testMessage(t)
+	if !bytes.Contains(message, []byte("\r\n ")) {
+		t.Fatal("test message has no folded header")
+	}
+
+	for name, s := range testSigners(t) {
+		t.Run(name, func(t *testing.T) {
+			signed, err := s.signer.sign(message, time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC))
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+			if !bytes.HasSuffix(signed, message) {
+				t.Fatal("signing changed the message")
+			}
+			if err := verifyDKIM(signed, s.public); err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+
+			for _, line := range strings.Split(string(signed), "\r\n") {
+				if len(line) > 998 {
+					t.Fatalf("line of %d characters", len(line))
+				}
+			}
+
+			tags, err := parseTagList(strings.SplitN(string(signed), ":", 2)[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tags["d"] != "example.com" || tags["s"] != "mail" || tags["t"] != "1772352000" {
+				t.Errorf("unexpected tags d=%q s=%q t=%q", tags["d"], tags["s"], tags["t"])
+			}
+			for _, header := range []string{"from", "to", "subject", "date", "message-id", "list-unsubscribe"} {
+				if !strings.Contains(":"+tags["h"]+":", ":"+header+":") {
+					t.Errorf("h=%q does not sign %s", tags["h"], header)
+				}
+			}
+		})
+	}
+}
+
+func TestDKIMSurvivesRelaxedChanges(t *testing.T) {
+	// Changes relaxed canonicalisation tolerates, as made by relays along the way
+	changes := map[string]func(string) string{
+		"header name case": func(m string) string {
+			return strings.Replace(m, "\r\nSubject:", "\r\nSUBJECT:", 1)
+		},
+		"header whitespace": func(m string) string {
+			return strings.Replace(m, "\r\nTo: ", "\r\nTo:   \t ", 1)
+		},
+		"unfolded header": func(m string) string {
+			head, body, _ := strings.Cut(m, "\r\n\r\n")
+			sig, rest, _ := strings.Cut(head, "\r\nFrom:")
+			return sig + "\r\nFrom:" + strings.ReplaceAll(rest, "\r\n ", " ") + "\r\n\r\n" + body
+		},
+		"refolded header": func(m string) string {
+			return strings.Replace(m, "\r\nTo: <jane@example.org>", "\r\nTo:\r\n\t<jane@example.org>", 1)
+		},
+		"trailing whitespace in body": func(m string) string {
+			return strings.Replace(m, "Hello Jane,\r\n", "Hello Jane, \t \r\n", 1)
+		},
+		"trailing empty lines": func(m string) string {
+			return m + "\r\n\r\n"
+		},
+	}
+
+	message := testMessage(t)
+	for name, s := range testSigners(t) {
+		signed, err := s.signer.sign(message, time.Now())
+		if err != nil {
+			t.Fatalf("%s: sign: %v", name, err)
+		}
+
+		for change, apply := range changes {
+			t.Run(name+"/"+change, func(t *testing.T) {
+				changed := apply(string(signed))
+				if changed == string(signed) {
+					t.Fatal("change did not apply")
+				}
+				if err := verifyDKIM([]byte(changed), s.public); err != nil {
+					t.Fatalf("verify: %v", err)
+				}
+			})
+		}
+
+		t.Run(name+"/tampered", func(t *testing.T) {
+			tampered := strings.Replace(string(signed), "Here is the news.", "Here is the new.", 1)
+			if err := verifyDKIM([]byte(tampered), s.public); err == nil {
+				t.Fatal("tampered body verified")
+			}
+			tampered = strings.Replace(string(signed), "\r\nTo: <jane@example.org>", "\r\nTo: <joe@example.org>", 1)
+			if err := verifyDKIM([]byte(tampered), s.public); err == nil {
+				t.Fatal("tampered header verified")
+			}
+		})
+	}
+}
+
+// TestDKIMCanonicalization checks the example of RFC 6376 section 3.4.5
+func TestDKIMCanonicalization(t *testing.T) {
+	fields := splitHeaderFields("A: X\r\nB : Y\t\r\n\tZ  \r\n")
+	if len(fields) != 2 {
+		t.Fatalf("got %d header fields, want 2", len(fields))
+	}
+
+	var headers string
+	for _, field := range fields {
+		headers += relaxedHeader(field)
+	}
+	if want := "a:X\r\nb:Y Z\r\n"; headers != want {
+		t.Errorf("relaxed headers = %q, want %q", headers, want)
+	}
+
+	body := relaxedBody([]byte(" C \r\nD \t E\r\n\r\n\r\n"))
+	if want := " C\r\nD E\r\n"; string(body) != want {
+		t.Errorf("relaxed body = %q, want %q", body, want)
+	}
+
+	if body := relaxedBody([]byte("\r\n\r\n")); len(body) != 0 {
+		t.Errorf("relaxed empty body = %q, want empty", body)
+	}
+}
+
+func TestNewDKIMSignerRejectsBadKeys(t *testing.T) {
+	if _, err := newDKIMSigner("example.com", "mail", "not a key"); err == nil {
+		t.Error("accepted a key that is not PEM")
+	}
+
+	block := pemEncode(t, "PRIVATE KEY", []byte("garbage"))
+	if _, err := newDKIMSigner("example.com", "mail", block); err == nil {
+		t.Error("accepted a malformed key")
+	}
+}
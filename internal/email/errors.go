@@ -0,0 +1,72 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+)
+
+// errOutcomeUnknown marks failures after the message data went out, when the server
+// may have accepted the message without confirming it
+var errOutcomeUnknown = errors.New("message data was sent but not confirmed")
+
+// SendError describes a failed send and whether retrying it could succeed
+type SendError struct {
+	Permanent bool
+	// OutcomeUnknown is set when the provider may have accepted the message before
+	// the send failed. Sending it again could deliver it twice, so it is not retried.
+	OutcomeUnknown bool
+	Err            error
+}
+
+func (e *SendError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
+// IsPermanent reports whether a send error will not succeed on retry.
+// Errors that were not classified are treated as transient.
+func IsPermanent(err error) bool {
+	var sendErr *SendError
+	if errors.As(err, &sendErr) {
+		return sendErr.Permanent
+	}
+	return false
+}
+
+// IsOutcomeUnknown reports whether a failed send may still have been delivered
+func IsOutcomeUnknown(err error) bool {
+	var sendErr *SendError
+	if errors.As(err, &sendErr) {
+		return sendErr.OutcomeUnknown
+	}
+	return false
+}
+
+// classifySMTPError marks SMTP 5xx replies as permanent, failures after the message
+// data went out as outcome unknown and everything else (4xx replies, network and
+// TLS errors) as transient
+func classifySMTPError(err error) error {
+	if errors.Is(err, errOutcomeUnknown) {
+		return &SendError{OutcomeUnknown: true, Err: err}
+	}
+
+	var protoErr *textproto.Error
+	permanent := errors.As(err, &protoErr) && protoErr.Code >= 500 && protoErr.Code < 600
+	return &SendError{Permanent: permanent, Err: err}
+}
+
+// classifyHTTPStatus marks 429 and 5xx responses as transient and other
+// non-2xx responses as permanent
+func classifyHTTPStatus(statusCode int, err error) error {
+	permanent := statusCode != 429 && statusCode < 500
+	return &SendError{Permanent: permanent, Err: err}
+}
+
+// transientError wraps errors such as network failures that may succeed on retry
+func transientError(format string, args ...interface{}) error {
+	return &SendError{Permanent: false, Err: fmt.Errorf(format, args...)}
+}
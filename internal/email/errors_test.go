@@ -0,0 +1,79 @@
+package email
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"testing"
+)
+
+func TestClassifySMTPError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		permanent bool
+	}{
+		{name: "mailbox busy", err: &textproto.Error{Code: 450, Msg: "mailbox busy"}},
+		{name: "too many connections", err: &textproto.Error{Code: 421, Msg: "too many connections"}},
+		{name: "wrapped 4xx", err: fmt.Errorf("rcpt: %w", &textproto.Error{Code: 452, Msg: "insufficient storage"})},
+		{name: "unknown user", err: &textproto.Error{Code: 550, Msg: "no such user"}, permanent: true},
+		{name: "message too large", err: &textproto.Error{Code: 552, Msg: "message too large"}, permanent: true},
+		{name: "wrapped 5xx", err: fmt.Errorf("data: %w", &textproto.Error{Code: 554, Msg: "rejected"}), permanent: true},
+		{name: "network error", err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}},
+		{name: "connection closed", err: io.EOF},
+		{name: "tls error", err: tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifySMTPError(tt.err)
+			if IsPermanent(err) != tt.permanent {
+				t.Errorf("IsPermanent = %t, want %t", IsPermanent(err), tt.permanent)
+			}
+			if IsOutcomeUnknown(err) {
+				t.Error("IsOutcomeUnknown = true, want false")
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("classified error %v does not wrap %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestClassifyHTTPStatus(t *testing.T) {
+	tests := []struct {
+		status    int
+		permanent bool
+	}{
+		{status: 400, permanent: true},
+		{status: 401, permanent: true},
+		{status: 403, permanent: true},
+		{status: 404, permanent: true},
+		{status: 422, permanent: true},
+		{status: 429},
+		{status: 500},
+		{status: 502},
+		{status: 503},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.status), func(t *testing.T) {
+			err := classifyHTTPStatus(tt.status, fmt.Errorf("provider returned status %d", tt.status))
+			if IsPermanent(err) != tt.permanent {
+				t.Errorf("IsPermanent = %t, want %t", IsPermanent(err), tt.permanent)
+			}
+		})
+	}
+}
+
+func TestIsPermanentTreatsUnclassifiedErrorsAsTransient(t *testing.T) {
+	if IsPermanent(errors.New("something went wrong")) {
+		t.Error("unclassified error reported as permanent")
+	}
+	if !IsPermanent(fmt.Errorf("send: %w", &SendError{Permanent: true, Err: errors.New("rejected")})) {
+		t.Error("wrapped permanent error reported as transient")
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"time"
 
+	"newsletter-assignment/internal/constants"
+
 	"go.uber.org/zap"
 )
 
@@ -28,11 +30,36 @@ type BrevoEmailRequest struct {
 		Email string `json:"email"`
 		Name  string `json:"name,omitempty"`
 	} `json:"to"`
-	Subject     string `json:"subject"`
-	HTMLContent string `json:"htmlContent,omitempty"`
-	TextContent string `json:"textContent,omitempty"`
+	Subject     string            `json:"subject"`
+	HTMLContent string            `json:"htmlContent,omitempty"`
+	TextContent string            `json:"textContent,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+
+	MessageVersions []BrevoMessageVersion `json:"messageVersions,omitempty"`
+}
+
+// BrevoMessageVersion is one personalised version of a batch email request
+type BrevoMessageVersion struct {
+	To []struct {
+		Email string `json:"email"`
+		Name  string `json:"name,omitempty"`
+	} `json:"to"`
+	Subject     string            `json:"subject,omitempty"`
+	HTMLContent string            `json:"htmlContent,omitempty"`
+	TextContent string            `json:"textContent,omitempty"`
+	Params      map[string]string `json:"params,omitempty"`
+}
+
+// BrevoEmailResponse represents the Brevo API response to an accepted email; batch
+// requests report one message ID per version instead
+type BrevoEmailResponse struct {
+	MessageID  string   `json:"messageId"`
+	MessageIDs []string `json:"messageIds"`
 }
 
+// brevoUnsubscribeParam is the version param that carries a recipient's unsubscribe URL
+const brevoUnsubscribeParam = "list_unsubscribe_url"
+
 // HTTPEmailSender handles HTTP-based email sending via Brevo API
 type HTTPEmailSender struct {
 	config *HTTPConfig
@@ -52,17 +79,17 @@ func NewHTTPEmailSender(config *HTTPConfig, logger *zap.Logger) *HTTPEmailSender
 }
 
 // Send sends an email via Brevo HTTP API
-func (h *HTTPEmailSender) Send(req *EmailRequest) error {
+func (h *HTTPEmailSender) Send(req *EmailRequest) (*SendResult, error) {
 	// Prepare Brevo API request
 	brevoReq := BrevoEmailRequest{
 		Subject:     req.Subject,
 		HTMLContent: req.HTMLBody,
 		TextContent: req.TextBody,
+		Headers:     listUnsubscribeHeaders(req),
 	}
 
 	// Set sender
-	brevoReq.Sender.Name = h.config.FromName
-	brevoReq.Sender.Email = h.config.FromEmail
+	brevoReq.Sender.Email, brevoReq.Sender.Name = senderIdentity(req, h.config.FromEmail, h.config.FromName)
 
 	// Set recipient
 	brevoReq.To = []struct {
@@ -78,14 +105,14 @@ func (h *HTTPEmailSender) Send(req *EmailRequest) error {
 	jsonData, err := json.Marshal(brevoReq)
 	if err != nil {
 		h.logger.Error("Failed to marshal email request", zap.Error(err))
-		return fmt.Errorf("failed to marshal email request: %w", err)
+		return nil, fmt.Errorf("failed to marshal email request: %w", err)
 	}
 
 	// Create HTTP request
 	httpReq, err := http.NewRequest("POST", h.config.BaseURL+"/v3/smtp/email", bytes.NewBuffer(jsonData))
 	if err != nil {
 		h.logger.Error("Failed to create HTTP request", zap.Error(err))
-		return fmt.Errorf("failed to create HTTP request: %w", err)
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	// Set headers
@@ -96,7 +123,7 @@ func (h *HTTPEmailSender) Send(req *EmailRequest) error {
 	resp, err := h.client.Do(httpReq)
 	if err != nil {
 		h.logger.Error("Failed to send HTTP request", zap.Error(err))
-		return fmt.Errorf("failed to send HTTP request: %w", err)
+		return nil, transientError("failed to send HTTP request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -106,14 +133,118 @@ func (h *HTTPEmailSender) Send(req *EmailRequest) error {
 			zap.Int("status_code", resp.StatusCode),
 			zap.String("status", resp.Status),
 		)
-		return fmt.Errorf("brevo API returned error status: %d %s", resp.StatusCode, resp.Status)
+		return nil, classifyHTTPStatus(resp.StatusCode, fmt.Errorf("brevo API returned error status: %d %s", resp.StatusCode, resp.Status))
+	}
+
+	// The email was accepted, so a malformed body must not turn into a failure
+	// that would resend it; the delivery just cannot be matched to events
+	var brevoResp BrevoEmailResponse
+	if err := json.NewDecoder(resp.Body).Decode(&brevoResp); err != nil {
+		h.logger.Warn("Failed to decode Brevo API response", zap.Error(err))
 	}
 
 	h.logger.Info("Email sent successfully via Brevo HTTP API",
 		zap.String("to", req.To),
 		zap.String("subject", req.Subject),
 		zap.Int("status_code", resp.StatusCode),
+		zap.String("message_id", brevoResp.MessageID),
+	)
+
+	return &SendResult{MessageID: brevoResp.MessageID}, nil
+}
+
+// MaxBatchSize returns the number of message versions Brevo accepts per request
+func (h *HTTPEmailSender) MaxBatchSize() int {
+	return constants.BrevoMaxMessageVersions
+}
+
+// SendBatch sends the requests as message versions of a single Brevo API call
+func (h *HTTPEmailSender) SendBatch(reqs []*EmailRequest) ([]BatchResult, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	if len(reqs) > h.MaxBatchSize() {
+		return nil, &SendError{Permanent: true, Err: fmt.Errorf("batch of %d emails exceeds the Brevo limit of %d", len(reqs), h.MaxBatchSize())}
+	}
+
+	first := reqs[0]
+	brevoReq := BrevoEmailRequest{
+		Subject:     first.Subject,
+		HTMLContent: first.HTMLBody,
+		TextContent: first.TextBody,
+	}
+	brevoReq.Sender.Email, brevoReq.Sender.Name = senderIdentity(first, h.config.FromEmail, h.config.FromName)
+
+	// Brevo has no per-version headers, so each recipient's unsubscribe URL is passed
+	// as a version param and referenced from the shared List-Unsubscribe header
+	if first.ListUnsubscribeURL != "" {
+		brevoReq.Headers = map[string]string{
+			"List-Unsubscribe":      fmt.Sprintf("<{{params.%s}}>", brevoUnsubscribeParam),
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		}
+	}
+
+	for _, req := range reqs {
+		fromEmail, fromName := senderIdentity(req, h.config.FromEmail, h.config.FromName)
+		if fromEmail != brevoReq.Sender.Email || fromName != brevoReq.Sender.Name {
+			return nil, &SendError{Permanent: true, Err: fmt.Errorf("all emails of a batch must share the same sender")}
+		}
+		if (req.ListUnsubscribeURL == "") != (first.ListUnsubscribeURL == "") {
+			return nil, &SendError{Permanent: true, Err: fmt.Errorf("all emails of a batch must either have an unsubscribe URL or none")}
+		}
+
+		version := BrevoMessageVersion{
+			Subject:     req.Subject,
+			HTMLContent: req.HTMLBody,
+			TextContent: req.TextBody,
+		}
+		version.To = append(version.To, struct {
+			Email string `json:"email"`
+			Name  string `json:"name,omitempty"`
+		}{Email: req.To})
+		if req.ListUnsubscribeURL != "" {
+			version.Params = map[string]string{brevoUnsubscribeParam: req.ListUnsubscribeURL}
+		}
+		brevoReq.MessageVersions = append(brevoReq.MessageVersions, version)
+	}
+
+	jsonData, err := json.Marshal(brevoReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch email request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest("POST", h.config.BaseURL+"/v3/smtp/email", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("api-key", h.config.APIKey)
+
+	_, body, err := doProviderRequest(h.client, httpReq, "brevo")
+	if err != nil {
+		h.logger.Error("Failed to send batch via Brevo HTTP API", zap.Int("emails", len(reqs)), zap.Error(err))
+		return nil, err
+	}
+
+	var brevoResp BrevoEmailResponse
+	if err := json.Unmarshal(body, &brevoResp); err != nil {
+		h.logger.Warn("Failed to decode Brevo API batch response", zap.Error(err))
+	}
+
+	// Message IDs are listed in version order; a short list only loses event matching
+	results := make([]BatchResult, len(reqs))
+	for i := range reqs {
+		result := &SendResult{}
+		if i < len(brevoResp.MessageIDs) {
+			result.MessageID = brevoResp.MessageIDs[i]
+		}
+		results[i] = BatchResult{Result: result}
+	}
+
+	h.logger.Info("Batch sent successfully via Brevo HTTP API",
+		zap.Int("emails", len(reqs)),
+		zap.Int("message_ids", len(brevoResp.MessageIDs)),
 	)
 
-	return nil
+	return results, nil
 }
@@ -0,0 +1,91 @@
+package email
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// MailgunConfig holds Mailgun messages API configuration
+type MailgunConfig struct {
+	APIKey    string
+	Domain    string
+	FromEmail string
+	FromName  string
+	BaseURL   string
+}
+
+// mailgunMessageResponse represents the Mailgun response to an accepted message
+type mailgunMessageResponse struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+// MailgunSender sends email through the Mailgun messages API
+type MailgunSender struct {
+	config *MailgunConfig
+	client *http.Client
+	logger *zap.Logger
+}
+
+// NewMailgunSender creates a new Mailgun sender
+func NewMailgunSender(config *MailgunConfig, logger *zap.Logger) *MailgunSender {
+	return &MailgunSender{
+		config: config,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// Send sends an email via the Mailgun API
+func (m *MailgunSender) Send(req *EmailRequest) (*SendResult, error) {
+	fromEmail, fromName := senderIdentity(req, m.config.FromEmail, m.config.FromName)
+
+	form := url.Values{}
+	form.Set("from", formatAddress(fromEmail, fromName))
+	form.Set("to", req.To)
+	form.Set("subject", req.Subject)
+	if req.TextBody != "" {
+		form.Set("text", req.TextBody)
+	}
+	if req.HTMLBody != "" {
+		form.Set("html", req.HTMLBody)
+	}
+	for name, value := range listUnsubscribeHeaders(req) {
+		form.Set("h:"+name, value)
+	}
+
+	endpoint := fmt.Sprintf("%s/v3/%s/messages", strings.TrimRight(m.config.BaseURL, "/"), url.PathEscape(m.config.Domain))
+	httpReq, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mailgun request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.SetBasicAuth("api", m.config.APIKey)
+
+	_, body, err := doProviderRequest(m.client, httpReq, "mailgun")
+	if err != nil {
+		m.logger.Error("Failed to send email via Mailgun", zap.String("to", req.To), zap.Error(err))
+		return nil, err
+	}
+
+	var mgResp mailgunMessageResponse
+	if err := json.Unmarshal(body, &mgResp); err != nil {
+		m.logger.Warn("Failed to decode Mailgun response", zap.Error(err))
+	}
+
+	m.logger.Info("Email sent successfully via Mailgun",
+		zap.String("to", req.To),
+		zap.String("subject", req.Subject),
+		zap.String("message_id", mgResp.ID),
+	)
+
+	return &SendResult{MessageID: mgResp.ID}, nil
+}
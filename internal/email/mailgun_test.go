@@ -0,0 +1,63 @@
+package email
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestMailgunSenderSend(t *testing.T) {
+	server, captured := newProviderServer(t, http.StatusOK, nil, `{"id":"<20260301.1@mg.example.com>","message":"Queued. Thank you."}`)
+	sender := NewMailgunSender(&MailgunConfig{APIKey: "key-123", Domain: "mg.example.com", FromEmail: "default@example.com", BaseURL: server.URL}, zap.NewNop())
+
+	result, err := sender.Send(testProviderRequest())
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if result.MessageID != "<20260301.1@mg.example.com>" {
+		t.Errorf("message ID = %q", result.MessageID)
+	}
+
+	if captured.method != http.MethodPost || captured.path != "/v3/mg.example.com/messages" {
+		t.Errorf("request %s %s", captured.method, captured.path)
+	}
+	req := &http.Request{Header: captured.header}
+	if user, password, ok := req.BasicAuth(); !ok || user != "api" || password != "key-123" {
+		t.Errorf("basic auth = %q:%q (%t)", user, password, ok)
+	}
+	if ct := captured.header.Get("Content-Type"); ct != "application/x-www-form-urlencoded" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	form, err := url.ParseQuery(string(captured.body))
+	if err != nil {
+		t.Fatalf("decoding form: %v", err)
+	}
+	want := map[string]string{
+		"from":                    `"Example News" <news@example.com>`,
+		"to":                      "jane@example.org",
+		"subject":                 "Grüße",
+		"text":                    "Hello",
+		"html":                    "<p>Hello</p>",
+		"h:List-Unsubscribe":      "<https://newsletter.example.com/unsubscribe?token=abc>",
+		"h:List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+	for field, value := range want {
+		if got := form.Get(field); got != value {
+			t.Errorf("%s = %q, want %q", field, got, value)
+		}
+	}
+}
+
+func TestMailgunSenderToleratesUndecodableResponse(t *testing.T) {
+	server, _ := newProviderServer(t, http.StatusOK, nil, "Queued")
+	sender := NewMailgunSender(&MailgunConfig{APIKey: "key-123", Domain: "mg.example.com", BaseURL: server.URL}, zap.NewNop())
+
+	// The message was accepted, so a response without ID is not an error
+	result, err := sender.Send(testProviderRequest())
+	if err != nil || result.MessageID != "" {
+		t.Errorf("Send() = %+v, %v; want an empty message ID and no error", result, err)
+	}
+}
@@ -0,0 +1,144 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// maxHeaderLineLength is the line length headers are folded at (RFC 5322 section 2.1.1)
+const maxHeaderLineLength = 78
+
+// mimeMessage builds an RFC 5322 / MIME message: headers are RFC 2047 encoded and
+// folded, bodies are quoted-printable, and multipart boundaries are random
+type mimeMessage struct {
+	buf bytes.Buffer
+}
+
+// buildMIMEMessage renders a request into a message ready for SMTP DATA and returns
+// it together with its Message-ID
+func buildMIMEMessage(req *EmailRequest, fromEmail, fromName string, date time.Time) ([]byte, string, error) {
+	messageID, err := newMessageID(fromEmail)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var m mimeMessage
+	m.header("From", (&mail.Address{Name: fromName, Address: fromEmail}).String())
+	m.header("To", (&mail.Address{Address: req.To}).String())
+	m.header("Subject", mime.QEncoding.Encode("utf-8", req.Subject))
+	m.header("Date", date.Format(time.RFC1123Z))
+	m.header("Message-ID", messageID)
+
+	extra := listUnsubscribeHeaders(req)
+	for _, name := range sortedHeaderNames(extra) {
+		m.header(name, extra[name])
+	}
+	m.header("MIME-Version", "1.0")
+
+	switch {
+	case req.HTMLBody != "" && req.TextBody != "":
+		if err := m.alternative(req.TextBody, req.HTMLBody); err != nil {
+			return nil, "", err
+		}
+	case req.HTMLBody != "":
+		if err := m.singlePart("text/html", req.HTMLBody); err != nil {
+			return nil, "", err
+		}
+	default:
+		if err := m.singlePart("text/plain", req.TextBody); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return m.buf.Bytes(), messageID, nil
+}
+
+// header writes a header field, dropping line breaks that would inject other headers
+// and folding the value at whitespace to keep lines short. A long first word, such as
+// a full-length encoded word, goes on a line of its own after the field name.
+func (m *mimeMessage) header(name, value string) {
+	value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+
+	line := name + ":"
+	lineLen := len(line)
+	for _, word := range strings.Split(value, " ") {
+		if lineLen+1+len(word) > maxHeaderLineLength {
+			line += "\r\n"
+			lineLen = 0
+		}
+		line += " " + word
+		lineLen += 1 + len(word)
+	}
+	m.buf.WriteString(line + "\r\n")
+}
+
+func (m *mimeMessage) singlePart(contentType, body string) error {
+	m.header("Content-Type", contentType+"; charset=UTF-8")
+	m.header("Content-Transfer-Encoding", "quoted-printable")
+	m.buf.WriteString("\r\n")
+	return writeQuotedPrintable(&m.buf, body)
+}
+
+func (m *mimeMessage) alternative(text, html string) error {
+	w := multipart.NewWriter(&m.buf)
+	m.header("Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": w.Boundary()}))
+	m.buf.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", text},
+		{"text/html", html},
+	} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType+"; charset=UTF-8")
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+		pw, err := w.CreatePart(header)
+		if err != nil {
+			return fmt.Errorf("failed to create MIME part: %w", err)
+		}
+		if err := writeQuotedPrintable(pw, part.body); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to close MIME message: %w", err)
+	}
+	return nil
+}
+
+// writeQuotedPrintable encodes a body with CRLF line endings and lines of at most 76 characters
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return fmt.Errorf("failed to encode body: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return fmt.Errorf("failed to encode body: %w", err)
+	}
+	return nil
+}
+
+// newMessageID returns a unique Message-ID in the sender's domain
+func newMessageID(fromEmail string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate message ID: %w", err)
+	}
+
+	domain := "localhost"
+	if at := strings.LastIndex(fromEmail, "@"); at >= 0 && at < len(fromEmail)-1 {
+		domain = fromEmail[at+1:]
+	}
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), domain), nil
+}
@@ -0,0 +1,306 @@
+package email
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+var testDate = time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+// mimeCases are rendered against testdata/<name>.eml
+var mimeCases = []struct {
+	name      string
+	req       *EmailRequest
+	fromEmail string
+	fromName  string
+}{
+	{
+		name: "text",
+		req: &EmailRequest{
+			To:       "jane@example.org",
+			Subject:  "Weekly digest",
+			TextBody: "Hello Jane,\n\nHere is the news.\n",
+		},
+		fromEmail: "news@example.com",
+		fromName:  "Example News",
+	},
+	{
+		name: "html",
+		req: &EmailRequest{
+			To:                 "jane@example.org",
+			Subject:            "Weekly digest",
+			HTMLBody:           "<p>Hello Jane,</p>\n<p>Here is the news.</p>\n",
+			ListUnsubscribeURL: "https://newsletter.example.com/unsubscribe?token=abc",
+		},
+		fromEmail: "news@example.com",
+	},
+	{
+		name: "alternative",
+		req: &EmailRequest{
+			To:                 "jane@example.org",
+			Subject:            "Weekly digest",
+			TextBody:           "Hello Jane,\n\nHere is the news.\n",
+			HTMLBody:           "<p>Hello Jane,</p>\n<p>Here is the news.</p>\n",
+			ListUnsubscribeURL: "https://newsletter.example.com/unsubscribe?token=abc",
+		},
+		fromEmail: "news@example.com",
+		fromName:  "Example News",
+	},
+	{
+		name: "non_ascii",
+		req: &EmailRequest{
+			To:       "jose@example.org",
+			Subject:  "Grüße aus Zürich – die Neuigkeiten der Woche für José und alle anderen Leserinnen",
+			TextBody: "Hallo José,\n\nschöne Grüße! 🎉\n",
+			HTMLBody: "<p>Hallo José,</p>\n<p>schöne Grüße! 🎉</p>\n",
+		},
+		fromEmail: "news@example.com",
+		fromName:  "Zürcher Nachrichten",
+	},
+	{
+		name: "long_line",
+		req: &EmailRequest{
+			To:       "jane@example.org",
+			Subject:  "A very long line",
+			TextBody: strings.Repeat("0123456789", 120) + "\n" + strings.Repeat("word ", 250) + "\n",
+		},
+		fromEmail: "news@example.com",
+		fromName:  "Example News",
+	},
+}
+
+// normalizeMessage replaces the random parts of a message so it can be compared
+func normalizeMessage(message []byte, messageID string) []byte {
+	message = bytes.ReplaceAll(message, []byte(messageID), []byte("<MESSAGE-ID>"))
+	if boundary := messageBoundary(message); boundary != "" {
+		message = bytes.ReplaceAll(message, []byte(boundary), []byte("BOUNDARY"))
+	}
+	return message
+}
+
+// messageBoundary returns the multipart boundary of a message, if it has one
+func messageBoundary(message []byte) string {
+	msg, err := mail.ReadMessage(bytes.NewReader(message))
+	if err != nil {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return params["boundary"]
+}
+
+func TestBuildMIMEMessageGolden(t *testing.T) {
+	for _, tc := range mimeCases {
+		t.Run(tc.name, func(t *testing.T) {
+			message, messageID, err := buildMIMEMessage(tc.req, tc.fromEmail, tc.fromName, testDate)
+			if err != nil {
+				t.Fatalf("buildMIMEMessage: %v", err)
+			}
+			got := normalizeMessage(message, messageID)
+
+			golden := filepath.Join("testdata", tc.name+".eml")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("reading golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("message does not match %s:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+func TestBuildMIMEMessageRoundTrip(t *testing.T) {
+	for _, tc := range mimeCases {
+		t.Run(tc.name, func(t *testing.T) {
+			message, messageID, err := buildMIMEMessage(tc.req, tc.fromEmail, tc.fromName, testDate)
+			if err != nil {
+				t.Fatalf("buildMIMEMessage: %v", err)
+			}
+
+			// RFC 5322 section 2.1.1 limits lines to 998 characters, and
+			// quoted-printable keeps them to 76
+			for _, line := range strings.Split(string(message), "\r\n") {
+				if len(line) > maxHeaderLineLength {
+					t.Errorf("line of %d characters: %q", len(line), line)
+				}
+			}
+			if bytes.Contains(bytes.ReplaceAll(message, []byte("\r\n"), nil), []byte("\n")) {
+				t.Error("message has bare line feeds")
+			}
+
+			msg, err := mail.ReadMessage(bytes.NewReader(message))
+			if err != nil {
+				t.Fatalf("ReadMessage: %v", err)
+			}
+
+			subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+			if err != nil {
+				t.Fatalf("decoding subject: %v", err)
+			}
+			if subject != tc.req.Subject {
+				t.Errorf("subject = %q, want %q", subject, tc.req.Subject)
+			}
+
+			from, err := mail.ParseAddress(msg.Header.Get("From"))
+			if err != nil {
+				t.Fatalf("parsing From: %v", err)
+			}
+			if from.Name != tc.fromName || from.Address != tc.fromEmail {
+				t.Errorf("from = %q <%s>, want %q <%s>", from.Name, from.Address, tc.fromName, tc.fromEmail)
+			}
+
+			to, err := mail.ParseAddress(msg.Header.Get("To"))
+			if err != nil || to.Address != tc.req.To {
+				t.Errorf("to = %v (%v), want %s", to, err, tc.req.To)
+			}
+
+			if date, err := msg.Header.Date(); err != nil || !date.Equal(testDate) {
+				t.Errorf("date = %v (%v), want %v", date, err, testDate)
+			}
+			if got := msg.Header.Get("Message-ID"); got != messageID || !strings.HasSuffix(messageID, "@example.com>") {
+				t.Errorf("Message-ID = %q, want %q in the sender's domain", got, messageID)
+			}
+
+			if tc.req.ListUnsubscribeURL != "" {
+				if got := msg.Header.Get("List-Unsubscribe"); got != "<"+tc.req.ListUnsubscribeURL+">" {
+					t.Errorf("List-Unsubscribe = %q", got)
+				}
+				if got := msg.Header.Get("List-Unsubscribe-Post"); got != "List-Unsubscribe=One-Click" {
+					t.Errorf("List-Unsubscribe-Post = %q", got)
+				}
+			}
+
+			bodies := readBodies(t, msg)
+			if tc.req.TextBody != "" && bodies["text/plain"] != tc.req.TextBody {
+				t.Errorf("text body = %q, want %q", bodies["text/plain"], tc.req.TextBody)
+			}
+			if tc.req.HTMLBody != "" && bodies["text/html"] != tc.req.HTMLBody {
+				t.Errorf("html body = %q, want %q", bodies["text/html"], tc.req.HTMLBody)
+			}
+		})
+	}
+}
+
+// readBodies decodes the text parts of a message by content type, with LF line endings
+func readBodies(t *testing.T, msg *mail.Message) map[string]string {
+	t.Helper()
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("parsing Content-Type: %v", err)
+	}
+
+	bodies := make(map[string]string)
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		if params["charset"] != "UTF-8" || msg.Header.Get("Content-Transfer-Encoding") != "quoted-printable" {
+			t.Errorf("unexpected part headers %v", msg.Header)
+		}
+		bodies[mediaType] = decodeQuotedPrintable(t, msg.Body)
+		return bodies
+	}
+
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("media type = %q", mediaType)
+	}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart: %v", err)
+		}
+
+		// The multipart reader removes the Content-Transfer-Encoding header once it
+		// has set up the quoted-printable decoding
+		partType, partParams, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if err != nil || partParams["charset"] != "UTF-8" {
+			t.Errorf("part Content-Type = %q", part.Header.Get("Content-Type"))
+		}
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("reading part: %v", err)
+		}
+		bodies[partType] = strings.ReplaceAll(string(body), "\r\n", "\n")
+	}
+	return bodies
+}
+
+func decodeQuotedPrintable(t *testing.T, body io.Reader) string {
+	t.Helper()
+
+	decoded, err := io.ReadAll(quotedprintable.NewReader(body))
+	if err != nil {
+		t.Fatalf("decoding quoted-printable: %v", err)
+	}
+	return strings.ReplaceAll(string(decoded), "\r\n", "\n")
+}
+
+func TestBuildMIMEMessageUniqueBoundaries(t *testing.T) {
+	req := mimeCases[2].req
+	seenBoundaries := make(map[string]bool)
+	seenIDs := make(map[string]bool)
+
+	for i := 0; i < 20; i++ {
+		message, messageID, err := buildMIMEMessage(req, "news@example.com", "", testDate)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		boundary := messageBoundary(message)
+		if boundary == "" {
+			t.Fatal("message has no boundary")
+		}
+		if seenBoundaries[boundary] {
+			t.Fatalf("boundary %q used twice", boundary)
+		}
+		seenBoundaries[boundary] = true
+
+		if seenIDs[messageID] {
+			t.Fatalf("Message-ID %q used twice", messageID)
+		}
+		seenIDs[messageID] = true
+	}
+}
+
+func TestBuildMIMEMessageDropsHeaderInjection(t *testing.T) {
+	req := &EmailRequest{
+		To:       "jane@example.org",
+		Subject:  "Hello\r\nBcc: victim@example.net",
+		TextBody: "Hi",
+	}
+	message, _, err := buildMIMEMessage(req, "news@example.com", "Example\nBcc: victim@example.net", testDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bcc := msg.Header.Get("Bcc"); bcc != "" {
+		t.Errorf("injected Bcc header %q", bcc)
+	}
+}
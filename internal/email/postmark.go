@@ -0,0 +1,112 @@
+package email
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PostmarkConfig holds Postmark email API configuration
+type PostmarkConfig struct {
+	ServerToken   string
+	MessageStream string
+	FromEmail     string
+	FromName      string
+	BaseURL       string
+}
+
+type postmarkHeader struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+// postmarkEmailRequest represents a Postmark single email request
+type postmarkEmailRequest struct {
+	From          string           `json:"From"`
+	To            string           `json:"To"`
+	Subject       string           `json:"Subject"`
+	HTMLBody      string           `json:"HtmlBody,omitempty"`
+	TextBody      string           `json:"TextBody,omitempty"`
+	Headers       []postmarkHeader `json:"Headers,omitempty"`
+	MessageStream string           `json:"MessageStream,omitempty"`
+}
+
+// postmarkEmailResponse represents the Postmark response to an email request
+type postmarkEmailResponse struct {
+	ErrorCode int    `json:"ErrorCode"`
+	Message   string `json:"Message"`
+	MessageID string `json:"MessageID"`
+}
+
+// PostmarkSender sends email through the Postmark email API
+type PostmarkSender struct {
+	config *PostmarkConfig
+	client *http.Client
+	logger *zap.Logger
+}
+
+// NewPostmarkSender creates a new Postmark sender
+func NewPostmarkSender(config *PostmarkConfig, logger *zap.Logger) *PostmarkSender {
+	return &PostmarkSender{
+		config: config,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// Send sends an email via the Postmark API
+func (p *PostmarkSender) Send(req *EmailRequest) (*SendResult, error) {
+	fromEmail, fromName := senderIdentity(req, p.config.FromEmail, p.config.FromName)
+
+	pmReq := postmarkEmailRequest{
+		From:          formatAddress(fromEmail, fromName),
+		To:            req.To,
+		Subject:       req.Subject,
+		HTMLBody:      req.HTMLBody,
+		TextBody:      req.TextBody,
+		MessageStream: p.config.MessageStream,
+	}
+	headers := listUnsubscribeHeaders(req)
+	for _, name := range sortedHeaderNames(headers) {
+		pmReq.Headers = append(pmReq.Headers, postmarkHeader{Name: name, Value: headers[name]})
+	}
+
+	jsonData, err := json.Marshal(pmReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal postmark request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, strings.TrimRight(p.config.BaseURL, "/")+"/email", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create postmark request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Postmark-Server-Token", p.config.ServerToken)
+
+	_, body, err := doProviderRequest(p.client, httpReq, "postmark")
+	if err != nil {
+		p.logger.Error("Failed to send email via Postmark", zap.String("to", req.To), zap.Error(err))
+		return nil, err
+	}
+
+	var pmResp postmarkEmailResponse
+	if err := json.Unmarshal(body, &pmResp); err != nil {
+		p.logger.Warn("Failed to decode Postmark response", zap.Error(err))
+	}
+
+	p.logger.Info("Email sent successfully via Postmark",
+		zap.String("to", req.To),
+		zap.String("subject", req.Subject),
+		zap.String("message_id", pmResp.MessageID),
+	)
+
+	return &SendResult{MessageID: pmResp.MessageID}, nil
+}
@@ -0,0 +1,47 @@
+package email
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestPostmarkSenderSend(t *testing.T) {
+	server, captured := newProviderServer(t, http.StatusOK, nil,
+		`{"To":"jane@example.org","SubmittedAt":"2026-03-01T08:00:00Z","MessageID":"b7bc2f4a-e38e-4336-af7d-e6c392c2f817","ErrorCode":0,"Message":"OK"}`)
+	sender := NewPostmarkSender(&PostmarkConfig{ServerToken: "pm-token", MessageStream: "broadcast", FromEmail: "default@example.com", BaseURL: server.URL}, zap.NewNop())
+
+	result, err := sender.Send(testProviderRequest())
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if result.MessageID != "b7bc2f4a-e38e-4336-af7d-e6c392c2f817" {
+		t.Errorf("message ID = %q", result.MessageID)
+	}
+
+	if captured.method != http.MethodPost || captured.path != "/email" {
+		t.Errorf("request %s %s", captured.method, captured.path)
+	}
+	if token := captured.header.Get("X-Postmark-Server-Token"); token != "pm-token" {
+		t.Errorf("X-Postmark-Server-Token = %q", token)
+	}
+	if accept := captured.header.Get("Accept"); accept != "application/json" {
+		t.Errorf("Accept = %q", accept)
+	}
+
+	var body postmarkEmailRequest
+	if err := json.Unmarshal(captured.body, &body); err != nil {
+		t.Fatalf("decoding request: %v", err)
+	}
+	if body.From != `"Example News" <news@example.com>` || body.To != "jane@example.org" || body.Subject != "Grüße" {
+		t.Errorf("addressing = %q -> %q: %q", body.From, body.To, body.Subject)
+	}
+	if body.TextBody != "Hello" || body.HTMLBody != "<p>Hello</p>" || body.MessageStream != "broadcast" {
+		t.Errorf("body = %+v", body)
+	}
+	if len(body.Headers) != 2 || body.Headers[0] != (postmarkHeader{Name: "List-Unsubscribe", Value: "<https://newsletter.example.com/unsubscribe?token=abc>"}) {
+		t.Errorf("headers = %+v", body.Headers)
+	}
+}
@@ -0,0 +1,54 @@
+package email
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"sort"
+	"strings"
+)
+
+// maxProviderErrorBody bounds how much of an error response is kept in the error message
+const maxProviderErrorBody = 512
+
+// doProviderRequest sends a provider API request and returns the response and its body.
+// Non-2xx responses are classified like those of the Brevo sender.
+func doProviderRequest(client *http.Client, req *http.Request, provider string) (*http.Response, []byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, transientError("failed to send %s request: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	// The email may already be accepted when reading the body fails, so that is not an error
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail := strings.TrimSpace(string(body))
+		if len(detail) > maxProviderErrorBody {
+			detail = detail[:maxProviderErrorBody]
+		}
+		return nil, nil, classifyHTTPStatus(resp.StatusCode, fmt.Errorf("%s API returned error status: %s: %s", provider, resp.Status, detail))
+	}
+
+	return resp, body, nil
+}
+
+// formatAddress renders a sender as an RFC 5322 address, encoding the display name if needed
+func formatAddress(email, name string) string {
+	if name == "" {
+		return email
+	}
+	return (&mail.Address{Name: name, Address: email}).String()
+}
+
+// sortedHeaderNames returns the names of headers in a stable order
+func sortedHeaderNames(headers map[string]string) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,113 @@
+package email
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// capturedRequest is what a fake provider API received
+type capturedRequest struct {
+	method string
+	path   string
+	host   string
+	header http.Header
+	body   []byte
+}
+
+// newProviderServer starts a fake provider API that records the last request and
+// answers with the given status, headers and body
+func newProviderServer(t *testing.T, status int, header map[string]string, body string) (*httptest.Server, *capturedRequest) {
+	t.Helper()
+
+	captured := &capturedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("reading request body: %v", err)
+		}
+		*captured = capturedRequest{method: r.Method, path: r.URL.EscapedPath(), host: r.Host, header: r.Header.Clone(), body: data}
+
+		for name, value := range header {
+			w.Header().Set(name, value)
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server, captured
+}
+
+// testProviderRequest is the request every adapter test sends
+func testProviderRequest() *EmailRequest {
+	return &EmailRequest{
+		To:                 "jane@example.org",
+		Subject:            "Grüße",
+		HTMLBody:           "<p>Hello</p>",
+		TextBody:           "Hello",
+		FromEmail:          "news@example.com",
+		FromName:           "Example News",
+		ListUnsubscribeURL: "https://newsletter.example.com/unsubscribe?token=abc",
+	}
+}
+
+// httpProviders builds each HTTP provider against a base URL
+var httpProviders = map[string]func(baseURL string) EmailSender{
+	"ses": func(baseURL string) EmailSender {
+		return NewSESSender(&SESConfig{Region: "eu-west-1", AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", BaseURL: baseURL}, zap.NewNop())
+	},
+	"sendgrid": func(baseURL string) EmailSender {
+		return NewSendGridSender(&SendGridConfig{APIKey: "sg-key", BaseURL: baseURL}, zap.NewNop())
+	},
+	"mailgun": func(baseURL string) EmailSender {
+		return NewMailgunSender(&MailgunConfig{APIKey: "mg-key", Domain: "mg.example.com", BaseURL: baseURL}, zap.NewNop())
+	},
+	"postmark": func(baseURL string) EmailSender {
+		return NewPostmarkSender(&PostmarkConfig{ServerToken: "pm-token", BaseURL: baseURL}, zap.NewNop())
+	},
+}
+
+func TestHTTPProvidersClassifyErrors(t *testing.T) {
+	tests := []struct {
+		status    int
+		permanent bool
+	}{
+		{http.StatusBadRequest, true},
+		{http.StatusUnauthorized, true},
+		{http.StatusForbidden, true},
+		{http.StatusUnprocessableEntity, true},
+		{http.StatusTooManyRequests, false},
+		{http.StatusInternalServerError, false},
+		{http.StatusBadGateway, false},
+		{http.StatusServiceUnavailable, false},
+	}
+
+	for name, newProvider := range httpProviders {
+		for _, tt := range tests {
+			t.Run(name+"/"+http.StatusText(tt.status), func(t *testing.T) {
+				server, _ := newProviderServer(t, tt.status, nil, `{"message":"rejected"}`)
+
+				result, err := newProvider(server.URL).Send(testProviderRequest())
+				if err == nil {
+					t.Fatalf("Send() = %+v, want an error", result)
+				}
+				if IsPermanent(err) != tt.permanent {
+					t.Errorf("IsPermanent(%v) = %t, want %t", err, IsPermanent(err), tt.permanent)
+				}
+			})
+		}
+
+		t.Run(name+"/network error", func(t *testing.T) {
+			server := httptest.NewServer(http.NotFoundHandler())
+			server.Close()
+
+			_, err := newProvider(server.URL).Send(testProviderRequest())
+			if err == nil || IsPermanent(err) {
+				t.Errorf("Send() error = %v, want a transient error", err)
+			}
+		})
+	}
+}
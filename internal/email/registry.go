@@ -0,0 +1,109 @@
+package email
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"newsletter-assignment/internal/constants"
+
+	"go.uber.org/zap"
+)
+
+// ProviderFactory builds the sender for one provider from the unified configuration
+type ProviderFactory func(config *UnifiedConfig, logger *zap.Logger) (EmailSender, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]ProviderFactory{
+		constants.EmailProviderSMTP:     newSMTPProvider,
+		constants.EmailProviderBrevo:    newBrevoProvider,
+		constants.EmailProviderSES:      newSESProvider,
+		constants.EmailProviderSendGrid: newSendGridProvider,
+		constants.EmailProviderMailgun:  newMailgunProvider,
+		constants.EmailProviderPostmark: newPostmarkProvider,
+	}
+)
+
+// RegisterProvider makes a provider selectable by name, replacing any provider
+// registered under the same name
+func RegisterProvider(name string, factory ProviderFactory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[strings.ToLower(name)] = factory
+}
+
+// Providers returns the names of all registered providers
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewProvider builds the sender registered under name
+func NewProvider(name string, config *UnifiedConfig, logger *zap.Logger) (EmailSender, error) {
+	providersMu.RLock()
+	factory, ok := providers[strings.ToLower(name)]
+	providersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown email provider %q (available: %s)", name, strings.Join(Providers(), ", "))
+	}
+	return factory(config, logger)
+}
+
+func newSMTPProvider(config *UnifiedConfig, logger *zap.Logger) (EmailSender, error) {
+	if config.SMTP == nil {
+		return nil, fmt.Errorf("smtp provider is not configured")
+	}
+	if err := validateSMTPConfig(config.SMTP); err != nil {
+		return nil, err
+	}
+	return NewSMTPSender(config.SMTP, logger)
+}
+
+func newBrevoProvider(config *UnifiedConfig, logger *zap.Logger) (EmailSender, error) {
+	if config.HTTP == nil {
+		return nil, fmt.Errorf("brevo provider is not configured")
+	}
+	return NewHTTPEmailSender(config.HTTP, logger), nil
+}
+
+func newSESProvider(config *UnifiedConfig, logger *zap.Logger) (EmailSender, error) {
+	if config.SES == nil || config.SES.AccessKeyID == "" || config.SES.SecretAccessKey == "" {
+		return nil, fmt.Errorf("ses provider requires an access key ID and secret access key")
+	}
+	// The region is part of every request signature, even with a custom endpoint
+	if config.SES.Region == "" {
+		return nil, fmt.Errorf("ses provider requires a region")
+	}
+	return NewSESSender(config.SES, logger), nil
+}
+
+func newSendGridProvider(config *UnifiedConfig, logger *zap.Logger) (EmailSender, error) {
+	if config.SendGrid == nil || config.SendGrid.APIKey == "" {
+		return nil, fmt.Errorf("sendgrid provider requires an API key")
+	}
+	return NewSendGridSender(config.SendGrid, logger), nil
+}
+
+func newMailgunProvider(config *UnifiedConfig, logger *zap.Logger) (EmailSender, error) {
+	if config.Mailgun == nil || config.Mailgun.APIKey == "" || config.Mailgun.Domain == "" {
+		return nil, fmt.Errorf("mailgun provider requires an API key and a sending domain")
+	}
+	return NewMailgunSender(config.Mailgun, logger), nil
+}
+
+func newPostmarkProvider(config *UnifiedConfig, logger *zap.Logger) (EmailSender, error) {
+	if config.Postmark == nil || config.Postmark.ServerToken == "" {
+		return nil, fmt.Errorf("postmark provider requires a server token")
+	}
+	return NewPostmarkSender(config.Postmark, logger), nil
+}
@@ -1,68 +1,310 @@
 package email
 
 import (
+	"errors"
 	"fmt"
-	
+	"io"
+	"math/rand/v2"
+	"strings"
+	"time"
+
 	"go.uber.org/zap"
 )
 
 // EmailSender defines the interface for sending emails
 type EmailSender interface {
-	Send(req *EmailRequest) error
+	Send(req *EmailRequest) (*SendResult, error)
+}
+
+// SendResult describes an accepted email
+type SendResult struct {
+	// MessageID is the identifier the provider assigned to the message, used to
+	// match delivery events back to deliveries. It is empty when the provider does not report one.
+	MessageID string
+
+	// Provider names the provider that accepted the message
+	Provider string
 }
 
-// UnifiedEmailSender can use either SMTP or HTTP API
+// UnifiedEmailSender sends through the configured providers. Providers are tried in
+// order, moving on to the next one on transient errors; with weights configured, the
+// provider tried first is picked by weight. Each provider has a circuit breaker so
+// that a provider that keeps failing is skipped until it recovers.
 type UnifiedEmailSender struct {
-	smtpSender *SMTPSender
-	httpSender *HTTPEmailSender
-	useHTTP    bool
-	logger     *zap.Logger
+	routes []*providerRoute
+	logger *zap.Logger
+}
+
+type providerRoute struct {
+	name    string
+	sender  EmailSender
+	weight  int
+	breaker *circuitBreaker
 }
 
-// UnifiedConfig holds configuration for both SMTP and HTTP
+// UnifiedConfig holds the configuration of every supported provider; only the
+// configuration of the selected providers is required
 type UnifiedConfig struct {
-	// SMTP Configuration
-	SMTP *SMTPConfig
-	
-	// HTTP Configuration
-	HTTP *HTTPConfig
-	
-	// Preference: true for HTTP, false for SMTP
-	UseHTTP bool
+	// Provider names the registered provider to send through, e.g. "smtp" or "brevo"
+	Provider string
+
+	// FailoverProviders are tried in order when the providers before them fail transiently
+	FailoverProviders []string
+
+	// Weights split traffic between providers; providers without weight only serve as failover
+	Weights map[string]int
+
+	CircuitFailureThreshold int
+	CircuitCooldown         time.Duration
+
+	SMTP     *SMTPConfig
+	HTTP     *HTTPConfig
+	SES      *SESConfig
+	SendGrid *SendGridConfig
+	Mailgun  *MailgunConfig
+	Postmark *PostmarkConfig
+}
+
+// ProviderStatus describes the circuit breaker state of one provider
+type ProviderStatus struct {
+	Name                string     `json:"name"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Weight              int        `json:"weight"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+}
+
+// NewUnifiedEmailSender creates a sender for the configured providers
+func NewUnifiedEmailSender(config *UnifiedConfig, logger *zap.Logger) (*UnifiedEmailSender, error) {
+	names := append([]string{config.Provider}, config.FailoverProviders...)
+
+	u := &UnifiedEmailSender{logger: logger}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		sender, err := NewProvider(name, config, logger)
+		if err != nil {
+			return nil, err
+		}
+		u.routes = append(u.routes, &providerRoute{
+			name:    name,
+			sender:  sender,
+			weight:  config.Weights[name],
+			breaker: newCircuitBreaker(config.CircuitFailureThreshold, config.CircuitCooldown),
+		})
+	}
+
+	for name := range config.Weights {
+		if !seen[name] {
+			return nil, fmt.Errorf("weighted email provider %q is not configured as primary or failover provider", name)
+		}
+	}
+
+	logger.Info("Email providers selected", zap.Strings("providers", names))
+	return u, nil
+}
+
+// Send sends an email, failing over to the next provider on transient errors.
+// Permanent errors are returned right away since another provider would reject the email too,
+// and so are errors with unknown outcome since the email may have been delivered.
+func (u *UnifiedEmailSender) Send(req *EmailRequest) (*SendResult, error) {
+	var lastErr error
+	for _, route := range u.attemptOrder() {
+		if !route.breaker.allow(time.Now()) {
+			continue
+		}
+
+		u.logger.Debug("Sending email", zap.String("provider", route.name))
+		result, err := route.sender.Send(req)
+		if err == nil || IsPermanent(err) {
+			route.breaker.recordSuccess()
+			if err != nil {
+				return nil, err
+			}
+			if result == nil {
+				result = &SendResult{}
+			}
+			result.Provider = route.name
+			return result, nil
+		}
+
+		route.breaker.recordFailure(time.Now())
+		if IsOutcomeUnknown(err) {
+			return nil, err
+		}
+
+		lastErr = err
+		u.logger.Warn("Email provider failed, trying next provider",
+			zap.String("provider", route.name),
+			zap.Error(err),
+		)
+	}
+
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, transientError("no email provider available: all circuits are open")
 }
 
-// NewUnifiedEmailSender creates a new unified email sender
-func NewUnifiedEmailSender(config *UnifiedConfig, logger *zap.Logger) *UnifiedEmailSender {
-	sender := &UnifiedEmailSender{
-		useHTTP: config.UseHTTP,
-		logger:  logger,
+// Status returns the circuit breaker state of every provider in failover order
+func (u *UnifiedEmailSender) Status() []ProviderStatus {
+	statuses := make([]ProviderStatus, 0, len(u.routes))
+	for _, route := range u.routes {
+		state, failures, openedAt := route.breaker.snapshot()
+		statuses = append(statuses, ProviderStatus{
+			Name:                route.name,
+			State:               state,
+			ConsecutiveFailures: failures,
+			Weight:              route.weight,
+			OpenedAt:            openedAt,
+		})
 	}
+	return statuses
+}
 
-	// Initialize SMTP sender if config provided
-	if config.SMTP != nil {
-		sender.smtpSender = NewSMTPSender(config.SMTP, logger)
+// Close releases the connections held by the providers, such as pooled SMTP sessions
+func (u *UnifiedEmailSender) Close() error {
+	var errs []error
+	for _, route := range u.routes {
+		if closer, ok := route.sender.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close %s provider: %w", route.name, err))
+			}
+		}
 	}
+	return errors.Join(errs...)
+}
 
-	// Initialize HTTP sender if config provided
-	if config.HTTP != nil {
-		sender.httpSender = NewHTTPEmailSender(config.HTTP, logger)
+// attemptOrder returns the providers in failover order, moving a provider picked by
+// weight among the available weighted providers to the front
+func (u *UnifiedEmailSender) attemptOrder() []*providerRoute {
+	now := time.Now()
+	total := 0
+	for _, route := range u.routes {
+		if route.weight > 0 && route.breaker.available(now) {
+			total += route.weight
+		}
+	}
+	if total == 0 {
+		return u.routes
 	}
 
-	return sender
+	pick := rand.IntN(total)
+	for i, route := range u.routes {
+		if route.weight == 0 || !route.breaker.available(now) {
+			continue
+		}
+		if pick < route.weight {
+			order := make([]*providerRoute, 0, len(u.routes))
+			order = append(order, route)
+			order = append(order, u.routes[:i]...)
+			return append(order, u.routes[i+1:]...)
+		}
+		pick -= route.weight
+	}
+	return u.routes
 }
 
-// Send sends an email using the configured method (HTTP or SMTP)
-func (u *UnifiedEmailSender) Send(req *EmailRequest) error {
-	if u.useHTTP && u.httpSender != nil {
-		u.logger.Debug("Sending email via HTTP API")
-		return u.httpSender.Send(req)
+// MaxBatchSize returns the batch size of the provider that is next in line, picked
+// the way SendBatch picks it, or 1 when that provider cannot batch. Failover providers
+// do not count: SendBatch only batches with the provider it tries first.
+func (u *UnifiedEmailSender) MaxBatchSize() int {
+	now := time.Now()
+	for _, route := range u.attemptOrder() {
+		if !route.breaker.available(now) {
+			continue
+		}
+
+		batchSender, ok := route.sender.(BatchSender)
+		if !ok || batchSender.MaxBatchSize() < 2 {
+			return 1
+		}
+		return batchSender.MaxBatchSize()
+	}
+	return 1
+}
+
+// SendBatch sends the batch in one call when the provider that is next in line can
+// batch. When it cannot, the requests are sent one by one with the usual failover. A
+// batch the provider rejects is split in halves until the rejection is narrowed down
+// to the requests that caused it. A batch call that fails transiently may have been
+// accepted after all, so it is not sent again: each request gets the error and is
+// left to the delivery retries.
+func (u *UnifiedEmailSender) SendBatch(reqs []*EmailRequest) ([]BatchResult, error) {
+	now := time.Now()
+	for _, route := range u.attemptOrder() {
+		if !route.breaker.available(now) {
+			continue
+		}
+
+		batchSender, ok := route.sender.(BatchSender)
+		if !ok || len(reqs) < 2 || batchSender.MaxBatchSize() < len(reqs) || !route.breaker.allow(now) {
+			break
+		}
+
+		results, err := batchSender.SendBatch(reqs)
+		if err == nil {
+			route.breaker.recordSuccess()
+			for _, result := range results {
+				if result.Result != nil {
+					result.Result.Provider = route.name
+				}
+			}
+			return results, nil
+		}
+
+		if IsPermanent(err) {
+			// The provider is up but refused the batch as a whole, which says nothing
+			// about most of the emails in it
+			route.breaker.recordSuccess()
+			u.logger.Warn("Email provider rejected batch, splitting it",
+				zap.String("provider", route.name),
+				zap.Int("emails", len(reqs)),
+				zap.Error(err),
+			)
+			return u.splitBatch(reqs), nil
+		}
+
+		route.breaker.recordFailure(time.Now())
+		u.logger.Warn("Email provider failed to send batch",
+			zap.String("provider", route.name),
+			zap.Int("emails", len(reqs)),
+			zap.Error(err),
+		)
+		results = make([]BatchResult, len(reqs))
+		for i := range results {
+			results[i].Err = err
+		}
+		return results, nil
 	}
 
-	if u.smtpSender != nil {
-		u.logger.Debug("Sending email via SMTP")
-		return u.smtpSender.Send(req)
+	results := make([]BatchResult, len(reqs))
+	for i, req := range reqs {
+		result, err := u.Send(req)
+		results[i] = BatchResult{Result: result, Err: err}
 	}
+	return results, nil
+}
 
-	u.logger.Error("No email sender configured")
-	return fmt.Errorf("no email sender configured")
+// splitBatch sends the two halves of a rejected batch separately and returns the
+// results of both. A half that fails as a whole gets the error on each of its requests.
+func (u *UnifiedEmailSender) splitBatch(reqs []*EmailRequest) []BatchResult {
+	mid := len(reqs) / 2
+	results := make([]BatchResult, 0, len(reqs))
+	for _, half := range [][]*EmailRequest{reqs[:mid], reqs[mid:]} {
+		halfResults, err := u.SendBatch(half)
+		if err != nil {
+			halfResults = make([]BatchResult, len(half))
+			for i := range halfResults {
+				halfResults[i].Err = err
+			}
+		}
+		results = append(results, halfResults...)
+	}
+	return results
 }
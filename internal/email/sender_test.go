@@ -0,0 +1,258 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// fakeBatchProvider refuses any batch that contains the rejected address
+type fakeBatchProvider struct {
+	rejected   string
+	batchErr   error
+	batchCalls int
+	sendCalls  int
+}
+
+func (f *fakeBatchProvider) MaxBatchSize() int {
+	return 100
+}
+
+func (f *fakeBatchProvider) Send(req *EmailRequest) (*SendResult, error) {
+	f.sendCalls++
+	if req.To == f.rejected {
+		return nil, &SendError{Permanent: true, Err: errors.New("invalid recipient")}
+	}
+	return &SendResult{MessageID: "single-" + req.To}, nil
+}
+
+func (f *fakeBatchProvider) SendBatch(reqs []*EmailRequest) ([]BatchResult, error) {
+	f.batchCalls++
+	if f.batchErr != nil {
+		return nil, f.batchErr
+	}
+	for _, req := range reqs {
+		if req.To == f.rejected {
+			return nil, &SendError{Permanent: true, Err: errors.New("batch contains an invalid recipient")}
+		}
+	}
+
+	results := make([]BatchResult, len(reqs))
+	for i, req := range reqs {
+		results[i] = BatchResult{Result: &SendResult{MessageID: "batch-" + req.To}}
+	}
+	return results, nil
+}
+
+func newTestUnifiedSender(provider EmailSender) *UnifiedEmailSender {
+	return &UnifiedEmailSender{
+		routes: []*providerRoute{{
+			name:    "fake",
+			sender:  provider,
+			breaker: newCircuitBreaker(5, time.Minute),
+		}},
+		logger: zap.NewNop(),
+	}
+}
+
+func testRequests(n int) []*EmailRequest {
+	reqs := make([]*EmailRequest, n)
+	for i := range reqs {
+		reqs[i] = &EmailRequest{To: fmt.Sprintf("user%d@example.com", i), Subject: "Hi", TextBody: "Hello"}
+	}
+	return reqs
+}
+
+func TestSendBatchSplitsRejectedBatch(t *testing.T) {
+	reqs := testRequests(16)
+	provider := &fakeBatchProvider{rejected: reqs[5].To}
+	sender := newTestUnifiedSender(provider)
+
+	results, err := sender.SendBatch(reqs)
+	if err != nil {
+		t.Fatalf("SendBatch: %v", err)
+	}
+	if len(results) != len(reqs) {
+		t.Fatalf("got %d results, want %d", len(results), len(reqs))
+	}
+
+	for i, result := range results {
+		if reqs[i].To == provider.rejected {
+			if !IsPermanent(result.Err) {
+				t.Errorf("result for rejected address: err = %v, want permanent error", result.Err)
+			}
+			continue
+		}
+		if result.Err != nil {
+			t.Errorf("result %d: unexpected error %v", i, result.Err)
+			continue
+		}
+		if result.Result.Provider != "fake" {
+			t.Errorf("result %d: provider = %q, want %q", i, result.Result.Provider, "fake")
+		}
+	}
+
+	// Only the half that is down to two requests is sent one by one
+	if provider.sendCalls != 2 {
+		t.Errorf("sent %d emails individually, want 2", provider.sendCalls)
+	}
+}
+
+func TestSendBatchTransientFailureIsNotResent(t *testing.T) {
+	reqs := testRequests(4)
+	provider := &fakeBatchProvider{batchErr: transientError("service unavailable")}
+	failover := &fakeBatchProvider{}
+	sender := newTestUnifiedSender(provider)
+	sender.routes = append(sender.routes, &providerRoute{name: "failover", sender: failover, breaker: newCircuitBreaker(5, time.Minute)})
+
+	// The provider may have accepted the batch before failing
+	results, err := sender.SendBatch(reqs)
+	if err != nil {
+		t.Fatalf("SendBatch: %v", err)
+	}
+	if len(results) != len(reqs) {
+		t.Fatalf("%d results for %d requests", len(results), len(reqs))
+	}
+	for i, result := range results {
+		if result.Err == nil || IsPermanent(result.Err) {
+			t.Errorf("result %d: error %v, want the transient batch error", i, result.Err)
+		}
+	}
+	if provider.batchCalls != 1 || provider.sendCalls != 0 {
+		t.Errorf("batch calls = %d, individual sends = %d; want 1 and 0", provider.batchCalls, provider.sendCalls)
+	}
+	if failover.batchCalls != 0 || failover.sendCalls != 0 {
+		t.Errorf("failover provider was used: %d batch calls, %d sends", failover.batchCalls, failover.sendCalls)
+	}
+}
+
+func TestSendBatchPermanentErrorIsPerRequest(t *testing.T) {
+	reqs := testRequests(3)
+	provider := &fakeBatchProvider{batchErr: &SendError{Permanent: true, Err: errors.New("bad request")}}
+	sender := newTestUnifiedSender(provider)
+
+	results, err := sender.SendBatch(reqs)
+	if err != nil {
+		t.Fatalf("SendBatch: %v", err)
+	}
+	// Each request ends up on its own and is accepted when sent individually
+	for i, result := range results {
+		if result.Err != nil {
+			t.Errorf("result %d: unexpected error %v", i, result.Err)
+		}
+	}
+}
+
+// closingProvider records whether it was closed
+type closingProvider struct {
+	fakeBatchProvider
+	closed bool
+	err    error
+}
+
+func (c *closingProvider) Close() error {
+	c.closed = true
+	return c.err
+}
+
+func TestCloseClosesProviders(t *testing.T) {
+	first := &closingProvider{err: errors.New("quit failed")}
+	second := &closingProvider{}
+	u := &UnifiedEmailSender{
+		routes: []*providerRoute{
+			{name: "first", sender: first},
+			{name: "plain", sender: &fakeBatchProvider{}},
+			{name: "second", sender: second},
+		},
+		logger: zap.NewNop(),
+	}
+
+	err := u.Close()
+	if !first.closed || !second.closed {
+		t.Errorf("closed = %t, %t; want every provider closed", first.closed, second.closed)
+	}
+	if !errors.Is(err, first.err) {
+		t.Errorf("Close() = %v, want %v", err, first.err)
+	}
+}
+
+// singleProvider sends one email per call
+type singleProvider struct{}
+
+func (singleProvider) Send(req *EmailRequest) (*SendResult, error) {
+	return &SendResult{MessageID: "single-" + req.To}, nil
+}
+
+func TestMaxBatchSizeFollowsPrimaryProvider(t *testing.T) {
+	route := func(name string, sender EmailSender) *providerRoute {
+		return &providerRoute{name: name, sender: sender, breaker: newCircuitBreaker(1, time.Minute)}
+	}
+
+	tests := []struct {
+		name        string
+		routes      []*providerRoute
+		openFirst   bool
+		wantSize    int
+		wantBatches int
+	}{
+		{"batching primary", []*providerRoute{route("brevo", &fakeBatchProvider{}), route("ses", singleProvider{})}, false, 100, 1},
+		{"batching failover only", []*providerRoute{route("ses", singleProvider{}), route("brevo", &fakeBatchProvider{})}, false, 1, 0},
+		{"batching primary circuit open", []*providerRoute{route("brevo", &fakeBatchProvider{}), route("ses", singleProvider{})}, true, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.openFirst {
+				tt.routes[0].breaker.recordFailure(time.Now())
+			}
+			u := &UnifiedEmailSender{routes: tt.routes, logger: zap.NewNop()}
+
+			if size := u.MaxBatchSize(); size != tt.wantSize {
+				t.Errorf("MaxBatchSize() = %d, want %d", size, tt.wantSize)
+			}
+
+			// SendBatch must agree with the reported size
+			if _, err := u.SendBatch(testRequests(4)); err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range tt.routes {
+				if fake, ok := r.sender.(*fakeBatchProvider); ok && fake.batchCalls != tt.wantBatches {
+					t.Errorf("%d batch calls, want %d", fake.batchCalls, tt.wantBatches)
+				}
+			}
+		})
+	}
+}
+
+// unconfirmedProvider fails every send after the message may have been accepted
+type unconfirmedProvider struct {
+	sendCalls int
+}
+
+func (p *unconfirmedProvider) Send(req *EmailRequest) (*SendResult, error) {
+	p.sendCalls++
+	return nil, &SendError{OutcomeUnknown: true, Err: errors.New("connection reset after data")}
+}
+
+func TestSendDoesNotFailOverWhenOutcomeUnknown(t *testing.T) {
+	primary := &unconfirmedProvider{}
+	failover := &fakeBatchProvider{}
+	u := &UnifiedEmailSender{
+		routes: []*providerRoute{
+			{name: "smtp", sender: primary, breaker: newCircuitBreaker(5, time.Minute)},
+			{name: "brevo", sender: failover, breaker: newCircuitBreaker(5, time.Minute)},
+		},
+		logger: zap.NewNop(),
+	}
+
+	_, err := u.Send(testRequests(1)[0])
+	if !IsOutcomeUnknown(err) {
+		t.Fatalf("Send() error = %v, want outcome unknown", err)
+	}
+	if primary.sendCalls != 1 || failover.sendCalls != 0 {
+		t.Errorf("primary sent %d times and failover %d times, want 1 and 0", primary.sendCalls, failover.sendCalls)
+	}
+}
@@ -0,0 +1,110 @@
+package email
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SendGridConfig holds SendGrid v3 API configuration
+type SendGridConfig struct {
+	APIKey    string
+	FromEmail string
+	FromName  string
+	BaseURL   string
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// sendGridMailRequest represents a SendGrid mail send request
+type sendGridMailRequest struct {
+	Personalizations []struct {
+		To []sendGridAddress `json:"to"`
+	} `json:"personalizations"`
+	From    sendGridAddress   `json:"from"`
+	Subject string            `json:"subject"`
+	Content []sendGridContent `json:"content"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// SendGridSender sends email through the SendGrid v3 mail send API
+type SendGridSender struct {
+	config *SendGridConfig
+	client *http.Client
+	logger *zap.Logger
+}
+
+// NewSendGridSender creates a new SendGrid sender
+func NewSendGridSender(config *SendGridConfig, logger *zap.Logger) *SendGridSender {
+	return &SendGridSender{
+		config: config,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// Send sends an email via the SendGrid API
+func (s *SendGridSender) Send(req *EmailRequest) (*SendResult, error) {
+	mailReq := sendGridMailRequest{
+		Subject: req.Subject,
+		Headers: listUnsubscribeHeaders(req),
+	}
+	mailReq.From.Email, mailReq.From.Name = senderIdentity(req, s.config.FromEmail, s.config.FromName)
+	mailReq.Personalizations = []struct {
+		To []sendGridAddress `json:"to"`
+	}{
+		{To: []sendGridAddress{{Email: req.To}}},
+	}
+
+	// SendGrid requires text/plain to precede text/html and rejects empty values
+	if req.TextBody != "" {
+		mailReq.Content = append(mailReq.Content, sendGridContent{Type: "text/plain", Value: req.TextBody})
+	}
+	if req.HTMLBody != "" {
+		mailReq.Content = append(mailReq.Content, sendGridContent{Type: "text/html", Value: req.HTMLBody})
+	}
+
+	jsonData, err := json.Marshal(mailReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sendgrid request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, strings.TrimRight(s.config.BaseURL, "/")+"/v3/mail/send", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sendgrid request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+s.config.APIKey)
+
+	resp, _, err := doProviderRequest(s.client, httpReq, "sendgrid")
+	if err != nil {
+		s.logger.Error("Failed to send email via SendGrid", zap.String("to", req.To), zap.Error(err))
+		return nil, err
+	}
+
+	// SendGrid answers 202 with an empty body and reports the message ID in a header
+	messageID := resp.Header.Get("X-Message-Id")
+
+	s.logger.Info("Email sent successfully via SendGrid",
+		zap.String("to", req.To),
+		zap.String("subject", req.Subject),
+		zap.String("message_id", messageID),
+	)
+
+	return &SendResult{MessageID: messageID}, nil
+}
@@ -0,0 +1,54 @@
+package email
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestSendGridSenderSend(t *testing.T) {
+	server, captured := newProviderServer(t, http.StatusAccepted, map[string]string{"X-Message-Id": "sg-message-1"}, "")
+	sender := NewSendGridSender(&SendGridConfig{APIKey: "SG.key", FromEmail: "default@example.com", BaseURL: server.URL + "/"}, zap.NewNop())
+
+	result, err := sender.Send(testProviderRequest())
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if result.MessageID != "sg-message-1" {
+		t.Errorf("message ID = %q", result.MessageID)
+	}
+
+	if captured.method != http.MethodPost || captured.path != "/v3/mail/send" {
+		t.Errorf("request %s %s", captured.method, captured.path)
+	}
+	if auth := captured.header.Get("Authorization"); auth != "Bearer SG.key" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if ct := captured.header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	var body sendGridMailRequest
+	if err := json.Unmarshal(captured.body, &body); err != nil {
+		t.Fatalf("decoding request: %v", err)
+	}
+	if body.From != (sendGridAddress{Email: "news@example.com", Name: "Example News"}) {
+		t.Errorf("from = %+v", body.From)
+	}
+	if len(body.Personalizations) != 1 || len(body.Personalizations[0].To) != 1 || body.Personalizations[0].To[0].Email != "jane@example.org" {
+		t.Errorf("personalizations = %+v", body.Personalizations)
+	}
+	if body.Subject != "Grüße" {
+		t.Errorf("subject = %q", body.Subject)
+	}
+	// text/plain must come first
+	want := []sendGridContent{{Type: "text/plain", Value: "Hello"}, {Type: "text/html", Value: "<p>Hello</p>"}}
+	if len(body.Content) != 2 || body.Content[0] != want[0] || body.Content[1] != want[1] {
+		t.Errorf("content = %+v, want %+v", body.Content, want)
+	}
+	if body.Headers["List-Unsubscribe-Post"] != "List-Unsubscribe=One-Click" {
+		t.Errorf("headers = %v", body.Headers)
+	}
+}
@@ -0,0 +1,181 @@
+package email
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SESConfig holds Amazon SES v2 API configuration. BaseURL defaults to the
+// regional SES endpoint.
+type SESConfig struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	FromEmail       string
+	FromName        string
+	BaseURL         string
+}
+
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset,omitempty"`
+}
+
+type sesHeader struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+// sesSendEmailRequest represents an SES v2 SendEmail request with simple content
+type sesSendEmailRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	Content struct {
+		Simple struct {
+			Subject sesContent `json:"Subject"`
+			Body    struct {
+				Text *sesContent `json:"Text,omitempty"`
+				Html *sesContent `json:"Html,omitempty"`
+			} `json:"Body"`
+			Headers []sesHeader `json:"Headers,omitempty"`
+		} `json:"Simple"`
+	} `json:"Content"`
+}
+
+// sesSendEmailResponse represents the SES v2 response to an accepted email
+type sesSendEmailResponse struct {
+	MessageID string `json:"MessageId"`
+}
+
+// SESSender sends email through the Amazon SES v2 API, signing requests with AWS Signature Version 4
+type SESSender struct {
+	config *SESConfig
+	client *http.Client
+	logger *zap.Logger
+}
+
+// NewSESSender creates a new SES sender
+func NewSESSender(config *SESConfig, logger *zap.Logger) *SESSender {
+	return &SESSender{
+		config: config,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// Send sends an email via the SES v2 API
+func (s *SESSender) Send(req *EmailRequest) (*SendResult, error) {
+	fromEmail, fromName := senderIdentity(req, s.config.FromEmail, s.config.FromName)
+
+	var sesReq sesSendEmailRequest
+	sesReq.FromEmailAddress = formatAddress(fromEmail, fromName)
+	sesReq.Destination.ToAddresses = []string{req.To}
+	sesReq.Content.Simple.Subject = sesContent{Data: req.Subject, Charset: "UTF-8"}
+	if req.TextBody != "" {
+		sesReq.Content.Simple.Body.Text = &sesContent{Data: req.TextBody, Charset: "UTF-8"}
+	}
+	if req.HTMLBody != "" {
+		sesReq.Content.Simple.Body.Html = &sesContent{Data: req.HTMLBody, Charset: "UTF-8"}
+	}
+	headers := listUnsubscribeHeaders(req)
+	for _, name := range sortedHeaderNames(headers) {
+		sesReq.Content.Simple.Headers = append(sesReq.Content.Simple.Headers, sesHeader{Name: name, Value: headers[name]})
+	}
+
+	jsonData, err := json.Marshal(sesReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ses request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, s.baseURL()+"/v2/email/outbound-emails", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ses request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	s.sign(httpReq, jsonData, time.Now())
+
+	_, body, err := doProviderRequest(s.client, httpReq, "ses")
+	if err != nil {
+		s.logger.Error("Failed to send email via SES", zap.String("to", req.To), zap.Error(err))
+		return nil, err
+	}
+
+	var sesResp sesSendEmailResponse
+	if err := json.Unmarshal(body, &sesResp); err != nil {
+		s.logger.Warn("Failed to decode SES response", zap.Error(err))
+	}
+
+	s.logger.Info("Email sent successfully via SES",
+		zap.String("to", req.To),
+		zap.String("subject", req.Subject),
+		zap.String("message_id", sesResp.MessageID),
+	)
+
+	return &SendResult{MessageID: sesResp.MessageID}, nil
+}
+
+func (s *SESSender) baseURL() string {
+	if s.config.BaseURL != "" {
+		return strings.TrimRight(s.config.BaseURL, "/")
+	}
+	return fmt.Sprintf("https://email.%s.amazonaws.com", s.config.Region)
+}
+
+// sign adds an AWS Signature Version 4 Authorization header for the SES service
+func (s *SESSender) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	signedHeaders := "content-type;host;x-amz-date"
+	canonicalHeaders := "content-type:" + req.Header.Get("Content-Type") + "\n" +
+		"host:" + req.URL.Host + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/ses/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), date)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "ses")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,145 @@
+package email
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// verifySigV4 recomputes the Signature Version 4 of a received SES request
+// (AWS General Reference, "Create a signed AWS API request") and compares it
+func verifySigV4(t *testing.T, r *capturedRequest, accessKeyID, secret, region string) {
+	t.Helper()
+
+	auth := regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=([^/]+)/(\d{8})/([^/]+)/ses/aws4_request, SignedHeaders=([a-z0-9;-]+), Signature=([0-9a-f]{64})$`).
+		FindStringSubmatch(r.header.Get("Authorization"))
+	if auth == nil {
+		t.Fatalf("malformed Authorization header %q", r.header.Get("Authorization"))
+	}
+	if auth[1] != accessKeyID || auth[3] != region {
+		t.Errorf("credential %s/%s, want %s/%s", auth[1], auth[3], accessKeyID, region)
+	}
+
+	amzDate := r.header.Get("X-Amz-Date")
+	if _, err := time.Parse("20060102T150405Z", amzDate); err != nil || amzDate[:8] != auth[2] {
+		t.Fatalf("X-Amz-Date %q does not match the credential date %s", amzDate, auth[2])
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range strings.Split(auth[4], ";") {
+		value := r.header.Get(name)
+		if name == "host" {
+			value = r.host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	if !strings.Contains(";"+auth[4]+";", ";host;") || !strings.Contains(";"+auth[4]+";", ";x-amz-date;") {
+		t.Errorf("SignedHeaders=%s must cover host and x-amz-date", auth[4])
+	}
+
+	canonicalRequest := strings.Join([]string{r.method, r.path, "", canonicalHeaders.String(), auth[4], sha256Hex(r.body)}, "\n")
+	scope := fmt.Sprintf("%s/%s/ses/aws4_request", auth[2], region)
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := []byte("AWS4" + secret)
+	for _, part := range []string{auth[2], region, "ses", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	if want := fmt.Sprintf("%x", hmacSHA256(key, stringToSign)); auth[5] != want {
+		t.Errorf("signature %s, want %s", auth[5], want)
+	}
+}
+
+func TestSESSenderSend(t *testing.T) {
+	server, captured := newProviderServer(t, http.StatusOK, nil, `{"MessageId":"0100018c-ses-message"}`)
+	sender := NewSESSender(&SESConfig{
+		Region:          "eu-west-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		FromEmail:       "default@example.com",
+		BaseURL:         server.URL,
+	}, zap.NewNop())
+
+	result, err := sender.Send(testProviderRequest())
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if result.MessageID != "0100018c-ses-message" {
+		t.Errorf("message ID = %q", result.MessageID)
+	}
+
+	if captured.method != http.MethodPost || captured.path != "/v2/email/outbound-emails" {
+		t.Errorf("request %s %s", captured.method, captured.path)
+	}
+	if ct := captured.header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	verifySigV4(t, captured, "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "eu-west-1")
+
+	var body sesSendEmailRequest
+	if err := json.Unmarshal(captured.body, &body); err != nil {
+		t.Fatalf("decoding request: %v", err)
+	}
+	if body.FromEmailAddress != `"Example News" <news@example.com>` {
+		t.Errorf("FromEmailAddress = %q", body.FromEmailAddress)
+	}
+	if len(body.Destination.ToAddresses) != 1 || body.Destination.ToAddresses[0] != "jane@example.org" {
+		t.Errorf("ToAddresses = %v", body.Destination.ToAddresses)
+	}
+	simple := body.Content.Simple
+	if simple.Subject.Data != "Grüße" || simple.Subject.Charset != "UTF-8" {
+		t.Errorf("Subject = %+v", simple.Subject)
+	}
+	if simple.Body.Text == nil || simple.Body.Text.Data != "Hello" || simple.Body.Html == nil || simple.Body.Html.Data != "<p>Hello</p>" {
+		t.Errorf("Body = %+v", simple.Body)
+	}
+	if len(simple.Headers) != 2 || simple.Headers[0].Name != "List-Unsubscribe" || simple.Headers[1].Value != "List-Unsubscribe=One-Click" {
+		t.Errorf("Headers = %+v", simple.Headers)
+	}
+}
+
+func TestSESSenderSignatureChangesWithBody(t *testing.T) {
+	sender := NewSESSender(&SESConfig{Region: "us-east-1", AccessKeyID: "AKID", SecretAccessKey: "secret"}, zap.NewNop())
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	signature := func(body string) string {
+		req, err := http.NewRequest(http.MethodPost, sender.baseURL()+"/v2/email/outbound-emails", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		sender.sign(req, []byte(body), now)
+		if got := req.Header.Get("X-Amz-Date"); got != "20260301T080000Z" {
+			t.Errorf("X-Amz-Date = %q", got)
+		}
+		return req.Header.Get("Authorization")
+	}
+
+	if sender.baseURL() != "https://email.us-east-1.amazonaws.com" {
+		t.Errorf("base URL = %q", sender.baseURL())
+	}
+	if signature(`{"a":1}`) == signature(`{"a":2}`) {
+		t.Error("signature does not cover the body")
+	}
+	if signature(`{"a":1}`) != signature(`{"a":1}`) {
+		t.Error("signature is not deterministic")
+	}
+}
+
+func TestNewSESProviderRequiresRegion(t *testing.T) {
+	config := &UnifiedConfig{SES: &SESConfig{AccessKeyID: "AKID", SecretAccessKey: "secret"}}
+	if _, err := NewProvider("ses", config, zap.NewNop()); err == nil {
+		t.Error("accepted an SES configuration without region")
+	}
+
+	config.SES.Region = "eu-west-1"
+	if _, err := NewProvider("ses", config, zap.NewNop()); err != nil {
+		t.Errorf("rejected a complete SES configuration: %v", err)
+	}
+}
@@ -2,8 +2,7 @@ package email
 
 import (
 	"fmt"
-	"net/smtp"
-	"strings"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -16,6 +15,23 @@ type SMTPConfig struct {
 	Password  string
 	FromEmail string
 	FromName  string
+
+	// TLSMode is "starttls" (required, the default), "implicit" for TLS from the
+	// first byte (port 465) or "none" for plaintext local development servers
+	TLSMode string
+	// AuthMechanism is "plain" (the default), "login" or "cram-md5"
+	AuthMechanism string
+
+	// PoolSize caps the open connections; idle ones are reused for further messages
+	PoolSize                 int
+	PoolIdleTimeout          time.Duration
+	MaxMessagesPerConnection int
+
+	// DKIMDomain enables DKIM signing with the PEM encoded RSA or Ed25519 DKIMPrivateKey,
+	// published under DKIMSelector._domainkey.DKIMDomain
+	DKIMDomain     string
+	DKIMSelector   string
+	DKIMPrivateKey string
 }
 
 // EmailRequest represents an email to be sent
@@ -24,95 +40,99 @@ type EmailRequest struct {
 	Subject  string
 	HTMLBody string
 	TextBody string
+
+	// ListUnsubscribeURL is advertised via RFC 8058 one-click unsubscribe headers
+	ListUnsubscribeURL string
+
+	// FromEmail and FromName override the configured sender identity when set
+	FromEmail string
+	FromName  string
+}
+
+// senderIdentity returns the sender of a request, falling back to the configured one
+func senderIdentity(req *EmailRequest, fromEmail, fromName string) (string, string) {
+	if req.FromEmail == "" {
+		return fromEmail, fromName
+	}
+	if req.FromName == "" {
+		return req.FromEmail, fromName
+	}
+	return req.FromEmail, req.FromName
+}
+
+// listUnsubscribeHeaders returns the RFC 8058 headers for a request, if any
+func listUnsubscribeHeaders(req *EmailRequest) map[string]string {
+	if req.ListUnsubscribeURL == "" {
+		return nil
+	}
+
+	return map[string]string{
+		"List-Unsubscribe":      fmt.Sprintf("<%s>", req.ListUnsubscribeURL),
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
 }
 
 // SMTPSender handles SMTP email sending
 type SMTPSender struct {
 	config *SMTPConfig
+	pool   *smtpPool
+	dkim   *dkimSigner
 	logger *zap.Logger
 }
 
 // NewSMTPSender creates a new SMTP sender
-func NewSMTPSender(config *SMTPConfig, logger *zap.Logger) *SMTPSender {
-	return &SMTPSender{
+func NewSMTPSender(config *SMTPConfig, logger *zap.Logger) (*SMTPSender, error) {
+	sender := &SMTPSender{
 		config: config,
+		pool:   newSMTPPool(config),
 		logger: logger,
 	}
+
+	if config.DKIMDomain != "" {
+		signer, err := newDKIMSigner(config.DKIMDomain, config.DKIMSelector, config.DKIMPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		sender.dkim = signer
+	}
+	return sender, nil
 }
 
-// Send sends an email via SMTP
-func (s *SMTPSender) Send(req *EmailRequest) error {
-	// Create SMTP auth
-	auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
-	
+// Send sends an email via SMTP over a pooled session
+func (s *SMTPSender) Send(req *EmailRequest) (*SendResult, error) {
 	// Build email message
-	message := s.buildMessage(req)
-	
-	// SMTP server address
-	addr := s.config.Host + ":" + s.config.Port
+	fromEmail, fromName := senderIdentity(req, s.config.FromEmail, s.config.FromName)
+	message, messageID, err := buildMIMEMessage(req, fromEmail, fromName, time.Now())
+	if err != nil {
+		return nil, &SendError{Permanent: true, Err: err}
+	}
+	if s.dkim != nil {
+		if message, err = s.dkim.sign(message, time.Now()); err != nil {
+			return nil, &SendError{Permanent: true, Err: err}
+		}
+	}
 	
-	// Send email using Go's built-in SMTP with STARTTLS
-	err := smtp.SendMail(addr, auth, s.config.FromEmail, []string{req.To}, message)
+	err = s.pool.send(fromEmail, req.To, message)
 	if err != nil {
 		s.logger.Error("Failed to send email",
 			zap.String("to", req.To),
 			zap.String("subject", req.Subject),
 			zap.Error(err),
 		)
-		return fmt.Errorf("failed to send email to %s: %w", req.To, err)
+		return nil, classifySMTPError(fmt.Errorf("failed to send email to %s: %w", req.To, err))
 	}
 	
 	s.logger.Debug("Email sent successfully",
 		zap.String("to", req.To),
 		zap.String("subject", req.Subject),
+		zap.String("message_id", messageID),
 	)
 	
-	return nil
+	return &SendResult{MessageID: messageID}, nil
 }
 
-
-// buildMessage constructs the email message
-func (s *SMTPSender) buildMessage(req *EmailRequest) []byte {
-	var message strings.Builder
-	
-	// Headers
-	message.WriteString(fmt.Sprintf("From: %s <%s>\r\n", s.config.FromName, s.config.FromEmail))
-	message.WriteString(fmt.Sprintf("To: %s\r\n", req.To))
-	message.WriteString(fmt.Sprintf("Subject: %s\r\n", req.Subject))
-	message.WriteString("MIME-Version: 1.0\r\n")
-	
-	// If both HTML and text body are provided, create multipart
-	if req.HTMLBody != "" && req.TextBody != "" {
-		boundary := "boundary-newsletter-email"
-		message.WriteString(fmt.Sprintf("Content-Type: multipart/alternative; boundary=%s\r\n", boundary))
-		message.WriteString("\r\n")
-		
-		// Text part
-		message.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-		message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
-		message.WriteString("\r\n")
-		message.WriteString(req.TextBody)
-		message.WriteString("\r\n")
-		
-		// HTML part
-		message.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-		message.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
-		message.WriteString("\r\n")
-		message.WriteString(req.HTMLBody)
-		message.WriteString("\r\n")
-		
-		message.WriteString(fmt.Sprintf("--%s--\r\n", boundary))
-	} else if req.HTMLBody != "" {
-		// HTML only
-		message.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
-		message.WriteString("\r\n")
-		message.WriteString(req.HTMLBody)
-	} else {
-		// Text only
-		message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
-		message.WriteString("\r\n")
-		message.WriteString(req.TextBody)
-	}
-	
-	return []byte(message.String())
+// Close ends the pooled SMTP sessions with QUIT
+func (s *SMTPSender) Close() error {
+	s.pool.close()
+	return nil
 }
@@ -0,0 +1,333 @@
+package email
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+
+	"newsletter-assignment/internal/constants"
+)
+
+// smtpConn is an authenticated SMTP session that can carry several messages
+type smtpConn struct {
+	conn     net.Conn
+	client   *smtp.Client
+	messages int
+	lastUsed time.Time
+}
+
+// smtpPool keeps authenticated SMTP sessions open so that consecutive messages
+// skip the TCP, TLS and AUTH handshakes. Sessions are reset with RSET between
+// messages and replaced after MaxMessagesPerConnection messages or when idle too long.
+type smtpPool struct {
+	config *SMTPConfig
+	slots  chan struct{}
+	idle   chan *smtpConn
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func newSMTPPool(config *SMTPConfig) *smtpPool {
+	size := config.PoolSize
+	if size <= 0 {
+		size = constants.DefaultSMTPPoolSize
+	}
+
+	return &smtpPool{
+		config: config,
+		slots:  make(chan struct{}, size),
+		idle:   make(chan *smtpConn, size),
+	}
+}
+
+// validateSMTPConfig rejects unknown TLS modes and authentication mechanisms
+func validateSMTPConfig(config *SMTPConfig) error {
+	switch strings.ToLower(config.TLSMode) {
+	case "", constants.SMTPTLSModeStartTLS, constants.SMTPTLSModeImplicit, constants.SMTPTLSModeNone:
+	default:
+		return fmt.Errorf("unknown SMTP TLS mode %q", config.TLSMode)
+	}
+
+	switch strings.ToLower(config.AuthMechanism) {
+	case "", constants.SMTPAuthPlain, constants.SMTPAuthLogin, constants.SMTPAuthCRAMMD5:
+	default:
+		return fmt.Errorf("unknown SMTP auth mechanism %q", config.AuthMechanism)
+	}
+
+	return nil
+}
+
+// send delivers one message over a pooled session. A reused session that fails
+// without an SMTP reply before the message data was sent was most likely closed by
+// the server, so the message is tried once more on a fresh session. Once the data
+// has gone out the server may have accepted the message even though the session
+// failed, and sending it again could deliver it twice; such failures are returned
+// as outcome unknown.
+func (p *smtpPool) send(from, to string, message []byte) error {
+	p.slots <- struct{}{}
+	defer func() { <-p.slots }()
+
+	conn, err := p.get()
+	if err != nil {
+		return err
+	}
+
+	dataSent, err := p.deliver(conn, from, to, message)
+	if err == nil {
+		p.put(conn)
+		return nil
+	}
+	p.discard(conn)
+
+	var protoErr *textproto.Error
+	if conn.messages == 0 || dataSent || errors.As(err, &protoErr) {
+		return deliveryError(err, dataSent)
+	}
+
+	conn, err = p.dial()
+	if err != nil {
+		return err
+	}
+	if dataSent, err := p.deliver(conn, from, to, message); err != nil {
+		p.discard(conn)
+		return deliveryError(err, dataSent)
+	}
+	p.put(conn)
+	return nil
+}
+
+// deliveryError marks a failure without SMTP reply after the message data went out as
+// outcome unknown. A reply, even after the data, tells for sure whether the server
+// took the message.
+func deliveryError(err error, dataSent bool) error {
+	var protoErr *textproto.Error
+	if dataSent && !errors.As(err, &protoErr) {
+		return fmt.Errorf("%w: %w", errOutcomeUnknown, err)
+	}
+	return err
+}
+
+// get returns an idle session that has not timed out, or a new one
+func (p *smtpPool) get() (*smtpConn, error) {
+	idleTimeout := p.config.PoolIdleTimeout
+	if idleTimeout <= 0 {
+		idleTimeout = constants.DefaultSMTPPoolIdleTimeout
+	}
+
+	for {
+		select {
+		case conn := <-p.idle:
+			if time.Since(conn.lastUsed) < idleTimeout {
+				return conn, nil
+			}
+			p.discard(conn)
+		default:
+			return p.dial()
+		}
+	}
+}
+
+// put resets a session after a successful message and keeps it for reuse
+func (p *smtpPool) put(conn *smtpConn) {
+	maxMessages := p.config.MaxMessagesPerConnection
+	if maxMessages <= 0 {
+		maxMessages = constants.DefaultSMTPMaxMessagesPerConnection
+	}
+
+	if conn.messages >= maxMessages {
+		p.quit(conn)
+		return
+	}
+
+	if err := conn.client.Reset(); err != nil {
+		p.discard(conn)
+		return
+	}
+
+	conn.lastUsed = time.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		p.quit(conn)
+		return
+	}
+
+	select {
+	case p.idle <- conn:
+	default:
+		p.discard(conn)
+	}
+}
+
+// discard closes a session that must not be reused
+func (p *smtpPool) discard(conn *smtpConn) {
+	conn.client.Close()
+}
+
+// quit ends a session politely with QUIT
+func (p *smtpPool) quit(conn *smtpConn) {
+	conn.conn.SetDeadline(time.Now().Add(constants.DefaultSMTPTimeout))
+	if err := conn.client.Quit(); err != nil {
+		p.discard(conn)
+	}
+}
+
+// close ends the idle sessions with QUIT on shutdown. Sessions that are still
+// sending are ended when they are returned to the pool.
+func (p *smtpPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+
+	for {
+		select {
+		case conn := <-p.idle:
+			p.quit(conn)
+		default:
+			return
+		}
+	}
+}
+
+// deliver sends one message over a session. dataSent reports whether the failure
+// came after the message data started going out, when the server may have accepted it.
+func (p *smtpPool) deliver(conn *smtpConn, from, to string, message []byte) (dataSent bool, err error) {
+	conn.conn.SetDeadline(time.Now().Add(constants.DefaultSMTPTimeout))
+
+	if err := conn.client.Mail(from); err != nil {
+		return false, err
+	}
+	if err := conn.client.Rcpt(to); err != nil {
+		return false, err
+	}
+
+	w, err := conn.client.Data()
+	if err != nil {
+		return false, err
+	}
+	if _, err := w.Write(message); err != nil {
+		return true, err
+	}
+	if err := w.Close(); err != nil {
+		return true, err
+	}
+
+	conn.messages++
+	return true, nil
+}
+
+// dial opens and authenticates a new session in the configured TLS mode
+func (p *smtpPool) dial() (*smtpConn, error) {
+	addr := net.JoinHostPort(p.config.Host, p.config.Port)
+	tlsConfig := &tls.Config{ServerName: p.config.Host}
+	dialer := &net.Dialer{Timeout: constants.DefaultSMTPTimeout}
+
+	var netConn net.Conn
+	var err error
+	if strings.ToLower(p.config.TLSMode) == constants.SMTPTLSModeImplicit {
+		netConn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		netConn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	netConn.SetDeadline(time.Now().Add(constants.DefaultSMTPTimeout))
+
+	client, err := smtp.NewClient(netConn, p.config.Host)
+	if err != nil {
+		netConn.Close()
+		return nil, fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+
+	if err := p.secure(client, tlsConfig); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	if p.config.Username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("SMTP server does not support authentication")
+		}
+		if err := client.Auth(p.auth()); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	return &smtpConn{conn: netConn, client: client, lastUsed: time.Now()}, nil
+}
+
+// secure upgrades the session with STARTTLS unless TLS is implicit or disabled.
+// STARTTLS is required: a server that does not offer it is refused.
+func (p *smtpPool) secure(client *smtp.Client, tlsConfig *tls.Config) error {
+	switch strings.ToLower(p.config.TLSMode) {
+	case constants.SMTPTLSModeImplicit, constants.SMTPTLSModeNone:
+		return nil
+	}
+
+	if ok, _ := client.Extension("STARTTLS"); !ok {
+		return fmt.Errorf("SMTP server does not support STARTTLS")
+	}
+	if err := client.StartTLS(tlsConfig); err != nil {
+		return fmt.Errorf("STARTTLS failed: %w", err)
+	}
+	return nil
+}
+
+func (p *smtpPool) auth() smtp.Auth {
+	switch strings.ToLower(p.config.AuthMechanism) {
+	case constants.SMTPAuthLogin:
+		return &loginAuth{username: p.config.Username, password: p.config.Password, host: p.config.Host}
+	case constants.SMTPAuthCRAMMD5:
+		return smtp.CRAMMD5Auth(p.config.Username, p.config.Password)
+	default:
+		return smtp.PlainAuth("", p.config.Username, p.config.Password, p.config.Host)
+	}
+}
+
+// loginAuth implements the LOGIN mechanism, which net/smtp does not provide. Like
+// PLAIN it refuses to send credentials over an unencrypted connection to a remote host.
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+	}
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
@@ -0,0 +1,270 @@
+package email
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"newsletter-assignment/internal/constants"
+
+	"go.uber.org/zap"
+)
+
+// fakeSMTPServer is a plaintext SMTP server that records what it receives. It can
+// drop sessions at the points where real servers and networks drop them.
+type fakeSMTPServer struct {
+	listener net.Listener
+
+	// dropAfterReset closes a session right after RSET, as a server does with
+	// sessions it considers idle
+	dropAfterReset bool
+	// dropAfterData closes the session instead of replying to the end of the data
+	// of the given message, counted from 1 across all sessions
+	dropAfterData int
+	// rejectData answers the end of every message's data with a 554 reply
+	rejectData bool
+
+	mu       sync.Mutex
+	conns    []net.Conn
+	sessions int
+	messages []string
+	quits    int
+	wg       sync.WaitGroup
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTPServer{listener: listener}
+	go s.serve()
+	t.Cleanup(func() {
+		listener.Close()
+		s.mu.Lock()
+		for _, conn := range s.conns {
+			conn.Close()
+		}
+		s.mu.Unlock()
+		s.wg.Wait()
+	})
+	return s
+}
+
+func (s *fakeSMTPServer) config() *SMTPConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return &SMTPConfig{Host: host, Port: port, TLSMode: constants.SMTPTLSModeNone, PoolSize: 1}
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.sessions++
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.session(conn)
+		}()
+	}
+}
+
+func (s *fakeSMTPServer) session(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		switch verb := strings.ToUpper(strings.Fields(line + " x")[0]); verb {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL", "RCPT", "NOOP":
+			reply("250 OK")
+		case "RSET":
+			reply("250 OK")
+			if s.dropAfterReset {
+				return
+			}
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+
+			s.mu.Lock()
+			s.messages = append(s.messages, data.String())
+			drop := len(s.messages) == s.dropAfterData
+			s.mu.Unlock()
+			if drop {
+				return
+			}
+			if s.rejectData {
+				reply("554 Message rejected")
+				continue
+			}
+			reply("250 OK queued")
+		case "QUIT":
+			s.mu.Lock()
+			s.quits++
+			s.mu.Unlock()
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) stats() (sessions, messages, quits int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions, len(s.messages), s.quits
+}
+
+func TestSMTPPoolReusesSessions(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	pool := newSMTPPool(server.config())
+
+	for i := 0; i < 3; i++ {
+		if err := pool.send("news@example.com", "jane@example.org", []byte("Subject: hi\r\n\r\nHello\r\n")); err != nil {
+			t.Fatalf("send %d: %v", i, err)
+		}
+	}
+
+	if sessions, messages, _ := server.stats(); sessions != 1 || messages != 3 {
+		t.Errorf("%d messages over %d sessions, want 3 over 1", messages, sessions)
+	}
+}
+
+func TestSMTPPoolRetriesSessionClosedBeforeData(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	server.dropAfterReset = true
+	pool := newSMTPPool(server.config())
+
+	for i := 0; i < 2; i++ {
+		if err := pool.send("news@example.com", "jane@example.org", []byte("Subject: hi\r\n\r\nHello\r\n")); err != nil {
+			t.Fatalf("send %d: %v", i, err)
+		}
+	}
+
+	// The second message finds its session closed and goes out on a new one
+	if sessions, messages, _ := server.stats(); sessions != 2 || messages != 2 {
+		t.Errorf("%d messages over %d sessions, want 2 over 2", messages, sessions)
+	}
+}
+
+func TestSMTPPoolDoesNotRetryAfterData(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	server.dropAfterData = 2
+	pool := newSMTPPool(server.config())
+
+	if err := pool.send("news@example.com", "jane@example.org", []byte("Subject: 1\r\n\r\nHello\r\n")); err != nil {
+		t.Fatalf("first send: %v", err)
+	}
+
+	// The server may have queued the second message before the session broke
+	err := pool.send("news@example.com", "jane@example.org", []byte("Subject: 2\r\n\r\nHello\r\n"))
+	if err == nil {
+		t.Fatal("send succeeded although the server never confirmed the message")
+	}
+	if sendErr := classifySMTPError(err); !IsOutcomeUnknown(sendErr) || IsPermanent(sendErr) {
+		t.Errorf("error %v is not reported as outcome unknown", err)
+	}
+
+	if sessions, messages, _ := server.stats(); sessions != 1 || messages != 2 {
+		t.Errorf("%d messages over %d sessions, want 2 over 1 without a resend", messages, sessions)
+	}
+}
+
+func TestSMTPSenderReportsDroppedSessionAfterData(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	server.dropAfterData = 1
+	sender, err := NewSMTPSender(server.config(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Not even a fresh session is retried once the data went out
+	_, err = sender.Send(&EmailRequest{To: "jane@example.org", Subject: "Hi", TextBody: "Hello"})
+	if !IsOutcomeUnknown(err) {
+		t.Fatalf("Send() error = %v, want outcome unknown", err)
+	}
+	if _, messages, _ := server.stats(); messages != 1 {
+		t.Errorf("server received %d messages, want 1", messages)
+	}
+}
+
+func TestSMTPSenderRejectionAfterDataIsPermanent(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	server.rejectData = true
+	sender, err := NewSMTPSender(server.config(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A reply after the data settles the outcome
+	_, err = sender.Send(&EmailRequest{To: "jane@example.org", Subject: "Hi", TextBody: "Hello"})
+	if !IsPermanent(err) || IsOutcomeUnknown(err) {
+		t.Errorf("Send() error = %v, want a permanent error", err)
+	}
+}
+
+func TestSMTPPoolCloseQuitsIdleSessions(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	config := server.config()
+	config.PoolSize = 2
+	pool := newSMTPPool(config)
+
+	if err := pool.send("news@example.com", "jane@example.org", []byte("Subject: hi\r\n\r\nHello\r\n")); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	sender := &SMTPSender{config: config, pool: pool}
+	if err := sender.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// A session returned after close is quit as well
+	conn, err := pool.dial()
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	pool.put(conn)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, _, quits := server.stats()
+		if quits == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d sessions quit, want 2", quits)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
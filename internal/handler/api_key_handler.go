@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/request"
+	"newsletter-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type APIKeyHandler struct {
+	apiKeyService service.APIKeyService
+	logger        *zap.Logger
+}
+
+func NewAPIKeyHandler(apiKeyService service.APIKeyService, logger *zap.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		logger:        logger,
+	}
+}
+
+// CreateAPIKey mints a key; the plaintext key is only ever shown in this response
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req request.CreateAPIKeyRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	key, rawKey, err := h.apiKeyService.CreateAPIKey(c.Request.Context(), currentWorkspaceID(c), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"api_key": key,
+		"key":     rawKey,
+	})
+}
+
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	limit, err := queryInt(c, "limit", "10")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	offset, err := queryInt(c, "offset", "0")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	keys, err := h.apiKeyService.ListAPIKeys(c.Request.Context(), currentWorkspaceID(c), limit, offset)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"api_keys": keys,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id, err := parseUUIDParam(c, "id", "api key")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = h.apiKeyService.RevokeAPIKey(c.Request.Context(), currentWorkspaceID(c), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// CurrentAPIKey returns the API key that authenticated the request, if any
+func CurrentAPIKey(c *gin.Context) *models.APIKey {
+	value, ok := c.Get(constants.ContextKeyAPIKey)
+	if !ok {
+		return nil
+	}
+	key, _ := value.(*models.APIKey)
+	return key
+}
+
+// currentWorkspaceID returns the workspace of the authenticated API key
+func currentWorkspaceID(c *gin.Context) uuid.UUID {
+	if key := CurrentAPIKey(c); key != nil {
+		return key.WorkspaceID
+	}
+	return uuid.Nil
+}
+
+// currentActorID returns the ID of the API key that made the request, for audit entries
+func currentActorID(c *gin.Context) *uuid.UUID {
+	if key := CurrentAPIKey(c); key != nil {
+		return &key.ID
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"html/template"
+	"net/http"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/service"
+	"newsletter-assignment/internal/token"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var confirmPage = template.Must(template.New("confirm").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Confirm subscription</title></head>
+<body>
+{{if .Done}}<p>Your subscription is confirmed.</p>{{else}}<form method="post" action="?token={{.Token}}">
+<p>Do you want to start receiving these emails?</p>
+<button type="submit">Confirm subscription</button>
+</form>{{end}}
+</body>
+</html>
+`))
+
+var errInvalidConfirmToken = apperr.Validation("invalid confirmation token", apperr.Field("token", "is invalid"))
+
+type ConfirmHandler struct {
+	subscriptionService service.SubscriptionService
+	signer              *token.Signer
+	logger              *zap.Logger
+}
+
+func NewConfirmHandler(subscriptionService service.SubscriptionService, signer *token.Signer, logger *zap.Logger) *ConfirmHandler {
+	return &ConfirmHandler{
+		subscriptionService: subscriptionService,
+		signer:              signer,
+		logger:              logger,
+	}
+}
+
+// ShowConfirm renders a confirmation page so that link scanners following
+// GET requests do not confirm subscriptions on the recipient's behalf
+func (h *ConfirmHandler) ShowConfirm(c *gin.Context) {
+	tok := c.Query("token")
+	if _, _, err := h.signer.VerifyConfirmSubscription(tok); err != nil {
+		c.Error(errInvalidConfirmToken)
+		return
+	}
+
+	h.renderPage(c, gin.H{"Token": tok, "Done": false})
+}
+
+// Confirm activates a double opt-in subscription
+func (h *ConfirmHandler) Confirm(c *gin.Context) {
+	workspaceID, subscriptionID, err := h.signer.VerifyConfirmSubscription(c.Query("token"))
+	if err != nil {
+		c.Error(errInvalidConfirmToken)
+		return
+	}
+
+	if _, err := h.subscriptionService.ConfirmSubscription(c.Request.Context(), workspaceID, subscriptionID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.renderPage(c, gin.H{"Done": true})
+}
+
+func (h *ConfirmHandler) renderPage(c *gin.Context, data gin.H) {
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := confirmPage.Execute(c.Writer, data); err != nil {
+		h.logger.Error("Failed to render confirmation page", zap.Error(err))
+	}
+}
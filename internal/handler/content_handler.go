@@ -2,13 +2,11 @@ package handler
 
 import (
 	"net/http"
-	"strconv"
 
 	"newsletter-assignment/internal/request"
 	"newsletter-assignment/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -26,44 +24,14 @@ func NewContentHandler(contentService service.ContentService, logger *zap.Logger
 
 func (h *ContentHandler) CreateContent(c *gin.Context) {
 	var req request.CreateContentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Invalid request payload", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request payload",
-			"details": err.Error(),
-		})
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
 		return
 	}
 
-	content, err := h.contentService.CreateContent(c.Request.Context(), &req)
+	content, err := h.contentService.CreateContent(c.Request.Context(), currentWorkspaceID(c), &req)
 	if err != nil {
-		switch err.Error() {
-		case "topic not found":
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Topic not found",
-			})
-			return
-		case "subject cannot be empty":
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Subject cannot be empty",
-			})
-			return
-		case "body cannot be empty":
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Body cannot be empty",
-			})
-			return
-		case "send_at must be in the future":
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Send time must be in the future",
-			})
-			return
-		}
-
-		h.logger.Error("Failed to create content", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to create content",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -71,28 +39,15 @@ func (h *ContentHandler) CreateContent(c *gin.Context) {
 }
 
 func (h *ContentHandler) GetContent(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+	id, err := parseUUIDParam(c, "id", "content")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid content ID format",
-		})
+		c.Error(err)
 		return
 	}
 
-	content, err := h.contentService.GetContent(c.Request.Context(), id)
+	content, err := h.contentService.GetContent(c.Request.Context(), currentWorkspaceID(c), id)
 	if err != nil {
-		if err.Error() == "content not found" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Content not found",
-			})
-			return
-		}
-
-		h.logger.Error("Failed to get content", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to get content",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -100,31 +55,21 @@ func (h *ContentHandler) GetContent(c *gin.Context) {
 }
 
 func (h *ContentHandler) ListContent(c *gin.Context) {
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
-
-	limit, err := strconv.Atoi(limitStr)
+	limit, err := queryInt(c, "limit", "10")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid limit parameter",
-		})
+		c.Error(err)
 		return
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
+	offset, err := queryInt(c, "offset", "0")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid offset parameter",
-		})
+		c.Error(err)
 		return
 	}
 
-	contents, err := h.contentService.ListContent(c.Request.Context(), limit, offset)
+	contents, err := h.contentService.ListContent(c.Request.Context(), currentWorkspaceID(c), limit, offset)
 	if err != nil {
-		h.logger.Error("Failed to list content", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to list content",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -136,47 +81,27 @@ func (h *ContentHandler) ListContent(c *gin.Context) {
 }
 
 func (h *ContentHandler) ListContentByTopic(c *gin.Context) {
-	topicIDStr := c.Param("id")
-	topicID, err := uuid.Parse(topicIDStr)
+	topicID, err := parseUUIDParam(c, "id", "topic")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid topic ID format",
-		})
+		c.Error(err)
 		return
 	}
 
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
-
-	limit, err := strconv.Atoi(limitStr)
+	limit, err := queryInt(c, "limit", "10")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid limit parameter",
-		})
+		c.Error(err)
 		return
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
+	offset, err := queryInt(c, "offset", "0")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid offset parameter",
-		})
+		c.Error(err)
 		return
 	}
 
-	contents, err := h.contentService.ListContentByTopic(c.Request.Context(), topicID, limit, offset)
+	contents, err := h.contentService.ListContentByTopic(c.Request.Context(), currentWorkspaceID(c), topicID, limit, offset)
 	if err != nil {
-		if err.Error() == "topic not found" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Topic not found",
-			})
-			return
-		}
-
-		h.logger.Error("Failed to list content by topic", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to list content",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -188,54 +113,59 @@ func (h *ContentHandler) ListContentByTopic(c *gin.Context) {
 }
 
 func (h *ContentHandler) UpdateContent(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+	id, err := parseUUIDParam(c, "id", "content")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid content ID format",
-		})
+		c.Error(err)
 		return
 	}
 
 	var req request.UpdateContentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Invalid request payload", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request payload",
-			"details": err.Error(),
-		})
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	content, err := h.contentService.UpdateContent(c.Request.Context(), currentWorkspaceID(c), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, content)
+}
+
+func (h *ContentHandler) RescheduleContent(c *gin.Context) {
+	id, err := parseUUIDParam(c, "id", "content")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req request.RescheduleContentRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	content, err := h.contentService.RescheduleContent(c.Request.Context(), currentWorkspaceID(c), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, content)
+}
+
+func (h *ContentHandler) CancelContent(c *gin.Context) {
+	id, err := parseUUIDParam(c, "id", "content")
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	content, err := h.contentService.UpdateContent(c.Request.Context(), id, &req)
+	content, err := h.contentService.CancelContent(c.Request.Context(), currentWorkspaceID(c), id)
 	if err != nil {
-		switch err.Error() {
-		case "content not found or cannot be updated (already sent)":
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Content not found or cannot be updated (already sent)",
-			})
-			return
-		case "subject cannot be empty":
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Subject cannot be empty",
-			})
-			return
-		case "body cannot be empty":
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Body cannot be empty",
-			})
-			return
-		case "send_at must be in the future":
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Send time must be in the future",
-			})
-			return
-		}
-
-		h.logger.Error("Failed to update content", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to update content",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -243,28 +173,15 @@ func (h *ContentHandler) UpdateContent(c *gin.Context) {
 }
 
 func (h *ContentHandler) DeleteContent(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+	id, err := parseUUIDParam(c, "id", "content")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid content ID format",
-		})
+		c.Error(err)
 		return
 	}
 
-	err = h.contentService.DeleteContent(c.Request.Context(), id)
+	err = h.contentService.DeleteContent(c.Request.Context(), currentWorkspaceID(c), id)
 	if err != nil {
-		if err.Error() == "content not found or cannot be deleted (already sent)" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Content not found or cannot be deleted (already sent)",
-			})
-			return
-		}
-
-		h.logger.Error("Failed to delete content", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to delete content",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -272,39 +189,15 @@ func (h *ContentHandler) DeleteContent(c *gin.Context) {
 }
 
 func (h *ContentHandler) ScheduleContent(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+	id, err := parseUUIDParam(c, "id", "content")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid content ID format",
-		})
+		c.Error(err)
 		return
 	}
 
-	err = h.contentService.ScheduleContent(c.Request.Context(), id)
+	err = h.contentService.ScheduleContent(c.Request.Context(), currentWorkspaceID(c), id)
 	if err != nil {
-		switch err.Error() {
-		case "content not found":
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Content not found",
-			})
-			return
-		case "content is not in scheduled status":
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Content is not in scheduled status",
-			})
-			return
-		case "content send time has not arrived yet":
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Content send time has not arrived yet",
-			})
-			return
-		}
-
-		h.logger.Error("Failed to schedule content", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to schedule content",
-		})
+		c.Error(err)
 		return
 	}
 
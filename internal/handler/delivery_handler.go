@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type DeliveryHandler struct {
+	deliveryService service.DeliveryService
+	logger          *zap.Logger
+}
+
+func NewDeliveryHandler(deliveryService service.DeliveryService, logger *zap.Logger) *DeliveryHandler {
+	return &DeliveryHandler{
+		deliveryService: deliveryService,
+		logger:          logger,
+	}
+}
+
+// ListContentDeliveries lists the deliveries of a content. Statuses are filtered with
+// repeated or comma-separated status parameters; pages continue from next_cursor.
+func (h *DeliveryHandler) ListContentDeliveries(c *gin.Context) {
+	id, err := parseUUIDParam(c, "id", "content")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	limit, err := queryInt(c, "limit", strconv.Itoa(constants.DefaultDeliveryPageSize))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	after := uuid.Nil
+	if cursor := c.Query("cursor"); cursor != "" {
+		after, err = uuid.Parse(cursor)
+		if err != nil {
+			c.Error(apperr.Validation("invalid cursor parameter", apperr.Field("cursor", "must be a cursor returned by a previous page")))
+			return
+		}
+	}
+
+	var statuses []string
+	for _, value := range c.QueryArray("status") {
+		for _, status := range strings.Split(value, ",") {
+			if status = strings.TrimSpace(status); status != "" {
+				statuses = append(statuses, status)
+			}
+		}
+	}
+
+	deliveries, err := h.deliveryService.ListContentDeliveries(c.Request.Context(), currentWorkspaceID(c), id, statuses, after, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// A full page may be followed by more deliveries
+	var nextCursor *string
+	if len(deliveries) == limit {
+		cursor := deliveries[len(deliveries)-1].ID.String()
+		nextCursor = &cursor
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries":  deliveries,
+		"limit":       limit,
+		"next_cursor": nextCursor,
+	})
+}
+
+// GetContentReport returns the delivery report of a content as JSON, or as CSV
+// with format=csv
+func (h *DeliveryHandler) GetContentReport(c *gin.Context) {
+	id, err := parseUUIDParam(c, "id", "content")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.Error(apperr.Validation("invalid format parameter", apperr.Field("format", "must be json or csv")))
+		return
+	}
+
+	report, err := h.deliveryService.GetContentReport(c.Request.Context(), currentWorkspaceID(c), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="content-%s-report.csv"`, id))
+	c.Status(http.StatusOK)
+	if err := writeReportCSV(csv.NewWriter(c.Writer), report); err != nil {
+		h.logger.Error("Failed to write delivery report CSV", zap.String("content_id", id.String()), zap.Error(err))
+	}
+}
+
+// writeReportCSV flattens a report into section,name,value rows
+func writeReportCSV(w *csv.Writer, report *models.DeliveryReport) error {
+	rows := [][]string{
+		{"section", "name", "value"},
+		{"summary", "content_id", report.ContentID.String()},
+		{"summary", "total", strconv.Itoa(report.Total)},
+	}
+
+	for _, status := range constants.DeliveryStatuses {
+		rows = append(rows, []string{"status", status, strconv.Itoa(report.ByStatus[status])})
+	}
+
+	rows = append(rows,
+		[]string{"timing", "first_sent_at", formatReportTime(report.FirstSentAt)},
+		[]string{"timing", "last_sent_at", formatReportTime(report.LastSentAt)},
+		[]string{"timing", "send_duration_seconds", formatReportFloat(report.SendDurationSeconds)},
+	)
+
+	for _, reason := range report.FailureReasons {
+		rows = append(rows, []string{"failure_reason", reason.ErrorMessage, strconv.Itoa(reason.Count)})
+	}
+
+	if e := report.Engagement; e != nil {
+		rows = append(rows,
+			[]string{"engagement", "opens", strconv.Itoa(e.Opens)},
+			[]string{"engagement", "unique_opens", strconv.Itoa(e.UniqueOpens)},
+			[]string{"engagement", "clicks", strconv.Itoa(e.Clicks)},
+			[]string{"engagement", "unique_clicks", strconv.Itoa(e.UniqueClicks)},
+			[]string{"engagement", "open_rate", formatReportFloat(&e.OpenRate)},
+			[]string{"engagement", "click_rate", formatReportFloat(&e.ClickRate)},
+		)
+	}
+
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return w.Error()
+}
+
+func formatReportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatReportFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', 4, 64)
+}
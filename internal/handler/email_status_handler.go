@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"newsletter-assignment/internal/email"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type EmailStatusHandler struct {
+	emailSender *email.UnifiedEmailSender
+	logger      *zap.Logger
+}
+
+func NewEmailStatusHandler(emailSender *email.UnifiedEmailSender, logger *zap.Logger) *EmailStatusHandler {
+	return &EmailStatusHandler{
+		emailSender: emailSender,
+		logger:      logger,
+	}
+}
+
+// GetProviderStatus returns the circuit breaker state of the email providers used by
+// the API, which sends double opt-in confirmation emails. Workers report their own
+// state on their health check server.
+func (h *EmailStatusHandler) GetProviderStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.emailSender.Status()})
+}
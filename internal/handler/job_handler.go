@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"net/http"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/request"
+	"newsletter-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type JobHandler struct {
+	jobService service.JobService
+	logger     *zap.Logger
+}
+
+func NewJobHandler(jobService service.JobService, logger *zap.Logger) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+		logger:     logger,
+	}
+}
+
+// ListJobs lists the jobs of the workspace, optionally filtered by status, job_type
+// and content_id
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	limit, err := queryInt(c, "limit", "10")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	offset, err := queryInt(c, "offset", "0")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filter := request.JobFilter{
+		Status:  c.Query("status"),
+		JobType: c.Query("job_type"),
+	}
+	if value := c.Query("content_id"); value != "" {
+		contentID, err := uuid.Parse(value)
+		if err != nil {
+			c.Error(apperr.Validation("invalid content_id parameter", apperr.Field("content_id", "must be a UUID")))
+			return
+		}
+		filter.ContentID = &contentID
+	}
+
+	jobs, err := h.jobService.ListJobs(c.Request.Context(), currentWorkspaceID(c), filter, limit, offset)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"jobs":   jobs,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// GetJob returns a job with its error history and audit log
+func (h *JobHandler) GetJob(c *gin.Context) {
+	id, err := parseUUIDParam(c, "id", "job")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	job, err := h.jobService.GetJob(c.Request.Context(), currentWorkspaceID(c), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// RetryJob returns a failed job to pending; override grants a job with no attempts
+// left one more
+func (h *JobHandler) RetryJob(c *gin.Context) {
+	id, err := parseUUIDParam(c, "id", "job")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// The body is optional
+	var req request.RetryJobRequest
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	job, err := h.jobService.RetryJob(c.Request.Context(), currentWorkspaceID(c), id, currentActorID(c), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// FailJob marks an unfinished job as failed
+func (h *JobHandler) FailJob(c *gin.Context) {
+	id, err := parseUUIDParam(c, "id", "job")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req request.FailJobRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	job, err := h.jobService.FailJob(c.Request.Context(), currentWorkspaceID(c), id, currentActorID(c), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func (h *JobHandler) DeleteJob(c *gin.Context) {
+	id, err := parseUUIDParam(c, "id", "job")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.jobService.DeleteJob(c.Request.Context(), currentWorkspaceID(c), id, currentActorID(c)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
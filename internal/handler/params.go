@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"newsletter-assignment/internal/apperr"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// bindJSON binds the request body, reporting binding failures as validation errors
+func bindJSON(c *gin.Context, obj interface{}) error {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		var validationErrs validator.ValidationErrors
+		if !errors.As(err, &validationErrs) {
+			return apperr.Validation("invalid request payload", apperr.Field("body", err.Error())).Wrap(err)
+		}
+
+		fields := make([]apperr.FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			fields = append(fields, apperr.Field(fieldErr.Field(), ruleMessage(fieldErr)))
+		}
+		return apperr.Validation("invalid request payload", fields...).Wrap(err)
+	}
+	return nil
+}
+
+func ruleMessage(fieldErr validator.FieldError) string {
+	switch {
+	case fieldErr.Tag() == "required":
+		return "is required"
+	case fieldErr.Param() != "":
+		return fmt.Sprintf("failed on the '%s=%s' rule", fieldErr.Tag(), fieldErr.Param())
+	default:
+		return fmt.Sprintf("failed on the '%s' rule", fieldErr.Tag())
+	}
+}
+
+// parseUUIDParam parses a UUID path parameter; resource names the ID in the error
+func parseUUIDParam(c *gin.Context, name, resource string) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		return uuid.Nil, apperr.Validation(fmt.Sprintf("invalid %s ID format", resource), apperr.Field(name, "must be a UUID"))
+	}
+	return id, nil
+}
+
+// queryInt parses an integer query parameter, falling back to defaultValue when absent
+func queryInt(c *gin.Context, name, defaultValue string) (int, error) {
+	value, err := strconv.Atoi(c.DefaultQuery(name, defaultValue))
+	if err != nil {
+		return 0, apperr.Validation(fmt.Sprintf("invalid %s parameter", name), apperr.Field(name, "must be an integer"))
+	}
+	return value, nil
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"net/http"
+
+	"newsletter-assignment/internal/request"
+	"newsletter-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type ScheduleHandler struct {
+	scheduleService service.RecurringScheduleService
+	logger          *zap.Logger
+}
+
+func NewScheduleHandler(scheduleService service.RecurringScheduleService, logger *zap.Logger) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduleService: scheduleService,
+		logger:          logger,
+	}
+}
+
+func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	var req request.CreateRecurringScheduleRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	schedule, err := h.scheduleService.CreateSchedule(c.Request.Context(), currentWorkspaceID(c), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
+	id, err := parseUUIDParam(c, "id", "schedule")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schedule, err := h.scheduleService.GetSchedule(c.Request.Context(), currentWorkspaceID(c), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
+	limit, err := queryInt(c, "limit", "10")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	offset, err := queryInt(c, "offset", "0")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schedules, err := h.scheduleService.ListSchedules(c.Request.Context(), currentWorkspaceID(c), limit, offset)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"schedules": schedules,
+		"limit":     limit,
+		"offset":    offset,
+	})
+}
+
+func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	id, err := parseUUIDParam(c, "id", "schedule")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req request.UpdateRecurringScheduleRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	schedule, err := h.scheduleService.UpdateSchedule(c.Request.Context(), currentWorkspaceID(c), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
+	id, err := parseUUIDParam(c, "id", "schedule")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = h.scheduleService.DeleteSchedule(c.Request.Context(), currentWorkspaceID(c), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *ScheduleHandler) PauseSchedule(c *gin.Context) {
+	id, err := parseUUIDParam(c, "id", "schedule")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schedule, err := h.scheduleService.PauseSchedule(c.Request.Context(), currentWorkspaceID(c), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (h *ScheduleHandler) ResumeSchedule(c *gin.Context) {
+	id, err := parseUUIDParam(c, "id", "schedule")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schedule, err := h.scheduleService.ResumeSchedule(c.Request.Context(), currentWorkspaceID(c), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// PreviewSchedule returns the next runs of a saved schedule
+func (h *ScheduleHandler) PreviewSchedule(c *gin.Context) {
+	id, err := parseUUIDParam(c, "id", "schedule")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	count, err := queryInt(c, "count", "5")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	runs, err := h.scheduleService.PreviewSchedule(c.Request.Context(), currentWorkspaceID(c), id, count)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"runs": runs,
+	})
+}
+
+// PreviewExpression returns the next runs of a cron expression without saving it
+func (h *ScheduleHandler) PreviewExpression(c *gin.Context) {
+	var req request.PreviewScheduleRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	runs, err := h.scheduleService.PreviewExpression(req.CronExpression, req.Timezone, req.Count)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"runs": runs,
+	})
+}
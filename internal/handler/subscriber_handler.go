@@ -2,13 +2,12 @@ package handler
 
 import (
 	"net/http"
-	"strconv"
 
+	"newsletter-assignment/internal/apperr"
 	"newsletter-assignment/internal/request"
 	"newsletter-assignment/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -26,28 +25,14 @@ func NewSubscriberHandler(subscriberService service.SubscriberService, logger *z
 
 func (h *SubscriberHandler) CreateSubscriber(c *gin.Context) {
 	var req request.CreateSubscriberRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Invalid request payload", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request payload",
-			"details": err.Error(),
-		})
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
 		return
 	}
 
-	subscriber, err := h.subscriberService.CreateSubscriber(c.Request.Context(), &req)
+	subscriber, err := h.subscriberService.CreateSubscriber(c.Request.Context(), currentWorkspaceID(c), &req)
 	if err != nil {
-		if err.Error() == "subscriber with email '"+req.Email+"' already exists" {
-			c.JSON(http.StatusConflict, gin.H{
-				"error": err.Error(),
-			})
-			return
-		}
-
-		h.logger.Error("Failed to create subscriber", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to create subscriber",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -55,28 +40,15 @@ func (h *SubscriberHandler) CreateSubscriber(c *gin.Context) {
 }
 
 func (h *SubscriberHandler) GetSubscriber(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+	id, err := parseUUIDParam(c, "id", "subscriber")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid subscriber ID format",
-		})
+		c.Error(err)
 		return
 	}
 
-	subscriber, err := h.subscriberService.GetSubscriber(c.Request.Context(), id)
+	subscriber, err := h.subscriberService.GetSubscriber(c.Request.Context(), currentWorkspaceID(c), id)
 	if err != nil {
-		if err.Error() == "subscriber not found" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Subscriber not found",
-			})
-			return
-		}
-
-		h.logger.Error("Failed to get subscriber", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to get subscriber",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -86,25 +58,13 @@ func (h *SubscriberHandler) GetSubscriber(c *gin.Context) {
 func (h *SubscriberHandler) GetSubscriberByEmail(c *gin.Context) {
 	email := c.Query("email")
 	if email == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Email parameter is required",
-		})
+		c.Error(apperr.Validation("email parameter is required", apperr.Field("email", "is required")))
 		return
 	}
 
-	subscriber, err := h.subscriberService.GetSubscriberByEmail(c.Request.Context(), email)
+	subscriber, err := h.subscriberService.GetSubscriberByEmail(c.Request.Context(), currentWorkspaceID(c), email)
 	if err != nil {
-		if err.Error() == "subscriber not found" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Subscriber not found",
-			})
-			return
-		}
-
-		h.logger.Error("Failed to get subscriber by email", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to get subscriber",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -112,31 +72,21 @@ func (h *SubscriberHandler) GetSubscriberByEmail(c *gin.Context) {
 }
 
 func (h *SubscriberHandler) ListSubscribers(c *gin.Context) {
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
-
-	limit, err := strconv.Atoi(limitStr)
+	limit, err := queryInt(c, "limit", "10")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid limit parameter",
-		})
+		c.Error(err)
 		return
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
+	offset, err := queryInt(c, "offset", "0")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid offset parameter",
-		})
+		c.Error(err)
 		return
 	}
 
-	subscribers, err := h.subscriberService.ListSubscribers(c.Request.Context(), limit, offset)
+	subscribers, err := h.subscriberService.ListSubscribers(c.Request.Context(), currentWorkspaceID(c), limit, offset)
 	if err != nil {
-		h.logger.Error("Failed to list subscribers", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to list subscribers",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -148,38 +98,21 @@ func (h *SubscriberHandler) ListSubscribers(c *gin.Context) {
 }
 
 func (h *SubscriberHandler) UpdateSubscriber(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+	id, err := parseUUIDParam(c, "id", "subscriber")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid subscriber ID format",
-		})
+		c.Error(err)
 		return
 	}
 
 	var req request.UpdateSubscriberRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Invalid request payload", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request payload",
-			"details": err.Error(),
-		})
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
 		return
 	}
 
-	subscriber, err := h.subscriberService.UpdateSubscriber(c.Request.Context(), id, &req)
+	subscriber, err := h.subscriberService.UpdateSubscriber(c.Request.Context(), currentWorkspaceID(c), id, &req)
 	if err != nil {
-		if err.Error() == "subscriber not found" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Subscriber not found",
-			})
-			return
-		}
-
-		h.logger.Error("Failed to update subscriber", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to update subscriber",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -187,28 +120,15 @@ func (h *SubscriberHandler) UpdateSubscriber(c *gin.Context) {
 }
 
 func (h *SubscriberHandler) DeleteSubscriber(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+	id, err := parseUUIDParam(c, "id", "subscriber")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid subscriber ID format",
-		})
+		c.Error(err)
 		return
 	}
 
-	err = h.subscriberService.DeleteSubscriber(c.Request.Context(), id)
+	err = h.subscriberService.DeleteSubscriber(c.Request.Context(), currentWorkspaceID(c), id)
 	if err != nil {
-		if err.Error() == "subscriber not found" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Subscriber not found",
-			})
-			return
-		}
-
-		h.logger.Error("Failed to delete subscriber", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to delete subscriber",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -7,7 +7,6 @@ import (
 	"newsletter-assignment/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -25,44 +24,14 @@ func NewSubscriptionHandler(subscriptionService service.SubscriptionService, log
 
 func (h *SubscriptionHandler) Subscribe(c *gin.Context) {
 	var req request.CreateSubscriptionRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Invalid request payload", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request payload",
-			"details": err.Error(),
-		})
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
 		return
 	}
 
-	subscription, err := h.subscriptionService.Subscribe(c.Request.Context(), &req)
+	subscription, err := h.subscriptionService.Subscribe(c.Request.Context(), currentWorkspaceID(c), &req)
 	if err != nil {
-		switch err.Error() {
-		case "subscriber not found":
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Subscriber not found",
-			})
-			return
-		case "topic not found":
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Topic not found",
-			})
-			return
-		case "subscriber is not active":
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Subscriber is not active",
-			})
-			return
-		case "subscriber is already subscribed to this topic":
-			c.JSON(http.StatusConflict, gin.H{
-				"error": "Subscriber is already subscribed to this topic",
-			})
-			return
-		}
-
-		h.logger.Error("Failed to create subscription", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to create subscription",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -70,43 +39,21 @@ func (h *SubscriptionHandler) Subscribe(c *gin.Context) {
 }
 
 func (h *SubscriptionHandler) Unsubscribe(c *gin.Context) {
-	subscriberIDStr := c.Param("subscriber_id")
-	subscriberID, err := uuid.Parse(subscriberIDStr)
+	subscriberID, err := parseUUIDParam(c, "subscriber_id", "subscriber")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid subscriber ID format",
-		})
+		c.Error(err)
 		return
 	}
 
-	topicIDStr := c.Param("id")
-	topicID, err := uuid.Parse(topicIDStr)
+	topicID, err := parseUUIDParam(c, "topic_id", "topic")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid topic ID format",
-		})
+		c.Error(err)
 		return
 	}
 
-	err = h.subscriptionService.Unsubscribe(c.Request.Context(), subscriberID, topicID)
+	err = h.subscriptionService.Unsubscribe(c.Request.Context(), currentWorkspaceID(c), subscriberID, topicID)
 	if err != nil {
-		switch err.Error() {
-		case "subscription not found":
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Subscription not found",
-			})
-			return
-		case "subscription is already inactive":
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Subscription is already inactive",
-			})
-			return
-		}
-
-		h.logger.Error("Failed to unsubscribe", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to unsubscribe",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -116,28 +63,15 @@ func (h *SubscriptionHandler) Unsubscribe(c *gin.Context) {
 }
 
 func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+	id, err := parseUUIDParam(c, "id", "subscription")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid subscription ID format",
-		})
+		c.Error(err)
 		return
 	}
 
-	subscription, err := h.subscriptionService.GetSubscription(c.Request.Context(), id)
+	subscription, err := h.subscriptionService.GetSubscription(c.Request.Context(), currentWorkspaceID(c), id)
 	if err != nil {
-		if err.Error() == "subscription not found" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Subscription not found",
-			})
-			return
-		}
-
-		h.logger.Error("Failed to get subscription", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to get subscription",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -145,28 +79,15 @@ func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
 }
 
 func (h *SubscriptionHandler) ListSubscriberTopics(c *gin.Context) {
-	subscriberIDStr := c.Param("id")
-	subscriberID, err := uuid.Parse(subscriberIDStr)
+	subscriberID, err := parseUUIDParam(c, "id", "subscriber")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid subscriber ID format",
-		})
+		c.Error(err)
 		return
 	}
 
-	subscriptions, err := h.subscriptionService.ListSubscriberTopics(c.Request.Context(), subscriberID)
+	subscriptions, err := h.subscriptionService.ListSubscriberTopics(c.Request.Context(), currentWorkspaceID(c), subscriberID)
 	if err != nil {
-		if err.Error() == "subscriber not found" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Subscriber not found",
-			})
-			return
-		}
-
-		h.logger.Error("Failed to list subscriber topics", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to list subscriber topics",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -176,28 +97,15 @@ func (h *SubscriptionHandler) ListSubscriberTopics(c *gin.Context) {
 }
 
 func (h *SubscriptionHandler) ListTopicSubscribers(c *gin.Context) {
-	topicIDStr := c.Param("id")
-	topicID, err := uuid.Parse(topicIDStr)
+	topicID, err := parseUUIDParam(c, "id", "topic")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid topic ID format",
-		})
+		c.Error(err)
 		return
 	}
 
-	subscriptions, err := h.subscriptionService.ListTopicSubscribers(c.Request.Context(), topicID)
+	subscriptions, err := h.subscriptionService.ListTopicSubscribers(c.Request.Context(), currentWorkspaceID(c), topicID)
 	if err != nil {
-		if err.Error() == "topic not found" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Topic not found",
-			})
-			return
-		}
-
-		h.logger.Error("Failed to list topic subscribers", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to list topic subscribers",
-		})
+		c.Error(err)
 		return
 	}
 
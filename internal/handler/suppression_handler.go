@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"net/http"
+
+	"newsletter-assignment/internal/request"
+	"newsletter-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type SuppressionHandler struct {
+	suppressionService service.SuppressionService
+	logger             *zap.Logger
+}
+
+func NewSuppressionHandler(suppressionService service.SuppressionService, logger *zap.Logger) *SuppressionHandler {
+	return &SuppressionHandler{
+		suppressionService: suppressionService,
+		logger:             logger,
+	}
+}
+
+func (h *SuppressionHandler) CreateSuppression(c *gin.Context) {
+	var req request.CreateSuppressionRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	suppression, err := h.suppressionService.CreateSuppression(c.Request.Context(), currentWorkspaceID(c), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, suppression)
+}
+
+// ImportSuppressions bulk suppresses addresses; already suppressed ones are skipped
+func (h *SuppressionHandler) ImportSuppressions(c *gin.Context) {
+	var req request.ImportSuppressionsRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	imported, err := h.suppressionService.ImportSuppressions(c.Request.Context(), currentWorkspaceID(c), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"submitted": len(req.Suppressions),
+		"imported":  imported,
+		"skipped":   len(req.Suppressions) - imported,
+	})
+}
+
+func (h *SuppressionHandler) GetSuppression(c *gin.Context) {
+	id, err := parseUUIDParam(c, "id", "suppression")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	suppression, err := h.suppressionService.GetSuppression(c.Request.Context(), currentWorkspaceID(c), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, suppression)
+}
+
+func (h *SuppressionHandler) ListSuppressions(c *gin.Context) {
+	limit, err := queryInt(c, "limit", "10")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	offset, err := queryInt(c, "offset", "0")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	suppressions, err := h.suppressionService.ListSuppressions(c.Request.Context(), currentWorkspaceID(c), limit, offset)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"suppressions": suppressions,
+		"limit":        limit,
+		"offset":       offset,
+	})
+}
+
+func (h *SuppressionHandler) DeleteSuppression(c *gin.Context) {
+	id, err := parseUUIDParam(c, "id", "suppression")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.suppressionService.DeleteSuppression(c.Request.Context(), currentWorkspaceID(c), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
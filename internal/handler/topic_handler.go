@@ -2,13 +2,11 @@ package handler
 
 import (
 	"net/http"
-	"strconv"
 
 	"newsletter-assignment/internal/request"
 	"newsletter-assignment/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -26,28 +24,14 @@ func NewTopicHandler(topicService service.TopicService, logger *zap.Logger) *Top
 
 func (h *TopicHandler) CreateTopic(c *gin.Context) {
 	var req request.CreateTopicRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Invalid request payload", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request payload",
-			"details": err.Error(),
-		})
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
 		return
 	}
 
-	topic, err := h.topicService.CreateTopic(c.Request.Context(), &req)
+	topic, err := h.topicService.CreateTopic(c.Request.Context(), currentWorkspaceID(c), &req)
 	if err != nil {
-		if err.Error() == "topic with name '"+req.Name+"' already exists" {
-			c.JSON(http.StatusConflict, gin.H{
-				"error": err.Error(),
-			})
-			return
-		}
-
-		h.logger.Error("Failed to create topic", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to create topic",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -55,28 +39,15 @@ func (h *TopicHandler) CreateTopic(c *gin.Context) {
 }
 
 func (h *TopicHandler) GetTopic(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+	id, err := parseUUIDParam(c, "id", "topic")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid topic ID format",
-		})
+		c.Error(err)
 		return
 	}
 
-	topic, err := h.topicService.GetTopic(c.Request.Context(), id)
+	topic, err := h.topicService.GetTopic(c.Request.Context(), currentWorkspaceID(c), id)
 	if err != nil {
-		if err.Error() == "topic not found" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Topic not found",
-			})
-			return
-		}
-
-		h.logger.Error("Failed to get topic", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to get topic",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -84,31 +55,21 @@ func (h *TopicHandler) GetTopic(c *gin.Context) {
 }
 
 func (h *TopicHandler) ListTopics(c *gin.Context) {
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
-
-	limit, err := strconv.Atoi(limitStr)
+	limit, err := queryInt(c, "limit", "10")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid limit parameter",
-		})
+		c.Error(err)
 		return
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
+	offset, err := queryInt(c, "offset", "0")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid offset parameter",
-		})
+		c.Error(err)
 		return
 	}
 
-	topics, err := h.topicService.ListTopics(c.Request.Context(), limit, offset)
+	topics, err := h.topicService.ListTopics(c.Request.Context(), currentWorkspaceID(c), limit, offset)
 	if err != nil {
-		h.logger.Error("Failed to list topics", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to list topics",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -120,38 +81,21 @@ func (h *TopicHandler) ListTopics(c *gin.Context) {
 }
 
 func (h *TopicHandler) UpdateTopic(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+	id, err := parseUUIDParam(c, "id", "topic")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid topic ID format",
-		})
+		c.Error(err)
 		return
 	}
 
 	var req request.UpdateTopicRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Invalid request payload", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request payload",
-			"details": err.Error(),
-		})
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
 		return
 	}
 
-	topic, err := h.topicService.UpdateTopic(c.Request.Context(), id, &req)
+	topic, err := h.topicService.UpdateTopic(c.Request.Context(), currentWorkspaceID(c), id, &req)
 	if err != nil {
-		if err.Error() == "topic not found" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Topic not found",
-			})
-			return
-		}
-
-		h.logger.Error("Failed to update topic", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to update topic",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -159,28 +103,15 @@ func (h *TopicHandler) UpdateTopic(c *gin.Context) {
 }
 
 func (h *TopicHandler) DeleteTopic(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+	id, err := parseUUIDParam(c, "id", "topic")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid topic ID format",
-		})
+		c.Error(err)
 		return
 	}
 
-	err = h.topicService.DeleteTopic(c.Request.Context(), id)
+	err = h.topicService.DeleteTopic(c.Request.Context(), currentWorkspaceID(c), id)
 	if err != nil {
-		if err.Error() == "topic not found" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Topic not found",
-			})
-			return
-		}
-
-		h.logger.Error("Failed to delete topic", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to delete topic",
-		})
+		c.Error(err)
 		return
 	}
 
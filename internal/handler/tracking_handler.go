@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/service"
+	"newsletter-assignment/internal/token"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// trackingPixel is a transparent 1x1 GIF
+var trackingPixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+var errInvalidTrackingToken = apperr.Validation("invalid tracking token", apperr.Field("token", "is invalid"))
+
+type TrackingHandler struct {
+	deliveryEventService service.DeliveryEventService
+	signer               *token.Signer
+	logger               *zap.Logger
+}
+
+func NewTrackingHandler(deliveryEventService service.DeliveryEventService, signer *token.Signer, logger *zap.Logger) *TrackingHandler {
+	return &TrackingHandler{
+		deliveryEventService: deliveryEventService,
+		signer:               signer,
+		logger:               logger,
+	}
+}
+
+// TrackOpen records an open and serves the tracking pixel. The pixel is served
+// even when the token is invalid or recording fails so that mail clients never
+// show a broken image.
+func (h *TrackingHandler) TrackOpen(c *gin.Context) {
+	workspaceID, deliveryID, err := h.signer.VerifyTrackOpen(c.Query("token"))
+	if err == nil {
+		// Failures are logged by the service
+		_ = h.deliveryEventService.RecordOpen(c.Request.Context(), workspaceID, deliveryID, c.Request.UserAgent())
+	}
+
+	c.Header("Cache-Control", "no-store, no-cache, must-revalidate, private")
+	c.Data(http.StatusOK, "image/gif", trackingPixel)
+}
+
+// TrackClick records a click and redirects to the link's original target. Only
+// targets signed into the token are redirected to, so the endpoint cannot be used
+// as an open redirect.
+func (h *TrackingHandler) TrackClick(c *gin.Context) {
+	workspaceID, deliveryID, target, err := h.signer.VerifyTrackClick(c.Query("token"))
+	if err != nil {
+		c.Error(errInvalidTrackingToken)
+		return
+	}
+
+	// A failure to record the click must not keep the reader from the link
+	_ = h.deliveryEventService.RecordClick(c.Request.Context(), workspaceID, deliveryID, target, c.Request.UserAgent())
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, target)
+}
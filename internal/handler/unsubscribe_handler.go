@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/service"
+	"newsletter-assignment/internal/token"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Unsubscribe</title></head>
+<body>
+{{if .Done}}<p>You have been unsubscribed.</p>{{else}}<form method="post" action="?token={{.Token}}">
+<p>Do you want to stop receiving these emails?</p>
+<button type="submit">Unsubscribe</button>
+</form>{{end}}
+</body>
+</html>
+`))
+
+var errInvalidUnsubscribeToken = apperr.Validation("invalid unsubscribe token", apperr.Field("token", "is invalid or expired"))
+
+type UnsubscribeHandler struct {
+	subscriptionService service.SubscriptionService
+	signer              *token.Signer
+	logger              *zap.Logger
+}
+
+func NewUnsubscribeHandler(subscriptionService service.SubscriptionService, signer *token.Signer, logger *zap.Logger) *UnsubscribeHandler {
+	return &UnsubscribeHandler{
+		subscriptionService: subscriptionService,
+		signer:              signer,
+		logger:              logger,
+	}
+}
+
+// ShowUnsubscribe renders a confirmation page so that link scanners following
+// GET requests do not unsubscribe recipients
+func (h *UnsubscribeHandler) ShowUnsubscribe(c *gin.Context) {
+	tok := c.Query("token")
+	if _, _, _, err := h.signer.VerifyUnsubscribe(tok); err != nil {
+		c.Error(errInvalidUnsubscribeToken)
+		return
+	}
+
+	h.renderPage(c, gin.H{"Token": tok, "Done": false})
+}
+
+// Unsubscribe handles both the confirmation form and RFC 8058 one-click POSTs
+func (h *UnsubscribeHandler) Unsubscribe(c *gin.Context) {
+	workspaceID, subscriberID, topicID, err := h.signer.VerifyUnsubscribe(c.Query("token"))
+	if err != nil {
+		c.Error(errInvalidUnsubscribeToken)
+		return
+	}
+
+	err = h.subscriptionService.Unsubscribe(c.Request.Context(), workspaceID, subscriberID, topicID)
+	// Treat repeated unsubscribes as success
+	if err != nil && !errors.Is(err, service.ErrSubscriptionInactive) {
+		c.Error(err)
+		return
+	}
+
+	h.renderPage(c, gin.H{"Done": true})
+}
+
+func (h *UnsubscribeHandler) renderPage(c *gin.Context, data gin.H) {
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := unsubscribePage.Execute(c.Writer, data); err != nil {
+		h.logger.Error("Failed to render unsubscribe page", zap.Error(err))
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/request"
+	"newsletter-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var errInvalidWebhookSecret = apperr.Unauthorized("invalid webhook secret")
+
+type WebhookHandler struct {
+	deliveryEventService service.DeliveryEventService
+	secret               string
+	logger               *zap.Logger
+}
+
+func NewWebhookHandler(deliveryEventService service.DeliveryEventService, secret string, logger *zap.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		deliveryEventService: deliveryEventService,
+		secret:               secret,
+		logger:               logger,
+	}
+}
+
+// BrevoEvents processes Brevo transactional event callbacks. Brevo posts a single
+// event per request, but a JSON array of events is accepted as well.
+func (h *WebhookHandler) BrevoEvents(c *gin.Context) {
+	if !h.authorized(c.Request) {
+		c.Error(errInvalidWebhookSecret)
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(apperr.Validation("invalid request payload", apperr.Field("body", err.Error())).Wrap(err))
+		return
+	}
+
+	var events []request.BrevoWebhookEvent
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &events)
+	} else {
+		var event request.BrevoWebhookEvent
+		err = json.Unmarshal(trimmed, &event)
+		events = append(events, event)
+	}
+	if err != nil {
+		c.Error(apperr.Validation("invalid request payload", apperr.Field("body", err.Error())).Wrap(err))
+		return
+	}
+
+	for i := range events {
+		if err := h.deliveryEventService.ProcessBrevoEvent(c.Request.Context(), &events[i]); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"processed": len(events)})
+}
+
+// authorized checks the shared webhook secret, sent either as a bearer token or as the
+// password of HTTP basic auth credentials embedded in the webhook URL. Without a
+// configured secret every request is rejected.
+func (h *WebhookHandler) authorized(r *http.Request) bool {
+	if h.secret == "" {
+		return false
+	}
+
+	var provided string
+	if _, password, ok := r.BasicAuth(); ok {
+		provided = password
+	} else if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		provided = strings.TrimSpace(token)
+	}
+
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(h.secret)) == 1
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/request"
+	"newsletter-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// recordingEventService collects the events passed on by the handler
+type recordingEventService struct {
+	service.DeliveryEventService
+	events []request.BrevoWebhookEvent
+}
+
+func (s *recordingEventService) ProcessBrevoEvent(ctx context.Context, event *request.BrevoWebhookEvent) error {
+	s.events = append(s.events, *event)
+	return nil
+}
+
+// postWebhook sends body to a handler configured with secret and returns the
+// response together with the error the handler attached, if any
+func postWebhook(t *testing.T, secret, body string, setAuth func(r *http.Request)) (*httptest.ResponseRecorder, *recordingEventService, error) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	events := &recordingEventService{}
+	h := NewWebhookHandler(events, secret, zap.NewNop())
+
+	var handlerErr error
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) > 0 {
+			handlerErr = c.Errors.Last().Err
+		}
+	})
+	router.POST("/webhooks/brevo", h.BrevoEvents)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/brevo", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if setAuth != nil {
+		setAuth(req)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec, events, handlerErr
+}
+
+func TestBrevoEventsAuthorization(t *testing.T) {
+	const event = `{"event":"delivered","message-id":"<1@smtp-relay.mailin.fr>"}`
+	bearer := func(token string) func(r *http.Request) {
+		return func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }
+	}
+	basic := func(user, password string) func(r *http.Request) {
+		return func(r *http.Request) { r.SetBasicAuth(user, password) }
+	}
+
+	tests := []struct {
+		name       string
+		secret     string
+		auth       func(r *http.Request)
+		authorized bool
+	}{
+		{name: "no secret configured", auth: bearer("")},
+		{name: "no secret configured and no credentials"},
+		{name: "missing credentials", secret: "s3cret"},
+		{name: "bearer", secret: "s3cret", auth: bearer("s3cret"), authorized: true},
+		{name: "wrong bearer", secret: "s3cret", auth: bearer("other")},
+		{name: "basic auth password", secret: "s3cret", auth: basic("brevo", "s3cret"), authorized: true},
+		{name: "basic auth user is not checked", secret: "s3cret", auth: basic("anyone", "s3cret"), authorized: true},
+		{name: "secret as basic auth user", secret: "s3cret", auth: basic("s3cret", "")},
+		{name: "wrong basic auth password", secret: "s3cret", auth: basic("brevo", "other")},
+		{name: "secret in other header", secret: "s3cret", auth: func(r *http.Request) { r.Header.Set("X-API-Key", "s3cret") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, events, err := postWebhook(t, tt.secret, event, tt.auth)
+
+			if tt.authorized {
+				if err != nil || rec.Code != http.StatusOK || len(events.events) != 1 {
+					t.Errorf("status %d, error %v, %d events processed; want the event processed", rec.Code, err, len(events.events))
+				}
+				return
+			}
+			if !errors.Is(err, errInvalidWebhookSecret) {
+				t.Errorf("error = %v, want %v", err, errInvalidWebhookSecret)
+			}
+			if len(events.events) != 0 {
+				t.Errorf("%d events processed without authorization", len(events.events))
+			}
+		})
+	}
+}
+
+func TestBrevoEventsPayloads(t *testing.T) {
+	auth := func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cret") }
+
+	tests := []struct {
+		name    string
+		body    string
+		want    []string
+		invalid bool
+	}{
+		{name: "single event", body: `{"event":"hard_bounce","message-id":"<1@relay>","reason":"no such user"}`, want: []string{"<1@relay>"}},
+		{name: "single event with whitespace", body: "\n  {\"event\":\"spam\",\"message-id\":\"<1@relay>\"}\n", want: []string{"<1@relay>"}},
+		{name: "array of events", body: `[{"event":"delivered","message-id":"<1@relay>"},{"event":"opened","message-id":"<2@relay>"}]`, want: []string{"<1@relay>", "<2@relay>"}},
+		{name: "empty array", body: `[]`, want: nil},
+		{name: "invalid JSON", body: `{"event":`, invalid: true},
+		{name: "invalid array", body: `[{"event":"delivered"},`, invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, events, err := postWebhook(t, "s3cret", tt.body, auth)
+
+			if tt.invalid {
+				if !errors.Is(err, apperr.ErrValidation) {
+					t.Errorf("error = %v, want a validation error", err)
+				}
+				if len(events.events) != 0 {
+					t.Errorf("%d events processed from an invalid payload", len(events.events))
+				}
+				return
+			}
+
+			if err != nil || rec.Code != http.StatusOK {
+				t.Fatalf("status %d, error %v", rec.Code, err)
+			}
+			var got []string
+			for _, event := range events.events {
+				got = append(got, event.MessageID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("processed message IDs %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+
+	"newsletter-assignment/internal/request"
+	"newsletter-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type WorkspaceHandler struct {
+	workspaceService service.WorkspaceService
+	logger           *zap.Logger
+}
+
+func NewWorkspaceHandler(workspaceService service.WorkspaceService, logger *zap.Logger) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceService: workspaceService,
+		logger:           logger,
+	}
+}
+
+// CreateWorkspace creates a workspace and returns the plaintext admin key for it
+func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+	var req request.CreateWorkspaceRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	workspace, key, rawKey, err := h.workspaceService.CreateWorkspace(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"workspace": workspace,
+		"api_key":   key,
+		"key":       rawKey,
+	})
+}
+
+// GetCurrentWorkspace returns the workspace of the authenticated API key
+func (h *WorkspaceHandler) GetCurrentWorkspace(c *gin.Context) {
+	workspace, err := h.workspaceService.GetWorkspace(c.Request.Context(), currentWorkspaceID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+// UpdateCurrentWorkspace updates the name and sender identity of the authenticated workspace
+func (h *WorkspaceHandler) UpdateCurrentWorkspace(c *gin.Context) {
+	var req request.UpdateWorkspaceRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	workspace, err := h.workspaceService.UpdateWorkspace(c.Request.Context(), currentWorkspaceID(c), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/handler"
+	"newsletter-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// authenticate resolves the API key of the request and stores it on the context
+func authenticate(apiKeyService service.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := apiKeyService.Authenticate(c.Request.Context(), apiKeyFromRequest(c.Request))
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="api"`)
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Set(constants.ContextKeyAPIKey, key)
+		c.Next()
+	}
+}
+
+// requireRole rejects requests whose API key has a lower role than required
+func requireRole(required string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := handler.CurrentAPIKey(c)
+		if key == nil || !service.RoleSatisfies(key.Role, required) {
+			c.Error(apperr.Forbidden("this action requires the " + required + " role"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// apiKeyFromRequest accepts both "Authorization: Bearer <key>" and the X-API-Key header
+func apiKeyFromRequest(r *http.Request) string {
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return strings.TrimSpace(r.Header.Get(apiKeyHeader))
+}
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/repo"
+	"newsletter-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakeAPIKeyRepo looks keys up by the hash the API key service stores
+type fakeAPIKeyRepo struct {
+	repo.APIKeyRepository
+	keys map[string]*models.APIKey
+}
+
+func (r *fakeAPIKeyRepo) add(rawKey, role string, revoked bool) {
+	sum := sha256.Sum256([]byte(rawKey))
+	key := &models.APIKey{ID: uuid.New(), WorkspaceID: uuid.New(), Name: role, Role: role}
+	if revoked {
+		revokedAt := time.Now()
+		key.RevokedAt = &revokedAt
+	}
+	r.keys[hex.EncodeToString(sum[:])] = key
+}
+
+func (r *fakeAPIKeyRepo) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	key, ok := r.keys[keyHash]
+	if !ok {
+		return nil, apperr.NotFound("api key not found")
+	}
+	return key, nil
+}
+
+func (r *fakeAPIKeyRepo) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func TestAuthentication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keys := &fakeAPIKeyRepo{keys: make(map[string]*models.APIKey)}
+	keys.add("viewer-key", constants.RoleViewer, false)
+	keys.add("editor-key", constants.RoleEditor, false)
+	keys.add("admin-key", constants.RoleAdmin, false)
+	keys.add("revoked-key", constants.RoleAdmin, true)
+
+	router := gin.New()
+	router.Use(ErrorHandler(zap.NewNop()))
+	router.Use(authenticate(service.NewAPIKeyService(keys, zap.NewNop())))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/viewer", requireRole(constants.RoleViewer), ok)
+	router.GET("/editor", requireRole(constants.RoleEditor), ok)
+	router.GET("/admin", requireRole(constants.RoleAdmin), ok)
+
+	tests := []struct {
+		name    string
+		path    string
+		headers map[string]string
+		want    int
+	}{
+		{name: "missing key", path: "/viewer", want: http.StatusUnauthorized},
+		{name: "unknown key", path: "/viewer", headers: map[string]string{"Authorization": "Bearer other-key"}, want: http.StatusUnauthorized},
+		{name: "revoked key", path: "/viewer", headers: map[string]string{"Authorization": "Bearer revoked-key"}, want: http.StatusUnauthorized},
+		{name: "bearer key", path: "/viewer", headers: map[string]string{"Authorization": "Bearer viewer-key"}, want: http.StatusOK},
+		{name: "lower case bearer", path: "/viewer", headers: map[string]string{"Authorization": "bearer viewer-key"}, want: http.StatusOK},
+		{name: "x-api-key header", path: "/viewer", headers: map[string]string{"X-API-Key": "viewer-key"}, want: http.StatusOK},
+		{name: "bearer wins over x-api-key", path: "/viewer", headers: map[string]string{"Authorization": "Bearer revoked-key", "X-API-Key": "viewer-key"}, want: http.StatusUnauthorized},
+		{name: "other scheme falls back to x-api-key", path: "/viewer", headers: map[string]string{"Authorization": "Basic dXNlcjpwYXNz", "X-API-Key": "viewer-key"}, want: http.StatusOK},
+		{name: "viewer on editor route", path: "/editor", headers: map[string]string{"X-API-Key": "viewer-key"}, want: http.StatusForbidden},
+		{name: "editor on editor route", path: "/editor", headers: map[string]string{"X-API-Key": "editor-key"}, want: http.StatusOK},
+		{name: "editor on admin route", path: "/admin", headers: map[string]string{"X-API-Key": "editor-key"}, want: http.StatusForbidden},
+		{name: "admin on editor route", path: "/editor", headers: map[string]string{"X-API-Key": "admin-key"}, want: http.StatusOK},
+		{name: "admin on admin route", path: "/admin", headers: map[string]string{"X-API-Key": "admin-key"}, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			challenge := rec.Header().Get("WWW-Authenticate")
+			if (tt.want == http.StatusUnauthorized) != (challenge != "") {
+				t.Errorf("WWW-Authenticate = %q for status %d", challenge, rec.Code)
+			}
+			if tt.want >= 400 && rec.Header().Get("Content-Type") != problemContentType {
+				t.Errorf("Content-Type = %q, want %q", rec.Header().Get("Content-Type"), problemContentType)
+			}
+		})
+	}
+}
+
+func TestAPIKeyFromRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{name: "no headers", want: ""},
+		{name: "bearer", headers: map[string]string{"Authorization": "Bearer abc"}, want: "abc"},
+		{name: "bearer any case", headers: map[string]string{"Authorization": "BEARER abc"}, want: "abc"},
+		{name: "bearer with spaces", headers: map[string]string{"Authorization": "Bearer  abc "}, want: "abc"},
+		{name: "x-api-key", headers: map[string]string{"X-API-Key": " abc "}, want: "abc"},
+		{name: "bearer preferred", headers: map[string]string{"Authorization": "Bearer abc", "X-API-Key": "def"}, want: "abc"},
+		{name: "basic ignored", headers: map[string]string{"Authorization": "Basic abc"}, want: ""},
+		{name: "scheme only", headers: map[string]string{"Authorization": "Bearer"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			if got := apiKeyFromRequest(req); got != tt.want {
+				t.Errorf("apiKeyFromRequest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
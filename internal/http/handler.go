@@ -3,9 +3,12 @@ package http
 import (
 	"net/http"
 
+	"newsletter-assignment/internal/constants"
 	"newsletter-assignment/internal/handler"
+	"newsletter-assignment/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 type Handler struct {
@@ -13,6 +16,19 @@ type Handler struct {
 	subscriberHandler   *handler.SubscriberHandler
 	subscriptionHandler *handler.SubscriptionHandler
 	contentHandler      *handler.ContentHandler
+	deliveryHandler     *handler.DeliveryHandler
+	unsubscribeHandler  *handler.UnsubscribeHandler
+	confirmHandler      *handler.ConfirmHandler
+	scheduleHandler     *handler.ScheduleHandler
+	jobHandler          *handler.JobHandler
+	suppressionHandler  *handler.SuppressionHandler
+	webhookHandler      *handler.WebhookHandler
+	trackingHandler     *handler.TrackingHandler
+	emailStatusHandler  *handler.EmailStatusHandler
+	apiKeyHandler       *handler.APIKeyHandler
+	workspaceHandler    *handler.WorkspaceHandler
+	apiKeyService       service.APIKeyService
+	logger              *zap.Logger
 }
 
 func NewHandler(
@@ -20,12 +36,38 @@ func NewHandler(
 	subscriberHandler *handler.SubscriberHandler,
 	subscriptionHandler *handler.SubscriptionHandler,
 	contentHandler *handler.ContentHandler,
+	deliveryHandler *handler.DeliveryHandler,
+	unsubscribeHandler *handler.UnsubscribeHandler,
+	confirmHandler *handler.ConfirmHandler,
+	scheduleHandler *handler.ScheduleHandler,
+	jobHandler *handler.JobHandler,
+	suppressionHandler *handler.SuppressionHandler,
+	webhookHandler *handler.WebhookHandler,
+	trackingHandler *handler.TrackingHandler,
+	emailStatusHandler *handler.EmailStatusHandler,
+	apiKeyHandler *handler.APIKeyHandler,
+	workspaceHandler *handler.WorkspaceHandler,
+	apiKeyService service.APIKeyService,
+	logger *zap.Logger,
 ) *Handler {
 	return &Handler{
 		topicHandler:        topicHandler,
 		subscriberHandler:   subscriberHandler,
 		subscriptionHandler: subscriptionHandler,
 		contentHandler:      contentHandler,
+		deliveryHandler:     deliveryHandler,
+		unsubscribeHandler:  unsubscribeHandler,
+		confirmHandler:      confirmHandler,
+		scheduleHandler:     scheduleHandler,
+		jobHandler:          jobHandler,
+		suppressionHandler:  suppressionHandler,
+		webhookHandler:      webhookHandler,
+		trackingHandler:     trackingHandler,
+		emailStatusHandler:  emailStatusHandler,
+		apiKeyHandler:       apiKeyHandler,
+		workspaceHandler:    workspaceHandler,
+		apiKeyService:       apiKeyService,
+		logger:              logger,
 	}
 }
 
@@ -33,61 +75,140 @@ func (h *Handler) SetupRoutes() *gin.Engine {
 	router := gin.New()
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
+	router.Use(ErrorHandler(h.logger))
+
+	useJSONFieldNames()
 
 	// Health check
 	router.GET("/healthz", h.healthCheck)
 
-	// API v1 routes
+	// Public unsubscribe routes (signed token, no authentication)
+	router.GET("/unsubscribe", h.unsubscribeHandler.ShowUnsubscribe)
+	router.POST("/unsubscribe", h.unsubscribeHandler.Unsubscribe)
+
+	// Public double opt-in confirmation routes (signed token, no authentication)
+	router.GET("/confirm", h.confirmHandler.ShowConfirm)
+	router.POST("/confirm", h.confirmHandler.Confirm)
+
+	// Email provider event webhooks (shared secret, no API key)
+	router.POST("/webhooks/brevo", h.webhookHandler.BrevoEvents)
+
+	// Public open and click tracking routes (signed token, no authentication)
+	router.GET("/track/open", h.trackingHandler.TrackOpen)
+	router.GET("/track/click", h.trackingHandler.TrackClick)
+
+	// API v1 routes, authenticated with an API key
+	viewer := requireRole(constants.RoleViewer)
+	editor := requireRole(constants.RoleEditor)
+	admin := requireRole(constants.RoleAdmin)
+
 	v1 := router.Group("/api/v1")
+	v1.Use(authenticate(h.apiKeyService))
 	{
 		// Topic routes
 		topics := v1.Group("/topics")
 		{
-			topics.POST("", h.topicHandler.CreateTopic)
-			topics.GET("", h.topicHandler.ListTopics)
-			topics.GET("/:id", h.topicHandler.GetTopic)
-			topics.PUT("/:id", h.topicHandler.UpdateTopic)
-			topics.DELETE("/:id", h.topicHandler.DeleteTopic)
+			topics.POST("", editor, h.topicHandler.CreateTopic)
+			topics.GET("", viewer, h.topicHandler.ListTopics)
+			topics.GET("/:id", viewer, h.topicHandler.GetTopic)
+			topics.PUT("/:id", editor, h.topicHandler.UpdateTopic)
+			topics.DELETE("/:id", editor, h.topicHandler.DeleteTopic)
 			
 			// Topic-specific subscription routes
-			topics.GET("/:id/subscribers", h.subscriptionHandler.ListTopicSubscribers)
+			topics.GET("/:id/subscribers", viewer, h.subscriptionHandler.ListTopicSubscribers)
 			
 			// Topic-specific content routes
-			topics.GET("/:id/content", h.contentHandler.ListContentByTopic)
+			topics.GET("/:id/content", viewer, h.contentHandler.ListContentByTopic)
 		}
 
 		// Subscriber routes
 		subscribers := v1.Group("/subscribers")
 		{
-			subscribers.POST("", h.subscriberHandler.CreateSubscriber)
-			subscribers.GET("", h.subscriberHandler.ListSubscribers)
-			subscribers.GET("/search", h.subscriberHandler.GetSubscriberByEmail) // ?email=user@example.com
-			subscribers.GET("/:id", h.subscriberHandler.GetSubscriber)
-			subscribers.PUT("/:id", h.subscriberHandler.UpdateSubscriber)
-			subscribers.DELETE("/:id", h.subscriberHandler.DeleteSubscriber)
+			subscribers.POST("", editor, h.subscriberHandler.CreateSubscriber)
+			subscribers.GET("", viewer, h.subscriberHandler.ListSubscribers)
+			subscribers.GET("/search", viewer, h.subscriberHandler.GetSubscriberByEmail) // ?email=user@example.com
+			subscribers.GET("/:id", viewer, h.subscriberHandler.GetSubscriber)
+			subscribers.PUT("/:id", editor, h.subscriberHandler.UpdateSubscriber)
+			subscribers.DELETE("/:id", editor, h.subscriberHandler.DeleteSubscriber)
 			
 			// Subscriber-specific subscription routes
-			subscribers.GET("/:id/topics", h.subscriptionHandler.ListSubscriberTopics)
+			subscribers.GET("/:id/topics", viewer, h.subscriptionHandler.ListSubscriberTopics)
 		}
 
 		// Subscription routes
 		subscriptions := v1.Group("/subscriptions")
 		{
-			subscriptions.POST("", h.subscriptionHandler.Subscribe)
-			subscriptions.GET("/:id", h.subscriptionHandler.GetSubscription)
-			subscriptions.DELETE("/:subscriber_id/:topic_id", h.subscriptionHandler.Unsubscribe)
+			subscriptions.POST("", editor, h.subscriptionHandler.Subscribe)
+			subscriptions.GET("/:id", viewer, h.subscriptionHandler.GetSubscription)
+			subscriptions.DELETE("/:subscriber_id/:topic_id", editor, h.subscriptionHandler.Unsubscribe)
 		}
 
 		// Content routes
 		content := v1.Group("/content")
 		{
-			content.POST("", h.contentHandler.CreateContent)
-			content.GET("", h.contentHandler.ListContent)
-			content.GET("/:id", h.contentHandler.GetContent)
-			content.PUT("/:id", h.contentHandler.UpdateContent)
-			content.DELETE("/:id", h.contentHandler.DeleteContent)
-			content.POST("/:id/schedule", h.contentHandler.ScheduleContent)
+			content.POST("", editor, h.contentHandler.CreateContent)
+			content.GET("", viewer, h.contentHandler.ListContent)
+			content.GET("/:id", viewer, h.contentHandler.GetContent)
+			content.PUT("/:id", editor, h.contentHandler.UpdateContent)
+			content.DELETE("/:id", editor, h.contentHandler.DeleteContent)
+			content.POST("/:id/schedule", editor, h.contentHandler.ScheduleContent)
+			content.POST("/:id/reschedule", editor, h.contentHandler.RescheduleContent)
+			content.POST("/:id/cancel", editor, h.contentHandler.CancelContent)
+			content.GET("/:id/deliveries", viewer, h.deliveryHandler.ListContentDeliveries) // ?status=failed,bounced&cursor=...
+			content.GET("/:id/report", viewer, h.deliveryHandler.GetContentReport)         // ?format=csv
+		}
+
+		// Recurring schedule routes
+		schedules := v1.Group("/schedules")
+		{
+			schedules.POST("", editor, h.scheduleHandler.CreateSchedule)
+			schedules.GET("", viewer, h.scheduleHandler.ListSchedules)
+			schedules.POST("/preview", viewer, h.scheduleHandler.PreviewExpression)
+			schedules.GET("/:id", viewer, h.scheduleHandler.GetSchedule)
+			schedules.PUT("/:id", editor, h.scheduleHandler.UpdateSchedule)
+			schedules.DELETE("/:id", editor, h.scheduleHandler.DeleteSchedule)
+			schedules.POST("/:id/pause", editor, h.scheduleHandler.PauseSchedule)
+			schedules.POST("/:id/resume", editor, h.scheduleHandler.ResumeSchedule)
+			schedules.GET("/:id/preview", viewer, h.scheduleHandler.PreviewSchedule)
+		}
+
+		// Job routes for inspecting and recovering scheduled sends. Manual
+		// interventions are recorded in the job's audit log.
+		jobs := v1.Group("/jobs")
+		{
+			jobs.GET("", viewer, h.jobHandler.ListJobs) // ?status=failed&job_type=send_newsletter&content_id=...
+			jobs.GET("/:id", viewer, h.jobHandler.GetJob)
+			jobs.POST("/:id/retry", admin, h.jobHandler.RetryJob)
+			jobs.POST("/:id/fail", admin, h.jobHandler.FailJob)
+			jobs.DELETE("/:id", admin, h.jobHandler.DeleteJob)
+		}
+
+		// Suppression list routes
+		suppressions := v1.Group("/suppressions")
+		{
+			suppressions.POST("", editor, h.suppressionHandler.CreateSuppression)
+			suppressions.POST("/import", editor, h.suppressionHandler.ImportSuppressions)
+			suppressions.GET("", viewer, h.suppressionHandler.ListSuppressions)
+			suppressions.GET("/:id", viewer, h.suppressionHandler.GetSuppression)
+			suppressions.DELETE("/:id", editor, h.suppressionHandler.DeleteSuppression)
 		}
+
+		// API key management routes
+		apiKeys := v1.Group("/api-keys", admin)
+		{
+			apiKeys.POST("", h.apiKeyHandler.CreateAPIKey)
+			apiKeys.GET("", h.apiKeyHandler.ListAPIKeys)
+			apiKeys.DELETE("/:id", h.apiKeyHandler.RevokeAPIKey)
+		}
+
+		// Workspace routes. Every key belongs to one workspace; creating a
+		// workspace returns the admin key for the new workspace.
+		v1.GET("/workspace", viewer, h.workspaceHandler.GetCurrentWorkspace)
+		v1.PUT("/workspace", admin, h.workspaceHandler.UpdateCurrentWorkspace)
+		v1.POST("/workspaces", admin, h.workspaceHandler.CreateWorkspace)
+
+		// Email provider circuit breaker state
+		v1.GET("/email/providers", admin, h.emailStatusHandler.GetProviderStatus)
 	}
 
 	return router
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"newsletter-assignment/internal/apperr"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details response
+type Problem struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	Code     apperr.Kind         `json:"code,omitempty"`
+	Errors   []apperr.FieldError `json:"errors,omitempty"`
+}
+
+// ErrorHandler renders the last error attached with c.Error as a problem response.
+// Domain errors keep their message; anything else becomes an opaque 500.
+func ErrorHandler(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		problem := Problem{
+			Type:     "about:blank",
+			Status:   http.StatusInternalServerError,
+			Detail:   "An unexpected error occurred",
+			Instance: c.Request.URL.Path,
+		}
+
+		if appErr, ok := apperr.As(err); ok {
+			problem.Status = statusForKind(appErr.Kind)
+			problem.Detail = err.Error()
+			problem.Code = appErr.Kind
+			problem.Errors = appErr.Fields
+		} else {
+			logger.Error("Request failed",
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+				zap.Error(err),
+			)
+		}
+		problem.Title = http.StatusText(problem.Status)
+
+		c.Header("Content-Type", problemContentType)
+		c.JSON(problem.Status, problem)
+	}
+}
+
+func statusForKind(kind apperr.Kind) int {
+	switch kind {
+	case apperr.KindNotFound:
+		return http.StatusNotFound
+	case apperr.KindConflict:
+		return http.StatusConflict
+	case apperr.KindValidation:
+		return http.StatusBadRequest
+	case apperr.KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case apperr.KindUnauthorized:
+		return http.StatusUnauthorized
+	case apperr.KindForbidden:
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// useJSONFieldNames makes binding validation errors report JSON field names
+func useJSONFieldNames() {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+}
@@ -0,0 +1,43 @@
+package links
+
+import (
+	"net/url"
+
+	"newsletter-assignment/internal/token"
+
+	"github.com/google/uuid"
+)
+
+// Builder creates signed public links embedded in outgoing emails
+type Builder struct {
+	baseURL string
+	signer  *token.Signer
+}
+
+// NewBuilder creates a new link builder
+func NewBuilder(baseURL string, signer *token.Signer) *Builder {
+	return &Builder{
+		baseURL: baseURL,
+		signer:  signer,
+	}
+}
+
+// UnsubscribeURL returns the one-click unsubscribe link for a subscriber and topic in a workspace
+func (b *Builder) UnsubscribeURL(workspaceID, subscriberID, topicID uuid.UUID) string {
+	return b.baseURL + "/unsubscribe?token=" + url.QueryEscape(b.signer.SignUnsubscribe(workspaceID, subscriberID, topicID))
+}
+
+// ConfirmSubscriptionURL returns the double opt-in confirmation link for a subscription
+func (b *Builder) ConfirmSubscriptionURL(workspaceID, subscriptionID uuid.UUID) string {
+	return b.baseURL + "/confirm?token=" + url.QueryEscape(b.signer.SignConfirmSubscription(workspaceID, subscriptionID))
+}
+
+// TrackOpenURL returns the tracking pixel image for a delivery
+func (b *Builder) TrackOpenURL(workspaceID, deliveryID uuid.UUID) string {
+	return b.baseURL + "/track/open?token=" + url.QueryEscape(b.signer.SignTrackOpen(workspaceID, deliveryID))
+}
+
+// TrackClickURL returns the redirect recording a click on target in a delivery
+func (b *Builder) TrackClickURL(workspaceID, deliveryID uuid.UUID, target string) string {
+	return b.baseURL + "/track/click?token=" + url.QueryEscape(b.signer.SignTrackClick(workspaceID, deliveryID, target))
+}
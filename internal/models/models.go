@@ -3,73 +3,247 @@ package models
 import (
 	"time"
 
+	"newsletter-assignment/internal/constants"
+
 	"github.com/google/uuid"
 )
 
+// Workspace is a tenant. It scopes all newsletter data and may override the sender identity.
+type Workspace struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Slug      string    `json:"slug" db:"slug"`
+	FromEmail *string   `json:"from_email" db:"from_email"`
+	FromName  *string   `json:"from_name" db:"from_name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
 // Topic represents a newsletter topic
 type Topic struct {
-	ID          uuid.UUID `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name"`
-	Description *string   `json:"description" db:"description"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID              uuid.UUID `json:"id" db:"id"`
+	WorkspaceID     uuid.UUID `json:"workspace_id" db:"workspace_id"`
+	Name            string    `json:"name" db:"name"`
+	Description     *string   `json:"description" db:"description"`
+	DoubleOptIn     bool      `json:"double_opt_in" db:"double_opt_in"`
+	TrackingEnabled bool      `json:"tracking_enabled" db:"tracking_enabled"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
 }
 
-
 // Subscriber represents an email subscriber
 type Subscriber struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	Email     string    `json:"email" db:"email"`
-	Name      *string   `json:"name" db:"name"`
-	IsActive  bool      `json:"is_active" db:"is_active"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID          uuid.UUID `json:"id" db:"id"`
+	WorkspaceID uuid.UUID `json:"workspace_id" db:"workspace_id"`
+	Email       string    `json:"email" db:"email"`
+	Name        *string   `json:"name" db:"name"`
+	Timezone    *string   `json:"timezone" db:"timezone"`
+	IsActive    bool      `json:"is_active" db:"is_active"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // Subscription represents a subscriber's subscription to a topic
 type Subscription struct {
-	ID           uuid.UUID `json:"id" db:"id"`
-	SubscriberID uuid.UUID `json:"subscriber_id" db:"subscriber_id"`
-	TopicID      uuid.UUID `json:"topic_id" db:"topic_id"`
-	SubscribedAt time.Time `json:"subscribed_at" db:"subscribed_at"`
-	IsActive     bool      `json:"is_active" db:"is_active"`
+	ID                    uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID           uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	SubscriberID          uuid.UUID  `json:"subscriber_id" db:"subscriber_id"`
+	TopicID               uuid.UUID  `json:"topic_id" db:"topic_id"`
+	SubscribedAt          time.Time  `json:"subscribed_at" db:"subscribed_at"`
+	IsActive              bool       `json:"is_active" db:"is_active"`
+	Status                string     `json:"status" db:"status"`
+	ConfirmationExpiresAt *time.Time `json:"confirmation_expires_at,omitempty" db:"confirmation_expires_at"`
+	ConfirmedAt           *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"`
 }
 
 // Content represents scheduled newsletter content
 type Content struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	TopicID   uuid.UUID `json:"topic_id" db:"topic_id"`
-	Subject   string    `json:"subject" db:"subject"`
-	Body      string    `json:"body" db:"body"`
-	SendAt    time.Time `json:"send_at" db:"send_at"`
-	Status    string    `json:"status" db:"status"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID                  uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID         uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	TopicID             uuid.UUID  `json:"topic_id" db:"topic_id"`
+	Subject             string     `json:"subject" db:"subject"`
+	Body                string     `json:"body" db:"body"`
+	SendAt              time.Time  `json:"send_at" db:"send_at"`
+	Status              string     `json:"status" db:"status"`
+	SentCount           int        `json:"sent_count" db:"sent_count"`
+	FailedCount         int        `json:"failed_count" db:"failed_count"`
+	SkippedCount        int        `json:"skipped_count" db:"skipped_count"`
+	BouncedCount        int        `json:"bounced_count" db:"bounced_count"`
+	RecurringScheduleID *uuid.UUID `json:"recurring_schedule_id" db:"recurring_schedule_id"`
+	DeliveryMode        string     `json:"delivery_mode" db:"delivery_mode"`
+	TrackingEnabled     *bool      `json:"tracking_enabled" db:"tracking_enabled"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// RecurringSchedule represents a cron schedule that generates content occurrences
+type RecurringSchedule struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID    uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	TopicID        uuid.UUID  `json:"topic_id" db:"topic_id"`
+	ContentID      *uuid.UUID `json:"content_id" db:"content_id"`
+	Subject        *string    `json:"subject" db:"subject"`
+	Body           *string    `json:"body" db:"body"`
+	CronExpression string     `json:"cron_expression" db:"cron_expression"`
+	Timezone       string     `json:"timezone" db:"timezone"`
+	Status         string     `json:"status" db:"status"`
+	NextRunAt      time.Time  `json:"next_run_at" db:"next_run_at"`
+	LastRunAt      *time.Time `json:"last_run_at" db:"last_run_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Suppression is an address that must not be mailed
+type Suppression struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	WorkspaceID uuid.UUID `json:"workspace_id" db:"workspace_id"`
+	Email       string    `json:"email" db:"email"`
+	Reason      string    `json:"reason" db:"reason"`
+	Source      string    `json:"source" db:"source"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// DeliveryCounts summarizes delivery outcomes for a send. Bounced is only known
+// for content totals, since bounces are reported after the send.
+type DeliveryCounts struct {
+	Sent    int `json:"sent"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+	Bounced int `json:"bounced"`
+}
+
+// ContentStatus returns the final status of content whose deliveries add up to the
+// counts: failed when emails failed and none was sent, partially sent when only some
+// failed, and sent otherwise. Bounced emails were sent.
+func (c DeliveryCounts) ContentStatus() string {
+	switch {
+	case c.Failed > 0 && c.Sent+c.Bounced == 0:
+		return constants.ContentStatusFailed
+	case c.Failed > 0:
+		return constants.ContentStatusPartiallySent
+	}
+	return constants.ContentStatusSent
 }
 
 // Delivery represents an individual email delivery
 type Delivery struct {
+	ID                uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID       uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	ContentID         uuid.UUID  `json:"content_id" db:"content_id"`
+	SubscriberID      uuid.UUID  `json:"subscriber_id" db:"subscriber_id"`
+	Email             string     `json:"email" db:"email"`
+	Status            string     `json:"status" db:"status"`
+	SentAt            *time.Time `json:"sent_at" db:"sent_at"`
+	ErrorMessage      *string    `json:"error_message" db:"error_message"`
+	Attempts          int        `json:"attempts" db:"attempts"`
+	NextRetryAt       *time.Time `json:"next_retry_at" db:"next_retry_at"`
+	ProviderMessageID *string    `json:"provider_message_id" db:"provider_message_id"`
+	DeliveredAt       *time.Time `json:"delivered_at" db:"delivered_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// DeliveryReport aggregates the deliveries of a content
+type DeliveryReport struct {
+	ContentID           uuid.UUID        `json:"content_id"`
+	Total               int              `json:"total"`
+	ByStatus            map[string]int   `json:"by_status"`
+	FirstSentAt         *time.Time       `json:"first_sent_at"`
+	LastSentAt          *time.Time       `json:"last_sent_at"`
+	SendDurationSeconds *float64         `json:"send_duration_seconds"`
+	FailureReasons      []FailureReason  `json:"failure_reasons"`
+	Engagement          *EngagementStats `json:"engagement"`
+}
+
+// FailureReason counts the deliveries that were not sent for the same reason
+type FailureReason struct {
+	ErrorMessage string `json:"error_message"`
+	Count        int    `json:"count"`
+}
+
+// EngagementStats summarizes the opens and clicks of tracked content. Rates are the
+// share of sent deliveries opened or clicked at least once.
+type EngagementStats struct {
+	Opens        int     `json:"opens"`
+	UniqueOpens  int     `json:"unique_opens"`
+	Clicks       int     `json:"clicks"`
+	UniqueClicks int     `json:"unique_clicks"`
+	OpenRate     float64 `json:"open_rate"`
+	ClickRate    float64 `json:"click_rate"`
+}
+
+// DeliveryEvent is an open or click recorded for a delivery
+type DeliveryEvent struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	WorkspaceID uuid.UUID `json:"workspace_id" db:"workspace_id"`
+	DeliveryID  uuid.UUID `json:"delivery_id" db:"delivery_id"`
+	EventType   string    `json:"event_type" db:"event_type"`
+	URL         *string   `json:"url" db:"url"`
+	UserAgent   *string   `json:"user_agent" db:"user_agent"`
+	OccurredAt  time.Time `json:"occurred_at" db:"occurred_at"`
+}
+
+// JobScheduler represents a scheduled job
+type JobScheduler struct {
 	ID           uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID  uuid.UUID  `json:"workspace_id" db:"workspace_id"`
 	ContentID    uuid.UUID  `json:"content_id" db:"content_id"`
-	SubscriberID uuid.UUID  `json:"subscriber_id" db:"subscriber_id"`
-	Email        string     `json:"email" db:"email"`
+	DeliveryID   *uuid.UUID `json:"delivery_id" db:"delivery_id"`
+	Timezone     *string    `json:"timezone" db:"timezone"`
+	TaskID       *string    `json:"task_id" db:"task_id"`
+	JobType      string     `json:"job_type" db:"job_type"`
+	ScheduledAt  time.Time  `json:"scheduled_at" db:"scheduled_at"`
 	Status       string     `json:"status" db:"status"`
-	SentAt       *time.Time `json:"sent_at" db:"sent_at"`
+	Attempts     int        `json:"attempts" db:"attempts"`
+	MaxAttempts  int        `json:"max_attempts" db:"max_attempts"`
 	ErrorMessage *string    `json:"error_message" db:"error_message"`
+	SentCount    int        `json:"sent_count" db:"sent_count"`
+	FailedCount  int        `json:"failed_count" db:"failed_count"`
+	SkippedCount int        `json:"skipped_count" db:"skipped_count"`
+	HeartbeatAt  *time.Time `json:"heartbeat_at" db:"heartbeat_at"`
 	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
 }
 
-// JobScheduler represents a scheduled job
-type JobScheduler struct {
+// JobError is one error recorded by a job attempt
+type JobError struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	JobID        uuid.UUID `json:"job_id" db:"job_id"`
+	Attempt      int       `json:"attempt" db:"attempt"`
+	ErrorMessage string    `json:"error_message" db:"error_message"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// JobAuditEntry records a manual intervention on a job and the API key that made it
+type JobAuditEntry struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID    uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	JobID          uuid.UUID  `json:"job_id" db:"job_id"`
+	Action         string     `json:"action" db:"action"`
+	APIKeyID       *uuid.UUID `json:"api_key_id" db:"api_key_id"`
+	PreviousStatus string     `json:"previous_status" db:"previous_status"`
+	NewStatus      *string    `json:"new_status" db:"new_status"`
+	Reason         *string    `json:"reason" db:"reason"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// JobDetails is a job with its error history and audit log
+type JobDetails struct {
+	*JobScheduler
+	Errors   []*JobError      `json:"errors"`
+	AuditLog []*JobAuditEntry `json:"audit_log"`
+}
+
+// APIKey is a credential for the admin API. Only a hash of the key is stored.
+type APIKey struct {
 	ID          uuid.UUID  `json:"id" db:"id"`
-	ContentID   uuid.UUID  `json:"content_id" db:"content_id"`
-	JobType     string     `json:"job_type" db:"job_type"`
-	ScheduledAt time.Time  `json:"scheduled_at" db:"scheduled_at"`
-	Status      string     `json:"status" db:"status"`
-	Attempts    int        `json:"attempts" db:"attempts"`
-	MaxAttempts int        `json:"max_attempts" db:"max_attempts"`
-	ErrorMessage *string   `json:"error_message" db:"error_message"`
+	WorkspaceID uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	Name        string     `json:"name" db:"name"`
+	KeyPrefix   string     `json:"key_prefix" db:"key_prefix"`
+	KeyHash     string     `json:"-" db:"key_hash"`
+	Role        string     `json:"role" db:"role"`
+	LastUsedAt  *time.Time `json:"last_used_at" db:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at" db:"revoked_at"`
 	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
 }
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+
+	"newsletter-assignment/internal/constants"
+)
+
+func TestDeliveryCountsContentStatus(t *testing.T) {
+	tests := []struct {
+		counts DeliveryCounts
+		want   string
+	}{
+		{DeliveryCounts{Sent: 3}, constants.ContentStatusSent},
+		{DeliveryCounts{Sent: 3, Skipped: 2}, constants.ContentStatusSent},
+		{DeliveryCounts{Skipped: 2}, constants.ContentStatusSent},
+		{DeliveryCounts{}, constants.ContentStatusSent},
+		{DeliveryCounts{Sent: 3, Failed: 1}, constants.ContentStatusPartiallySent},
+		{DeliveryCounts{Failed: 1}, constants.ContentStatusFailed},
+		{DeliveryCounts{Failed: 1, Bounced: 2}, constants.ContentStatusPartiallySent},
+		{DeliveryCounts{Bounced: 2}, constants.ContentStatusSent},
+		{DeliveryCounts{Failed: 1, Skipped: 2}, constants.ContentStatusFailed},
+	}
+
+	for _, tt := range tests {
+		if got := tt.counts.ContentStatus(); got != tt.want {
+			t.Errorf("%+v.ContentStatus() = %q, want %q", tt.counts, got, tt.want)
+		}
+	}
+}
@@ -4,15 +4,30 @@ import (
 	// "crypto/tls"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/hibiken/asynq"
 )
 
+// defaultQueue is the Asynq queue tasks are enqueued to
+const defaultQueue = "default"
+
+// ErrTaskNotRevocable is returned when a task is already running
+var ErrTaskNotRevocable = errors.New("task cannot be revoked")
+
+// ErrTaskNotFound is returned when a task is no longer in the queue. It is also
+// an ErrTaskNotRevocable.
+var ErrTaskNotFound = fmt.Errorf("%w: task not found", ErrTaskNotRevocable)
+
 // Queue defines the interface for job queue operations
 type Queue interface {
 	// Client operations
 	EnqueueSendContent(contentID, jobID string) (*asynq.TaskInfo, error)
+	EnqueueRetryDelivery(deliveryID, jobID string) (*asynq.TaskInfo, error)
+	RevokeTask(taskID string) error
+	TaskState(taskID string) (asynq.TaskState, error)
 	Close() error
 
 	// Server operations
@@ -24,10 +39,11 @@ type Queue interface {
 
 // AsynqQueue implements the Queue interface using Asynq
 type AsynqQueue struct {
-	client *asynq.Client
-	server *asynq.Server
-	mux    *asynq.ServeMux
-	logger interface{} // Using interface{} to avoid zap dependency in interface
+	client    *asynq.Client
+	inspector *asynq.Inspector
+	server    *asynq.Server
+	mux       *asynq.ServeMux
+	logger    interface{} // Using interface{} to avoid zap dependency in interface
 }
 
 // NewAsynqQueue creates a new Asynq-based queue
@@ -45,6 +61,7 @@ func NewAsynqQueue(redisAddr, redisPassword string, redisDB int, tlsConfigNeeded
 	}
 
 	client := asynq.NewClient(redisOpt)
+	inspector := asynq.NewInspector(redisOpt)
 
 	server := asynq.NewServer(
 		redisOpt,
@@ -61,10 +78,11 @@ func NewAsynqQueue(redisAddr, redisPassword string, redisDB int, tlsConfigNeeded
 	mux := asynq.NewServeMux()
 
 	return &AsynqQueue{
-		client: client,
-		server: server,
-		mux:    mux,
-		logger: logger,
+		client:    client,
+		inspector: inspector,
+		server:    server,
+		mux:       mux,
+		logger:    logger,
 	}
 }
 
@@ -84,8 +102,67 @@ func (q *AsynqQueue) EnqueueSendContent(contentID, jobID string) (*asynq.TaskInf
 	return q.client.Enqueue(task)
 }
 
-// Close closes the client connection
+// EnqueueRetryDelivery enqueues a retry of a single failed delivery
+func (q *AsynqQueue) EnqueueRetryDelivery(deliveryID, jobID string) (*asynq.TaskInfo, error) {
+	payload := map[string]interface{}{
+		"delivery_id": deliveryID,
+		"job_id":      jobID,
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	task := asynq.NewTask("retry_delivery", payloadBytes)
+	return q.client.Enqueue(task)
+}
+
+// RevokeTask deletes a task unless it is being processed. It returns
+// ErrTaskNotRevocable if the task is running, or ErrTaskNotFound if it is no longer
+// in the queue.
+func (q *AsynqQueue) RevokeTask(taskID string) error {
+	info, err := q.inspector.GetTaskInfo(defaultQueue, taskID)
+	if err != nil {
+		if errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+			return ErrTaskNotFound
+		}
+		return err
+	}
+
+	if info.State == asynq.TaskStateActive {
+		return ErrTaskNotRevocable
+	}
+
+	if err := q.inspector.DeleteTask(defaultQueue, taskID); err != nil {
+		if errors.Is(err, asynq.ErrTaskNotFound) {
+			return ErrTaskNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// TaskState returns the state of a task in the queue, or ErrTaskNotFound if the
+// task is no longer there
+func (q *AsynqQueue) TaskState(taskID string) (asynq.TaskState, error) {
+	info, err := q.inspector.GetTaskInfo(defaultQueue, taskID)
+	if err != nil {
+		if errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+			return 0, ErrTaskNotFound
+		}
+		return 0, err
+	}
+
+	return info.State, nil
+}
+
+// Close closes the client and inspector connections
 func (q *AsynqQueue) Close() error {
+	if err := q.inspector.Close(); err != nil {
+		return err
+	}
 	return q.client.Close()
 }
 
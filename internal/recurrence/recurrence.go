@@ -0,0 +1,57 @@
+package recurrence
+
+import (
+	"fmt"
+	"time"
+
+	"newsletter-assignment/internal/apperr"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ErrInvalidSchedule is returned when a cron expression or time zone cannot be used
+var ErrInvalidSchedule = apperr.Validation("invalid schedule")
+
+// parser accepts standard five-field expressions and descriptors such as @weekly
+var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Schedule is a parsed cron expression evaluated in a time zone
+type Schedule struct {
+	schedule cron.Schedule
+	location *time.Location
+}
+
+// Parse parses a cron expression to be evaluated in the named IANA time zone
+func Parse(expression, timezone string) (*Schedule, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidSchedule, timezone)
+	}
+
+	schedule, err := parser.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+
+	return &Schedule{schedule: schedule, location: location}, nil
+}
+
+// Next returns the first run strictly after the given time, or the zero time
+// if the expression never fires
+func (s *Schedule) Next(after time.Time) time.Time {
+	return s.schedule.Next(after.In(s.location))
+}
+
+// NextN returns up to n consecutive runs after the given time
+func (s *Schedule) NextN(after time.Time, n int) []time.Time {
+	runs := make([]time.Time, 0, n)
+	for len(runs) < n {
+		next := s.Next(after)
+		if next.IsZero() {
+			break
+		}
+		runs = append(runs, next)
+		after = next
+	}
+	return runs
+}
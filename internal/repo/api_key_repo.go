@@ -0,0 +1,165 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/db"
+	"newsletter-assignment/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type apiKeyRepo struct {
+	db *db.DB
+}
+
+func NewAPIKeyRepository(database *db.DB) APIKeyRepository {
+	return &apiKeyRepo{
+		db: database,
+	}
+}
+
+func (r *apiKeyRepo) Create(ctx context.Context, workspaceID uuid.UUID, name, keyPrefix, keyHash, role string) (*models.APIKey, error) {
+	query := `
+		INSERT INTO api_keys (workspace_id, name, key_prefix, key_hash, role)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, workspace_id, name, key_prefix, key_hash, role, last_used_at, revoked_at, created_at, updated_at
+	`
+
+	var key models.APIKey
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, name, keyPrefix, keyHash, role).Scan(
+		&key.ID,
+		&key.WorkspaceID,
+		&key.Name,
+		&key.KeyPrefix,
+		&key.KeyHash,
+		&key.Role,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+		&key.UpdatedAt,
+	)
+
+	if err != nil {
+		if isUniqueViolation(err, "api_keys_key_hash_key") {
+			return nil, apperr.Conflict("api key already exists")
+		}
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return &key, nil
+}
+
+// GetByHash returns the key with the given hash, including revoked keys. It is not
+// scoped to a workspace because the key itself determines the workspace.
+func (r *apiKeyRepo) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	query := `
+		SELECT id, workspace_id, name, key_prefix, key_hash, role, last_used_at, revoked_at, created_at, updated_at
+		FROM api_keys
+		WHERE key_hash = $1
+	`
+
+	var key models.APIKey
+	err := r.db.Pool.QueryRow(ctx, query, keyHash).Scan(
+		&key.ID,
+		&key.WorkspaceID,
+		&key.Name,
+		&key.KeyPrefix,
+		&key.KeyHash,
+		&key.Role,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+		&key.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, apperr.NotFound("api key not found")
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return &key, nil
+}
+
+func (r *apiKeyRepo) List(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.APIKey, error) {
+	query := `
+		SELECT id, workspace_id, name, key_prefix, key_hash, role, last_used_at, revoked_at, created_at, updated_at
+		FROM api_keys
+		WHERE workspace_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, workspaceID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		var key models.APIKey
+		err := rows.Scan(
+			&key.ID,
+			&key.WorkspaceID,
+			&key.Name,
+			&key.KeyPrefix,
+			&key.KeyHash,
+			&key.Role,
+			&key.LastUsedAt,
+			&key.RevokedAt,
+			&key.CreatedAt,
+			&key.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, &key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Revoke marks a key as revoked; revoking an already revoked key is a no-op
+func (r *apiKeyRepo) Revoke(ctx context.Context, workspaceID, id uuid.UUID) error {
+	query := `
+		UPDATE api_keys
+		SET revoked_at = COALESCE(revoked_at, NOW()), updated_at = NOW()
+		WHERE workspace_id = $1 AND id = $2
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, workspaceID, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperr.NotFound("api key not found")
+	}
+
+	return nil
+}
+
+// TouchLastUsed records key usage, at most once a minute per key to limit writes
+func (r *apiKeyRepo) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to update api key usage: %w", err)
+	}
+
+	return nil
+}
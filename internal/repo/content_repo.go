@@ -3,7 +3,9 @@ package repo
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"newsletter-assignment/internal/apperr"
 	"newsletter-assignment/internal/constants"
 	"newsletter-assignment/internal/db"
 	"newsletter-assignment/internal/models"
@@ -23,21 +25,29 @@ func NewContentRepository(database *db.DB) ContentRepository {
 	}
 }
 
-func (r *contentRepo) Create(ctx context.Context, req *request.CreateContentRequest) (*models.Content, error) {
+func (r *contentRepo) Create(ctx context.Context, workspaceID uuid.UUID, req *request.CreateContentRequest) (*models.Content, error) {
 	query := `
-		INSERT INTO content (topic_id, subject, body, send_at)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, topic_id, subject, body, send_at, status, created_at, updated_at
+		INSERT INTO content (workspace_id, topic_id, subject, body, send_at, delivery_mode, tracking_enabled)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, workspace_id, topic_id, subject, body, send_at, status, sent_count, failed_count, skipped_count, bounced_count, recurring_schedule_id, delivery_mode, tracking_enabled, created_at, updated_at
 	`
 
 	var content models.Content
-	err := r.db.Pool.QueryRow(ctx, query, req.TopicID, req.Subject, req.Body, req.SendAt).Scan(
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, req.TopicID, req.Subject, req.Body, req.SendAt, req.DeliveryMode, req.TrackingEnabled).Scan(
 		&content.ID,
+		&content.WorkspaceID,
 		&content.TopicID,
 		&content.Subject,
 		&content.Body,
 		&content.SendAt,
 		&content.Status,
+		&content.SentCount,
+		&content.FailedCount,
+		&content.SkippedCount,
+		&content.BouncedCount,
+		&content.RecurringScheduleID,
+		&content.DeliveryMode,
+		&content.TrackingEnabled,
 		&content.CreatedAt,
 		&content.UpdatedAt,
 	)
@@ -49,21 +59,29 @@ func (r *contentRepo) Create(ctx context.Context, req *request.CreateContentRequ
 	return &content, nil
 }
 
-func (r *contentRepo) CreateTx(ctx context.Context, tx pgx.Tx, req *request.CreateContentRequest) (*models.Content, error) {
+func (r *contentRepo) CreateTx(ctx context.Context, tx pgx.Tx, workspaceID uuid.UUID, req *request.CreateContentRequest) (*models.Content, error) {
 	query := `
-		INSERT INTO content (topic_id, subject, body, send_at)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, topic_id, subject, body, send_at, status, created_at, updated_at
+		INSERT INTO content (workspace_id, topic_id, subject, body, send_at, delivery_mode, tracking_enabled)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, workspace_id, topic_id, subject, body, send_at, status, sent_count, failed_count, skipped_count, bounced_count, recurring_schedule_id, delivery_mode, tracking_enabled, created_at, updated_at
 	`
 
 	var content models.Content
-	err := tx.QueryRow(ctx, query, req.TopicID, req.Subject, req.Body, req.SendAt).Scan(
+	err := tx.QueryRow(ctx, query, workspaceID, req.TopicID, req.Subject, req.Body, req.SendAt, req.DeliveryMode, req.TrackingEnabled).Scan(
 		&content.ID,
+		&content.WorkspaceID,
 		&content.TopicID,
 		&content.Subject,
 		&content.Body,
 		&content.SendAt,
 		&content.Status,
+		&content.SentCount,
+		&content.FailedCount,
+		&content.SkippedCount,
+		&content.BouncedCount,
+		&content.RecurringScheduleID,
+		&content.DeliveryMode,
+		&content.TrackingEnabled,
 		&content.CreatedAt,
 		&content.UpdatedAt,
 	)
@@ -75,28 +93,36 @@ func (r *contentRepo) CreateTx(ctx context.Context, tx pgx.Tx, req *request.Crea
 	return &content, nil
 }
 
-func (r *contentRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Content, error) {
+func (r *contentRepo) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Content, error) {
 	query := `
-		SELECT id, topic_id, subject, body, send_at, status, created_at, updated_at
+		SELECT id, workspace_id, topic_id, subject, body, send_at, status, sent_count, failed_count, skipped_count, bounced_count, recurring_schedule_id, delivery_mode, tracking_enabled, created_at, updated_at
 		FROM content
-		WHERE id = $1
+		WHERE workspace_id = $1 AND id = $2
 	`
 
 	var content models.Content
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, id).Scan(
 		&content.ID,
+		&content.WorkspaceID,
 		&content.TopicID,
 		&content.Subject,
 		&content.Body,
 		&content.SendAt,
 		&content.Status,
+		&content.SentCount,
+		&content.FailedCount,
+		&content.SkippedCount,
+		&content.BouncedCount,
+		&content.RecurringScheduleID,
+		&content.DeliveryMode,
+		&content.TrackingEnabled,
 		&content.CreatedAt,
 		&content.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("content not found")
+			return nil, apperr.NotFound("content not found")
 		}
 		return nil, fmt.Errorf("failed to get content: %w", err)
 	}
@@ -104,15 +130,16 @@ func (r *contentRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Conten
 	return &content, nil
 }
 
-func (r *contentRepo) List(ctx context.Context, limit, offset int) ([]*models.Content, error) {
+func (r *contentRepo) List(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.Content, error) {
 	query := `
-		SELECT id, topic_id, subject, body, send_at, status, created_at, updated_at
+		SELECT id, workspace_id, topic_id, subject, body, send_at, status, sent_count, failed_count, skipped_count, bounced_count, recurring_schedule_id, delivery_mode, tracking_enabled, created_at, updated_at
 		FROM content
+		WHERE workspace_id = $1
 		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
+		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, limit, offset)
+	rows, err := r.db.Pool.Query(ctx, query, workspaceID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list content: %w", err)
 	}
@@ -123,11 +150,19 @@ func (r *contentRepo) List(ctx context.Context, limit, offset int) ([]*models.Co
 		var content models.Content
 		err := rows.Scan(
 			&content.ID,
+			&content.WorkspaceID,
 			&content.TopicID,
 			&content.Subject,
 			&content.Body,
 			&content.SendAt,
 			&content.Status,
+			&content.SentCount,
+			&content.FailedCount,
+			&content.SkippedCount,
+			&content.BouncedCount,
+			&content.RecurringScheduleID,
+			&content.DeliveryMode,
+			&content.TrackingEnabled,
 			&content.CreatedAt,
 			&content.UpdatedAt,
 		)
@@ -144,16 +179,16 @@ func (r *contentRepo) List(ctx context.Context, limit, offset int) ([]*models.Co
 	return contents, nil
 }
 
-func (r *contentRepo) ListByTopic(ctx context.Context, topicID uuid.UUID, limit, offset int) ([]*models.Content, error) {
+func (r *contentRepo) ListByTopic(ctx context.Context, workspaceID, topicID uuid.UUID, limit, offset int) ([]*models.Content, error) {
 	query := `
-		SELECT id, topic_id, subject, body, send_at, status, created_at, updated_at
+		SELECT id, workspace_id, topic_id, subject, body, send_at, status, sent_count, failed_count, skipped_count, bounced_count, recurring_schedule_id, delivery_mode, tracking_enabled, created_at, updated_at
 		FROM content
-		WHERE topic_id = $1
+		WHERE workspace_id = $1 AND topic_id = $2
 		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
+		LIMIT $3 OFFSET $4
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, topicID, limit, offset)
+	rows, err := r.db.Pool.Query(ctx, query, workspaceID, topicID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list content by topic: %w", err)
 	}
@@ -164,11 +199,19 @@ func (r *contentRepo) ListByTopic(ctx context.Context, topicID uuid.UUID, limit,
 		var content models.Content
 		err := rows.Scan(
 			&content.ID,
+			&content.WorkspaceID,
 			&content.TopicID,
 			&content.Subject,
 			&content.Body,
 			&content.SendAt,
 			&content.Status,
+			&content.SentCount,
+			&content.FailedCount,
+			&content.SkippedCount,
+			&content.BouncedCount,
+			&content.RecurringScheduleID,
+			&content.DeliveryMode,
+			&content.TrackingEnabled,
 			&content.CreatedAt,
 			&content.UpdatedAt,
 		)
@@ -187,7 +230,7 @@ func (r *contentRepo) ListByTopic(ctx context.Context, topicID uuid.UUID, limit,
 
 func (r *contentRepo) ListScheduled(ctx context.Context, limit int) ([]*models.Content, error) {
 	query := `
-		SELECT id, topic_id, subject, body, send_at, status, created_at, updated_at
+		SELECT id, workspace_id, topic_id, subject, body, send_at, status, sent_count, failed_count, skipped_count, bounced_count, recurring_schedule_id, delivery_mode, tracking_enabled, created_at, updated_at
 		FROM content
 		WHERE status = $1 AND send_at <= NOW()
 		ORDER BY send_at ASC
@@ -205,11 +248,19 @@ func (r *contentRepo) ListScheduled(ctx context.Context, limit int) ([]*models.C
 		var content models.Content
 		err := rows.Scan(
 			&content.ID,
+			&content.WorkspaceID,
 			&content.TopicID,
 			&content.Subject,
 			&content.Body,
 			&content.SendAt,
 			&content.Status,
+			&content.SentCount,
+			&content.FailedCount,
+			&content.SkippedCount,
+			&content.BouncedCount,
+			&content.RecurringScheduleID,
+			&content.DeliveryMode,
+			&content.TrackingEnabled,
 			&content.CreatedAt,
 			&content.UpdatedAt,
 		)
@@ -226,29 +277,37 @@ func (r *contentRepo) ListScheduled(ctx context.Context, limit int) ([]*models.C
 	return contents, nil
 }
 
-func (r *contentRepo) Update(ctx context.Context, id uuid.UUID, req *request.UpdateContentRequest) (*models.Content, error) {
+func (r *contentRepo) Update(ctx context.Context, workspaceID, id uuid.UUID, req *request.UpdateContentRequest) (*models.Content, error) {
 	query := `
 		UPDATE content
-		SET subject = $2, body = $3, send_at = $4, updated_at = NOW()
-		WHERE id = $1 AND status = $5
-		RETURNING id, topic_id, subject, body, send_at, status, created_at, updated_at
+		SET subject = $3, body = $4, send_at = $5, tracking_enabled = $7, updated_at = NOW()
+		WHERE workspace_id = $1 AND id = $2 AND status = $6
+		RETURNING id, workspace_id, topic_id, subject, body, send_at, status, sent_count, failed_count, skipped_count, bounced_count, recurring_schedule_id, delivery_mode, tracking_enabled, created_at, updated_at
 	`
 
 	var content models.Content
-	err := r.db.Pool.QueryRow(ctx, query, id, req.Subject, req.Body, req.SendAt, constants.ContentStatusScheduled).Scan(
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, id, req.Subject, req.Body, req.SendAt, constants.ContentStatusScheduled, req.TrackingEnabled).Scan(
 		&content.ID,
+		&content.WorkspaceID,
 		&content.TopicID,
 		&content.Subject,
 		&content.Body,
 		&content.SendAt,
 		&content.Status,
+		&content.SentCount,
+		&content.FailedCount,
+		&content.SkippedCount,
+		&content.BouncedCount,
+		&content.RecurringScheduleID,
+		&content.DeliveryMode,
+		&content.TrackingEnabled,
 		&content.CreatedAt,
 		&content.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("content not found or cannot be updated (already sent)")
+			return nil, notScheduledError(r.db.Pool.QueryRow(ctx, contentStatusQuery, workspaceID, id), "updated")
 		}
 		return nil, fmt.Errorf("failed to update content: %w", err)
 	}
@@ -256,39 +315,216 @@ func (r *contentRepo) Update(ctx context.Context, id uuid.UUID, req *request.Upd
 	return &content, nil
 }
 
-func (r *contentRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
+func (r *contentRepo) UpdateTx(ctx context.Context, tx pgx.Tx, workspaceID, id uuid.UUID, req *request.UpdateContentRequest) (*models.Content, error) {
+	query := `
+		UPDATE content
+		SET subject = $3, body = $4, send_at = $5, tracking_enabled = $7, updated_at = NOW()
+		WHERE workspace_id = $1 AND id = $2 AND status = $6
+		RETURNING id, workspace_id, topic_id, subject, body, send_at, status, sent_count, failed_count, skipped_count, bounced_count, recurring_schedule_id, delivery_mode, tracking_enabled, created_at, updated_at
+	`
+
+	var content models.Content
+	err := tx.QueryRow(ctx, query, workspaceID, id, req.Subject, req.Body, req.SendAt, constants.ContentStatusScheduled, req.TrackingEnabled).Scan(
+		&content.ID,
+		&content.WorkspaceID,
+		&content.TopicID,
+		&content.Subject,
+		&content.Body,
+		&content.SendAt,
+		&content.Status,
+		&content.SentCount,
+		&content.FailedCount,
+		&content.SkippedCount,
+		&content.BouncedCount,
+		&content.RecurringScheduleID,
+		&content.DeliveryMode,
+		&content.TrackingEnabled,
+		&content.CreatedAt,
+		&content.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, notScheduledError(tx.QueryRow(ctx, contentStatusQuery, workspaceID, id), "updated")
+		}
+		return nil, fmt.Errorf("failed to update content in transaction: %w", err)
+	}
+
+	return &content, nil
+}
+
+// CancelTx marks scheduled content as cancelled
+func (r *contentRepo) CancelTx(ctx context.Context, tx pgx.Tx, workspaceID, id uuid.UUID) error {
+	query := `
+		UPDATE content
+		SET status = $3, updated_at = NOW()
+		WHERE workspace_id = $1 AND id = $2 AND status = $4
+	`
+
+	result, err := tx.Exec(ctx, query, workspaceID, id, constants.ContentStatusCancelled, constants.ContentStatusScheduled)
+	if err != nil {
+		return fmt.Errorf("failed to cancel content: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperr.PreconditionFailed("content is not in scheduled status")
+	}
+
+	return nil
+}
+
+// ReopenTx returns content that finished sending to scheduled so that a retried
+// send job processes it again
+func (r *contentRepo) ReopenTx(ctx context.Context, tx pgx.Tx, workspaceID, id uuid.UUID) error {
+	query := `
+		UPDATE content
+		SET status = $3, updated_at = NOW()
+		WHERE workspace_id = $1 AND id = $2 AND status IN ($4, $5, $6)
+	`
+
+	result, err := tx.Exec(ctx, query, workspaceID, id, constants.ContentStatusScheduled,
+		constants.ContentStatusSent, constants.ContentStatusPartiallySent, constants.ContentStatusFailed)
+	if err != nil {
+		return fmt.Errorf("failed to reopen content: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperr.PreconditionFailed("content cannot be sent again")
+	}
+
+	return nil
+}
+
+func (r *contentRepo) UpdateStatus(ctx context.Context, workspaceID, id uuid.UUID, status string) error {
 	query := `
 		UPDATE content
-		SET status = $2, updated_at = NOW()
-		WHERE id = $1
+		SET status = $3, updated_at = NOW()
+		WHERE workspace_id = $1 AND id = $2
 	`
 
-	result, err := r.db.Pool.Exec(ctx, query, id, status)
+	result, err := r.db.Pool.Exec(ctx, query, workspaceID, id, status)
 	if err != nil {
 		return fmt.Errorf("failed to update content status: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("content not found")
+		return apperr.NotFound("content not found")
 	}
 
 	return nil
 }
 
-func (r *contentRepo) Delete(ctx context.Context, id uuid.UUID) error {
+// UpdateStatusWithCounts sets the final status of content along with its delivery totals
+func (r *contentRepo) UpdateStatusWithCounts(ctx context.Context, workspaceID, id uuid.UUID, status string, counts models.DeliveryCounts) error {
+	query := `
+		UPDATE content
+		SET status = $3, sent_count = $4, failed_count = $5, skipped_count = $6, bounced_count = $7, updated_at = NOW()
+		WHERE workspace_id = $1 AND id = $2
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, workspaceID, id, status, counts.Sent, counts.Failed, counts.Skipped, counts.Bounced)
+	if err != nil {
+		return fmt.Errorf("failed to update content status: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperr.NotFound("content not found")
+	}
+
+	return nil
+}
+
+func (r *contentRepo) Delete(ctx context.Context, workspaceID, id uuid.UUID) error {
 	query := `
 		DELETE FROM content 
-		WHERE id = $1 AND status = $2
+		WHERE workspace_id = $1 AND id = $2 AND status = $3
 	`
 
-	result, err := r.db.Pool.Exec(ctx, query, id, constants.ContentStatusScheduled)
+	result, err := r.db.Pool.Exec(ctx, query, workspaceID, id, constants.ContentStatusScheduled)
 	if err != nil {
 		return fmt.Errorf("failed to delete content: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("content not found or cannot be deleted (already sent)")
+		return notScheduledError(r.db.Pool.QueryRow(ctx, contentStatusQuery, workspaceID, id), "deleted")
 	}
 
 	return nil
 }
+
+// contentStatusQuery reads the status of content of a workspace
+const contentStatusQuery = `SELECT status FROM content WHERE workspace_id = $1 AND id = $2`
+
+// notScheduledError explains why a change to scheduled content matched no row:
+// the content does not exist, or it has left the scheduled status. row is the
+// result of contentStatusQuery.
+func notScheduledError(row pgx.Row, action string) error {
+	var status string
+	if err := row.Scan(&status); err != nil {
+		if err == pgx.ErrNoRows {
+			return apperr.NotFound("content not found")
+		}
+		return fmt.Errorf("failed to get content status: %w", err)
+	}
+
+	return apperr.PreconditionFailed(fmt.Sprintf("content is %s and can no longer be %s", status, action))
+}
+
+// CreateOccurrenceTx creates the content for one occurrence of a recurring schedule
+func (r *contentRepo) CreateOccurrenceTx(ctx context.Context, tx pgx.Tx, schedule *models.RecurringSchedule, subject, body string, sendAt time.Time) (*models.Content, error) {
+	query := `
+		INSERT INTO content (workspace_id, topic_id, subject, body, send_at, recurring_schedule_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, workspace_id, topic_id, subject, body, send_at, status, sent_count, failed_count, skipped_count, bounced_count, recurring_schedule_id, delivery_mode, tracking_enabled, created_at, updated_at
+	`
+
+	var content models.Content
+	err := tx.QueryRow(ctx, query, schedule.WorkspaceID, schedule.TopicID, subject, body, sendAt, schedule.ID).Scan(
+		&content.ID,
+		&content.WorkspaceID,
+		&content.TopicID,
+		&content.Subject,
+		&content.Body,
+		&content.SendAt,
+		&content.Status,
+		&content.SentCount,
+		&content.FailedCount,
+		&content.SkippedCount,
+		&content.BouncedCount,
+		&content.RecurringScheduleID,
+		&content.DeliveryMode,
+		&content.TrackingEnabled,
+		&content.CreatedAt,
+		&content.UpdatedAt,
+	)
+
+	if err != nil {
+		if isUniqueViolation(err, "idx_content_recurring_occurrence") {
+			return nil, apperr.Conflict("schedule occurrence already exists")
+		}
+		return nil, fmt.Errorf("failed to create schedule occurrence: %w", err)
+	}
+
+	return &content, nil
+}
+
+// DeletePendingOccurrences removes generated content of a schedule whose send job has not started yet
+func (r *contentRepo) DeletePendingOccurrences(ctx context.Context, workspaceID, scheduleID uuid.UUID) (int64, error) {
+	query := `
+		DELETE FROM content c
+		WHERE c.workspace_id = $4
+		  AND c.recurring_schedule_id = $1
+		  AND c.status = $2
+		  AND NOT EXISTS (
+			SELECT 1 FROM job_scheduler j
+			WHERE j.content_id = c.id AND j.status <> $3
+		  )
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, scheduleID, constants.ContentStatusScheduled, constants.JobStatusPending, workspaceID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete pending occurrences: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,51 @@
+package repo
+
+import (
+	"errors"
+	"testing"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// statusRow is the result of contentStatusQuery
+type statusRow struct {
+	status string
+	err    error
+}
+
+func (r statusRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*string) = r.status
+	return nil
+}
+
+func TestNotScheduledError(t *testing.T) {
+	tests := []struct {
+		name string
+		row  statusRow
+		want error
+	}{
+		{"missing", statusRow{err: pgx.ErrNoRows}, apperr.ErrNotFound},
+		{"sent", statusRow{status: constants.ContentStatusSent}, apperr.ErrPreconditionFailed},
+		{"cancelled", statusRow{status: constants.ContentStatusCancelled}, apperr.ErrPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := notScheduledError(tt.row, "updated"); !errors.Is(err, tt.want) {
+				t.Errorf("notScheduledError() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	dbErr := errors.New("connection reset")
+	err := notScheduledError(statusRow{err: dbErr}, "deleted")
+	if !errors.Is(err, dbErr) || errors.Is(err, apperr.ErrNotFound) {
+		t.Errorf("notScheduledError() = %v, want the database error", err)
+	}
+}
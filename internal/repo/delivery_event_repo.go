@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/db"
+	"newsletter-assignment/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type deliveryEventRepo struct {
+	db *db.DB
+}
+
+func NewDeliveryEventRepository(database *db.DB) DeliveryEventRepository {
+	return &deliveryEventRepo{
+		db: database,
+	}
+}
+
+// Create records an event for a delivery of the workspace
+func (r *deliveryEventRepo) Create(ctx context.Context, workspaceID, deliveryID uuid.UUID, eventType string, url, userAgent *string) (*models.DeliveryEvent, error) {
+	query := `
+		INSERT INTO delivery_events (workspace_id, delivery_id, event_type, url, user_agent)
+		SELECT workspace_id, id, $3, $4, $5
+		FROM deliveries
+		WHERE workspace_id = $1 AND id = $2
+		RETURNING id, workspace_id, delivery_id, event_type, url, user_agent, occurred_at
+	`
+
+	var event models.DeliveryEvent
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, deliveryID, eventType, url, userAgent).Scan(
+		&event.ID,
+		&event.WorkspaceID,
+		&event.DeliveryID,
+		&event.EventType,
+		&event.URL,
+		&event.UserAgent,
+		&event.OccurredAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, apperr.NotFound("delivery not found")
+		}
+		return nil, fmt.Errorf("failed to create delivery event: %w", err)
+	}
+
+	return &event, nil
+}
+
+// CountByContent counts the opens and clicks of a content's deliveries, in total and
+// by distinct delivery. Rates are left to the caller.
+func (r *deliveryEventRepo) CountByContent(ctx context.Context, workspaceID, contentID uuid.UUID) (*models.EngagementStats, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE e.event_type = $3),
+			COUNT(DISTINCT e.delivery_id) FILTER (WHERE e.event_type = $3),
+			COUNT(*) FILTER (WHERE e.event_type = $4),
+			COUNT(DISTINCT e.delivery_id) FILTER (WHERE e.event_type = $4)
+		FROM delivery_events e
+		JOIN deliveries d ON d.id = e.delivery_id
+		WHERE e.workspace_id = $1 AND d.content_id = $2
+	`
+
+	var stats models.EngagementStats
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, contentID, constants.DeliveryEventOpen, constants.DeliveryEventClick).Scan(
+		&stats.Opens,
+		&stats.UniqueOpens,
+		&stats.Clicks,
+		&stats.UniqueClicks,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to count delivery events: %w", err)
+	}
+
+	return &stats, nil
+}
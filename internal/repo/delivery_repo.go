@@ -2,13 +2,17 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
 	"newsletter-assignment/internal/db"
 	"newsletter-assignment/internal/models"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type deliveryRepo struct {
@@ -23,22 +27,27 @@ func NewDeliveryRepository(database *db.DB) DeliveryRepository {
 }
 
 // CreateDelivery creates a new delivery record
-func (r *deliveryRepo) CreateDelivery(ctx context.Context, contentID, subscriberID uuid.UUID, email, status string) (*models.Delivery, error) {
+func (r *deliveryRepo) CreateDelivery(ctx context.Context, workspaceID, contentID, subscriberID uuid.UUID, email, status string) (*models.Delivery, error) {
 	query := `
-		INSERT INTO deliveries (content_id, subscriber_id, email, status)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, content_id, subscriber_id, email, status, sent_at, error_message, created_at, updated_at
+		INSERT INTO deliveries (workspace_id, content_id, subscriber_id, email, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, workspace_id, content_id, subscriber_id, email, status, sent_at, error_message, attempts, next_retry_at, provider_message_id, delivered_at, created_at, updated_at
 	`
 
 	var delivery models.Delivery
-	err := r.db.Pool.QueryRow(ctx, query, contentID, subscriberID, email, status).Scan(
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, contentID, subscriberID, email, status).Scan(
 		&delivery.ID,
+		&delivery.WorkspaceID,
 		&delivery.ContentID,
 		&delivery.SubscriberID,
 		&delivery.Email,
 		&delivery.Status,
 		&delivery.SentAt,
 		&delivery.ErrorMessage,
+		&delivery.Attempts,
+		&delivery.NextRetryAt,
+		&delivery.ProviderMessageID,
+		&delivery.DeliveredAt,
 		&delivery.CreatedAt,
 		&delivery.UpdatedAt,
 	)
@@ -50,15 +59,149 @@ func (r *deliveryRepo) CreateDelivery(ctx context.Context, contentID, subscriber
 	return &delivery, nil
 }
 
+// ClaimDelivery creates a pending delivery or reclaims an existing one for another
+// attempt. A pending delivery is only taken over once the claim of the run that left
+// it has expired, so that overlapping runs never mail the same subscriber. When the
+// delivery is not claimed it returns false with the delivery as it stands: pending
+// while another run holds it, otherwise final or owned by a scheduled retry.
+func (r *deliveryRepo) ClaimDelivery(ctx context.Context, workspaceID, contentID, subscriberID uuid.UUID, email string) (*models.Delivery, bool, error) {
+	query := `
+		INSERT INTO deliveries (workspace_id, content_id, subscriber_id, email, status, attempts, claimed_at)
+		VALUES ($6, $1, $2, $3, $4, 1, NOW())
+		ON CONFLICT (content_id, subscriber_id) DO UPDATE
+		SET email = EXCLUDED.email, status = $4, error_message = NULL,
+			attempts = deliveries.attempts + 1, claimed_at = NOW(), updated_at = NOW()
+		WHERE deliveries.workspace_id = $6 AND (
+			(deliveries.status = $4 AND (deliveries.claimed_at IS NULL
+				OR deliveries.claimed_at < NOW() - make_interval(secs => $7)))
+			OR (deliveries.status = $5 AND deliveries.next_retry_at IS NULL))
+		RETURNING id, workspace_id, content_id, subscriber_id, email, status, sent_at, error_message, attempts, next_retry_at, provider_message_id, delivered_at, created_at, updated_at
+	`
+
+	var delivery models.Delivery
+	err := r.db.Pool.QueryRow(ctx, query, contentID, subscriberID, email, constants.DeliveryStatusPending, constants.DeliveryStatusFailed, workspaceID, constants.DeliveryClaimLease.Seconds()).Scan(
+		&delivery.ID,
+		&delivery.WorkspaceID,
+		&delivery.ContentID,
+		&delivery.SubscriberID,
+		&delivery.Email,
+		&delivery.Status,
+		&delivery.SentAt,
+		&delivery.ErrorMessage,
+		&delivery.Attempts,
+		&delivery.NextRetryAt,
+		&delivery.ProviderMessageID,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return r.unclaimedDelivery(ctx, workspaceID, contentID, subscriberID)
+		}
+		return nil, false, fmt.Errorf("failed to claim delivery: %w", err)
+	}
+
+	return &delivery, true, nil
+}
+
+// unclaimedDelivery returns the delivery ClaimDelivery could not claim. It is nil when
+// the conflicting row is not in the workspace.
+func (r *deliveryRepo) unclaimedDelivery(ctx context.Context, workspaceID, contentID, subscriberID uuid.UUID) (*models.Delivery, bool, error) {
+	delivery, err := r.GetDeliveryByContentAndSubscriber(ctx, workspaceID, contentID, subscriberID)
+	if err != nil {
+		if errors.Is(err, apperr.ErrNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return delivery, false, nil
+}
+
+// ClaimRetry moves a failed delivery whose retry is due back to pending for another attempt.
+// It returns false when the delivery is no longer waiting for a retry.
+func (r *deliveryRepo) ClaimRetry(ctx context.Context, workspaceID, id uuid.UUID) (*models.Delivery, bool, error) {
+	query := `
+		UPDATE deliveries
+		SET status = $2, attempts = attempts + 1, next_retry_at = NULL, claimed_at = NOW(), updated_at = NOW()
+		WHERE workspace_id = $4 AND id = $1 AND status = $3 AND next_retry_at IS NOT NULL
+		RETURNING id, workspace_id, content_id, subscriber_id, email, status, sent_at, error_message, attempts, next_retry_at, provider_message_id, delivered_at, created_at, updated_at
+	`
+
+	var delivery models.Delivery
+	err := r.db.Pool.QueryRow(ctx, query, id, constants.DeliveryStatusPending, constants.DeliveryStatusFailed, workspaceID).Scan(
+		&delivery.ID,
+		&delivery.WorkspaceID,
+		&delivery.ContentID,
+		&delivery.SubscriberID,
+		&delivery.Email,
+		&delivery.Status,
+		&delivery.SentAt,
+		&delivery.ErrorMessage,
+		&delivery.Attempts,
+		&delivery.NextRetryAt,
+		&delivery.ProviderMessageID,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to claim delivery retry: %w", err)
+	}
+
+	return &delivery, true, nil
+}
+
+// RearmRetryTx makes a failed or undeliverable delivery due for another retry now
+func (r *deliveryRepo) RearmRetryTx(ctx context.Context, tx pgx.Tx, workspaceID, id uuid.UUID) error {
+	query := `
+		UPDATE deliveries
+		SET status = $3, next_retry_at = NOW(), updated_at = NOW()
+		WHERE workspace_id = $1 AND id = $2 AND status IN ($3, $4)
+	`
+
+	result, err := tx.Exec(ctx, query, workspaceID, id, constants.DeliveryStatusFailed, constants.DeliveryStatusUndeliverable)
+	if err != nil {
+		return fmt.Errorf("failed to rearm delivery retry: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperr.PreconditionFailed("delivery is no longer failed")
+	}
+
+	return nil
+}
+
+// UpdateDeliveryFailure records a failed attempt; nextRetryAt is set when a retry is scheduled
+func (r *deliveryRepo) UpdateDeliveryFailure(ctx context.Context, workspaceID, id uuid.UUID, status string, errorMessage *string, nextRetryAt *time.Time) error {
+	query := `
+		UPDATE deliveries
+		SET status = $3, error_message = $4, next_retry_at = $5, updated_at = NOW()
+		WHERE workspace_id = $1 AND id = $2
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, workspaceID, id, status, errorMessage, nextRetryAt)
+	if err != nil {
+		return fmt.Errorf("failed to update delivery failure: %w", err)
+	}
+
+	return nil
+}
+
 // UpdateDeliveryStatus updates the delivery status
-func (r *deliveryRepo) UpdateDeliveryStatus(ctx context.Context, id uuid.UUID, status string, sentAt *time.Time, errorMessage *string) error {
+func (r *deliveryRepo) UpdateDeliveryStatus(ctx context.Context, workspaceID, id uuid.UUID, status string, sentAt *time.Time, errorMessage *string) error {
 	query := `
 		UPDATE deliveries 
-		SET status = $2, sent_at = $3, error_message = $4, updated_at = NOW()
-		WHERE id = $1
+		SET status = $3, sent_at = $4, error_message = $5, updated_at = NOW()
+		WHERE workspace_id = $1 AND id = $2
 	`
 
-	_, err := r.db.Pool.Exec(ctx, query, id, status, sentAt, errorMessage)
+	_, err := r.db.Pool.Exec(ctx, query, workspaceID, id, status, sentAt, errorMessage)
 	if err != nil {
 		return fmt.Errorf("failed to update delivery status: %w", err)
 	}
@@ -66,44 +209,193 @@ func (r *deliveryRepo) UpdateDeliveryStatus(ctx context.Context, id uuid.UUID, s
 	return nil
 }
 
+// MarkSent records a successful send together with the message ID the provider assigned
+func (r *deliveryRepo) MarkSent(ctx context.Context, workspaceID, id uuid.UUID, sentAt time.Time, providerMessageID *string) error {
+	query := `
+		UPDATE deliveries
+		SET status = $3, sent_at = $4, error_message = NULL, provider_message_id = $5, updated_at = NOW()
+		WHERE workspace_id = $1 AND id = $2
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, workspaceID, id, constants.DeliveryStatusSent, sentAt, providerMessageID)
+	if err != nil {
+		return fmt.Errorf("failed to mark delivery sent: %w", err)
+	}
+
+	return nil
+}
+
+// MarkDelivered records when the provider handed the message to the recipient's mail server.
+// Repeated events keep the first timestamp.
+func (r *deliveryRepo) MarkDelivered(ctx context.Context, workspaceID, id uuid.UUID, deliveredAt time.Time) error {
+	query := `
+		UPDATE deliveries
+		SET delivered_at = $3, updated_at = NOW()
+		WHERE workspace_id = $1 AND id = $2 AND delivered_at IS NULL
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, workspaceID, id, deliveredAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark delivery delivered: %w", err)
+	}
+
+	return nil
+}
+
+// GetByProviderMessageID gets a delivery by the message ID its provider assigned. Provider
+// events carry no workspace, so the lookup spans all workspaces.
+func (r *deliveryRepo) GetByProviderMessageID(ctx context.Context, providerMessageID string) (*models.Delivery, error) {
+	query := `
+		SELECT id, workspace_id, content_id, subscriber_id, email, status, sent_at, error_message, attempts, next_retry_at, provider_message_id, delivered_at, created_at, updated_at
+		FROM deliveries
+		WHERE provider_message_id = $1
+	`
+
+	var delivery models.Delivery
+	err := r.db.Pool.QueryRow(ctx, query, providerMessageID).Scan(
+		&delivery.ID,
+		&delivery.WorkspaceID,
+		&delivery.ContentID,
+		&delivery.SubscriberID,
+		&delivery.Email,
+		&delivery.Status,
+		&delivery.SentAt,
+		&delivery.ErrorMessage,
+		&delivery.Attempts,
+		&delivery.NextRetryAt,
+		&delivery.ProviderMessageID,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, apperr.NotFound("delivery not found")
+		}
+		return nil, fmt.Errorf("failed to get delivery by provider message ID: %w", err)
+	}
+
+	return &delivery, nil
+}
+
+// GetByID gets a delivery by ID
+func (r *deliveryRepo) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Delivery, error) {
+	query := `
+		SELECT id, workspace_id, content_id, subscriber_id, email, status, sent_at, error_message, attempts, next_retry_at, provider_message_id, delivered_at, created_at, updated_at
+		FROM deliveries
+		WHERE workspace_id = $1 AND id = $2
+	`
+
+	var delivery models.Delivery
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, id).Scan(
+		&delivery.ID,
+		&delivery.WorkspaceID,
+		&delivery.ContentID,
+		&delivery.SubscriberID,
+		&delivery.Email,
+		&delivery.Status,
+		&delivery.SentAt,
+		&delivery.ErrorMessage,
+		&delivery.Attempts,
+		&delivery.NextRetryAt,
+		&delivery.ProviderMessageID,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, apperr.NotFound("delivery not found")
+		}
+		return nil, fmt.Errorf("failed to get delivery: %w", err)
+	}
+
+	return &delivery, nil
+}
+
 // GetDeliveryByContentAndSubscriber gets delivery by content and subscriber
-func (r *deliveryRepo) GetDeliveryByContentAndSubscriber(ctx context.Context, contentID, subscriberID uuid.UUID) (*models.Delivery, error) {
+func (r *deliveryRepo) GetDeliveryByContentAndSubscriber(ctx context.Context, workspaceID, contentID, subscriberID uuid.UUID) (*models.Delivery, error) {
 	query := `
-		SELECT id, content_id, subscriber_id, email, status, sent_at, error_message, created_at, updated_at
+		SELECT id, workspace_id, content_id, subscriber_id, email, status, sent_at, error_message, attempts, next_retry_at, provider_message_id, delivered_at, created_at, updated_at
 		FROM deliveries
-		WHERE content_id = $1 AND subscriber_id = $2
+		WHERE workspace_id = $1 AND content_id = $2 AND subscriber_id = $3
 	`
 
 	var delivery models.Delivery
-	err := r.db.Pool.QueryRow(ctx, query, contentID, subscriberID).Scan(
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, contentID, subscriberID).Scan(
 		&delivery.ID,
+		&delivery.WorkspaceID,
 		&delivery.ContentID,
 		&delivery.SubscriberID,
 		&delivery.Email,
 		&delivery.Status,
 		&delivery.SentAt,
 		&delivery.ErrorMessage,
+		&delivery.Attempts,
+		&delivery.NextRetryAt,
+		&delivery.ProviderMessageID,
+		&delivery.DeliveredAt,
 		&delivery.CreatedAt,
 		&delivery.UpdatedAt,
 	)
 
 	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, apperr.NotFound("delivery not found")
+		}
 		return nil, fmt.Errorf("failed to get delivery: %w", err)
 	}
 
 	return &delivery, nil
 }
 
-// ListDeliveriesByContent lists all deliveries for a content
-func (r *deliveryRepo) ListDeliveriesByContent(ctx context.Context, contentID uuid.UUID) ([]*models.Delivery, error) {
+// CountByContent returns the delivery totals for a content
+func (r *deliveryRepo) CountByContent(ctx context.Context, workspaceID, contentID uuid.UUID) (models.DeliveryCounts, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE status = $2),
+			COUNT(*) FILTER (WHERE status IN ($3, $4)),
+			COUNT(*) FILTER (WHERE status = $6),
+			COUNT(*) FILTER (WHERE status = $7)
+		FROM deliveries
+		WHERE workspace_id = $5 AND content_id = $1
+	`
+
+	var counts models.DeliveryCounts
+	err := r.db.Pool.QueryRow(ctx, query, contentID, constants.DeliveryStatusSent, constants.DeliveryStatusFailed, constants.DeliveryStatusUndeliverable, workspaceID,
+		constants.DeliveryStatusBounced, constants.DeliveryStatusSuppressed).Scan(
+		&counts.Sent,
+		&counts.Failed,
+		&counts.Bounced,
+		&counts.Skipped,
+	)
+
+	if err != nil {
+		return counts, fmt.Errorf("failed to count deliveries: %w", err)
+	}
+
+	return counts, nil
+}
+
+// ListDeliveriesByContent lists the deliveries of a content ordered by ID and starting
+// after the given ID, for keyset pagination. An empty status list matches every status.
+func (r *deliveryRepo) ListDeliveriesByContent(ctx context.Context, workspaceID, contentID uuid.UUID, statuses []string, afterID uuid.UUID, limit int) ([]*models.Delivery, error) {
 	query := `
-		SELECT id, content_id, subscriber_id, email, status, sent_at, error_message, created_at, updated_at
+		SELECT id, workspace_id, content_id, subscriber_id, email, status, sent_at, error_message, attempts, next_retry_at, provider_message_id, delivered_at, created_at, updated_at
 		FROM deliveries
-		WHERE content_id = $1
-		ORDER BY created_at DESC
+		WHERE workspace_id = $1 AND content_id = $2 AND id > $3
+		  AND (cardinality($4::text[]) = 0 OR status = ANY($4))
+		ORDER BY id
+		LIMIT $5
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, contentID)
+	if statuses == nil {
+		statuses = []string{}
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, workspaceID, contentID, afterID, statuses, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list deliveries: %w", err)
 	}
@@ -114,12 +406,17 @@ func (r *deliveryRepo) ListDeliveriesByContent(ctx context.Context, contentID uu
 		var delivery models.Delivery
 		err := rows.Scan(
 			&delivery.ID,
+			&delivery.WorkspaceID,
 			&delivery.ContentID,
 			&delivery.SubscriberID,
 			&delivery.Email,
 			&delivery.Status,
 			&delivery.SentAt,
 			&delivery.ErrorMessage,
+			&delivery.Attempts,
+			&delivery.NextRetryAt,
+			&delivery.ProviderMessageID,
+			&delivery.DeliveredAt,
 			&delivery.CreatedAt,
 			&delivery.UpdatedAt,
 		)
@@ -129,5 +426,86 @@ func (r *deliveryRepo) ListDeliveriesByContent(ctx context.Context, contentID uu
 		deliveries = append(deliveries, &delivery)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deliveries: %w", err)
+	}
+
 	return deliveries, nil
 }
+
+// ReportByContent aggregates the deliveries of a content by status and send time and
+// groups the most common error messages of deliveries that were not sent
+func (r *deliveryRepo) ReportByContent(ctx context.Context, workspaceID, contentID uuid.UUID, maxFailureReasons int) (*models.DeliveryReport, error) {
+	report := &models.DeliveryReport{
+		ContentID:      contentID,
+		ByStatus:       make(map[string]int),
+		FailureReasons: []models.FailureReason{},
+	}
+	for _, status := range constants.DeliveryStatuses {
+		report.ByStatus[status] = 0
+	}
+
+	statusQuery := `
+		SELECT status, COUNT(*), MIN(sent_at), MAX(sent_at)
+		FROM deliveries
+		WHERE workspace_id = $1 AND content_id = $2
+		GROUP BY status
+	`
+
+	rows, err := r.db.Pool.Query(ctx, statusQuery, workspaceID, contentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		var count int
+		var firstSentAt, lastSentAt *time.Time
+		if err := rows.Scan(&status, &count, &firstSentAt, &lastSentAt); err != nil {
+			return nil, fmt.Errorf("failed to scan delivery aggregate: %w", err)
+		}
+
+		report.ByStatus[status] = count
+		report.Total += count
+		if firstSentAt != nil && (report.FirstSentAt == nil || firstSentAt.Before(*report.FirstSentAt)) {
+			report.FirstSentAt = firstSentAt
+		}
+		if lastSentAt != nil && (report.LastSentAt == nil || lastSentAt.After(*report.LastSentAt)) {
+			report.LastSentAt = lastSentAt
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating delivery aggregates: %w", err)
+	}
+
+	reasonQuery := `
+		SELECT error_message, COUNT(*)
+		FROM deliveries
+		WHERE workspace_id = $1 AND content_id = $2 AND status NOT IN ($3, $4) AND error_message IS NOT NULL
+		GROUP BY error_message
+		ORDER BY COUNT(*) DESC, error_message
+		LIMIT $5
+	`
+
+	reasonRows, err := r.db.Pool.Query(ctx, reasonQuery, workspaceID, contentID, constants.DeliveryStatusSent, constants.DeliveryStatusPending, maxFailureReasons)
+	if err != nil {
+		return nil, fmt.Errorf("failed to group failure reasons: %w", err)
+	}
+	defer reasonRows.Close()
+
+	for reasonRows.Next() {
+		var reason models.FailureReason
+		if err := reasonRows.Scan(&reason.ErrorMessage, &reason.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan failure reason: %w", err)
+		}
+		report.FailureReasons = append(report.FailureReasons, reason)
+	}
+
+	if err := reasonRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating failure reasons: %w", err)
+	}
+
+	return report, nil
+}
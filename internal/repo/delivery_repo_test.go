@@ -0,0 +1,107 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"newsletter-assignment/internal/constants"
+
+	"github.com/google/uuid"
+)
+
+func TestDeliveryRepoUpdatesAreScopedToWorkspace(t *testing.T) {
+	database, fake := newFakeDB(t, func(sql string) fakeResult {
+		return affected("UPDATE 1")
+	})
+	r := NewDeliveryRepository(database)
+
+	workspaceID, id := uuid.New(), uuid.New()
+	now := time.Now()
+	errorMsg := "mailbox full"
+	messageID := "<message@example.com>"
+
+	updates := map[string]func(ctx context.Context) error{
+		"UpdateDeliveryStatus": func(ctx context.Context) error {
+			return r.UpdateDeliveryStatus(ctx, workspaceID, id, constants.DeliveryStatusSuppressed, nil, &errorMsg)
+		},
+		"UpdateDeliveryFailure": func(ctx context.Context) error {
+			return r.UpdateDeliveryFailure(ctx, workspaceID, id, constants.DeliveryStatusFailed, &errorMsg, &now)
+		},
+		"MarkSent": func(ctx context.Context) error {
+			return r.MarkSent(ctx, workspaceID, id, now, &messageID)
+		},
+		"MarkDelivered": func(ctx context.Context) error {
+			return r.MarkDelivered(ctx, workspaceID, id, now)
+		},
+	}
+
+	for name, update := range updates {
+		t.Run(name, func(t *testing.T) {
+			if err := update(context.Background()); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			query := fake.lastQuery()
+			if !strings.Contains(query, "workspace_id = '"+workspaceID.String()+"'") || !strings.Contains(query, "id = '"+id.String()+"'") {
+				t.Errorf("update is not scoped to the workspace:\n%s", query)
+			}
+		})
+	}
+}
+
+var deliveryColumns = []fakeColumn{
+	{"id", oidUUID},
+	{"workspace_id", oidUUID},
+	{"content_id", oidUUID},
+	{"subscriber_id", oidUUID},
+	{"email", oidText},
+	{"status", oidText},
+	{"sent_at", oidTimestamptz},
+	{"error_message", oidText},
+	{"attempts", oidInt4},
+	{"next_retry_at", oidTimestamptz},
+	{"provider_message_id", oidText},
+	{"delivered_at", oidTimestamptz},
+	{"created_at", oidTimestamptz},
+	{"updated_at", oidTimestamptz},
+}
+
+func TestClaimDeliveryNotClaimed(t *testing.T) {
+	workspaceID, contentID, subscriberID := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+	held := []any{uuid.New(), workspaceID, contentID, subscriberID, "jane@example.org", constants.DeliveryStatusPending,
+		nil, nil, 1, nil, nil, nil, now, now}
+
+	tests := []struct {
+		name     string
+		existing [][]any
+		wantNil  bool
+	}{
+		{"held by another run", [][]any{held}, false},
+		{"row of another workspace", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database, _ := newFakeDB(t, func(sql string) fakeResult {
+				if strings.Contains(sql, "INSERT INTO deliveries") {
+					// The conflicting row did not satisfy the claim conditions
+					return fakeResult{columns: deliveryColumns, tag: "INSERT 0 0"}
+				}
+				return fakeResult{columns: deliveryColumns, rows: tt.existing}
+			})
+
+			delivery, claimed, err := NewDeliveryRepository(database).ClaimDelivery(context.Background(), workspaceID, contentID, subscriberID, "jane@example.org")
+			if err != nil {
+				t.Fatalf("ClaimDelivery: %v", err)
+			}
+			if claimed {
+				t.Error("delivery reported as claimed")
+			}
+			if (delivery == nil) != tt.wantNil {
+				t.Errorf("delivery = %+v, want nil: %t", delivery, tt.wantNil)
+			}
+		})
+	}
+}
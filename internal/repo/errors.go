@@ -0,0 +1,20 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniqueViolation is the Postgres SQLSTATE for unique constraint violations
+const uniqueViolation = "23505"
+
+// isUniqueViolation reports whether err is a unique constraint violation,
+// optionally on the named constraint
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != uniqueViolation {
+		return false
+	}
+	return constraint == "" || pgErr.ConstraintName == constraint
+}
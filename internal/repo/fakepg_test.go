@@ -0,0 +1,197 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"newsletter-assignment/internal/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// fakeColumn is a result column with the type Postgres would report for it
+type fakeColumn struct {
+	name string
+	oid  uint32
+}
+
+// fakeResult is what the fake server returns for one query. Values are rendered in
+// the text format; nil is NULL.
+type fakeResult struct {
+	columns []fakeColumn
+	rows    [][]any
+	tag     string
+}
+
+// fakePostgres speaks enough of the Postgres wire protocol to serve repository
+// queries. The pool uses the simple query protocol, so the arguments arrive
+// interpolated in the SQL text that tests can inspect.
+type fakePostgres struct {
+	t       *testing.T
+	respond func(sql string) fakeResult
+
+	mu      sync.Mutex
+	queries []string
+}
+
+// newFakeDB returns a database whose queries are answered by respond
+func newFakeDB(t *testing.T, respond func(sql string) fakeResult) (*db.DB, *fakePostgres) {
+	t.Helper()
+
+	fake := &fakePostgres{t: t, respond: respond}
+	config, err := pgxpool.ParseConfig("postgres://test@fake/test?sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	config.ConnConfig.LookupFunc = func(ctx context.Context, host string) ([]string, error) {
+		return []string{host}, nil
+	}
+	config.ConnConfig.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		client, server := net.Pipe()
+		go fake.serve(server)
+		return client, nil
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return &db.DB{Pool: pool}, fake
+}
+
+// lastQuery returns the most recent query other than transaction control
+func (f *fakePostgres) lastQuery() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := len(f.queries) - 1; i >= 0; i-- {
+		switch strings.ToLower(strings.TrimSpace(f.queries[i])) {
+		case "begin", "commit", "rollback":
+			continue
+		}
+		return f.queries[i]
+	}
+	return ""
+}
+
+func (f *fakePostgres) serve(conn net.Conn) {
+	defer conn.Close()
+	backend := pgproto3.NewBackend(conn, conn)
+
+	if _, err := backend.ReceiveStartupMessage(); err != nil {
+		return
+	}
+	backend.Send(&pgproto3.AuthenticationOk{})
+	backend.Send(&pgproto3.ParameterStatus{Name: "standard_conforming_strings", Value: "on"})
+	backend.Send(&pgproto3.ParameterStatus{Name: "client_encoding", Value: "UTF8"})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	if err := backend.Flush(); err != nil {
+		return
+	}
+
+	for {
+		msg, err := backend.Receive()
+		if err != nil {
+			return
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.Query:
+			f.mu.Lock()
+			f.queries = append(f.queries, msg.String)
+			f.mu.Unlock()
+			f.answer(backend, msg.String)
+		case *pgproto3.Terminate:
+			return
+		default:
+			backend.Send(&pgproto3.ErrorResponse{Severity: "ERROR", Code: "0A000", Message: fmt.Sprintf("unsupported message %T", msg)})
+			backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+		}
+		if err := backend.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakePostgres) answer(backend *pgproto3.Backend, sql string) {
+	var result fakeResult
+	switch strings.ToLower(strings.TrimSpace(sql)) {
+	case "begin":
+		result.tag = "BEGIN"
+	case "commit":
+		result.tag = "COMMIT"
+	case "rollback":
+		result.tag = "ROLLBACK"
+	default:
+		result = f.respond(sql)
+	}
+
+	if result.columns != nil {
+		fields := make([]pgproto3.FieldDescription, len(result.columns))
+		for i, column := range result.columns {
+			fields[i] = pgproto3.FieldDescription{Name: []byte(column.name), DataTypeOID: column.oid, DataTypeSize: -1, TypeModifier: -1}
+		}
+		backend.Send(&pgproto3.RowDescription{Fields: fields})
+
+		for _, row := range result.rows {
+			if len(row) != len(result.columns) {
+				f.t.Errorf("fake row has %d values for %d columns", len(row), len(result.columns))
+			}
+			values := make([][]byte, len(row))
+			for i, value := range row {
+				values[i] = textValue(value)
+			}
+			backend.Send(&pgproto3.DataRow{Values: values})
+		}
+	}
+
+	tag := result.tag
+	if tag == "" {
+		tag = fmt.Sprintf("SELECT %d", len(result.rows))
+	}
+	backend.Send(&pgproto3.CommandComplete{CommandTag: []byte(tag)})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+}
+
+// textValue renders a value in the Postgres text format
+func textValue(value any) []byte {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case bool:
+		if v {
+			return []byte("t")
+		}
+		return []byte("f")
+	case time.Time:
+		return []byte(v.UTC().Format("2006-01-02 15:04:05.999999Z07:00"))
+	case uuid.UUID:
+		return []byte(v.String())
+	default:
+		return []byte(fmt.Sprint(v))
+	}
+}
+
+// Column types used by the fakes
+const (
+	oidBool        = pgtype.BoolOID
+	oidInt4        = pgtype.Int4OID
+	oidText        = pgtype.TextOID
+	oidTimestamptz = pgtype.TimestamptzOID
+	oidUUID        = pgtype.UUIDOID
+)
+
+// affected is the result of a statement that returns no rows
+func affected(tag string) fakeResult {
+	return fakeResult{tag: tag}
+}
@@ -11,66 +11,163 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+// WorkspaceRepository defines the interface for workspace data operations
+type WorkspaceRepository interface {
+	Create(ctx context.Context, req *request.CreateWorkspaceRequest) (*models.Workspace, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Workspace, error)
+	Update(ctx context.Context, id uuid.UUID, req *request.UpdateWorkspaceRequest) (*models.Workspace, error)
+}
+
 // TopicRepository defines the interface for topic data operations
 type TopicRepository interface {
-	Create(ctx context.Context, req *request.CreateTopicRequest) (*models.Topic, error)
-	GetByID(ctx context.Context, id uuid.UUID) (*models.Topic, error)
-	GetByName(ctx context.Context, name string) (*models.Topic, error)
-	List(ctx context.Context, limit, offset int) ([]*models.Topic, error)
-	Update(ctx context.Context, id uuid.UUID, req *request.UpdateTopicRequest) (*models.Topic, error)
-	Delete(ctx context.Context, id uuid.UUID) error
+	Create(ctx context.Context, workspaceID uuid.UUID, req *request.CreateTopicRequest) (*models.Topic, error)
+	GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Topic, error)
+	GetByName(ctx context.Context, workspaceID uuid.UUID, name string) (*models.Topic, error)
+	List(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.Topic, error)
+	Update(ctx context.Context, workspaceID, id uuid.UUID, req *request.UpdateTopicRequest) (*models.Topic, error)
+	Delete(ctx context.Context, workspaceID, id uuid.UUID) error
 }
 
 // SubscriberRepository defines the interface for subscriber data operations
 type SubscriberRepository interface {
-	Create(ctx context.Context, req *request.CreateSubscriberRequest) (*models.Subscriber, error)
-	GetByID(ctx context.Context, id uuid.UUID) (*models.Subscriber, error)
-	GetByEmail(ctx context.Context, email string) (*models.Subscriber, error)
-	List(ctx context.Context, limit, offset int) ([]*models.Subscriber, error)
-	Update(ctx context.Context, id uuid.UUID, req *request.UpdateSubscriberRequest) (*models.Subscriber, error)
-	Delete(ctx context.Context, id uuid.UUID) error
+	Create(ctx context.Context, workspaceID uuid.UUID, req *request.CreateSubscriberRequest) (*models.Subscriber, error)
+	GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Subscriber, error)
+	GetByEmail(ctx context.Context, workspaceID uuid.UUID, email string) (*models.Subscriber, error)
+	List(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.Subscriber, error)
+	ListActiveByTopic(ctx context.Context, workspaceID, topicID uuid.UUID, filter AudienceFilter, afterID uuid.UUID, limit int) ([]*models.Subscriber, error)
+	ListTimezonesByTopic(ctx context.Context, workspaceID, topicID uuid.UUID) ([]string, error)
+	Update(ctx context.Context, workspaceID, id uuid.UUID, req *request.UpdateSubscriberRequest) (*models.Subscriber, error)
+	Delete(ctx context.Context, workspaceID, id uuid.UUID) error
+}
+
+// AudienceFilter narrows a topic audience to one time-zone bucket. A nil Timezone
+// matches every subscriber whose time zone is not listed in ExcludeTimezones.
+type AudienceFilter struct {
+	Timezone         *string
+	ExcludeTimezones []string
 }
 
 // SubscriptionRepository defines the interface for subscription data operations
 type SubscriptionRepository interface {
-	Create(ctx context.Context, req *request.CreateSubscriptionRequest) (*models.Subscription, error)
-	GetByID(ctx context.Context, id uuid.UUID) (*models.Subscription, error)
-	GetBySubscriberAndTopic(ctx context.Context, subscriberID, topicID uuid.UUID) (*models.Subscription, error)
-	ListBySubscriber(ctx context.Context, subscriberID uuid.UUID) ([]*models.Subscription, error)
-	ListByTopic(ctx context.Context, topicID uuid.UUID) ([]*models.Subscription, error)
-	Update(ctx context.Context, id uuid.UUID, isActive bool) (*models.Subscription, error)
-	Delete(ctx context.Context, id uuid.UUID) error
+	Create(ctx context.Context, workspaceID uuid.UUID, req *request.CreateSubscriptionRequest, status string, confirmationExpiresAt *time.Time) (*models.Subscription, error)
+	GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Subscription, error)
+	GetBySubscriberAndTopic(ctx context.Context, workspaceID, subscriberID, topicID uuid.UUID) (*models.Subscription, error)
+	ListBySubscriber(ctx context.Context, workspaceID, subscriberID uuid.UUID) ([]*models.Subscription, error)
+	ListByTopic(ctx context.Context, workspaceID, topicID uuid.UUID) ([]*models.Subscription, error)
+	UpdateStatus(ctx context.Context, workspaceID, id uuid.UUID, status string, confirmationExpiresAt *time.Time) (*models.Subscription, error)
+	Confirm(ctx context.Context, workspaceID, id uuid.UUID) (*models.Subscription, error)
+	// ExpirePending is not scoped to a workspace; the scheduler runs it for all tenants
+	ExpirePending(ctx context.Context, now time.Time) (int, error)
+	Delete(ctx context.Context, workspaceID, id uuid.UUID) error
 }
 
 // ContentRepository defines the interface for content data operations
 type ContentRepository interface {
-	Create(ctx context.Context, req *request.CreateContentRequest) (*models.Content, error)
-	CreateTx(ctx context.Context, tx pgx.Tx, req *request.CreateContentRequest) (*models.Content, error)
-	GetByID(ctx context.Context, id uuid.UUID) (*models.Content, error)
-	List(ctx context.Context, limit, offset int) ([]*models.Content, error)
-	ListByTopic(ctx context.Context, topicID uuid.UUID, limit, offset int) ([]*models.Content, error)
+	Create(ctx context.Context, workspaceID uuid.UUID, req *request.CreateContentRequest) (*models.Content, error)
+	CreateTx(ctx context.Context, tx pgx.Tx, workspaceID uuid.UUID, req *request.CreateContentRequest) (*models.Content, error)
+	GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Content, error)
+	List(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.Content, error)
+	ListByTopic(ctx context.Context, workspaceID, topicID uuid.UUID, limit, offset int) ([]*models.Content, error)
 	ListScheduled(ctx context.Context, limit int) ([]*models.Content, error)
-	Update(ctx context.Context, id uuid.UUID, req *request.UpdateContentRequest) (*models.Content, error)
-	UpdateStatus(ctx context.Context, id uuid.UUID, status string) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	Update(ctx context.Context, workspaceID, id uuid.UUID, req *request.UpdateContentRequest) (*models.Content, error)
+	UpdateTx(ctx context.Context, tx pgx.Tx, workspaceID, id uuid.UUID, req *request.UpdateContentRequest) (*models.Content, error)
+	UpdateStatus(ctx context.Context, workspaceID, id uuid.UUID, status string) error
+	CancelTx(ctx context.Context, tx pgx.Tx, workspaceID, id uuid.UUID) error
+	ReopenTx(ctx context.Context, tx pgx.Tx, workspaceID, id uuid.UUID) error
+	UpdateStatusWithCounts(ctx context.Context, workspaceID, id uuid.UUID, status string, counts models.DeliveryCounts) error
+	Delete(ctx context.Context, workspaceID, id uuid.UUID) error
+	CreateOccurrenceTx(ctx context.Context, tx pgx.Tx, schedule *models.RecurringSchedule, subject, body string, sendAt time.Time) (*models.Content, error)
+	DeletePendingOccurrences(ctx context.Context, workspaceID, scheduleID uuid.UUID) (int64, error)
 }
 
+// RecurringScheduleRepository defines the interface for recurring schedule data operations
+type RecurringScheduleRepository interface {
+	Create(ctx context.Context, workspaceID, topicID uuid.UUID, req *request.CreateRecurringScheduleRequest, nextRunAt time.Time) (*models.RecurringSchedule, error)
+	GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.RecurringSchedule, error)
+	List(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.RecurringSchedule, error)
+	ListDue(ctx context.Context, until time.Time, limit int) ([]*models.RecurringSchedule, error)
+	Update(ctx context.Context, workspaceID, id uuid.UUID, req *request.UpdateRecurringScheduleRequest, nextRunAt time.Time) (*models.RecurringSchedule, error)
+	UpdateStatus(ctx context.Context, workspaceID, id uuid.UUID, status string, nextRunAt time.Time) error
+	AdvanceTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, expectedRunAt, nextRunAt time.Time) (bool, error)
+	Delete(ctx context.Context, workspaceID, id uuid.UUID) error
+}
 
-// JobRepository defines the interface for job scheduler data operations
+
+// JobRepository defines the interface for job scheduler data operations. Methods that
+// take only a job ID serve the scheduler and worker, which run across all workspaces.
 type JobRepository interface {
-	CreateTx(ctx context.Context, tx pgx.Tx, contentID uuid.UUID, jobType string, scheduledAt time.Time) (*models.JobScheduler, error)
+	CreateTx(ctx context.Context, tx pgx.Tx, workspaceID, contentID uuid.UUID, jobType string, scheduledAt time.Time) (*models.JobScheduler, error)
+	CreateInTimezoneTx(ctx context.Context, tx pgx.Tx, workspaceID, contentID uuid.UUID, jobType string, scheduledAt time.Time, timezone string) (*models.JobScheduler, error)
+	CreateRetryJob(ctx context.Context, workspaceID, contentID, deliveryID uuid.UUID, scheduledAt time.Time) (*models.JobScheduler, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*models.JobScheduler, error)
+	GetByIDForUpdateTx(ctx context.Context, tx pgx.Tx, workspaceID, id uuid.UUID) (*models.JobScheduler, error)
 	GetPendingJobs(ctx context.Context, limit int) ([]*models.JobScheduler, error)
-	List(ctx context.Context, limit, offset int) ([]*models.JobScheduler, error)
+	ListTimezonesByContent(ctx context.Context, workspaceID, contentID uuid.UUID) ([]string, error)
+	CountUnfinishedSendJobs(ctx context.Context, workspaceID, contentID uuid.UUID) (int, error)
+	List(ctx context.Context, workspaceID uuid.UUID, filter request.JobFilter, limit, offset int) ([]*models.JobScheduler, error)
+	ListErrors(ctx context.Context, jobID uuid.UUID) ([]*models.JobError, error)
 	UpdateStatus(ctx context.Context, id uuid.UUID, status string) error
+	UpdateStatusTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, status string) error
+	MarkEnqueued(ctx context.Context, id uuid.UUID, taskID string) (bool, error)
+	Heartbeat(ctx context.Context, id uuid.UUID) error
+	ListStaleEnqueued(ctx context.Context, staleBefore time.Time, limit int) ([]*models.JobScheduler, error)
+	ReleaseStale(ctx context.Context, id uuid.UUID, taskID, status string, attempts int, errorMessage string) (bool, error)
+	ListOpenByContentForUpdateTx(ctx context.Context, tx pgx.Tx, workspaceID, contentID uuid.UUID) ([]*models.JobScheduler, error)
+	RescheduleTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, scheduledAt time.Time) error
 	UpdateStatusWithError(ctx context.Context, id uuid.UUID, status string, attempts int, errorMessage *string) error
+	UpdateResult(ctx context.Context, id uuid.UUID, status string, attempts int, errorMessage *string, counts models.DeliveryCounts) error
+	ResetForRetryTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, maxAttempts int) error
+	FailTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, errorMessage string) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteTx(ctx context.Context, tx pgx.Tx, id uuid.UUID) error
+}
+
+// JobAuditRepository defines the interface for the audit log of manual job interventions
+type JobAuditRepository interface {
+	CreateTx(ctx context.Context, tx pgx.Tx, entry *models.JobAuditEntry) (*models.JobAuditEntry, error)
+	ListByJob(ctx context.Context, workspaceID, jobID uuid.UUID) ([]*models.JobAuditEntry, error)
 }
 
 // DeliveryRepository defines the interface for delivery data operations
 type DeliveryRepository interface {
-	CreateDelivery(ctx context.Context, contentID, subscriberID uuid.UUID, email, status string) (*models.Delivery, error)
-	UpdateDeliveryStatus(ctx context.Context, id uuid.UUID, status string, sentAt *time.Time, errorMessage *string) error
-	GetDeliveryByContentAndSubscriber(ctx context.Context, contentID, subscriberID uuid.UUID) (*models.Delivery, error)
-	ListDeliveriesByContent(ctx context.Context, contentID uuid.UUID) ([]*models.Delivery, error)
+	CreateDelivery(ctx context.Context, workspaceID, contentID, subscriberID uuid.UUID, email, status string) (*models.Delivery, error)
+	ClaimDelivery(ctx context.Context, workspaceID, contentID, subscriberID uuid.UUID, email string) (*models.Delivery, bool, error)
+	ClaimRetry(ctx context.Context, workspaceID, id uuid.UUID) (*models.Delivery, bool, error)
+	RearmRetryTx(ctx context.Context, tx pgx.Tx, workspaceID, id uuid.UUID) error
+	GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Delivery, error)
+	UpdateDeliveryStatus(ctx context.Context, workspaceID, id uuid.UUID, status string, sentAt *time.Time, errorMessage *string) error
+	UpdateDeliveryFailure(ctx context.Context, workspaceID, id uuid.UUID, status string, errorMessage *string, nextRetryAt *time.Time) error
+	MarkSent(ctx context.Context, workspaceID, id uuid.UUID, sentAt time.Time, providerMessageID *string) error
+	MarkDelivered(ctx context.Context, workspaceID, id uuid.UUID, deliveredAt time.Time) error
+	GetByProviderMessageID(ctx context.Context, providerMessageID string) (*models.Delivery, error)
+	GetDeliveryByContentAndSubscriber(ctx context.Context, workspaceID, contentID, subscriberID uuid.UUID) (*models.Delivery, error)
+	ListDeliveriesByContent(ctx context.Context, workspaceID, contentID uuid.UUID, statuses []string, afterID uuid.UUID, limit int) ([]*models.Delivery, error)
+	ReportByContent(ctx context.Context, workspaceID, contentID uuid.UUID, maxFailureReasons int) (*models.DeliveryReport, error)
+	CountByContent(ctx context.Context, workspaceID, contentID uuid.UUID) (models.DeliveryCounts, error)
+}
+
+// DeliveryEventRepository defines the interface for delivery engagement event data operations
+type DeliveryEventRepository interface {
+	Create(ctx context.Context, workspaceID, deliveryID uuid.UUID, eventType string, url, userAgent *string) (*models.DeliveryEvent, error)
+	CountByContent(ctx context.Context, workspaceID, contentID uuid.UUID) (*models.EngagementStats, error)
+}
+
+// SuppressionRepository defines the interface for suppression list data operations
+type SuppressionRepository interface {
+	Create(ctx context.Context, workspaceID uuid.UUID, email, reason, source string) (*models.Suppression, error)
+	Import(ctx context.Context, workspaceID uuid.UUID, emails, reasons []string, source string) (int, error)
+	GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Suppression, error)
+	GetByEmail(ctx context.Context, workspaceID uuid.UUID, email string) (*models.Suppression, error)
+	List(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.Suppression, error)
+	ReasonsByEmail(ctx context.Context, workspaceID uuid.UUID, emails []string) (map[string]string, error)
+	Delete(ctx context.Context, workspaceID, id uuid.UUID) error
+}
+
+// APIKeyRepository defines the interface for API key data operations
+type APIKeyRepository interface {
+	Create(ctx context.Context, workspaceID uuid.UUID, name, keyPrefix, keyHash, role string) (*models.APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	List(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.APIKey, error)
+	Revoke(ctx context.Context, workspaceID, id uuid.UUID) error
+	TouchLastUsed(ctx context.Context, id uuid.UUID) error
 }
@@ -0,0 +1,92 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"newsletter-assignment/internal/db"
+	"newsletter-assignment/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type jobAuditRepo struct {
+	db *db.DB
+}
+
+func NewJobAuditRepository(database *db.DB) JobAuditRepository {
+	return &jobAuditRepo{
+		db: database,
+	}
+}
+
+// CreateTx records a manual intervention on a job
+func (r *jobAuditRepo) CreateTx(ctx context.Context, tx pgx.Tx, entry *models.JobAuditEntry) (*models.JobAuditEntry, error) {
+	query := `
+		INSERT INTO job_audit_log (workspace_id, job_id, action, api_key_id, previous_status, new_status, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, workspace_id, job_id, action, api_key_id, previous_status, new_status, reason, created_at
+	`
+
+	var created models.JobAuditEntry
+	err := tx.QueryRow(ctx, query, entry.WorkspaceID, entry.JobID, entry.Action, entry.APIKeyID, entry.PreviousStatus, entry.NewStatus, entry.Reason).Scan(
+		&created.ID,
+		&created.WorkspaceID,
+		&created.JobID,
+		&created.Action,
+		&created.APIKeyID,
+		&created.PreviousStatus,
+		&created.NewStatus,
+		&created.Reason,
+		&created.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job audit entry: %w", err)
+	}
+
+	return &created, nil
+}
+
+// ListByJob returns the audit log of a job, oldest first
+func (r *jobAuditRepo) ListByJob(ctx context.Context, workspaceID, jobID uuid.UUID) ([]*models.JobAuditEntry, error) {
+	query := `
+		SELECT id, workspace_id, job_id, action, api_key_id, previous_status, new_status, reason, created_at
+		FROM job_audit_log
+		WHERE workspace_id = $1 AND job_id = $2
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, workspaceID, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.JobAuditEntry
+	for rows.Next() {
+		var entry models.JobAuditEntry
+		err := rows.Scan(
+			&entry.ID,
+			&entry.WorkspaceID,
+			&entry.JobID,
+			&entry.Action,
+			&entry.APIKeyID,
+			&entry.PreviousStatus,
+			&entry.NewStatus,
+			&entry.Reason,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job audit entry: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating job audit log: %w", err)
+	}
+
+	return entries, nil
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"time"
 
+	"newsletter-assignment/internal/apperr"
 	"newsletter-assignment/internal/constants"
 	"newsletter-assignment/internal/db"
 	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/request"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -23,23 +25,31 @@ func NewJobRepository(database *db.DB) JobRepository {
 	}
 }
 
-func (r *jobRepo) CreateTx(ctx context.Context, tx pgx.Tx, contentID uuid.UUID, jobType string, scheduledAt time.Time) (*models.JobScheduler, error) {
+func (r *jobRepo) CreateTx(ctx context.Context, tx pgx.Tx, workspaceID, contentID uuid.UUID, jobType string, scheduledAt time.Time) (*models.JobScheduler, error) {
 	query := `
-		INSERT INTO job_scheduler (content_id, job_type, scheduled_at)
-		VALUES ($1, $2, $3)
-		RETURNING id, content_id, job_type, scheduled_at, status, attempts, max_attempts, error_message, created_at, updated_at
+		INSERT INTO job_scheduler (workspace_id, content_id, job_type, scheduled_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, workspace_id, content_id, delivery_id, timezone, task_id, job_type, scheduled_at, status, attempts, max_attempts, error_message, sent_count, failed_count, skipped_count, heartbeat_at, created_at, updated_at
 	`
 
 	var job models.JobScheduler
-	err := tx.QueryRow(ctx, query, contentID, jobType, scheduledAt).Scan(
+	err := tx.QueryRow(ctx, query, workspaceID, contentID, jobType, scheduledAt).Scan(
 		&job.ID,
+		&job.WorkspaceID,
 		&job.ContentID,
+		&job.DeliveryID,
+		&job.Timezone,
+		&job.TaskID,
 		&job.JobType,
 		&job.ScheduledAt,
 		&job.Status,
 		&job.Attempts,
 		&job.MaxAttempts,
 		&job.ErrorMessage,
+		&job.SentCount,
+		&job.FailedCount,
+		&job.SkippedCount,
+		&job.HeartbeatAt,
 		&job.CreatedAt,
 		&job.UpdatedAt,
 	)
@@ -51,9 +61,83 @@ func (r *jobRepo) CreateTx(ctx context.Context, tx pgx.Tx, contentID uuid.UUID,
 	return &job, nil
 }
 
+// CreateInTimezoneTx creates a send job that targets the subscribers of one time zone
+func (r *jobRepo) CreateInTimezoneTx(ctx context.Context, tx pgx.Tx, workspaceID, contentID uuid.UUID, jobType string, scheduledAt time.Time, timezone string) (*models.JobScheduler, error) {
+	query := `
+		INSERT INTO job_scheduler (workspace_id, content_id, job_type, scheduled_at, timezone)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, workspace_id, content_id, delivery_id, timezone, task_id, job_type, scheduled_at, status, attempts, max_attempts, error_message, sent_count, failed_count, skipped_count, heartbeat_at, created_at, updated_at
+	`
+
+	var job models.JobScheduler
+	err := tx.QueryRow(ctx, query, workspaceID, contentID, jobType, scheduledAt, timezone).Scan(
+		&job.ID,
+		&job.WorkspaceID,
+		&job.ContentID,
+		&job.DeliveryID,
+		&job.Timezone,
+		&job.TaskID,
+		&job.JobType,
+		&job.ScheduledAt,
+		&job.Status,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.ErrorMessage,
+		&job.SentCount,
+		&job.FailedCount,
+		&job.SkippedCount,
+		&job.HeartbeatAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job in transaction: %w", err)
+	}
+
+	return &job, nil
+}
+
+// CreateRetryJob schedules a retry of a single failed delivery
+func (r *jobRepo) CreateRetryJob(ctx context.Context, workspaceID, contentID, deliveryID uuid.UUID, scheduledAt time.Time) (*models.JobScheduler, error) {
+	query := `
+		INSERT INTO job_scheduler (workspace_id, content_id, delivery_id, job_type, scheduled_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, workspace_id, content_id, delivery_id, timezone, task_id, job_type, scheduled_at, status, attempts, max_attempts, error_message, sent_count, failed_count, skipped_count, heartbeat_at, created_at, updated_at
+	`
+
+	var job models.JobScheduler
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, contentID, deliveryID, constants.JobTypeRetryDelivery, scheduledAt).Scan(
+		&job.ID,
+		&job.WorkspaceID,
+		&job.ContentID,
+		&job.DeliveryID,
+		&job.Timezone,
+		&job.TaskID,
+		&job.JobType,
+		&job.ScheduledAt,
+		&job.Status,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.ErrorMessage,
+		&job.SentCount,
+		&job.FailedCount,
+		&job.SkippedCount,
+		&job.HeartbeatAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create retry job: %w", err)
+	}
+
+	return &job, nil
+}
+
 func (r *jobRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.JobScheduler, error) {
 	query := `
-		SELECT id, content_id, job_type, scheduled_at, status, attempts, max_attempts, error_message, created_at, updated_at
+		SELECT id, workspace_id, content_id, delivery_id, timezone, task_id, job_type, scheduled_at, status, attempts, max_attempts, error_message, sent_count, failed_count, skipped_count, heartbeat_at, created_at, updated_at
 		FROM job_scheduler
 		WHERE id = $1
 	`
@@ -61,20 +145,69 @@ func (r *jobRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.JobSchedul
 	var job models.JobScheduler
 	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
 		&job.ID,
+		&job.WorkspaceID,
 		&job.ContentID,
+		&job.DeliveryID,
+		&job.Timezone,
+		&job.TaskID,
 		&job.JobType,
 		&job.ScheduledAt,
 		&job.Status,
 		&job.Attempts,
 		&job.MaxAttempts,
 		&job.ErrorMessage,
+		&job.SentCount,
+		&job.FailedCount,
+		&job.SkippedCount,
+		&job.HeartbeatAt,
 		&job.CreatedAt,
 		&job.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("job not found")
+			return nil, apperr.NotFound("job not found")
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// GetByIDForUpdateTx locks and returns a job of the workspace
+func (r *jobRepo) GetByIDForUpdateTx(ctx context.Context, tx pgx.Tx, workspaceID, id uuid.UUID) (*models.JobScheduler, error) {
+	query := `
+		SELECT id, workspace_id, content_id, delivery_id, timezone, task_id, job_type, scheduled_at, status, attempts, max_attempts, error_message, sent_count, failed_count, skipped_count, heartbeat_at, created_at, updated_at
+		FROM job_scheduler
+		WHERE workspace_id = $1 AND id = $2
+		FOR UPDATE
+	`
+
+	var job models.JobScheduler
+	err := tx.QueryRow(ctx, query, workspaceID, id).Scan(
+		&job.ID,
+		&job.WorkspaceID,
+		&job.ContentID,
+		&job.DeliveryID,
+		&job.Timezone,
+		&job.TaskID,
+		&job.JobType,
+		&job.ScheduledAt,
+		&job.Status,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.ErrorMessage,
+		&job.SentCount,
+		&job.FailedCount,
+		&job.SkippedCount,
+		&job.HeartbeatAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, apperr.NotFound("job not found")
 		}
 		return nil, fmt.Errorf("failed to get job: %w", err)
 	}
@@ -84,7 +217,7 @@ func (r *jobRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.JobSchedul
 
 func (r *jobRepo) GetPendingJobs(ctx context.Context, limit int) ([]*models.JobScheduler, error) {
 	query := `
-		SELECT id, content_id, job_type, scheduled_at, status, attempts, max_attempts, error_message, created_at, updated_at
+		SELECT id, workspace_id, content_id, delivery_id, timezone, task_id, job_type, scheduled_at, status, attempts, max_attempts, error_message, sent_count, failed_count, skipped_count, heartbeat_at, created_at, updated_at
 		FROM job_scheduler
 		WHERE status = $1 AND scheduled_at <= NOW()
 		ORDER BY scheduled_at ASC
@@ -103,13 +236,21 @@ func (r *jobRepo) GetPendingJobs(ctx context.Context, limit int) ([]*models.JobS
 		var job models.JobScheduler
 		err := rows.Scan(
 			&job.ID,
+			&job.WorkspaceID,
 			&job.ContentID,
+			&job.DeliveryID,
+			&job.Timezone,
+			&job.TaskID,
 			&job.JobType,
 			&job.ScheduledAt,
 			&job.Status,
 			&job.Attempts,
 			&job.MaxAttempts,
 			&job.ErrorMessage,
+			&job.SentCount,
+			&job.FailedCount,
+			&job.SkippedCount,
+			&job.HeartbeatAt,
 			&job.CreatedAt,
 			&job.UpdatedAt,
 		)
@@ -126,15 +267,70 @@ func (r *jobRepo) GetPendingJobs(ctx context.Context, limit int) ([]*models.JobS
 	return jobs, nil
 }
 
-func (r *jobRepo) List(ctx context.Context, limit, offset int) ([]*models.JobScheduler, error) {
+// ListTimezonesByContent returns the time zones that have their own send job for the content
+func (r *jobRepo) ListTimezonesByContent(ctx context.Context, workspaceID, contentID uuid.UUID) ([]string, error) {
 	query := `
-		SELECT id, content_id, job_type, scheduled_at, status, attempts, max_attempts, error_message, created_at, updated_at
+		SELECT DISTINCT timezone
 		FROM job_scheduler
+		WHERE workspace_id = $3 AND content_id = $1 AND job_type = $2 AND timezone IS NOT NULL
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, contentID, constants.JobTypeSendNewsletter, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job time zones: %w", err)
+	}
+	defer rows.Close()
+
+	var timezones []string
+	for rows.Next() {
+		var timezone string
+		if err := rows.Scan(&timezone); err != nil {
+			return nil, fmt.Errorf("failed to scan time zone: %w", err)
+		}
+		timezones = append(timezones, timezone)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating time zones: %w", err)
+	}
+
+	return timezones, nil
+}
+
+// CountUnfinishedSendJobs counts the send jobs of the content that are still waiting,
+// running or due for another attempt
+func (r *jobRepo) CountUnfinishedSendJobs(ctx context.Context, workspaceID, contentID uuid.UUID) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM job_scheduler
+		WHERE workspace_id = $6 AND content_id = $1 AND job_type = $2
+		  AND (status IN ($3, $4) OR (status = $5 AND attempts < max_attempts))
+	`
+
+	var count int
+	err := r.db.Pool.QueryRow(ctx, query, contentID, constants.JobTypeSendNewsletter,
+		constants.JobStatusPending, constants.JobStatusEnqueued, constants.JobStatusFailed, workspaceID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unfinished send jobs: %w", err)
+	}
+
+	return count, nil
+}
+
+// List returns the jobs of the workspace that match the filter, newest first
+func (r *jobRepo) List(ctx context.Context, workspaceID uuid.UUID, filter request.JobFilter, limit, offset int) ([]*models.JobScheduler, error) {
+	query := `
+		SELECT id, workspace_id, content_id, delivery_id, timezone, task_id, job_type, scheduled_at, status, attempts, max_attempts, error_message, sent_count, failed_count, skipped_count, heartbeat_at, created_at, updated_at
+		FROM job_scheduler
+		WHERE workspace_id = $1
+		  AND ($4 = '' OR status = $4)
+		  AND ($5 = '' OR job_type = $5)
+		  AND ($6::uuid IS NULL OR content_id = $6)
 		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
+		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, limit, offset)
+	rows, err := r.db.Pool.Query(ctx, query, workspaceID, limit, offset, filter.Status, filter.JobType, filter.ContentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list jobs: %w", err)
 	}
@@ -145,13 +341,21 @@ func (r *jobRepo) List(ctx context.Context, limit, offset int) ([]*models.JobSch
 		var job models.JobScheduler
 		err := rows.Scan(
 			&job.ID,
+			&job.WorkspaceID,
 			&job.ContentID,
+			&job.DeliveryID,
+			&job.Timezone,
+			&job.TaskID,
 			&job.JobType,
 			&job.ScheduledAt,
 			&job.Status,
 			&job.Attempts,
 			&job.MaxAttempts,
 			&job.ErrorMessage,
+			&job.SentCount,
+			&job.FailedCount,
+			&job.SkippedCount,
+			&job.HeartbeatAt,
 			&job.CreatedAt,
 			&job.UpdatedAt,
 		)
@@ -168,6 +372,44 @@ func (r *jobRepo) List(ctx context.Context, limit, offset int) ([]*models.JobSch
 	return jobs, nil
 }
 
+// ListErrors returns the errors recorded by the attempts of a job, oldest first
+func (r *jobRepo) ListErrors(ctx context.Context, jobID uuid.UUID) ([]*models.JobError, error) {
+	query := `
+		SELECT id, job_id, attempt, error_message, created_at
+		FROM job_errors
+		WHERE job_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job errors: %w", err)
+	}
+	defer rows.Close()
+
+	var jobErrors []*models.JobError
+	for rows.Next() {
+		var jobError models.JobError
+		err := rows.Scan(
+			&jobError.ID,
+			&jobError.JobID,
+			&jobError.Attempt,
+			&jobError.ErrorMessage,
+			&jobError.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job error: %w", err)
+		}
+		jobErrors = append(jobErrors, &jobError)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating job errors: %w", err)
+	}
+
+	return jobErrors, nil
+}
+
 func (r *jobRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
 	query := `
 		UPDATE job_scheduler
@@ -181,26 +423,321 @@ func (r *jobRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string)
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("job not found")
+		return apperr.NotFound("job not found")
 	}
 
 	return nil
 }
 
-func (r *jobRepo) UpdateStatusWithError(ctx context.Context, id uuid.UUID, status string, attempts int, errorMessage *string) error {
+// MarkEnqueued records the Asynq task of a job that was pending. It reports false
+// when the job left the pending state in the meantime, e.g. because it was cancelled.
+func (r *jobRepo) MarkEnqueued(ctx context.Context, id uuid.UUID, taskID string) (bool, error) {
+	query := `
+		UPDATE job_scheduler
+		SET status = $2, task_id = $3, updated_at = NOW()
+		WHERE id = $1 AND status = $4
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, constants.JobStatusEnqueued, taskID, constants.JobStatusPending)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark job enqueued: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// Heartbeat records that a worker is still processing the job
+func (r *jobRepo) Heartbeat(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE job_scheduler
+		SET heartbeat_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to record job heartbeat: %w", err)
+	}
+
+	return nil
+}
+
+// ListStaleEnqueued returns enqueued jobs that have neither changed nor sent a
+// heartbeat since staleBefore, oldest first
+func (r *jobRepo) ListStaleEnqueued(ctx context.Context, staleBefore time.Time, limit int) ([]*models.JobScheduler, error) {
+	query := `
+		SELECT id, workspace_id, content_id, delivery_id, timezone, task_id, job_type, scheduled_at, status, attempts, max_attempts, error_message, sent_count, failed_count, skipped_count, heartbeat_at, created_at, updated_at
+		FROM job_scheduler
+		WHERE status = $1 AND updated_at < $2 AND (heartbeat_at IS NULL OR heartbeat_at < $2)
+		ORDER BY updated_at ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, constants.JobStatusEnqueued, staleBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*models.JobScheduler
+	for rows.Next() {
+		var job models.JobScheduler
+		err := rows.Scan(
+			&job.ID,
+			&job.WorkspaceID,
+			&job.ContentID,
+			&job.DeliveryID,
+			&job.Timezone,
+			&job.TaskID,
+			&job.JobType,
+			&job.ScheduledAt,
+			&job.Status,
+			&job.Attempts,
+			&job.MaxAttempts,
+			&job.ErrorMessage,
+			&job.SentCount,
+			&job.FailedCount,
+			&job.SkippedCount,
+			&job.HeartbeatAt,
+			&job.CreatedAt,
+			&job.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, &job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// ReleaseStale moves an enqueued job whose task was lost to status, counting the
+// lost run as an attempt and adding the message to its error history. Pending jobs
+// are scheduled again right away. It reports false when the job is no longer
+// enqueued with that task.
+func (r *jobRepo) ReleaseStale(ctx context.Context, id uuid.UUID, taskID, status string, attempts int, errorMessage string) (bool, error) {
+	query := `
+		WITH updated AS (
+			UPDATE job_scheduler
+			SET status = $4, attempts = $5, error_message = $6,
+			    task_id = CASE WHEN $4 = $7 THEN NULL ELSE task_id END,
+			    scheduled_at = CASE WHEN $4 = $7 THEN NOW() ELSE scheduled_at END,
+			    updated_at = NOW()
+			WHERE id = $1 AND status = $2 AND task_id = $3
+			RETURNING id, attempts
+		), recorded AS (
+			INSERT INTO job_errors (job_id, attempt, error_message)
+			SELECT id, attempts, $6 FROM updated
+		)
+		SELECT COUNT(*) FROM updated
+	`
+
+	var updated int
+	err := r.db.Pool.QueryRow(ctx, query, id, constants.JobStatusEnqueued, taskID, status, attempts, errorMessage, constants.JobStatusPending).Scan(&updated)
+	if err != nil {
+		return false, fmt.Errorf("failed to release stale job: %w", err)
+	}
+
+	return updated == 1, nil
+}
+
+// ListOpenByContentForUpdateTx locks and returns the jobs of the content that have
+// not finished yet: pending, enqueued, or failed with attempts left
+func (r *jobRepo) ListOpenByContentForUpdateTx(ctx context.Context, tx pgx.Tx, workspaceID, contentID uuid.UUID) ([]*models.JobScheduler, error) {
+	query := `
+		SELECT id, workspace_id, content_id, delivery_id, timezone, task_id, job_type, scheduled_at, status, attempts, max_attempts, error_message, sent_count, failed_count, skipped_count, heartbeat_at, created_at, updated_at
+		FROM job_scheduler
+		WHERE workspace_id = $5 AND content_id = $1
+		  AND (status IN ($2, $3) OR (status = $4 AND attempts < max_attempts))
+		ORDER BY scheduled_at ASC
+		FOR UPDATE
+	`
+
+	rows, err := tx.Query(ctx, query, contentID, constants.JobStatusPending, constants.JobStatusEnqueued, constants.JobStatusFailed, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list content jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*models.JobScheduler
+	for rows.Next() {
+		var job models.JobScheduler
+		err := rows.Scan(
+			&job.ID,
+			&job.WorkspaceID,
+			&job.ContentID,
+			&job.DeliveryID,
+			&job.Timezone,
+			&job.TaskID,
+			&job.JobType,
+			&job.ScheduledAt,
+			&job.Status,
+			&job.Attempts,
+			&job.MaxAttempts,
+			&job.ErrorMessage,
+			&job.SentCount,
+			&job.FailedCount,
+			&job.SkippedCount,
+			&job.HeartbeatAt,
+			&job.CreatedAt,
+			&job.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, &job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (r *jobRepo) UpdateStatusTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, status string) error {
 	query := `
 		UPDATE job_scheduler
-		SET status = $2, attempts = $3, error_message = $4, updated_at = NOW()
+		SET status = $2, updated_at = NOW()
 		WHERE id = $1
 	`
 
-	result, err := r.db.Pool.Exec(ctx, query, id, status, attempts, errorMessage)
+	result, err := tx.Exec(ctx, query, id, status)
 	if err != nil {
+		return fmt.Errorf("failed to update job status: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperr.NotFound("job not found")
+	}
+
+	return nil
+}
+
+// RescheduleTx moves a pending job to a new time
+func (r *jobRepo) RescheduleTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, scheduledAt time.Time) error {
+	query := `
+		UPDATE job_scheduler
+		SET scheduled_at = $2, updated_at = NOW()
+		WHERE id = $1 AND status = $3
+	`
+
+	result, err := tx.Exec(ctx, query, id, scheduledAt, constants.JobStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule job: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperr.NotFound("job not found or no longer pending")
+	}
+
+	return nil
+}
+
+// UpdateStatusWithError records the outcome of a job run. A non-nil error message
+// is also added to the job's error history.
+func (r *jobRepo) UpdateStatusWithError(ctx context.Context, id uuid.UUID, status string, attempts int, errorMessage *string) error {
+	query := `
+		WITH updated AS (
+			UPDATE job_scheduler
+			SET status = $2, attempts = $3, error_message = $4, updated_at = NOW()
+			WHERE id = $1
+			RETURNING id, attempts
+		), recorded AS (
+			INSERT INTO job_errors (job_id, attempt, error_message)
+			SELECT id, attempts, $4 FROM updated WHERE $4::text IS NOT NULL
+		)
+		SELECT COUNT(*) FROM updated
+	`
+
+	var updated int
+	if err := r.db.Pool.QueryRow(ctx, query, id, status, attempts, errorMessage).Scan(&updated); err != nil {
 		return fmt.Errorf("failed to update job status with error: %w", err)
 	}
 
+	if updated == 0 {
+		return apperr.NotFound("job not found")
+	}
+
+	return nil
+}
+
+// UpdateResult records the outcome of a job run including its delivery counts. A
+// non-nil error message is also added to the job's error history.
+func (r *jobRepo) UpdateResult(ctx context.Context, id uuid.UUID, status string, attempts int, errorMessage *string, counts models.DeliveryCounts) error {
+	query := `
+		WITH updated AS (
+			UPDATE job_scheduler
+			SET status = $2, attempts = $3, error_message = $4, sent_count = $5, failed_count = $6, skipped_count = $7, updated_at = NOW()
+			WHERE id = $1
+			RETURNING id, attempts
+		), recorded AS (
+			INSERT INTO job_errors (job_id, attempt, error_message)
+			SELECT id, attempts, $4 FROM updated WHERE $4::text IS NOT NULL
+		)
+		SELECT COUNT(*) FROM updated
+	`
+
+	var updated int
+	err := r.db.Pool.QueryRow(ctx, query, id, status, attempts, errorMessage, counts.Sent, counts.Failed, counts.Skipped).Scan(&updated)
+	if err != nil {
+		return fmt.Errorf("failed to update job result: %w", err)
+	}
+
+	if updated == 0 {
+		return apperr.NotFound("job not found")
+	}
+
+	return nil
+}
+
+// ResetForRetryTx returns a job to pending so the scheduler enqueues it again right
+// away, with the given attempt limit
+func (r *jobRepo) ResetForRetryTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, maxAttempts int) error {
+	query := `
+		UPDATE job_scheduler
+		SET status = $2, task_id = NULL, scheduled_at = NOW(), max_attempts = $3, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := tx.Exec(ctx, query, id, constants.JobStatusPending, maxAttempts)
+	if err != nil {
+		return fmt.Errorf("failed to reset job for retry: %w", err)
+	}
+
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("job not found")
+		return apperr.NotFound("job not found")
+	}
+
+	return nil
+}
+
+// FailTx marks a job as failed with no attempts left and adds the message to its
+// error history
+func (r *jobRepo) FailTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, errorMessage string) error {
+	query := `
+		WITH updated AS (
+			UPDATE job_scheduler
+			SET status = $2, attempts = GREATEST(attempts, max_attempts), error_message = $3, updated_at = NOW()
+			WHERE id = $1
+			RETURNING id, attempts
+		), recorded AS (
+			INSERT INTO job_errors (job_id, attempt, error_message)
+			SELECT id, attempts, $3 FROM updated
+		)
+		SELECT COUNT(*) FROM updated
+	`
+
+	var updated int
+	if err := tx.QueryRow(ctx, query, id, constants.JobStatusFailed, errorMessage).Scan(&updated); err != nil {
+		return fmt.Errorf("failed to fail job: %w", err)
+	}
+
+	if updated == 0 {
+		return apperr.NotFound("job not found")
 	}
 
 	return nil
@@ -215,7 +752,22 @@ func (r *jobRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("job not found")
+		return apperr.NotFound("job not found")
+	}
+
+	return nil
+}
+
+func (r *jobRepo) DeleteTx(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	query := `DELETE FROM job_scheduler WHERE id = $1`
+
+	result, err := tx.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperr.NotFound("job not found")
 	}
 
 	return nil
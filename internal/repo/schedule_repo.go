@@ -0,0 +1,246 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/db"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/request"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type scheduleRepo struct {
+	db *db.DB
+}
+
+func NewRecurringScheduleRepository(database *db.DB) RecurringScheduleRepository {
+	return &scheduleRepo{
+		db: database,
+	}
+}
+
+func (r *scheduleRepo) Create(ctx context.Context, workspaceID, topicID uuid.UUID, req *request.CreateRecurringScheduleRequest, nextRunAt time.Time) (*models.RecurringSchedule, error) {
+	query := `
+		INSERT INTO recurring_schedules (workspace_id, topic_id, content_id, subject, body, cron_expression, timezone, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, workspace_id, topic_id, content_id, subject, body, cron_expression, timezone, status, next_run_at, last_run_at, created_at, updated_at
+	`
+
+	var schedule models.RecurringSchedule
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, topicID, req.ContentID, req.Subject, req.Body, req.CronExpression, req.Timezone, nextRunAt).Scan(
+		&schedule.ID,
+		&schedule.WorkspaceID,
+		&schedule.TopicID,
+		&schedule.ContentID,
+		&schedule.Subject,
+		&schedule.Body,
+		&schedule.CronExpression,
+		&schedule.Timezone,
+		&schedule.Status,
+		&schedule.NextRunAt,
+		&schedule.LastRunAt,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schedule: %w", err)
+	}
+
+	return &schedule, nil
+}
+
+func (r *scheduleRepo) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.RecurringSchedule, error) {
+	query := `
+		SELECT id, workspace_id, topic_id, content_id, subject, body, cron_expression, timezone, status, next_run_at, last_run_at, created_at, updated_at
+		FROM recurring_schedules
+		WHERE workspace_id = $1 AND id = $2
+	`
+
+	var schedule models.RecurringSchedule
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, id).Scan(
+		&schedule.ID,
+		&schedule.WorkspaceID,
+		&schedule.TopicID,
+		&schedule.ContentID,
+		&schedule.Subject,
+		&schedule.Body,
+		&schedule.CronExpression,
+		&schedule.Timezone,
+		&schedule.Status,
+		&schedule.NextRunAt,
+		&schedule.LastRunAt,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, apperr.NotFound("schedule not found")
+		}
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+
+	return &schedule, nil
+}
+
+func (r *scheduleRepo) List(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.RecurringSchedule, error) {
+	query := `
+		SELECT id, workspace_id, topic_id, content_id, subject, body, cron_expression, timezone, status, next_run_at, last_run_at, created_at, updated_at
+		FROM recurring_schedules
+		WHERE workspace_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, workspaceID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSchedules(rows)
+}
+
+// ListDue returns active schedules of all workspaces whose next run is at or before the given time
+func (r *scheduleRepo) ListDue(ctx context.Context, until time.Time, limit int) ([]*models.RecurringSchedule, error) {
+	query := `
+		SELECT id, workspace_id, topic_id, content_id, subject, body, cron_expression, timezone, status, next_run_at, last_run_at, created_at, updated_at
+		FROM recurring_schedules
+		WHERE status = $1 AND next_run_at <= $2
+		ORDER BY next_run_at ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, constants.RecurringScheduleStatusActive, until, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due schedules: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSchedules(rows)
+}
+
+func (r *scheduleRepo) Update(ctx context.Context, workspaceID, id uuid.UUID, req *request.UpdateRecurringScheduleRequest, nextRunAt time.Time) (*models.RecurringSchedule, error) {
+	query := `
+		UPDATE recurring_schedules
+		SET subject = COALESCE($3, subject), body = COALESCE($4, body), cron_expression = $5, timezone = $6, next_run_at = $7, updated_at = NOW()
+		WHERE workspace_id = $1 AND id = $2
+		RETURNING id, workspace_id, topic_id, content_id, subject, body, cron_expression, timezone, status, next_run_at, last_run_at, created_at, updated_at
+	`
+
+	var schedule models.RecurringSchedule
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, id, req.Subject, req.Body, req.CronExpression, req.Timezone, nextRunAt).Scan(
+		&schedule.ID,
+		&schedule.WorkspaceID,
+		&schedule.TopicID,
+		&schedule.ContentID,
+		&schedule.Subject,
+		&schedule.Body,
+		&schedule.CronExpression,
+		&schedule.Timezone,
+		&schedule.Status,
+		&schedule.NextRunAt,
+		&schedule.LastRunAt,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, apperr.NotFound("schedule not found")
+		}
+		return nil, fmt.Errorf("failed to update schedule: %w", err)
+	}
+
+	return &schedule, nil
+}
+
+func (r *scheduleRepo) UpdateStatus(ctx context.Context, workspaceID, id uuid.UUID, status string, nextRunAt time.Time) error {
+	query := `
+		UPDATE recurring_schedules
+		SET status = $3, next_run_at = $4, updated_at = NOW()
+		WHERE workspace_id = $1 AND id = $2
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, workspaceID, id, status, nextRunAt)
+	if err != nil {
+		return fmt.Errorf("failed to update schedule status: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperr.NotFound("schedule not found")
+	}
+
+	return nil
+}
+
+// AdvanceTx moves a schedule past the run at expectedRunAt. It reports false when
+// another scheduler instance already advanced the schedule.
+func (r *scheduleRepo) AdvanceTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, expectedRunAt, nextRunAt time.Time) (bool, error) {
+	query := `
+		UPDATE recurring_schedules
+		SET next_run_at = $3, last_run_at = $2, updated_at = NOW()
+		WHERE id = $1 AND next_run_at = $2 AND status = $4
+	`
+
+	result, err := tx.Exec(ctx, query, id, expectedRunAt, nextRunAt, constants.RecurringScheduleStatusActive)
+	if err != nil {
+		return false, fmt.Errorf("failed to advance schedule: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+func (r *scheduleRepo) Delete(ctx context.Context, workspaceID, id uuid.UUID) error {
+	query := `DELETE FROM recurring_schedules WHERE workspace_id = $1 AND id = $2`
+
+	result, err := r.db.Pool.Exec(ctx, query, workspaceID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete schedule: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperr.NotFound("schedule not found")
+	}
+
+	return nil
+}
+
+func scanSchedules(rows pgx.Rows) ([]*models.RecurringSchedule, error) {
+	var schedules []*models.RecurringSchedule
+	for rows.Next() {
+		var schedule models.RecurringSchedule
+		err := rows.Scan(
+			&schedule.ID,
+			&schedule.WorkspaceID,
+			&schedule.TopicID,
+			&schedule.ContentID,
+			&schedule.Subject,
+			&schedule.Body,
+			&schedule.CronExpression,
+			&schedule.Timezone,
+			&schedule.Status,
+			&schedule.NextRunAt,
+			&schedule.LastRunAt,
+			&schedule.CreatedAt,
+			&schedule.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		schedules = append(schedules, &schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedules: %w", err)
+	}
+
+	return schedules, nil
+}
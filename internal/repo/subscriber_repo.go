@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"newsletter-assignment/internal/apperr"
 	"newsletter-assignment/internal/db"
 	"newsletter-assignment/internal/models"
 	"newsletter-assignment/internal/request"
@@ -22,26 +23,28 @@ func NewSubscriberRepository(database *db.DB) SubscriberRepository {
 	}
 }
 
-func (r *subscriberRepo) Create(ctx context.Context, req *request.CreateSubscriberRequest) (*models.Subscriber, error) {
+func (r *subscriberRepo) Create(ctx context.Context, workspaceID uuid.UUID, req *request.CreateSubscriberRequest) (*models.Subscriber, error) {
 	query := `
-		INSERT INTO subscribers (email, name)
-		VALUES ($1, $2)
-		RETURNING id, email, name, is_active, created_at, updated_at
+		INSERT INTO subscribers (workspace_id, email, name, timezone)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, workspace_id, email, name, timezone, is_active, created_at, updated_at
 	`
 
 	var subscriber models.Subscriber
-	err := r.db.Pool.QueryRow(ctx, query, req.Email, req.Name).Scan(
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, req.Email, req.Name, req.Timezone).Scan(
 		&subscriber.ID,
+		&subscriber.WorkspaceID,
 		&subscriber.Email,
 		&subscriber.Name,
+		&subscriber.Timezone,
 		&subscriber.IsActive,
 		&subscriber.CreatedAt,
 		&subscriber.UpdatedAt,
 	)
 
 	if err != nil {
-		if err.Error() == `ERROR: duplicate key value violates unique constraint "subscribers_email_key" (SQLSTATE 23505)` {
-			return nil, fmt.Errorf("subscriber with email '%s' already exists", req.Email)
+		if isUniqueViolation(err, "subscribers_workspace_id_email_key") {
+			return nil, apperr.Conflict(fmt.Sprintf("subscriber with email '%s' already exists", req.Email))
 		}
 		return nil, fmt.Errorf("failed to create subscriber: %w", err)
 	}
@@ -49,18 +52,20 @@ func (r *subscriberRepo) Create(ctx context.Context, req *request.CreateSubscrib
 	return &subscriber, nil
 }
 
-func (r *subscriberRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Subscriber, error) {
+func (r *subscriberRepo) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Subscriber, error) {
 	query := `
-		SELECT id, email, name, is_active, created_at, updated_at
+		SELECT id, workspace_id, email, name, timezone, is_active, created_at, updated_at
 		FROM subscribers
-		WHERE id = $1
+		WHERE workspace_id = $1 AND id = $2
 	`
 
 	var subscriber models.Subscriber
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, id).Scan(
 		&subscriber.ID,
+		&subscriber.WorkspaceID,
 		&subscriber.Email,
 		&subscriber.Name,
+		&subscriber.Timezone,
 		&subscriber.IsActive,
 		&subscriber.CreatedAt,
 		&subscriber.UpdatedAt,
@@ -68,7 +73,7 @@ func (r *subscriberRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Sub
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("subscriber not found")
+			return nil, apperr.NotFound("subscriber not found")
 		}
 		return nil, fmt.Errorf("failed to get subscriber: %w", err)
 	}
@@ -76,18 +81,20 @@ func (r *subscriberRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Sub
 	return &subscriber, nil
 }
 
-func (r *subscriberRepo) GetByEmail(ctx context.Context, email string) (*models.Subscriber, error) {
+func (r *subscriberRepo) GetByEmail(ctx context.Context, workspaceID uuid.UUID, email string) (*models.Subscriber, error) {
 	query := `
-		SELECT id, email, name, is_active, created_at, updated_at
+		SELECT id, workspace_id, email, name, timezone, is_active, created_at, updated_at
 		FROM subscribers
-		WHERE email = $1
+		WHERE workspace_id = $1 AND email = $2
 	`
 
 	var subscriber models.Subscriber
-	err := r.db.Pool.QueryRow(ctx, query, email).Scan(
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, email).Scan(
 		&subscriber.ID,
+		&subscriber.WorkspaceID,
 		&subscriber.Email,
 		&subscriber.Name,
+		&subscriber.Timezone,
 		&subscriber.IsActive,
 		&subscriber.CreatedAt,
 		&subscriber.UpdatedAt,
@@ -95,7 +102,7 @@ func (r *subscriberRepo) GetByEmail(ctx context.Context, email string) (*models.
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("subscriber not found")
+			return nil, apperr.NotFound("subscriber not found")
 		}
 		return nil, fmt.Errorf("failed to get subscriber: %w", err)
 	}
@@ -103,15 +110,16 @@ func (r *subscriberRepo) GetByEmail(ctx context.Context, email string) (*models.
 	return &subscriber, nil
 }
 
-func (r *subscriberRepo) List(ctx context.Context, limit, offset int) ([]*models.Subscriber, error) {
+func (r *subscriberRepo) List(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.Subscriber, error) {
 	query := `
-		SELECT id, email, name, is_active, created_at, updated_at
+		SELECT id, workspace_id, email, name, timezone, is_active, created_at, updated_at
 		FROM subscribers
+		WHERE workspace_id = $1
 		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
+		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, limit, offset)
+	rows, err := r.db.Pool.Query(ctx, query, workspaceID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list subscribers: %w", err)
 	}
@@ -122,8 +130,10 @@ func (r *subscriberRepo) List(ctx context.Context, limit, offset int) ([]*models
 		var subscriber models.Subscriber
 		err := rows.Scan(
 			&subscriber.ID,
+			&subscriber.WorkspaceID,
 			&subscriber.Email,
 			&subscriber.Name,
+			&subscriber.Timezone,
 			&subscriber.IsActive,
 			&subscriber.CreatedAt,
 			&subscriber.UpdatedAt,
@@ -141,19 +151,108 @@ func (r *subscriberRepo) List(ctx context.Context, limit, offset int) ([]*models
 	return subscribers, nil
 }
 
-func (r *subscriberRepo) Update(ctx context.Context, id uuid.UUID, req *request.UpdateSubscriberRequest) (*models.Subscriber, error) {
+// ListActiveByTopic returns active subscribers with an active subscription to the topic,
+// ordered by ID and starting after the given ID, for keyset pagination. The filter
+// narrows the audience to one time-zone bucket. Subscriptions awaiting double opt-in
+// confirmation are never part of the audience.
+func (r *subscriberRepo) ListActiveByTopic(ctx context.Context, workspaceID, topicID uuid.UUID, filter AudienceFilter, afterID uuid.UUID, limit int) ([]*models.Subscriber, error) {
+	query := `
+		SELECT s.id, s.workspace_id, s.email, s.name, s.timezone, s.is_active, s.created_at, s.updated_at
+		FROM subscriptions sub
+		JOIN subscribers s ON s.id = sub.subscriber_id
+		WHERE sub.topic_id = $1 AND sub.status = 'active' AND sub.is_active = true AND s.is_active = true AND s.id > $2
+		  AND sub.workspace_id = $6 AND s.workspace_id = $6
+		  AND ($4::text IS NULL OR s.timezone = $4)
+		  AND (cardinality($5::text[]) = 0 OR s.timezone IS NULL OR NOT (s.timezone = ANY($5)))
+		ORDER BY s.id
+		LIMIT $3
+	`
+
+	excluded := filter.ExcludeTimezones
+	if excluded == nil {
+		excluded = []string{}
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, topicID, afterID, limit, filter.Timezone, excluded, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active subscribers by topic: %w", err)
+	}
+	defer rows.Close()
+
+	var subscribers []*models.Subscriber
+	for rows.Next() {
+		var subscriber models.Subscriber
+		err := rows.Scan(
+			&subscriber.ID,
+			&subscriber.WorkspaceID,
+			&subscriber.Email,
+			&subscriber.Name,
+			&subscriber.Timezone,
+			&subscriber.IsActive,
+			&subscriber.CreatedAt,
+			&subscriber.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subscriber: %w", err)
+		}
+		subscribers = append(subscribers, &subscriber)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating subscribers: %w", err)
+	}
+
+	return subscribers, nil
+}
+
+// ListTimezonesByTopic returns the distinct time zones set by the active audience of a topic
+func (r *subscriberRepo) ListTimezonesByTopic(ctx context.Context, workspaceID, topicID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT DISTINCT s.timezone
+		FROM subscriptions sub
+		JOIN subscribers s ON s.id = sub.subscriber_id
+		WHERE sub.topic_id = $1 AND sub.status = 'active' AND sub.is_active = true AND s.is_active = true AND s.timezone IS NOT NULL
+		  AND sub.workspace_id = $2 AND s.workspace_id = $2
+		ORDER BY s.timezone
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, topicID, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audience time zones: %w", err)
+	}
+	defer rows.Close()
+
+	var timezones []string
+	for rows.Next() {
+		var timezone string
+		if err := rows.Scan(&timezone); err != nil {
+			return nil, fmt.Errorf("failed to scan time zone: %w", err)
+		}
+		timezones = append(timezones, timezone)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating time zones: %w", err)
+	}
+
+	return timezones, nil
+}
+
+func (r *subscriberRepo) Update(ctx context.Context, workspaceID, id uuid.UUID, req *request.UpdateSubscriberRequest) (*models.Subscriber, error) {
 	query := `
 		UPDATE subscribers
-		SET email = $2, name = $3, is_active = $4, updated_at = NOW()
-		WHERE id = $1
-		RETURNING id, email, name, is_active, created_at, updated_at
+		SET email = $3, name = $4, timezone = $5, is_active = $6, updated_at = NOW()
+		WHERE workspace_id = $1 AND id = $2
+		RETURNING id, workspace_id, email, name, timezone, is_active, created_at, updated_at
 	`
 
 	var subscriber models.Subscriber
-	err := r.db.Pool.QueryRow(ctx, query, id, req.Email, req.Name, req.IsActive).Scan(
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, id, req.Email, req.Name, req.Timezone, req.IsActive).Scan(
 		&subscriber.ID,
+		&subscriber.WorkspaceID,
 		&subscriber.Email,
 		&subscriber.Name,
+		&subscriber.Timezone,
 		&subscriber.IsActive,
 		&subscriber.CreatedAt,
 		&subscriber.UpdatedAt,
@@ -161,7 +260,10 @@ func (r *subscriberRepo) Update(ctx context.Context, id uuid.UUID, req *request.
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("subscriber not found")
+			return nil, apperr.NotFound("subscriber not found")
+		}
+		if isUniqueViolation(err, "subscribers_workspace_id_email_key") {
+			return nil, apperr.Conflict(fmt.Sprintf("subscriber with email '%s' already exists", req.Email))
 		}
 		return nil, fmt.Errorf("failed to update subscriber: %w", err)
 	}
@@ -169,16 +271,16 @@ func (r *subscriberRepo) Update(ctx context.Context, id uuid.UUID, req *request.
 	return &subscriber, nil
 }
 
-func (r *subscriberRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM subscribers WHERE id = $1`
+func (r *subscriberRepo) Delete(ctx context.Context, workspaceID, id uuid.UUID) error {
+	query := `DELETE FROM subscribers WHERE workspace_id = $1 AND id = $2`
 
-	result, err := r.db.Pool.Exec(ctx, query, id)
+	result, err := r.db.Pool.Exec(ctx, query, workspaceID, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete subscriber: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("subscriber not found")
+		return apperr.NotFound("subscriber not found")
 	}
 
 	return nil
@@ -3,7 +3,10 @@ package repo
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
 	"newsletter-assignment/internal/db"
 	"newsletter-assignment/internal/models"
 	"newsletter-assignment/internal/request"
@@ -22,25 +25,29 @@ func NewSubscriptionRepository(database *db.DB) SubscriptionRepository {
 	}
 }
 
-func (r *subscriptionRepo) Create(ctx context.Context, req *request.CreateSubscriptionRequest) (*models.Subscription, error) {
+func (r *subscriptionRepo) Create(ctx context.Context, workspaceID uuid.UUID, req *request.CreateSubscriptionRequest, status string, confirmationExpiresAt *time.Time) (*models.Subscription, error) {
 	query := `
-		INSERT INTO subscriptions (subscriber_id, topic_id)
-		VALUES ($1, $2)
-		RETURNING id, subscriber_id, topic_id, subscribed_at, is_active
+		INSERT INTO subscriptions (workspace_id, subscriber_id, topic_id, status, is_active, confirmation_expires_at)
+		VALUES ($1, $2, $3, $4, $4 = 'active', $5)
+		RETURNING id, workspace_id, subscriber_id, topic_id, subscribed_at, is_active, status, confirmation_expires_at, confirmed_at
 	`
 
 	var subscription models.Subscription
-	err := r.db.Pool.QueryRow(ctx, query, req.SubscriberID, req.TopicID).Scan(
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, req.SubscriberID, req.TopicID, status, confirmationExpiresAt).Scan(
 		&subscription.ID,
+		&subscription.WorkspaceID,
 		&subscription.SubscriberID,
 		&subscription.TopicID,
 		&subscription.SubscribedAt,
 		&subscription.IsActive,
+		&subscription.Status,
+		&subscription.ConfirmationExpiresAt,
+		&subscription.ConfirmedAt,
 	)
 
 	if err != nil {
-		if err.Error() == `ERROR: duplicate key value violates unique constraint "subscriptions_subscriber_id_topic_id_key" (SQLSTATE 23505)` {
-			return nil, fmt.Errorf("subscription already exists for this subscriber and topic")
+		if isUniqueViolation(err, "subscriptions_subscriber_id_topic_id_key") {
+			return nil, apperr.Conflict("subscription already exists for this subscriber and topic")
 		}
 		return nil, fmt.Errorf("failed to create subscription: %w", err)
 	}
@@ -48,25 +55,29 @@ func (r *subscriptionRepo) Create(ctx context.Context, req *request.CreateSubscr
 	return &subscription, nil
 }
 
-func (r *subscriptionRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+func (r *subscriptionRepo) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Subscription, error) {
 	query := `
-		SELECT id, subscriber_id, topic_id, subscribed_at, is_active
+		SELECT id, workspace_id, subscriber_id, topic_id, subscribed_at, is_active, status, confirmation_expires_at, confirmed_at
 		FROM subscriptions
-		WHERE id = $1
+		WHERE workspace_id = $1 AND id = $2
 	`
 
 	var subscription models.Subscription
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, id).Scan(
 		&subscription.ID,
+		&subscription.WorkspaceID,
 		&subscription.SubscriberID,
 		&subscription.TopicID,
 		&subscription.SubscribedAt,
 		&subscription.IsActive,
+		&subscription.Status,
+		&subscription.ConfirmationExpiresAt,
+		&subscription.ConfirmedAt,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("subscription not found")
+			return nil, apperr.NotFound("subscription not found")
 		}
 		return nil, fmt.Errorf("failed to get subscription: %w", err)
 	}
@@ -74,25 +85,29 @@ func (r *subscriptionRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.S
 	return &subscription, nil
 }
 
-func (r *subscriptionRepo) GetBySubscriberAndTopic(ctx context.Context, subscriberID, topicID uuid.UUID) (*models.Subscription, error) {
+func (r *subscriptionRepo) GetBySubscriberAndTopic(ctx context.Context, workspaceID, subscriberID, topicID uuid.UUID) (*models.Subscription, error) {
 	query := `
-		SELECT id, subscriber_id, topic_id, subscribed_at, is_active
+		SELECT id, workspace_id, subscriber_id, topic_id, subscribed_at, is_active, status, confirmation_expires_at, confirmed_at
 		FROM subscriptions
-		WHERE subscriber_id = $1 AND topic_id = $2
+		WHERE workspace_id = $1 AND subscriber_id = $2 AND topic_id = $3
 	`
 
 	var subscription models.Subscription
-	err := r.db.Pool.QueryRow(ctx, query, subscriberID, topicID).Scan(
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, subscriberID, topicID).Scan(
 		&subscription.ID,
+		&subscription.WorkspaceID,
 		&subscription.SubscriberID,
 		&subscription.TopicID,
 		&subscription.SubscribedAt,
 		&subscription.IsActive,
+		&subscription.Status,
+		&subscription.ConfirmationExpiresAt,
+		&subscription.ConfirmedAt,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("subscription not found")
+			return nil, apperr.NotFound("subscription not found")
 		}
 		return nil, fmt.Errorf("failed to get subscription: %w", err)
 	}
@@ -100,15 +115,15 @@ func (r *subscriptionRepo) GetBySubscriberAndTopic(ctx context.Context, subscrib
 	return &subscription, nil
 }
 
-func (r *subscriptionRepo) ListBySubscriber(ctx context.Context, subscriberID uuid.UUID) ([]*models.Subscription, error) {
+func (r *subscriptionRepo) ListBySubscriber(ctx context.Context, workspaceID, subscriberID uuid.UUID) ([]*models.Subscription, error) {
 	query := `
-		SELECT id, subscriber_id, topic_id, subscribed_at, is_active
+		SELECT id, workspace_id, subscriber_id, topic_id, subscribed_at, is_active, status, confirmation_expires_at, confirmed_at
 		FROM subscriptions
-		WHERE subscriber_id = $1 AND is_active = true
+		WHERE workspace_id = $1 AND subscriber_id = $2 AND is_active = true
 		ORDER BY subscribed_at DESC
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, subscriberID)
+	rows, err := r.db.Pool.Query(ctx, query, workspaceID, subscriberID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list subscriptions by subscriber: %w", err)
 	}
@@ -119,10 +134,14 @@ func (r *subscriptionRepo) ListBySubscriber(ctx context.Context, subscriberID uu
 		var subscription models.Subscription
 		err := rows.Scan(
 			&subscription.ID,
+			&subscription.WorkspaceID,
 			&subscription.SubscriberID,
 			&subscription.TopicID,
 			&subscription.SubscribedAt,
 			&subscription.IsActive,
+			&subscription.Status,
+			&subscription.ConfirmationExpiresAt,
+			&subscription.ConfirmedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan subscription: %w", err)
@@ -137,15 +156,15 @@ func (r *subscriptionRepo) ListBySubscriber(ctx context.Context, subscriberID uu
 	return subscriptions, nil
 }
 
-func (r *subscriptionRepo) ListByTopic(ctx context.Context, topicID uuid.UUID) ([]*models.Subscription, error) {
+func (r *subscriptionRepo) ListByTopic(ctx context.Context, workspaceID, topicID uuid.UUID) ([]*models.Subscription, error) {
 	query := `
-		SELECT id, subscriber_id, topic_id, subscribed_at, is_active
+		SELECT id, workspace_id, subscriber_id, topic_id, subscribed_at, is_active, status, confirmation_expires_at, confirmed_at
 		FROM subscriptions
-		WHERE topic_id = $1 AND is_active = true
+		WHERE workspace_id = $1 AND topic_id = $2 AND is_active = true
 		ORDER BY subscribed_at DESC
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, topicID)
+	rows, err := r.db.Pool.Query(ctx, query, workspaceID, topicID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list subscriptions by topic: %w", err)
 	}
@@ -156,10 +175,14 @@ func (r *subscriptionRepo) ListByTopic(ctx context.Context, topicID uuid.UUID) (
 		var subscription models.Subscription
 		err := rows.Scan(
 			&subscription.ID,
+			&subscription.WorkspaceID,
 			&subscription.SubscriberID,
 			&subscription.TopicID,
 			&subscription.SubscribedAt,
 			&subscription.IsActive,
+			&subscription.Status,
+			&subscription.ConfirmationExpiresAt,
+			&subscription.ConfirmedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan subscription: %w", err)
@@ -174,26 +197,31 @@ func (r *subscriptionRepo) ListByTopic(ctx context.Context, topicID uuid.UUID) (
 	return subscriptions, nil
 }
 
-func (r *subscriptionRepo) Update(ctx context.Context, id uuid.UUID, isActive bool) (*models.Subscription, error) {
+// UpdateStatus moves a subscription to a new status
+func (r *subscriptionRepo) UpdateStatus(ctx context.Context, workspaceID, id uuid.UUID, status string, confirmationExpiresAt *time.Time) (*models.Subscription, error) {
 	query := `
 		UPDATE subscriptions
-		SET is_active = $2
-		WHERE id = $1
-		RETURNING id, subscriber_id, topic_id, subscribed_at, is_active
+		SET status = $3, is_active = $3 = 'active', confirmation_expires_at = $4
+		WHERE workspace_id = $1 AND id = $2
+		RETURNING id, workspace_id, subscriber_id, topic_id, subscribed_at, is_active, status, confirmation_expires_at, confirmed_at
 	`
 
 	var subscription models.Subscription
-	err := r.db.Pool.QueryRow(ctx, query, id, isActive).Scan(
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, id, status, confirmationExpiresAt).Scan(
 		&subscription.ID,
+		&subscription.WorkspaceID,
 		&subscription.SubscriberID,
 		&subscription.TopicID,
 		&subscription.SubscribedAt,
 		&subscription.IsActive,
+		&subscription.Status,
+		&subscription.ConfirmationExpiresAt,
+		&subscription.ConfirmedAt,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("subscription not found")
+			return nil, apperr.NotFound("subscription not found")
 		}
 		return nil, fmt.Errorf("failed to update subscription: %w", err)
 	}
@@ -201,16 +229,66 @@ func (r *subscriptionRepo) Update(ctx context.Context, id uuid.UUID, isActive bo
 	return &subscription, nil
 }
 
-func (r *subscriptionRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM subscriptions WHERE id = $1`
+// Confirm activates a subscription that is awaiting confirmation and whose
+// confirmation window has not passed
+func (r *subscriptionRepo) Confirm(ctx context.Context, workspaceID, id uuid.UUID) (*models.Subscription, error) {
+	query := `
+		UPDATE subscriptions
+		SET status = $3, is_active = true, confirmation_expires_at = NULL, confirmed_at = NOW()
+		WHERE workspace_id = $1 AND id = $2 AND status = $4 AND confirmation_expires_at > NOW()
+		RETURNING id, workspace_id, subscriber_id, topic_id, subscribed_at, is_active, status, confirmation_expires_at, confirmed_at
+	`
+
+	var subscription models.Subscription
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, id, constants.SubscriptionStatusActive, constants.SubscriptionStatusPendingConfirmation).Scan(
+		&subscription.ID,
+		&subscription.WorkspaceID,
+		&subscription.SubscriberID,
+		&subscription.TopicID,
+		&subscription.SubscribedAt,
+		&subscription.IsActive,
+		&subscription.Status,
+		&subscription.ConfirmationExpiresAt,
+		&subscription.ConfirmedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, apperr.PreconditionFailed("subscription is not awaiting confirmation")
+		}
+		return nil, fmt.Errorf("failed to confirm subscription: %w", err)
+	}
+
+	return &subscription, nil
+}
+
+// ExpirePending expires unconfirmed subscriptions whose confirmation window ended
+// before now, across all workspaces
+func (r *subscriptionRepo) ExpirePending(ctx context.Context, now time.Time) (int, error) {
+	query := `
+		UPDATE subscriptions
+		SET status = $1, confirmation_expires_at = NULL
+		WHERE status = $2 AND confirmation_expires_at <= $3
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, constants.SubscriptionStatusExpired, constants.SubscriptionStatusPendingConfirmation, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire pending subscriptions: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+func (r *subscriptionRepo) Delete(ctx context.Context, workspaceID, id uuid.UUID) error {
+	query := `DELETE FROM subscriptions WHERE workspace_id = $1 AND id = $2`
 
-	result, err := r.db.Pool.Exec(ctx, query, id)
+	result, err := r.db.Pool.Exec(ctx, query, workspaceID, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete subscription: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("subscription not found")
+		return apperr.NotFound("subscription not found")
 	}
 
 	return nil
@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var subscriptionColumns = []fakeColumn{
+	{"id", oidUUID},
+	{"workspace_id", oidUUID},
+	{"subscriber_id", oidUUID},
+	{"topic_id", oidUUID},
+	{"subscribed_at", oidTimestamptz},
+	{"is_active", oidBool},
+	{"status", oidText},
+	{"confirmation_expires_at", oidTimestamptz},
+	{"confirmed_at", oidTimestamptz},
+}
+
+func TestSubscriptionRepoLists(t *testing.T) {
+	workspaceID, subscriberID, topicID := uuid.New(), uuid.New(), uuid.New()
+	subscribedAt := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	rows := [][]any{
+		{uuid.New(), workspaceID, subscriberID, topicID, subscribedAt, true, constants.SubscriptionStatusActive, nil, subscribedAt},
+		{uuid.New(), workspaceID, subscriberID, topicID, subscribedAt, true, constants.SubscriptionStatusPendingConfirmation, subscribedAt.Add(time.Hour), nil},
+	}
+
+	database, fake := newFakeDB(t, func(sql string) fakeResult {
+		return fakeResult{columns: subscriptionColumns, rows: rows}
+	})
+	r := NewSubscriptionRepository(database)
+
+	lists := map[string]func() ([]*models.Subscription, error){
+		"ListBySubscriber": func() ([]*models.Subscription, error) {
+			return r.ListBySubscriber(context.Background(), workspaceID, subscriberID)
+		},
+		"ListByTopic": func() ([]*models.Subscription, error) {
+			return r.ListByTopic(context.Background(), workspaceID, topicID)
+		},
+	}
+
+	for name, list := range lists {
+		t.Run(name, func(t *testing.T) {
+			subscriptions, err := list()
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			checkSubscriptions(t, subscriptions, rows)
+			if query := fake.lastQuery(); !strings.Contains(query, workspaceID.String()) {
+				t.Errorf("query is not scoped to the workspace: %s", query)
+			}
+		})
+	}
+}
+
+func checkSubscriptions(t *testing.T, subscriptions []*models.Subscription, rows [][]any) {
+	t.Helper()
+
+	if len(subscriptions) != len(rows) {
+		t.Fatalf("%d subscriptions, want %d", len(subscriptions), len(rows))
+	}
+	for i, subscription := range subscriptions {
+		if subscription.ID != rows[i][0] || subscription.Status != rows[i][6] {
+			t.Errorf("subscription %d = %s/%s, want %s/%s", i, subscription.ID, subscription.Status, rows[i][0], rows[i][6])
+		}
+	}
+	if subscriptions[0].ConfirmedAt == nil || subscriptions[0].ConfirmationExpiresAt != nil {
+		t.Errorf("confirmed subscription has confirmed_at %v and expiry %v", subscriptions[0].ConfirmedAt, subscriptions[0].ConfirmationExpiresAt)
+	}
+	if subscriptions[1].ConfirmedAt != nil || subscriptions[1].ConfirmationExpiresAt == nil {
+		t.Errorf("pending subscription has confirmed_at %v and expiry %v", subscriptions[1].ConfirmedAt, subscriptions[1].ConfirmationExpiresAt)
+	}
+}
@@ -0,0 +1,206 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/db"
+	"newsletter-assignment/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type suppressionRepo struct {
+	db *db.DB
+}
+
+func NewSuppressionRepository(database *db.DB) SuppressionRepository {
+	return &suppressionRepo{
+		db: database,
+	}
+}
+
+func (r *suppressionRepo) Create(ctx context.Context, workspaceID uuid.UUID, email, reason, source string) (*models.Suppression, error) {
+	query := `
+		INSERT INTO suppressions (workspace_id, email, reason, source)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, workspace_id, email, reason, source, created_at
+	`
+
+	var suppression models.Suppression
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, email, reason, source).Scan(
+		&suppression.ID,
+		&suppression.WorkspaceID,
+		&suppression.Email,
+		&suppression.Reason,
+		&suppression.Source,
+		&suppression.CreatedAt,
+	)
+
+	if err != nil {
+		if isUniqueViolation(err, "suppressions_workspace_id_email_key") {
+			return nil, apperr.Conflict(fmt.Sprintf("email '%s' is already suppressed", email))
+		}
+		return nil, fmt.Errorf("failed to create suppression: %w", err)
+	}
+
+	return &suppression, nil
+}
+
+// Import suppresses many addresses at once. Addresses that are already suppressed
+// keep their original entry; the number of new entries is returned.
+func (r *suppressionRepo) Import(ctx context.Context, workspaceID uuid.UUID, emails, reasons []string, source string) (int, error) {
+	query := `
+		INSERT INTO suppressions (workspace_id, email, reason, source)
+		SELECT $1, entry.email, entry.reason, $4
+		FROM unnest($2::text[], $3::text[]) AS entry(email, reason)
+		ON CONFLICT (workspace_id, email) DO NOTHING
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, workspaceID, emails, reasons, source)
+	if err != nil {
+		return 0, fmt.Errorf("failed to import suppressions: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+func (r *suppressionRepo) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Suppression, error) {
+	query := `
+		SELECT id, workspace_id, email, reason, source, created_at
+		FROM suppressions
+		WHERE workspace_id = $1 AND id = $2
+	`
+
+	var suppression models.Suppression
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, id).Scan(
+		&suppression.ID,
+		&suppression.WorkspaceID,
+		&suppression.Email,
+		&suppression.Reason,
+		&suppression.Source,
+		&suppression.CreatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, apperr.NotFound("suppression not found")
+		}
+		return nil, fmt.Errorf("failed to get suppression: %w", err)
+	}
+
+	return &suppression, nil
+}
+
+func (r *suppressionRepo) GetByEmail(ctx context.Context, workspaceID uuid.UUID, email string) (*models.Suppression, error) {
+	query := `
+		SELECT id, workspace_id, email, reason, source, created_at
+		FROM suppressions
+		WHERE workspace_id = $1 AND email = $2
+	`
+
+	var suppression models.Suppression
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, email).Scan(
+		&suppression.ID,
+		&suppression.WorkspaceID,
+		&suppression.Email,
+		&suppression.Reason,
+		&suppression.Source,
+		&suppression.CreatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, apperr.NotFound("suppression not found")
+		}
+		return nil, fmt.Errorf("failed to get suppression by email: %w", err)
+	}
+
+	return &suppression, nil
+}
+
+func (r *suppressionRepo) List(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.Suppression, error) {
+	query := `
+		SELECT id, workspace_id, email, reason, source, created_at
+		FROM suppressions
+		WHERE workspace_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, workspaceID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list suppressions: %w", err)
+	}
+	defer rows.Close()
+
+	var suppressions []*models.Suppression
+	for rows.Next() {
+		var suppression models.Suppression
+		err := rows.Scan(
+			&suppression.ID,
+			&suppression.WorkspaceID,
+			&suppression.Email,
+			&suppression.Reason,
+			&suppression.Source,
+			&suppression.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan suppression: %w", err)
+		}
+		suppressions = append(suppressions, &suppression)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating suppressions: %w", err)
+	}
+
+	return suppressions, nil
+}
+
+// ReasonsByEmail returns the suppression reason of each of the given addresses that is suppressed
+func (r *suppressionRepo) ReasonsByEmail(ctx context.Context, workspaceID uuid.UUID, emails []string) (map[string]string, error) {
+	query := `
+		SELECT email, reason
+		FROM suppressions
+		WHERE workspace_id = $1 AND email = ANY($2)
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, workspaceID, emails)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up suppressions: %w", err)
+	}
+	defer rows.Close()
+
+	reasons := make(map[string]string)
+	for rows.Next() {
+		var email, reason string
+		if err := rows.Scan(&email, &reason); err != nil {
+			return nil, fmt.Errorf("failed to scan suppression: %w", err)
+		}
+		reasons[email] = reason
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating suppressions: %w", err)
+	}
+
+	return reasons, nil
+}
+
+func (r *suppressionRepo) Delete(ctx context.Context, workspaceID, id uuid.UUID) error {
+	query := `DELETE FROM suppressions WHERE workspace_id = $1 AND id = $2`
+
+	result, err := r.db.Pool.Exec(ctx, query, workspaceID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete suppression: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperr.NotFound("suppression not found")
+	}
+
+	return nil
+}
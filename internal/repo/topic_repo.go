@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"newsletter-assignment/internal/apperr"
 	"newsletter-assignment/internal/db"
 	"newsletter-assignment/internal/models"
 	"newsletter-assignment/internal/request"
@@ -22,25 +23,28 @@ func NewTopicRepository(database *db.DB) TopicRepository {
 	}
 }
 
-func (r *topicRepo) Create(ctx context.Context, req *request.CreateTopicRequest) (*models.Topic, error) {
+func (r *topicRepo) Create(ctx context.Context, workspaceID uuid.UUID, req *request.CreateTopicRequest) (*models.Topic, error) {
 	query := `
-		INSERT INTO topics (name, description)
-		VALUES ($1, $2)
-		RETURNING id, name, description, created_at, updated_at
+		INSERT INTO topics (workspace_id, name, description, double_opt_in, tracking_enabled)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, workspace_id, name, description, double_opt_in, tracking_enabled, created_at, updated_at
 	`
 
 	var topic models.Topic
-	err := r.db.Pool.QueryRow(ctx, query, req.Name, req.Description).Scan(
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, req.Name, req.Description, req.DoubleOptIn, req.TrackingEnabled).Scan(
 		&topic.ID,
+		&topic.WorkspaceID,
 		&topic.Name,
 		&topic.Description,
+		&topic.DoubleOptIn,
+		&topic.TrackingEnabled,
 		&topic.CreatedAt,
 		&topic.UpdatedAt,
 	)
 
 	if err != nil {
-		if err.Error() == `ERROR: duplicate key value violates unique constraint "topics_name_key" (SQLSTATE 23505)` {
-			return nil, fmt.Errorf("topic with name '%s' already exists", req.Name)
+		if isUniqueViolation(err, "topics_workspace_id_name_key") {
+			return nil, apperr.Conflict(fmt.Sprintf("topic with name '%s' already exists", req.Name))
 		}
 		return nil, fmt.Errorf("failed to create topic: %w", err)
 	}
@@ -48,25 +52,28 @@ func (r *topicRepo) Create(ctx context.Context, req *request.CreateTopicRequest)
 	return &topic, nil
 }
 
-func (r *topicRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Topic, error) {
+func (r *topicRepo) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Topic, error) {
 	query := `
-		SELECT id, name, description, created_at, updated_at
+		SELECT id, workspace_id, name, description, double_opt_in, tracking_enabled, created_at, updated_at
 		FROM topics
-		WHERE id = $1
+		WHERE workspace_id = $1 AND id = $2
 	`
 
 	var topic models.Topic
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, id).Scan(
 		&topic.ID,
+		&topic.WorkspaceID,
 		&topic.Name,
 		&topic.Description,
+		&topic.DoubleOptIn,
+		&topic.TrackingEnabled,
 		&topic.CreatedAt,
 		&topic.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("topic not found")
+			return nil, apperr.NotFound("topic not found")
 		}
 		return nil, fmt.Errorf("failed to get topic: %w", err)
 	}
@@ -74,25 +81,28 @@ func (r *topicRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Topic, e
 	return &topic, nil
 }
 
-func (r *topicRepo) GetByName(ctx context.Context, name string) (*models.Topic, error) {
+func (r *topicRepo) GetByName(ctx context.Context, workspaceID uuid.UUID, name string) (*models.Topic, error) {
 	query := `
-		SELECT id, name, description, created_at, updated_at
+		SELECT id, workspace_id, name, description, double_opt_in, tracking_enabled, created_at, updated_at
 		FROM topics
-		WHERE name = $1
+		WHERE workspace_id = $1 AND name = $2
 	`
 
 	var topic models.Topic
-	err := r.db.Pool.QueryRow(ctx, query, name).Scan(
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, name).Scan(
 		&topic.ID,
+		&topic.WorkspaceID,
 		&topic.Name,
 		&topic.Description,
+		&topic.DoubleOptIn,
+		&topic.TrackingEnabled,
 		&topic.CreatedAt,
 		&topic.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("topic not found")
+			return nil, apperr.NotFound("topic not found")
 		}
 		return nil, fmt.Errorf("failed to get topic: %w", err)
 	}
@@ -100,15 +110,16 @@ func (r *topicRepo) GetByName(ctx context.Context, name string) (*models.Topic,
 	return &topic, nil
 }
 
-func (r *topicRepo) List(ctx context.Context, limit, offset int) ([]*models.Topic, error) {
+func (r *topicRepo) List(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.Topic, error) {
 	query := `
-		SELECT id, name, description, created_at, updated_at
+		SELECT id, workspace_id, name, description, double_opt_in, tracking_enabled, created_at, updated_at
 		FROM topics
+		WHERE workspace_id = $1
 		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
+		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, limit, offset)
+	rows, err := r.db.Pool.Query(ctx, query, workspaceID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list topics: %w", err)
 	}
@@ -119,8 +130,11 @@ func (r *topicRepo) List(ctx context.Context, limit, offset int) ([]*models.Topi
 		var topic models.Topic
 		err := rows.Scan(
 			&topic.ID,
+			&topic.WorkspaceID,
 			&topic.Name,
 			&topic.Description,
+			&topic.DoubleOptIn,
+			&topic.TrackingEnabled,
 			&topic.CreatedAt,
 			&topic.UpdatedAt,
 		)
@@ -137,26 +151,32 @@ func (r *topicRepo) List(ctx context.Context, limit, offset int) ([]*models.Topi
 	return topics, nil
 }
 
-func (r *topicRepo) Update(ctx context.Context, id uuid.UUID, req *request.UpdateTopicRequest) (*models.Topic, error) {
+func (r *topicRepo) Update(ctx context.Context, workspaceID, id uuid.UUID, req *request.UpdateTopicRequest) (*models.Topic, error) {
 	query := `
 		UPDATE topics
-		SET name = $2, description = $3, updated_at = NOW()
-		WHERE id = $1
-		RETURNING id, name, description, created_at, updated_at
+		SET name = $3, description = $4, double_opt_in = $5, tracking_enabled = $6, updated_at = NOW()
+		WHERE workspace_id = $1 AND id = $2
+		RETURNING id, workspace_id, name, description, double_opt_in, tracking_enabled, created_at, updated_at
 	`
 
 	var topic models.Topic
-	err := r.db.Pool.QueryRow(ctx, query, id, req.Name, req.Description).Scan(
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID, id, req.Name, req.Description, req.DoubleOptIn, req.TrackingEnabled).Scan(
 		&topic.ID,
+		&topic.WorkspaceID,
 		&topic.Name,
 		&topic.Description,
+		&topic.DoubleOptIn,
+		&topic.TrackingEnabled,
 		&topic.CreatedAt,
 		&topic.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("topic not found")
+			return nil, apperr.NotFound("topic not found")
+		}
+		if isUniqueViolation(err, "topics_workspace_id_name_key") {
+			return nil, apperr.Conflict(fmt.Sprintf("topic with name '%s' already exists", req.Name))
 		}
 		return nil, fmt.Errorf("failed to update topic: %w", err)
 	}
@@ -164,16 +184,16 @@ func (r *topicRepo) Update(ctx context.Context, id uuid.UUID, req *request.Updat
 	return &topic, nil
 }
 
-func (r *topicRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM topics WHERE id = $1`
+func (r *topicRepo) Delete(ctx context.Context, workspaceID, id uuid.UUID) error {
+	query := `DELETE FROM topics WHERE workspace_id = $1 AND id = $2`
 
-	result, err := r.db.Pool.Exec(ctx, query, id)
+	result, err := r.db.Pool.Exec(ctx, query, workspaceID, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete topic: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("topic not found")
+		return apperr.NotFound("topic not found")
 	}
 
 	return nil
@@ -0,0 +1,109 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/db"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/request"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type workspaceRepo struct {
+	db *db.DB
+}
+
+func NewWorkspaceRepository(database *db.DB) WorkspaceRepository {
+	return &workspaceRepo{
+		db: database,
+	}
+}
+
+func (r *workspaceRepo) Create(ctx context.Context, req *request.CreateWorkspaceRequest) (*models.Workspace, error) {
+	query := `
+		INSERT INTO workspaces (name, slug, from_email, from_name)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, name, slug, from_email, from_name, created_at, updated_at
+	`
+
+	var workspace models.Workspace
+	err := r.db.Pool.QueryRow(ctx, query, req.Name, req.Slug, req.FromEmail, req.FromName).Scan(
+		&workspace.ID,
+		&workspace.Name,
+		&workspace.Slug,
+		&workspace.FromEmail,
+		&workspace.FromName,
+		&workspace.CreatedAt,
+		&workspace.UpdatedAt,
+	)
+
+	if err != nil {
+		if isUniqueViolation(err, "workspaces_slug_key") {
+			return nil, apperr.Conflict(fmt.Sprintf("workspace with slug '%s' already exists", req.Slug))
+		}
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	return &workspace, nil
+}
+
+func (r *workspaceRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Workspace, error) {
+	query := `
+		SELECT id, name, slug, from_email, from_name, created_at, updated_at
+		FROM workspaces
+		WHERE id = $1
+	`
+
+	var workspace models.Workspace
+	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+		&workspace.ID,
+		&workspace.Name,
+		&workspace.Slug,
+		&workspace.FromEmail,
+		&workspace.FromName,
+		&workspace.CreatedAt,
+		&workspace.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, apperr.NotFound("workspace not found")
+		}
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+
+	return &workspace, nil
+}
+
+func (r *workspaceRepo) Update(ctx context.Context, id uuid.UUID, req *request.UpdateWorkspaceRequest) (*models.Workspace, error) {
+	query := `
+		UPDATE workspaces
+		SET name = $2, from_email = $3, from_name = $4, updated_at = NOW()
+		WHERE id = $1
+		RETURNING id, name, slug, from_email, from_name, created_at, updated_at
+	`
+
+	var workspace models.Workspace
+	err := r.db.Pool.QueryRow(ctx, query, id, req.Name, req.FromEmail, req.FromName).Scan(
+		&workspace.ID,
+		&workspace.Name,
+		&workspace.Slug,
+		&workspace.FromEmail,
+		&workspace.FromName,
+		&workspace.CreatedAt,
+		&workspace.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, apperr.NotFound("workspace not found")
+		}
+		return nil, fmt.Errorf("failed to update workspace: %w", err)
+	}
+
+	return &workspace, nil
+}
@@ -0,0 +1,7 @@
+package request
+
+// CreateAPIKeyRequest represents the request payload for minting an API key
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+	Role string `json:"role" binding:"required,oneof=admin editor viewer"`
+}
@@ -8,10 +8,13 @@ import (
 
 // CreateContentRequest represents the request payload for creating content
 type CreateContentRequest struct {
-	TopicID uuid.UUID `json:"topic_id" binding:"required"`
-	Subject string    `json:"subject" binding:"required,min=1,max=500"`
-	Body    string    `json:"body" binding:"required,min=1"`
-	SendAt  time.Time `json:"send_at" binding:"required"`
+	TopicID      uuid.UUID `json:"topic_id" binding:"required"`
+	Subject      string    `json:"subject" binding:"required,min=1,max=500"`
+	Body         string    `json:"body" binding:"required,min=1"`
+	SendAt       time.Time `json:"send_at" binding:"required"`
+	DeliveryMode string    `json:"delivery_mode" binding:"omitempty,oneof=absolute local_time"`
+	// TrackingEnabled overrides the topic's open and click tracking setting when set
+	TrackingEnabled *bool `json:"tracking_enabled"`
 }
 
 // UpdateContentRequest represents the request payload for updating content
@@ -19,4 +22,11 @@ type UpdateContentRequest struct {
 	Subject string    `json:"subject" binding:"required,min=1,max=500"`
 	Body    string    `json:"body" binding:"required,min=1"`
 	SendAt  time.Time `json:"send_at" binding:"required"`
+	// TrackingEnabled overrides the topic's open and click tracking setting when set
+	TrackingEnabled *bool `json:"tracking_enabled"`
+}
+
+// RescheduleContentRequest represents the request payload for moving the send time of content
+type RescheduleContentRequest struct {
+	SendAt time.Time `json:"send_at" binding:"required"`
 }
@@ -0,0 +1,22 @@
+package request
+
+import "github.com/google/uuid"
+
+// JobFilter narrows a job listing. Empty fields match every job.
+type JobFilter struct {
+	Status    string
+	JobType   string
+	ContentID *uuid.UUID
+}
+
+// RetryJobRequest represents the request payload for manually retrying a failed job
+type RetryJobRequest struct {
+	// Override grants one more attempt to a job that used all of its attempts
+	Override bool    `json:"override"`
+	Reason   *string `json:"reason" binding:"omitempty,max=1000"`
+}
+
+// FailJobRequest represents the request payload for force-failing a job
+type FailJobRequest struct {
+	Reason string `json:"reason" binding:"required,min=1,max=1000"`
+}
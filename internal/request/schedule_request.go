@@ -0,0 +1,31 @@
+package request
+
+import (
+	"github.com/google/uuid"
+)
+
+// CreateRecurringScheduleRequest represents the request payload for creating a recurring schedule.
+// Either content_id or topic_id with subject and body must be provided.
+type CreateRecurringScheduleRequest struct {
+	TopicID        *uuid.UUID `json:"topic_id"`
+	ContentID      *uuid.UUID `json:"content_id"`
+	Subject        *string    `json:"subject" binding:"omitempty,min=1,max=500"`
+	Body           *string    `json:"body" binding:"omitempty,min=1"`
+	CronExpression string     `json:"cron_expression" binding:"required,max=255"`
+	Timezone       string     `json:"timezone" binding:"omitempty,max=64"`
+}
+
+// UpdateRecurringScheduleRequest represents the request payload for updating a recurring schedule
+type UpdateRecurringScheduleRequest struct {
+	Subject        *string `json:"subject" binding:"omitempty,min=1,max=500"`
+	Body           *string `json:"body" binding:"omitempty,min=1"`
+	CronExpression string  `json:"cron_expression" binding:"required,max=255"`
+	Timezone       string  `json:"timezone" binding:"omitempty,max=64"`
+}
+
+// PreviewScheduleRequest represents the request payload for previewing a cron expression
+type PreviewScheduleRequest struct {
+	CronExpression string `json:"cron_expression" binding:"required,max=255"`
+	Timezone       string `json:"timezone" binding:"omitempty,max=64"`
+	Count          int    `json:"count" binding:"omitempty,min=1"`
+}
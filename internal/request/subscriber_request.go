@@ -2,13 +2,15 @@ package request
 
 // CreateSubscriberRequest represents the request payload for creating a subscriber
 type CreateSubscriberRequest struct {
-	Email string  `json:"email" binding:"required,email,max=255"`
-	Name  *string `json:"name" binding:"omitempty,max=255"`
+	Email    string  `json:"email" binding:"required,email,max=255"`
+	Name     *string `json:"name" binding:"omitempty,max=255"`
+	Timezone *string `json:"timezone" binding:"omitempty,max=64"`
 }
 
 // UpdateSubscriberRequest represents the request payload for updating a subscriber
 type UpdateSubscriberRequest struct {
-	Email    string `json:"email" binding:"required,email,max=255"`
+	Email    string  `json:"email" binding:"required,email,max=255"`
 	Name     *string `json:"name" binding:"omitempty,max=255"`
-	IsActive bool   `json:"is_active"`
+	Timezone *string `json:"timezone" binding:"omitempty,max=64"`
+	IsActive bool    `json:"is_active"`
 }
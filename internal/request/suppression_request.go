@@ -0,0 +1,12 @@
+package request
+
+// CreateSuppressionRequest represents the request payload for suppressing an address
+type CreateSuppressionRequest struct {
+	Email  string `json:"email" binding:"required,email,max=255"`
+	Reason string `json:"reason" binding:"required,oneof=hard_bounce complaint unsubscribe manual"`
+}
+
+// ImportSuppressionsRequest represents the request payload for bulk suppressing addresses
+type ImportSuppressionsRequest struct {
+	Suppressions []CreateSuppressionRequest `json:"suppressions" binding:"required,min=1,max=1000,dive"`
+}
@@ -0,0 +1,10 @@
+package request
+
+// BrevoWebhookEvent represents a Brevo transactional email event callback
+type BrevoWebhookEvent struct {
+	Event     string `json:"event"`
+	Email     string `json:"email"`
+	MessageID string `json:"message-id"`
+	Reason    string `json:"reason"`
+	TsEvent   int64  `json:"ts_event"`
+}
@@ -0,0 +1,16 @@
+package request
+
+// CreateWorkspaceRequest represents the request payload for creating a workspace
+type CreateWorkspaceRequest struct {
+	Name      string  `json:"name" binding:"required,min=1,max=255"`
+	Slug      string  `json:"slug" binding:"required,min=1,max=64"`
+	FromEmail *string `json:"from_email" binding:"omitempty,email,max=255"`
+	FromName  *string `json:"from_name" binding:"omitempty,max=255"`
+}
+
+// UpdateWorkspaceRequest represents the request payload for updating a workspace
+type UpdateWorkspaceRequest struct {
+	Name      string  `json:"name" binding:"required,min=1,max=255"`
+	FromEmail *string `json:"from_email" binding:"omitempty,email,max=255"`
+	FromName  *string `json:"from_name" binding:"omitempty,max=255"`
+}
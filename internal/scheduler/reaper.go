@@ -0,0 +1,132 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/queue"
+	"newsletter-assignment/internal/repo"
+	"newsletter-assignment/internal/service"
+
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+// Reaper recovers jobs that stay enqueued because their Asynq task was lost or the
+// worker never reported back
+type Reaper struct {
+	jobRepo           repo.JobRepository
+	jobService        service.JobService
+	queue             queue.Queue
+	logger            *zap.Logger
+	visibilityTimeout time.Duration
+	batchSize         int
+}
+
+// NewReaper creates a reaper for jobs that made no progress within visibilityTimeout
+func NewReaper(
+	jobRepo repo.JobRepository,
+	jobService service.JobService,
+	queue queue.Queue,
+	logger *zap.Logger,
+	visibilityTimeout time.Duration,
+	batchSize int,
+) *Reaper {
+	if visibilityTimeout <= 0 {
+		visibilityTimeout = constants.DefaultSchedulerVisibilityTimeout
+	}
+	if batchSize <= 0 {
+		batchSize = constants.DefaultSchedulerBatchSize
+	}
+
+	return &Reaper{
+		jobRepo:           jobRepo,
+		jobService:        jobService,
+		queue:             queue,
+		logger:            logger,
+		visibilityTimeout: visibilityTimeout,
+		batchSize:         batchSize,
+	}
+}
+
+// Reap checks stale enqueued jobs against the queue. Jobs whose task is still
+// waiting or running are left alone; the others are re-queued, or failed once they
+// have no attempts left. It returns the number of recovered jobs.
+func (r *Reaper) Reap(ctx context.Context) (int, error) {
+	jobs, err := r.jobRepo.ListStaleEnqueued(ctx, time.Now().Add(-r.visibilityTimeout), r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	recovered := 0
+	for _, job := range jobs {
+		released, err := r.reapJob(ctx, job)
+		if err != nil {
+			r.logger.Error("Failed to recover stale job", zap.String("job_id", job.ID.String()), zap.Error(err))
+			continue
+		}
+		if released {
+			recovered++
+		}
+	}
+
+	return recovered, nil
+}
+
+// reapJob recovers one stale job unless its task is still in the queue
+func (r *Reaper) reapJob(ctx context.Context, job *models.JobScheduler) (bool, error) {
+	// The scheduler records the task ID when it marks a job enqueued
+	if job.TaskID == nil {
+		return false, fmt.Errorf("enqueued job has no task ID")
+	}
+
+	var reason string
+	state, err := r.queue.TaskState(*job.TaskID)
+	switch {
+	case errors.Is(err, queue.ErrTaskNotFound):
+		reason = "task was lost from the queue"
+	case err != nil:
+		return false, fmt.Errorf("failed to inspect task: %w", err)
+	case state == asynq.TaskStateCompleted || state == asynq.TaskStateArchived:
+		reason = fmt.Sprintf("task is %s but the job was never updated", state)
+	default:
+		// Waiting, retrying or running; Asynq itself recovers tasks of crashed workers
+		r.logger.Warn("Stale job still has a task in the queue",
+			zap.String("job_id", job.ID.String()),
+			zap.String("asynq_id", *job.TaskID),
+			zap.String("task_state", state.String()),
+		)
+		return false, nil
+	}
+
+	// The lost run counts as an attempt, as a run that failed would
+	attempts := job.Attempts + 1
+	status := constants.JobStatusPending
+	if attempts >= job.MaxAttempts {
+		status = constants.JobStatusFailed
+	}
+
+	errorMsg := fmt.Sprintf("No progress for %s: %s", r.visibilityTimeout, reason)
+	released, err := r.jobRepo.ReleaseStale(ctx, job.ID, *job.TaskID, status, attempts, errorMsg)
+	if err != nil || !released {
+		return false, err
+	}
+
+	r.logger.Warn("Recovered stale job",
+		zap.String("job_id", job.ID.String()),
+		zap.String("asynq_id", *job.TaskID),
+		zap.String("reason", reason),
+		zap.String("status", status),
+		zap.Int("attempts", attempts),
+	)
+
+	if status == constants.JobStatusFailed && job.JobType == constants.JobTypeSendNewsletter {
+		r.jobService.FinalizeIfLastJob(ctx, job.WorkspaceID, job.ContentID)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,140 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/queue"
+	"newsletter-assignment/internal/repo"
+	"newsletter-assignment/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+// reaperQueue reports a fixed state for every task
+type reaperQueue struct {
+	queue.Queue
+	state asynq.TaskState
+	err   error
+}
+
+func (q *reaperQueue) TaskState(taskID string) (asynq.TaskState, error) {
+	return q.state, q.err
+}
+
+// releaseCall records the arguments of a ReleaseStale call
+type releaseCall struct {
+	taskID   string
+	status   string
+	attempts int
+}
+
+type reaperJobRepo struct {
+	repo.JobRepository
+	// stillEnqueued is false when another process already moved the job on
+	stillEnqueued bool
+	released      *releaseCall
+}
+
+func (r *reaperJobRepo) ReleaseStale(ctx context.Context, id uuid.UUID, taskID, status string, attempts int, errorMessage string) (bool, error) {
+	r.released = &releaseCall{taskID: taskID, status: status, attempts: attempts}
+	return r.stillEnqueued, nil
+}
+
+type reaperJobService struct {
+	service.JobService
+	finalized []uuid.UUID
+}
+
+func (s *reaperJobService) FinalizeIfLastJob(ctx context.Context, workspaceID, contentID uuid.UUID) {
+	s.finalized = append(s.finalized, contentID)
+}
+
+func TestReapJob(t *testing.T) {
+	taskID := "task-1"
+	redisDown := errors.New("redis unavailable")
+
+	tests := []struct {
+		name          string
+		jobType       string
+		taskID        *string
+		attempts      int
+		state         asynq.TaskState
+		queueErr      error
+		movedOn       bool
+		wantErr       bool
+		wantReleased  bool
+		wantStatus    string
+		wantAttempts  int
+		wantFinalized bool
+	}{
+		{name: "task lost", taskID: &taskID, queueErr: queue.ErrTaskNotFound, wantReleased: true, wantStatus: constants.JobStatusPending, wantAttempts: 1},
+		{name: "task completed", taskID: &taskID, state: asynq.TaskStateCompleted, wantReleased: true, wantStatus: constants.JobStatusPending, wantAttempts: 1},
+		{name: "task archived", taskID: &taskID, attempts: 1, state: asynq.TaskStateArchived, wantReleased: true, wantStatus: constants.JobStatusPending, wantAttempts: 2},
+		{name: "task pending", taskID: &taskID, state: asynq.TaskStatePending},
+		{name: "task scheduled", taskID: &taskID, state: asynq.TaskStateScheduled},
+		{name: "task active", taskID: &taskID, state: asynq.TaskStateActive},
+		{name: "task retrying", taskID: &taskID, state: asynq.TaskStateRetry},
+		{name: "last attempt lost", taskID: &taskID, attempts: 2, queueErr: queue.ErrTaskNotFound, wantReleased: true, wantStatus: constants.JobStatusFailed, wantAttempts: 3, wantFinalized: true},
+		{name: "last attempt completed", taskID: &taskID, attempts: 2, state: asynq.TaskStateCompleted, wantReleased: true, wantStatus: constants.JobStatusFailed, wantAttempts: 3, wantFinalized: true},
+		{name: "last attempt of a delivery retry", jobType: constants.JobTypeRetryDelivery, taskID: &taskID, attempts: 2, queueErr: queue.ErrTaskNotFound, wantReleased: true, wantStatus: constants.JobStatusFailed, wantAttempts: 3},
+		{name: "job moved on meanwhile", taskID: &taskID, attempts: 2, queueErr: queue.ErrTaskNotFound, movedOn: true, wantStatus: constants.JobStatusFailed, wantAttempts: 3},
+		{name: "queue unavailable", taskID: &taskID, queueErr: redisDown, wantErr: true},
+		{name: "no task ID", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobType := tt.jobType
+			if jobType == "" {
+				jobType = constants.JobTypeSendNewsletter
+			}
+			job := &models.JobScheduler{
+				ID:          uuid.New(),
+				WorkspaceID: uuid.New(),
+				ContentID:   uuid.New(),
+				JobType:     jobType,
+				Status:      constants.JobStatusEnqueued,
+				Attempts:    tt.attempts,
+				MaxAttempts: 3,
+				TaskID:      tt.taskID,
+			}
+			jobs := &reaperJobRepo{stillEnqueued: !tt.movedOn}
+			jobService := &reaperJobService{}
+			r := NewReaper(jobs, jobService, &reaperQueue{state: tt.state, err: tt.queueErr}, zap.NewNop(), time.Minute, 10)
+
+			released, err := r.reapJob(context.Background(), job)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("reapJob() error = %v, want error %t", err, tt.wantErr)
+			}
+			if released != tt.wantReleased {
+				t.Errorf("reapJob() released = %t, want %t", released, tt.wantReleased)
+			}
+
+			switch {
+			case tt.wantStatus == "" && jobs.released != nil:
+				t.Errorf("job released as %q, want it left alone", jobs.released.status)
+			case tt.wantStatus != "" && jobs.released == nil:
+				t.Errorf("job not released, want %q", tt.wantStatus)
+			case tt.wantStatus != "":
+				if jobs.released.status != tt.wantStatus || jobs.released.attempts != tt.wantAttempts || jobs.released.taskID != taskID {
+					t.Errorf("job released as %q after %d attempts for task %q, want %q after %d for %q",
+						jobs.released.status, jobs.released.attempts, jobs.released.taskID, tt.wantStatus, tt.wantAttempts, taskID)
+				}
+			}
+
+			if finalized := len(jobService.finalized) > 0; finalized != tt.wantFinalized {
+				t.Errorf("content finalized = %t, want %t", finalized, tt.wantFinalized)
+			}
+			if tt.wantFinalized && jobService.finalized[0] != job.ContentID {
+				t.Errorf("finalized content %s, want %s", jobService.finalized[0], job.ContentID)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"newsletter-assignment/internal/models"
 	"newsletter-assignment/internal/queue"
 	"newsletter-assignment/internal/repo"
+	"newsletter-assignment/internal/service"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -22,29 +23,41 @@ type NewsletterJobPayload struct {
 
 // Scheduler handles the periodic processing of scheduled jobs
 type Scheduler struct {
-	jobRepo   repo.JobRepository
-	queue     queue.Queue
-	logger    *zap.Logger
-	interval  time.Duration
-	batchSize int
-	stopCh    chan struct{}
+	jobRepo             repo.JobRepository
+	scheduleService     service.RecurringScheduleService
+	subscriptionService service.SubscriptionService
+	reaper              *Reaper
+	queue               queue.Queue
+	logger              *zap.Logger
+	interval            time.Duration
+	batchSize           int
+	lookahead           time.Duration
+	stopCh              chan struct{}
 }
 
 // NewScheduler creates a new scheduler instance
 func NewScheduler(
 	jobRepo repo.JobRepository,
+	scheduleService service.RecurringScheduleService,
+	subscriptionService service.SubscriptionService,
+	reaper *Reaper,
 	queue queue.Queue,
 	logger *zap.Logger,
 	interval time.Duration,
 	batchSize int,
+	lookahead time.Duration,
 ) *Scheduler {
 	return &Scheduler{
-		jobRepo:   jobRepo,
-		queue:     queue,
-		logger:    logger,
-		interval:  interval,
-		batchSize: batchSize,
-		stopCh:    make(chan struct{}),
+		jobRepo:             jobRepo,
+		scheduleService:     scheduleService,
+		subscriptionService: subscriptionService,
+		reaper:              reaper,
+		queue:               queue,
+		logger:              logger,
+		interval:            interval,
+		batchSize:           batchSize,
+		lookahead:           lookahead,
+		stopCh:              make(chan struct{}),
 	}
 }
 
@@ -82,6 +95,10 @@ func (s *Scheduler) Stop() {
 
 // processJobs fetches pending jobs and enqueues them to Asynq
 func (s *Scheduler) processJobs(ctx context.Context) {
+	s.expandRecurringSchedules(ctx)
+	s.expirePendingSubscriptions(ctx)
+	s.reapStaleJobs(ctx)
+
 	jobs, err := s.jobRepo.GetPendingJobs(ctx, s.batchSize)
 	if err != nil {
 		s.logger.Error("Failed to get pending jobs", zap.Error(err))
@@ -121,11 +138,52 @@ func (s *Scheduler) processJobs(ctx context.Context) {
 	}
 }
 
+// expandRecurringSchedules creates jobs for recurring schedule runs within the lookahead window
+func (s *Scheduler) expandRecurringSchedules(ctx context.Context) {
+	created, err := s.scheduleService.ExpandDue(ctx, time.Now().Add(s.lookahead), s.batchSize)
+	if err != nil {
+		s.logger.Error("Failed to expand recurring schedules", zap.Error(err))
+		return
+	}
+
+	if created > 0 {
+		s.logger.Info("Created recurring schedule occurrences", zap.Int("count", created))
+	}
+}
+
+// expirePendingSubscriptions expires double opt-in subscriptions that were not confirmed in time
+func (s *Scheduler) expirePendingSubscriptions(ctx context.Context) {
+	expired, err := s.subscriptionService.ExpirePendingConfirmations(ctx, time.Now())
+	if err != nil {
+		s.logger.Error("Failed to expire pending subscriptions", zap.Error(err))
+		return
+	}
+
+	if expired > 0 {
+		s.logger.Info("Expired unconfirmed subscriptions", zap.Int("count", expired))
+	}
+}
+
+// reapStaleJobs recovers enqueued jobs that made no progress within the visibility timeout
+func (s *Scheduler) reapStaleJobs(ctx context.Context) {
+	recovered, err := s.reaper.Reap(ctx)
+	if err != nil {
+		s.logger.Error("Failed to reap stale jobs", zap.Error(err))
+		return
+	}
+
+	if recovered > 0 {
+		s.logger.Info("Recovered stale jobs", zap.Int("count", recovered))
+	}
+}
+
 // processJob processes a single job by enqueuing it to Asynq
 func (s *Scheduler) processJob(ctx context.Context, job *models.JobScheduler) error {
 	switch job.JobType {
 	case constants.JobTypeSendNewsletter:
 		return s.enqueueNewsletterJob(ctx, job)
+	case constants.JobTypeRetryDelivery:
+		return s.enqueueRetryDeliveryJob(ctx, job)
 	default:
 		return fmt.Errorf("unknown job type: %s", job.JobType)
 	}
@@ -147,17 +205,57 @@ func (s *Scheduler) enqueueNewsletterJob(ctx context.Context, job *models.JobSch
 	)
 
 	// Update job status to enqueued
-	err = s.jobRepo.UpdateStatus(ctx, job.ID, constants.JobStatusEnqueued)
+	s.markEnqueued(ctx, job, info.ID)
+
+	return nil
+}
+
+// enqueueRetryDeliveryJob enqueues a retry of a single failed delivery to Asynq
+func (s *Scheduler) enqueueRetryDeliveryJob(ctx context.Context, job *models.JobScheduler) error {
+	if job.DeliveryID == nil {
+		return fmt.Errorf("retry job has no delivery ID")
+	}
+
+	info, err := s.queue.EnqueueRetryDelivery(job.DeliveryID.String(), job.ID.String())
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task to Asynq: %w", err)
+	}
+
+	s.logger.Info("Retry job enqueued to Asynq",
+		zap.String("job_id", job.ID.String()),
+		zap.String("delivery_id", job.DeliveryID.String()),
+		zap.String("asynq_id", info.ID),
+		zap.String("queue", info.Queue),
+	)
+
+	// Update job status to enqueued
+	s.markEnqueued(ctx, job, info.ID)
+
+	return nil
+}
+
+// markEnqueued records the Asynq task of a job. If the job was cancelled while it
+// was being enqueued, the task is revoked again.
+func (s *Scheduler) markEnqueued(ctx context.Context, job *models.JobScheduler, taskID string) {
+	marked, err := s.jobRepo.MarkEnqueued(ctx, job.ID, taskID)
 	if err != nil {
 		s.logger.Error("Failed to update job status to enqueued",
 			zap.String("job_id", job.ID.String()),
 			zap.Error(err),
 		)
-		// Don't return error here as the job was successfully enqueued to Asynq
-		// The status update failure is not critical
+		// Don't fail here as the job was successfully enqueued to Asynq
+		return
 	}
 
-	return nil
+	if !marked {
+		s.logger.Warn("Job left pending state while enqueuing, revoking task",
+			zap.String("job_id", job.ID.String()),
+			zap.String("asynq_id", taskID),
+		)
+		if err := s.queue.RevokeTask(taskID); err != nil {
+			s.logger.Error("Failed to revoke task", zap.String("asynq_id", taskID), zap.Error(err))
+		}
+	}
 }
 
 // GetStats returns scheduler statistics
@@ -166,6 +264,7 @@ func (s *Scheduler) GetStats(ctx context.Context) (map[string]interface{}, error
 	return map[string]interface{}{
 		"interval":    s.interval.String(),
 		"batch_size":  s.batchSize,
+		"lookahead":   s.lookahead.String(),
 		"status":      "running",
 		"last_run":    time.Now(), // In a real implementation, you'd track this
 	}, nil
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/repo"
+	"newsletter-assignment/internal/request"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// errInvalidAPIKey does not say whether the key is unknown or revoked
+var errInvalidAPIKey = apperr.Unauthorized("invalid api key")
+
+// roleRanks orders roles so that a higher role includes the permissions of lower ones
+var roleRanks = map[string]int{
+	constants.RoleViewer: 1,
+	constants.RoleEditor: 2,
+	constants.RoleAdmin:  3,
+}
+
+// RoleSatisfies reports whether role grants at least the permissions of required
+func RoleSatisfies(role, required string) bool {
+	rank, ok := roleRanks[role]
+	return ok && rank >= roleRanks[required]
+}
+
+type apiKeyService struct {
+	apiKeyRepo repo.APIKeyRepository
+	logger     *zap.Logger
+}
+
+func NewAPIKeyService(apiKeyRepo repo.APIKeyRepository, logger *zap.Logger) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo: apiKeyRepo,
+		logger:     logger,
+	}
+}
+
+// CreateAPIKey mints a new key. The plaintext key is returned only here.
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, workspaceID uuid.UUID, req *request.CreateAPIKeyRequest) (*models.APIKey, string, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, "", apperr.Validation("name cannot be empty", apperr.Field("name", "cannot be empty"))
+	}
+
+	secret := make([]byte, constants.APIKeyBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	rawKey := constants.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	key, err := s.apiKeyRepo.Create(ctx, workspaceID, req.Name, displayPrefix(rawKey), hashAPIKey(rawKey), req.Role)
+	if err != nil {
+		s.logger.Error("Failed to create api key", zap.Error(err))
+		return nil, "", err
+	}
+
+	s.logger.Info("API key created",
+		zap.String("id", key.ID.String()),
+		zap.String("workspace_id", workspaceID.String()),
+		zap.String("name", key.Name),
+		zap.String("role", key.Role),
+	)
+
+	return key, rawKey, nil
+}
+
+func (s *apiKeyService) ListAPIKeys(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.APIKey, error) {
+	if limit <= 0 {
+		limit = constants.DefaultLimit
+	}
+	if limit > constants.MaxLimit {
+		limit = constants.MaxLimit
+	}
+	if offset < 0 {
+		offset = constants.DefaultOffset
+	}
+
+	return s.apiKeyRepo.List(ctx, workspaceID, limit, offset)
+}
+
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, workspaceID, id uuid.UUID) error {
+	if err := s.apiKeyRepo.Revoke(ctx, workspaceID, id); err != nil {
+		s.logger.Error("Failed to revoke api key", zap.Error(err), zap.String("id", id.String()))
+		return err
+	}
+
+	s.logger.Info("API key revoked", zap.String("id", id.String()))
+	return nil
+}
+
+// EnsureAPIKey stores a key supplied from configuration unless it already exists.
+// A configured key that was revoked through the API stays revoked.
+func (s *apiKeyService) EnsureAPIKey(ctx context.Context, workspaceID uuid.UUID, name, rawKey, role string) error {
+	if len(rawKey) < constants.MinAPIKeyLength {
+		return fmt.Errorf("api key must be at least %d characters", constants.MinAPIKeyLength)
+	}
+
+	_, err := s.apiKeyRepo.Create(ctx, workspaceID, name, displayPrefix(rawKey), hashAPIKey(rawKey), role)
+	if err != nil && !errors.Is(err, apperr.ErrConflict) {
+		return err
+	}
+	return nil
+}
+
+// Authenticate resolves a plaintext key to an active API key
+func (s *apiKeyService) Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	if rawKey == "" {
+		return nil, apperr.Unauthorized("api key is required")
+	}
+
+	key, err := s.apiKeyRepo.GetByHash(ctx, hashAPIKey(rawKey))
+	if err != nil {
+		if errors.Is(err, apperr.ErrNotFound) {
+			return nil, errInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	if key.RevokedAt != nil {
+		return nil, errInvalidAPIKey
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(ctx, key.ID); err != nil {
+		s.logger.Warn("Failed to record api key usage", zap.Error(err), zap.String("id", key.ID.String()))
+	}
+
+	return key, nil
+}
+
+// hashAPIKey hashes a key for storage. Keys are long random strings, so a fast
+// hash is sufficient and allows lookup by hash.
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func displayPrefix(rawKey string) string {
+	if len(rawKey) <= constants.APIKeyDisplayLength {
+		return rawKey
+	}
+	return rawKey[:constants.APIKeyDisplayLength]
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+
+	"newsletter-assignment/internal/constants"
+)
+
+func TestRoleSatisfies(t *testing.T) {
+	tests := []struct {
+		role     string
+		required string
+		want     bool
+	}{
+		{role: constants.RoleViewer, required: constants.RoleViewer, want: true},
+		{role: constants.RoleViewer, required: constants.RoleEditor, want: false},
+		{role: constants.RoleViewer, required: constants.RoleAdmin, want: false},
+		{role: constants.RoleEditor, required: constants.RoleViewer, want: true},
+		{role: constants.RoleEditor, required: constants.RoleEditor, want: true},
+		{role: constants.RoleEditor, required: constants.RoleAdmin, want: false},
+		{role: constants.RoleAdmin, required: constants.RoleViewer, want: true},
+		{role: constants.RoleAdmin, required: constants.RoleEditor, want: true},
+		{role: constants.RoleAdmin, required: constants.RoleAdmin, want: true},
+		{role: "", required: constants.RoleViewer, want: false},
+		{role: "owner", required: constants.RoleViewer, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role+"/"+tt.required, func(t *testing.T) {
+			if got := RoleSatisfies(tt.role, tt.required); got != tt.want {
+				t.Errorf("RoleSatisfies(%q, %q) = %t, want %t", tt.role, tt.required, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,66 +2,100 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"newsletter-assignment/internal/apperr"
 	"newsletter-assignment/internal/constants"
 	"newsletter-assignment/internal/db"
 	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/queue"
 	"newsletter-assignment/internal/repo"
 	"newsletter-assignment/internal/request"
+	"newsletter-assignment/internal/templating"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 )
 
+// errContentSending is returned when content has a send job that was already handed to the queue
+var errContentSending = apperr.Conflict("content is already being sent")
+
 type contentService struct {
-	contentRepo repo.ContentRepository
-	topicRepo   repo.TopicRepository
-	jobRepo     repo.JobRepository
-	db          *db.DB
-	logger      *zap.Logger
+	contentRepo    repo.ContentRepository
+	topicRepo      repo.TopicRepository
+	subscriberRepo repo.SubscriberRepository
+	jobRepo        repo.JobRepository
+	queue          queue.Queue
+	db             *db.DB
+	logger         *zap.Logger
 }
 
 func NewContentService(
 	contentRepo repo.ContentRepository,
 	topicRepo repo.TopicRepository,
+	subscriberRepo repo.SubscriberRepository,
 	jobRepo repo.JobRepository,
+	jobQueue queue.Queue,
 	database *db.DB,
 	logger *zap.Logger,
 ) ContentService {
 	return &contentService{
-		contentRepo: contentRepo,
-		topicRepo:   topicRepo,
-		jobRepo:     jobRepo,
-		db:          database,
-		logger:      logger,
+		contentRepo:    contentRepo,
+		topicRepo:      topicRepo,
+		subscriberRepo: subscriberRepo,
+		jobRepo:        jobRepo,
+		queue:          jobQueue,
+		db:             database,
+		logger:         logger,
 	}
 }
 
-func (s *contentService) CreateContent(ctx context.Context, req *request.CreateContentRequest) (*models.Content, error) {
+func (s *contentService) CreateContent(ctx context.Context, workspaceID uuid.UUID, req *request.CreateContentRequest) (*models.Content, error) {
 	// Validate and sanitize input
 	req.Subject = strings.TrimSpace(req.Subject)
 	if req.Subject == "" {
-		return nil, fmt.Errorf("subject cannot be empty")
+		return nil, apperr.Validation("subject cannot be empty", apperr.Field("subject", "cannot be empty"))
 	}
 
 	req.Body = strings.TrimSpace(req.Body)
 	if req.Body == "" {
-		return nil, fmt.Errorf("body cannot be empty")
+		return nil, apperr.Validation("body cannot be empty", apperr.Field("body", "cannot be empty"))
+	}
+
+	// Validate templates so that errors surface now rather than at send time
+	if err := templating.Validate(req.Subject, req.Body); err != nil {
+		return nil, err
 	}
 
 	// Validate send_at is in the future
 	if req.SendAt.Before(time.Now()) {
-		return nil, fmt.Errorf("send_at must be in the future")
+		return nil, apperr.Validation("send_at must be in the future", apperr.Field("send_at", "must be in the future"))
+	}
+
+	if req.DeliveryMode == "" {
+		req.DeliveryMode = constants.DeliveryModeAbsolute
 	}
 
 	// Validate that topic exists
-	topic, err := s.topicRepo.GetByID(ctx, req.TopicID)
+	topic, err := s.topicRepo.GetByID(ctx, workspaceID, req.TopicID)
 	if err != nil {
 		s.logger.Error("Topic not found for content", zap.Error(err), zap.String("topic_id", req.TopicID.String()))
-		return nil, fmt.Errorf("topic not found")
+		return nil, err
+	}
+
+	// Local-time content gets one job per audience time zone, firing at the
+	// wall-clock time of send_at in that zone
+	var localJobs map[string]time.Time
+	if req.DeliveryMode == constants.DeliveryModeLocalTime {
+		localJobs, err = s.localSendTimes(ctx, workspaceID, req.TopicID, req.SendAt)
+		if err != nil {
+			s.logger.Error("Failed to resolve audience time zones", zap.Error(err))
+			return nil, err
+		}
 	}
 
 	s.logger.Info("Creating content with job",
@@ -79,19 +113,28 @@ func (s *contentService) CreateContent(ctx context.Context, req *request.CreateC
 	defer tx.Rollback(ctx)
 
 	// Create content within transaction
-	content, err := s.contentRepo.CreateTx(ctx, tx, req)
+	content, err := s.contentRepo.CreateTx(ctx, tx, workspaceID, req)
 	if err != nil {
 		s.logger.Error("Failed to create content", zap.Error(err))
 		return nil, err
 	}
 
-	// Create job within same transaction
-	_, err = s.jobRepo.CreateTx(ctx, tx, content.ID, constants.JobTypeSendNewsletter, content.SendAt)
+	// Create job within same transaction; for local-time content this is the
+	// default bucket for subscribers without a time zone of their own
+	_, err = s.jobRepo.CreateTx(ctx, tx, workspaceID, content.ID, constants.JobTypeSendNewsletter, content.SendAt)
 	if err != nil {
 		s.logger.Error("Failed to create job", zap.Error(err))
 		return nil, fmt.Errorf("failed to create job: %w", err)
 	}
 
+	for timezone, sendAt := range localJobs {
+		_, err = s.jobRepo.CreateInTimezoneTx(ctx, tx, workspaceID, content.ID, constants.JobTypeSendNewsletter, sendAt, timezone)
+		if err != nil {
+			s.logger.Error("Failed to create time zone job", zap.Error(err), zap.String("timezone", timezone))
+			return nil, fmt.Errorf("failed to create job: %w", err)
+		}
+	}
+
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		s.logger.Error("Failed to commit transaction", zap.Error(err))
@@ -103,14 +146,45 @@ func (s *contentService) CreateContent(ctx context.Context, req *request.CreateC
 		zap.String("topic_name", topic.Name),
 		zap.String("subject", content.Subject),
 		zap.String("status", content.Status),
+		zap.String("delivery_mode", content.DeliveryMode),
+		zap.Int("time_zone_jobs", len(localJobs)),
 		zap.Time("send_at", content.SendAt),
 	)
 
 	return content, nil
 }
 
-func (s *contentService) GetContent(ctx context.Context, id uuid.UUID) (*models.Content, error) {
-	content, err := s.contentRepo.GetByID(ctx, id)
+// localSendTimes maps every time zone of the topic audience to the instant at which
+// the wall-clock date and time of sendAt occur there. Zones where that moment has
+// already passed are sent right away.
+func (s *contentService) localSendTimes(ctx context.Context, workspaceID, topicID uuid.UUID, sendAt time.Time) (map[string]time.Time, error) {
+	timezones, err := s.subscriberRepo.ListTimezonesByTopic(ctx, workspaceID, topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	sendTimes := make(map[string]time.Time, len(timezones))
+	for _, timezone := range timezones {
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			s.logger.Warn("Skipping unknown subscriber time zone", zap.String("timezone", timezone))
+			continue
+		}
+		sendTimes[timezone] = wallClockIn(sendAt, location)
+	}
+
+	return sendTimes, nil
+}
+
+// wallClockIn returns the instant at which the date and time of t, as written, occur in location
+func wallClockIn(t time.Time, location *time.Location) time.Time {
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
+	return time.Date(year, month, day, hour, minute, second, 0, location)
+}
+
+func (s *contentService) GetContent(ctx context.Context, workspaceID, id uuid.UUID) (*models.Content, error) {
+	content, err := s.contentRepo.GetByID(ctx, workspaceID, id)
 	if err != nil {
 		s.logger.Error("Failed to get content", zap.Error(err), zap.String("id", id.String()))
 		return nil, err
@@ -119,7 +193,7 @@ func (s *contentService) GetContent(ctx context.Context, id uuid.UUID) (*models.
 	return content, nil
 }
 
-func (s *contentService) ListContent(ctx context.Context, limit, offset int) ([]*models.Content, error) {
+func (s *contentService) ListContent(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.Content, error) {
 	// Set default and max limits
 	if limit <= 0 {
 		limit = constants.DefaultLimit
@@ -131,7 +205,7 @@ func (s *contentService) ListContent(ctx context.Context, limit, offset int) ([]
 		offset = constants.DefaultOffset
 	}
 
-	contents, err := s.contentRepo.List(ctx, limit, offset)
+	contents, err := s.contentRepo.List(ctx, workspaceID, limit, offset)
 	if err != nil {
 		s.logger.Error("Failed to list content", zap.Error(err))
 		return nil, err
@@ -140,12 +214,12 @@ func (s *contentService) ListContent(ctx context.Context, limit, offset int) ([]
 	return contents, nil
 }
 
-func (s *contentService) ListContentByTopic(ctx context.Context, topicID uuid.UUID, limit, offset int) ([]*models.Content, error) {
+func (s *contentService) ListContentByTopic(ctx context.Context, workspaceID, topicID uuid.UUID, limit, offset int) ([]*models.Content, error) {
 	// Validate that topic exists
-	_, err := s.topicRepo.GetByID(ctx, topicID)
+	_, err := s.topicRepo.GetByID(ctx, workspaceID, topicID)
 	if err != nil {
 		s.logger.Error("Topic not found", zap.Error(err), zap.String("topic_id", topicID.String()))
-		return nil, fmt.Errorf("topic not found")
+		return nil, err
 	}
 
 	// Set default and max limits
@@ -159,7 +233,7 @@ func (s *contentService) ListContentByTopic(ctx context.Context, topicID uuid.UU
 		offset = constants.DefaultOffset
 	}
 
-	contents, err := s.contentRepo.ListByTopic(ctx, topicID, limit, offset)
+	contents, err := s.contentRepo.ListByTopic(ctx, workspaceID, topicID, limit, offset)
 	if err != nil {
 		s.logger.Error("Failed to list content by topic", zap.Error(err))
 		return nil, err
@@ -168,21 +242,26 @@ func (s *contentService) ListContentByTopic(ctx context.Context, topicID uuid.UU
 	return contents, nil
 }
 
-func (s *contentService) UpdateContent(ctx context.Context, id uuid.UUID, req *request.UpdateContentRequest) (*models.Content, error) {
+func (s *contentService) UpdateContent(ctx context.Context, workspaceID, id uuid.UUID, req *request.UpdateContentRequest) (*models.Content, error) {
 	// Validate and sanitize input
 	req.Subject = strings.TrimSpace(req.Subject)
 	if req.Subject == "" {
-		return nil, fmt.Errorf("subject cannot be empty")
+		return nil, apperr.Validation("subject cannot be empty", apperr.Field("subject", "cannot be empty"))
 	}
 
 	req.Body = strings.TrimSpace(req.Body)
 	if req.Body == "" {
-		return nil, fmt.Errorf("body cannot be empty")
+		return nil, apperr.Validation("body cannot be empty", apperr.Field("body", "cannot be empty"))
+	}
+
+	// Validate templates so that errors surface now rather than at send time
+	if err := templating.Validate(req.Subject, req.Body); err != nil {
+		return nil, err
 	}
 
 	// Validate send_at is in the future
 	if req.SendAt.Before(time.Now()) {
-		return nil, fmt.Errorf("send_at must be in the future")
+		return nil, apperr.Validation("send_at must be in the future", apperr.Field("send_at", "must be in the future"))
 	}
 
 	s.logger.Info("Updating content",
@@ -191,7 +270,7 @@ func (s *contentService) UpdateContent(ctx context.Context, id uuid.UUID, req *r
 		zap.Time("send_at", req.SendAt),
 	)
 
-	content, err := s.contentRepo.Update(ctx, id, req)
+	content, err := s.updateWithJobs(ctx, workspaceID, id, req)
 	if err != nil {
 		s.logger.Error("Failed to update content", zap.Error(err), zap.String("id", id.String()))
 		return nil, err
@@ -205,10 +284,173 @@ func (s *contentService) UpdateContent(ctx context.Context, id uuid.UUID, req *r
 	return content, nil
 }
 
-func (s *contentService) DeleteContent(ctx context.Context, id uuid.UUID) error {
+// RescheduleContent moves the send time of content that has not started sending
+func (s *contentService) RescheduleContent(ctx context.Context, workspaceID, id uuid.UUID, req *request.RescheduleContentRequest) (*models.Content, error) {
+	if req.SendAt.Before(time.Now()) {
+		return nil, apperr.Validation("send_at must be in the future", apperr.Field("send_at", "must be in the future"))
+	}
+
+	existing, err := s.contentRepo.GetByID(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Rescheduling content",
+		zap.String("id", id.String()),
+		zap.Time("old_send_at", existing.SendAt),
+		zap.Time("send_at", req.SendAt),
+	)
+
+	content, err := s.updateWithJobs(ctx, workspaceID, id, &request.UpdateContentRequest{
+		Subject:         existing.Subject,
+		Body:            existing.Body,
+		SendAt:          req.SendAt,
+		TrackingEnabled: existing.TrackingEnabled,
+	})
+	if err != nil {
+		s.logger.Error("Failed to reschedule content", zap.Error(err), zap.String("id", id.String()))
+		return nil, err
+	}
+
+	s.logger.Info("Content rescheduled successfully", zap.String("id", id.String()), zap.Time("send_at", content.SendAt))
+	return content, nil
+}
+
+// updateWithJobs updates content and moves its pending send jobs in one transaction.
+// It refuses once any job has been handed to the queue.
+func (s *contentService) updateWithJobs(ctx context.Context, workspaceID, id uuid.UUID, req *request.UpdateContentRequest) (*models.Content, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	content, err := s.contentRepo.UpdateTx(ctx, tx, workspaceID, id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := s.jobRepo.ListOpenByContentForUpdateTx(ctx, tx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, job := range jobs {
+		if job.Status != constants.JobStatusPending {
+			return nil, errContentSending
+		}
+	}
+
+	for _, job := range jobs {
+		// Time-zone jobs of local-time content keep firing at the local wall-clock time
+		scheduledAt := req.SendAt
+		if job.Timezone != nil {
+			if location, err := time.LoadLocation(*job.Timezone); err == nil {
+				scheduledAt = wallClockIn(req.SendAt, location)
+			}
+		}
+
+		if err := s.jobRepo.RescheduleTx(ctx, tx, job.ID, scheduledAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return content, nil
+}
+
+// CancelContent cancels scheduled content and its open jobs. Jobs already handed
+// to the queue are revoked; if one is already running, nothing is cancelled.
+func (s *contentService) CancelContent(ctx context.Context, workspaceID, id uuid.UUID) (*models.Content, error) {
+	content, err := s.contentRepo.GetByID(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if content.Status != constants.ContentStatusScheduled {
+		return nil, apperr.PreconditionFailed("content is not in scheduled status")
+	}
+
+	s.logger.Info("Cancelling content", zap.String("id", id.String()))
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	cancelled, revoked, err := s.cancelJobsTx(ctx, tx, workspaceID, id)
+	if err == nil {
+		err = s.contentRepo.CancelTx(ctx, tx, workspaceID, id)
+	}
+	if err == nil {
+		if err = tx.Commit(ctx); err != nil {
+			err = fmt.Errorf("failed to commit transaction: %w", err)
+		}
+	}
+	if err != nil {
+		tx.Rollback(ctx)
+		s.requeueRevoked(ctx, revoked)
+		return nil, err
+	}
+
+	s.logger.Info("Content cancelled successfully",
+		zap.String("id", id.String()),
+		zap.Int("cancelled_jobs", cancelled),
+	)
+
+	return s.contentRepo.GetByID(ctx, workspaceID, id)
+}
+
+// cancelJobsTx cancels the open jobs of the content, revoking tasks that are already
+// queued. It returns the number of cancelled jobs and the jobs whose task was revoked.
+func (s *contentService) cancelJobsTx(ctx context.Context, tx pgx.Tx, workspaceID, contentID uuid.UUID) (int, []uuid.UUID, error) {
+	jobs, err := s.jobRepo.ListOpenByContentForUpdateTx(ctx, tx, workspaceID, contentID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var revoked []uuid.UUID
+	for _, job := range jobs {
+		// Pending jobs have not reached the queue yet; anything else must be revoked
+		if job.Status != constants.JobStatusPending {
+			if job.TaskID == nil {
+				return 0, revoked, errContentSending
+			}
+			if err := s.queue.RevokeTask(*job.TaskID); err != nil {
+				if errors.Is(err, queue.ErrTaskNotRevocable) {
+					return 0, revoked, errContentSending
+				}
+				return 0, revoked, fmt.Errorf("failed to revoke task: %w", err)
+			}
+			revoked = append(revoked, job.ID)
+		}
+
+		if err := s.jobRepo.UpdateStatusTx(ctx, tx, job.ID, constants.JobStatusCancelled); err != nil {
+			return 0, revoked, err
+		}
+	}
+
+	return len(jobs), revoked, nil
+}
+
+// requeueRevoked returns jobs whose task was revoked by a cancellation that did not
+// go through to pending, so that the scheduler enqueues them again
+func (s *contentService) requeueRevoked(ctx context.Context, jobIDs []uuid.UUID) {
+	for _, jobID := range jobIDs {
+		if err := s.jobRepo.UpdateStatus(ctx, jobID, constants.JobStatusPending); err != nil {
+			s.logger.Error("Failed to requeue revoked job", zap.String("job_id", jobID.String()), zap.Error(err))
+		}
+	}
+}
+
+func (s *contentService) DeleteContent(ctx context.Context, workspaceID, id uuid.UUID) error {
 	s.logger.Info("Deleting content", zap.String("id", id.String()))
 
-	err := s.contentRepo.Delete(ctx, id)
+	err := s.contentRepo.Delete(ctx, workspaceID, id)
 	if err != nil {
 		s.logger.Error("Failed to delete content", zap.Error(err), zap.String("id", id.String()))
 		return err
@@ -218,19 +460,19 @@ func (s *contentService) DeleteContent(ctx context.Context, id uuid.UUID) error
 	return nil
 }
 
-func (s *contentService) ScheduleContent(ctx context.Context, contentID uuid.UUID) error {
+func (s *contentService) ScheduleContent(ctx context.Context, workspaceID, contentID uuid.UUID) error {
 	// Get the content to validate it exists and is in correct state
-	content, err := s.contentRepo.GetByID(ctx, contentID)
+	content, err := s.contentRepo.GetByID(ctx, workspaceID, contentID)
 	if err != nil {
 		return err
 	}
 
 	if content.Status != constants.ContentStatusScheduled {
-		return fmt.Errorf("content is not in scheduled status")
+		return apperr.PreconditionFailed("content is not in scheduled status")
 	}
 
 	if content.SendAt.After(time.Now()) {
-		return fmt.Errorf("content send time has not arrived yet")
+		return apperr.PreconditionFailed("content send time has not arrived yet")
 	}
 
 	s.logger.Info("Scheduling content for processing",
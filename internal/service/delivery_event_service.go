@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/repo"
+	"newsletter-assignment/internal/request"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type deliveryEventService struct {
+	deliveryRepo        repo.DeliveryRepository
+	deliveryEventRepo   repo.DeliveryEventRepository
+	contentRepo         repo.ContentRepository
+	suppressionRepo     repo.SuppressionRepository
+	subscriptionService SubscriptionService
+	logger              *zap.Logger
+}
+
+func NewDeliveryEventService(
+	deliveryRepo repo.DeliveryRepository,
+	deliveryEventRepo repo.DeliveryEventRepository,
+	contentRepo repo.ContentRepository,
+	suppressionRepo repo.SuppressionRepository,
+	subscriptionService SubscriptionService,
+	logger *zap.Logger,
+) DeliveryEventService {
+	return &deliveryEventService{
+		deliveryRepo:        deliveryRepo,
+		deliveryEventRepo:   deliveryEventRepo,
+		contentRepo:         contentRepo,
+		suppressionRepo:     suppressionRepo,
+		subscriptionService: subscriptionService,
+		logger:              logger,
+	}
+}
+
+// ProcessBrevoEvent applies a Brevo event to the delivery it concerns. Events that
+// cannot be matched to a delivery, such as those for confirmation emails, are ignored
+// so that the provider does not keep retrying them; only storage failures are returned.
+func (s *deliveryEventService) ProcessBrevoEvent(ctx context.Context, event *request.BrevoWebhookEvent) error {
+	if event.MessageID == "" {
+		s.logger.Debug("Ignoring provider event without message ID", zap.String("event", event.Event))
+		return nil
+	}
+
+	delivery, err := s.deliveryRepo.GetByProviderMessageID(ctx, event.MessageID)
+	if err != nil {
+		if errors.Is(err, apperr.ErrNotFound) {
+			s.logger.Debug("Ignoring provider event for unknown message",
+				zap.String("event", event.Event),
+				zap.String("message_id", event.MessageID),
+			)
+			return nil
+		}
+		return err
+	}
+
+	occurredAt := time.Now()
+	if event.TsEvent > 0 {
+		occurredAt = time.Unix(event.TsEvent, 0)
+	}
+
+	switch event.Event {
+	case constants.BrevoEventDelivered:
+		if err := s.deliveryRepo.MarkDelivered(ctx, delivery.WorkspaceID, delivery.ID, occurredAt); err != nil {
+			return err
+		}
+
+	case constants.BrevoEventHardBounce:
+		errorMsg := "hard bounce"
+		if event.Reason != "" {
+			errorMsg = fmt.Sprintf("hard bounce: %s", event.Reason)
+		}
+		if err := s.deliveryRepo.UpdateDeliveryFailure(ctx, delivery.WorkspaceID, delivery.ID, constants.DeliveryStatusBounced, &errorMsg, nil); err != nil {
+			return err
+		}
+		if err := s.suppress(ctx, delivery, constants.SuppressionReasonHardBounce); err != nil {
+			return err
+		}
+
+	case constants.BrevoEventSpam:
+		if err := s.suppress(ctx, delivery, constants.SuppressionReasonComplaint); err != nil {
+			return err
+		}
+
+	case constants.BrevoEventUnsubscribed:
+		if err := s.unsubscribe(ctx, delivery); err != nil {
+			return err
+		}
+
+	case constants.BrevoEventSoftBounce, constants.BrevoEventOpened, constants.BrevoEventClick:
+		// Brevo retries soft bounces itself; engagement events need no state change
+
+	default:
+		s.logger.Debug("Ignoring unsupported provider event", zap.String("event", event.Event))
+		return nil
+	}
+
+	s.logger.Info("Provider event processed",
+		zap.String("event", event.Event),
+		zap.String("delivery_id", delivery.ID.String()),
+		zap.String("reason", event.Reason),
+	)
+	return nil
+}
+
+// RecordOpen records that the tracking pixel of a delivery was loaded
+func (s *deliveryEventService) RecordOpen(ctx context.Context, workspaceID, deliveryID uuid.UUID, userAgent string) error {
+	return s.recordEngagement(ctx, workspaceID, deliveryID, constants.DeliveryEventOpen, nil, userAgent)
+}
+
+// RecordClick records that a tracked link of a delivery was followed
+func (s *deliveryEventService) RecordClick(ctx context.Context, workspaceID, deliveryID uuid.UUID, url, userAgent string) error {
+	return s.recordEngagement(ctx, workspaceID, deliveryID, constants.DeliveryEventClick, &url, userAgent)
+}
+
+func (s *deliveryEventService) recordEngagement(ctx context.Context, workspaceID, deliveryID uuid.UUID, eventType string, url *string, userAgent string) error {
+	var agent *string
+	if userAgent != "" {
+		if len(userAgent) > constants.MaxTrackedUserAgentLength {
+			userAgent = userAgent[:constants.MaxTrackedUserAgentLength]
+		}
+		agent = &userAgent
+	}
+
+	if _, err := s.deliveryEventRepo.Create(ctx, workspaceID, deliveryID, eventType, url, agent); err != nil {
+		s.logger.Error("Failed to record delivery event",
+			zap.String("event", eventType),
+			zap.String("delivery_id", deliveryID.String()),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	s.logger.Debug("Delivery event recorded",
+		zap.String("event", eventType),
+		zap.String("delivery_id", deliveryID.String()),
+	)
+	return nil
+}
+
+// suppress adds the delivery's address to its workspace suppression list. An address
+// that is already suppressed keeps its original entry, which makes replayed events harmless.
+func (s *deliveryEventService) suppress(ctx context.Context, delivery *models.Delivery, reason string) error {
+	_, err := s.suppressionRepo.Import(ctx, delivery.WorkspaceID, []string{delivery.Email}, []string{reason}, constants.SuppressionSourceProvider)
+	if err != nil {
+		s.logger.Error("Failed to suppress address from provider event",
+			zap.String("delivery_id", delivery.ID.String()),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// unsubscribe deactivates the subscription to the topic the delivered content belongs to
+func (s *deliveryEventService) unsubscribe(ctx context.Context, delivery *models.Delivery) error {
+	content, err := s.contentRepo.GetByID(ctx, delivery.WorkspaceID, delivery.ContentID)
+	if err != nil {
+		return err
+	}
+
+	err = s.subscriptionService.Unsubscribe(ctx, delivery.WorkspaceID, delivery.SubscriberID, content.TopicID)
+	if err != nil && !errors.Is(err, ErrSubscriptionInactive) && !errors.Is(err, apperr.ErrNotFound) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/repo"
+	"newsletter-assignment/internal/request"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// eventStore holds one delivery, the subscription it was sent for and the suppressions
+// recorded by provider events
+type eventStore struct {
+	delivery     *models.Delivery
+	messageID    string
+	content      *models.Content
+	subscription *models.Subscription
+	suppressed   map[string]string
+	delivered    *time.Time
+}
+
+type eventDeliveryRepo struct {
+	repo.DeliveryRepository
+	s *eventStore
+}
+
+func (r *eventDeliveryRepo) GetByProviderMessageID(ctx context.Context, providerMessageID string) (*models.Delivery, error) {
+	if providerMessageID != r.s.messageID {
+		return nil, apperr.NotFound("delivery not found")
+	}
+	delivery := *r.s.delivery
+	return &delivery, nil
+}
+
+func (r *eventDeliveryRepo) MarkDelivered(ctx context.Context, workspaceID, id uuid.UUID, deliveredAt time.Time) error {
+	r.s.delivered = &deliveredAt
+	return nil
+}
+
+func (r *eventDeliveryRepo) UpdateDeliveryFailure(ctx context.Context, workspaceID, id uuid.UUID, status string, errorMessage *string, nextRetryAt *time.Time) error {
+	r.s.delivery.Status = status
+	r.s.delivery.ErrorMessage = errorMessage
+	return nil
+}
+
+type eventContentRepo struct {
+	repo.ContentRepository
+	s *eventStore
+}
+
+func (r *eventContentRepo) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Content, error) {
+	return r.s.content, nil
+}
+
+type eventSuppressionRepo struct {
+	repo.SuppressionRepository
+	s *eventStore
+}
+
+func (r *eventSuppressionRepo) Import(ctx context.Context, workspaceID uuid.UUID, emails, reasons []string, source string) (int, error) {
+	imported := 0
+	for i, address := range emails {
+		if _, ok := r.s.suppressed[address]; !ok {
+			r.s.suppressed[address] = reasons[i]
+			imported++
+		}
+	}
+	return imported, nil
+}
+
+type eventSubscriptionRepo struct {
+	repo.SubscriptionRepository
+	s *eventStore
+}
+
+func (r *eventSubscriptionRepo) GetBySubscriberAndTopic(ctx context.Context, workspaceID, subscriberID, topicID uuid.UUID) (*models.Subscription, error) {
+	subscription := r.s.subscription
+	if subscription.WorkspaceID != workspaceID || subscription.SubscriberID != subscriberID || subscription.TopicID != topicID {
+		return nil, apperr.NotFound("subscription not found")
+	}
+	found := *subscription
+	return &found, nil
+}
+
+func (r *eventSubscriptionRepo) UpdateStatus(ctx context.Context, workspaceID, id uuid.UUID, status string, confirmationExpiresAt *time.Time) (*models.Subscription, error) {
+	r.s.subscription.Status = status
+	r.s.subscription.IsActive = status == constants.SubscriptionStatusActive
+	subscription := *r.s.subscription
+	return &subscription, nil
+}
+
+func newEventStore() *eventStore {
+	workspaceID := uuid.New()
+	content := &models.Content{ID: uuid.New(), WorkspaceID: workspaceID, TopicID: uuid.New()}
+	delivery := &models.Delivery{
+		ID:           uuid.New(),
+		WorkspaceID:  workspaceID,
+		ContentID:    content.ID,
+		SubscriberID: uuid.New(),
+		Email:        "jane@example.com",
+		Status:       constants.DeliveryStatusSent,
+	}
+	return &eventStore{
+		delivery:  delivery,
+		messageID: "<202601010900.1@smtp-relay.mailin.fr>",
+		content:   content,
+		subscription: &models.Subscription{
+			ID:           uuid.New(),
+			WorkspaceID:  workspaceID,
+			SubscriberID: delivery.SubscriberID,
+			TopicID:      content.TopicID,
+			IsActive:     true,
+			Status:       constants.SubscriptionStatusActive,
+		},
+		suppressed: make(map[string]string),
+	}
+}
+
+func newEventService(s *eventStore) DeliveryEventService {
+	subscriptions := &subscriptionService{subscriptionRepo: &eventSubscriptionRepo{s: s}, logger: zap.NewNop()}
+	return NewDeliveryEventService(
+		&eventDeliveryRepo{s: s},
+		nil,
+		&eventContentRepo{s: s},
+		&eventSuppressionRepo{s: s},
+		subscriptions,
+		zap.NewNop(),
+	)
+}
+
+func TestProcessBrevoEvent(t *testing.T) {
+	tests := []struct {
+		name           string
+		event          string
+		reason         string
+		wantStatus     string
+		wantError      string
+		wantSuppressed string
+		wantDelivered  bool
+		wantInactive   bool
+	}{
+		{name: "delivered", event: constants.BrevoEventDelivered, wantStatus: constants.DeliveryStatusSent, wantDelivered: true},
+		{name: "hard bounce", event: constants.BrevoEventHardBounce, reason: "mailbox does not exist", wantStatus: constants.DeliveryStatusBounced, wantError: "hard bounce: mailbox does not exist", wantSuppressed: constants.SuppressionReasonHardBounce},
+		{name: "hard bounce without reason", event: constants.BrevoEventHardBounce, wantStatus: constants.DeliveryStatusBounced, wantError: "hard bounce", wantSuppressed: constants.SuppressionReasonHardBounce},
+		{name: "spam", event: constants.BrevoEventSpam, wantStatus: constants.DeliveryStatusSent, wantSuppressed: constants.SuppressionReasonComplaint},
+		{name: "unsubscribed", event: constants.BrevoEventUnsubscribed, wantStatus: constants.DeliveryStatusSent, wantInactive: true},
+		{name: "soft bounce", event: constants.BrevoEventSoftBounce, wantStatus: constants.DeliveryStatusSent},
+		{name: "opened", event: constants.BrevoEventOpened, wantStatus: constants.DeliveryStatusSent},
+		{name: "unsupported event", event: "proxy_open", wantStatus: constants.DeliveryStatusSent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newEventStore()
+
+			err := newEventService(s).ProcessBrevoEvent(context.Background(), &request.BrevoWebhookEvent{
+				Event:     tt.event,
+				Email:     s.delivery.Email,
+				MessageID: s.messageID,
+				Reason:    tt.reason,
+				TsEvent:   1767258000,
+			})
+			if err != nil {
+				t.Fatalf("ProcessBrevoEvent() error = %v", err)
+			}
+
+			if s.delivery.Status != tt.wantStatus {
+				t.Errorf("delivery status = %q, want %q", s.delivery.Status, tt.wantStatus)
+			}
+			if tt.wantError != "" && (s.delivery.ErrorMessage == nil || *s.delivery.ErrorMessage != tt.wantError) {
+				t.Errorf("delivery error = %v, want %q", s.delivery.ErrorMessage, tt.wantError)
+			}
+			if reason := s.suppressed[s.delivery.Email]; reason != tt.wantSuppressed {
+				t.Errorf("suppression reason = %q, want %q", reason, tt.wantSuppressed)
+			}
+			if delivered := s.delivered != nil; delivered != tt.wantDelivered {
+				t.Errorf("marked delivered = %t, want %t", delivered, tt.wantDelivered)
+			}
+			if tt.wantDelivered && !s.delivered.Equal(time.Unix(1767258000, 0)) {
+				t.Errorf("delivered at %v, want the event time", s.delivered)
+			}
+			if inactive := s.subscription.Status == constants.SubscriptionStatusUnsubscribed; inactive != tt.wantInactive {
+				t.Errorf("subscription status = %q, unsubscribed want %t", s.subscription.Status, tt.wantInactive)
+			}
+		})
+	}
+}
+
+func TestProcessBrevoEventIgnoresUnmatchedEvents(t *testing.T) {
+	tests := []struct {
+		name      string
+		messageID string
+	}{
+		{name: "unknown message", messageID: "<unknown@smtp-relay.mailin.fr>"},
+		{name: "no message ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newEventStore()
+
+			err := newEventService(s).ProcessBrevoEvent(context.Background(), &request.BrevoWebhookEvent{
+				Event:     constants.BrevoEventHardBounce,
+				Email:     s.delivery.Email,
+				MessageID: tt.messageID,
+			})
+			if err != nil {
+				t.Fatalf("ProcessBrevoEvent() error = %v", err)
+			}
+			if s.delivery.Status != constants.DeliveryStatusSent || len(s.suppressed) != 0 {
+				t.Errorf("unmatched event changed the delivery to %q and suppressed %v", s.delivery.Status, s.suppressed)
+			}
+		})
+	}
+}
+
+func TestProcessBrevoEventUnsubscribeIsIdempotent(t *testing.T) {
+	s := newEventStore()
+	s.subscription.Status = constants.SubscriptionStatusUnsubscribed
+	s.subscription.IsActive = false
+
+	err := newEventService(s).ProcessBrevoEvent(context.Background(), &request.BrevoWebhookEvent{
+		Event:     constants.BrevoEventUnsubscribed,
+		MessageID: s.messageID,
+	})
+	if err != nil {
+		t.Errorf("replayed unsubscribe error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/repo"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type deliveryService struct {
+	contentRepo       repo.ContentRepository
+	topicRepo         repo.TopicRepository
+	deliveryRepo      repo.DeliveryRepository
+	deliveryEventRepo repo.DeliveryEventRepository
+	logger            *zap.Logger
+}
+
+func NewDeliveryService(
+	contentRepo repo.ContentRepository,
+	topicRepo repo.TopicRepository,
+	deliveryRepo repo.DeliveryRepository,
+	deliveryEventRepo repo.DeliveryEventRepository,
+	logger *zap.Logger,
+) DeliveryService {
+	return &deliveryService{
+		contentRepo:       contentRepo,
+		topicRepo:         topicRepo,
+		deliveryRepo:      deliveryRepo,
+		deliveryEventRepo: deliveryEventRepo,
+		logger:            logger,
+	}
+}
+
+// ListContentDeliveries returns a page of a content's deliveries, optionally limited
+// to some statuses, starting after the delivery ID given as cursor
+func (s *deliveryService) ListContentDeliveries(ctx context.Context, workspaceID, contentID uuid.UUID, statuses []string, after uuid.UUID, limit int) ([]*models.Delivery, error) {
+	for _, status := range statuses {
+		if !slices.Contains(constants.DeliveryStatuses, status) {
+			return nil, apperr.Validation(fmt.Sprintf("unknown delivery status '%s'", status), apperr.Field("status", "is not a delivery status"))
+		}
+	}
+	if limit <= 0 || limit > constants.MaxDeliveryPageSize {
+		return nil, apperr.Validation("invalid limit parameter", apperr.Field("limit", fmt.Sprintf("must be between 1 and %d", constants.MaxDeliveryPageSize)))
+	}
+
+	// Deliveries of unknown content would be an empty page; report the content as missing instead
+	if _, err := s.contentRepo.GetByID(ctx, workspaceID, contentID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.deliveryRepo.ListDeliveriesByContent(ctx, workspaceID, contentID, statuses, after, limit)
+	if err != nil {
+		s.logger.Error("Failed to list deliveries", zap.String("content_id", contentID.String()), zap.Error(err))
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// GetContentReport aggregates the deliveries of a content. Engagement is only
+// reported for content sent with open and click tracking.
+func (s *deliveryService) GetContentReport(ctx context.Context, workspaceID, contentID uuid.UUID) (*models.DeliveryReport, error) {
+	content, err := s.contentRepo.GetByID(ctx, workspaceID, contentID)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.deliveryRepo.ReportByContent(ctx, workspaceID, contentID, constants.DeliveryReportMaxFailureReasons)
+	if err != nil {
+		s.logger.Error("Failed to build delivery report", zap.String("content_id", contentID.String()), zap.Error(err))
+		return nil, err
+	}
+
+	if report.FirstSentAt != nil && report.LastSentAt != nil {
+		duration := report.LastSentAt.Sub(*report.FirstSentAt).Seconds()
+		report.SendDurationSeconds = &duration
+	}
+
+	tracked, err := s.trackingEnabled(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+	if !tracked {
+		return report, nil
+	}
+
+	engagement, err := s.deliveryEventRepo.CountByContent(ctx, workspaceID, contentID)
+	if err != nil {
+		s.logger.Error("Failed to count delivery events", zap.String("content_id", contentID.String()), zap.Error(err))
+		return nil, err
+	}
+
+	if sent := report.ByStatus[constants.DeliveryStatusSent]; sent > 0 {
+		engagement.OpenRate = float64(engagement.UniqueOpens) / float64(sent)
+		engagement.ClickRate = float64(engagement.UniqueClicks) / float64(sent)
+	}
+	report.Engagement = engagement
+
+	return report, nil
+}
+
+// trackingEnabled reports whether the content is sent with open and click tracking;
+// the content's own setting wins over its topic's
+func (s *deliveryService) trackingEnabled(ctx context.Context, content *models.Content) (bool, error) {
+	if content.TrackingEnabled != nil {
+		return *content.TrackingEnabled, nil
+	}
+
+	topic, err := s.topicRepo.GetByID(ctx, content.WorkspaceID, content.TopicID)
+	if err != nil {
+		return false, err
+	}
+	return topic.TrackingEnabled, nil
+}
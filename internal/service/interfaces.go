@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"newsletter-assignment/internal/models"
 	"newsletter-assignment/internal/request"
@@ -11,41 +12,106 @@ import (
 
 // TopicService defines the interface for topic business logic
 type TopicService interface {
-	CreateTopic(ctx context.Context, req *request.CreateTopicRequest) (*models.Topic, error)
-	GetTopic(ctx context.Context, id uuid.UUID) (*models.Topic, error)
-	GetTopicByName(ctx context.Context, name string) (*models.Topic, error)
-	ListTopics(ctx context.Context, limit, offset int) ([]*models.Topic, error)
-	UpdateTopic(ctx context.Context, id uuid.UUID, req *request.UpdateTopicRequest) (*models.Topic, error)
-	DeleteTopic(ctx context.Context, id uuid.UUID) error
+	CreateTopic(ctx context.Context, workspaceID uuid.UUID, req *request.CreateTopicRequest) (*models.Topic, error)
+	GetTopic(ctx context.Context, workspaceID, id uuid.UUID) (*models.Topic, error)
+	GetTopicByName(ctx context.Context, workspaceID uuid.UUID, name string) (*models.Topic, error)
+	ListTopics(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.Topic, error)
+	UpdateTopic(ctx context.Context, workspaceID, id uuid.UUID, req *request.UpdateTopicRequest) (*models.Topic, error)
+	DeleteTopic(ctx context.Context, workspaceID, id uuid.UUID) error
 }
 
 // SubscriberService defines the interface for subscriber business logic
 type SubscriberService interface {
-	CreateSubscriber(ctx context.Context, req *request.CreateSubscriberRequest) (*models.Subscriber, error)
-	GetSubscriber(ctx context.Context, id uuid.UUID) (*models.Subscriber, error)
-	GetSubscriberByEmail(ctx context.Context, email string) (*models.Subscriber, error)
-	ListSubscribers(ctx context.Context, limit, offset int) ([]*models.Subscriber, error)
-	UpdateSubscriber(ctx context.Context, id uuid.UUID, req *request.UpdateSubscriberRequest) (*models.Subscriber, error)
-	DeleteSubscriber(ctx context.Context, id uuid.UUID) error
+	CreateSubscriber(ctx context.Context, workspaceID uuid.UUID, req *request.CreateSubscriberRequest) (*models.Subscriber, error)
+	GetSubscriber(ctx context.Context, workspaceID, id uuid.UUID) (*models.Subscriber, error)
+	GetSubscriberByEmail(ctx context.Context, workspaceID uuid.UUID, email string) (*models.Subscriber, error)
+	ListSubscribers(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.Subscriber, error)
+	UpdateSubscriber(ctx context.Context, workspaceID, id uuid.UUID, req *request.UpdateSubscriberRequest) (*models.Subscriber, error)
+	DeleteSubscriber(ctx context.Context, workspaceID, id uuid.UUID) error
 }
 
 // SubscriptionService defines the interface for subscription business logic
 type SubscriptionService interface {
-	Subscribe(ctx context.Context, req *request.CreateSubscriptionRequest) (*models.Subscription, error)
-	Unsubscribe(ctx context.Context, subscriberID, topicID uuid.UUID) error
-	GetSubscription(ctx context.Context, id uuid.UUID) (*models.Subscription, error)
-	ListSubscriberTopics(ctx context.Context, subscriberID uuid.UUID) ([]*models.Subscription, error)
-	ListTopicSubscribers(ctx context.Context, topicID uuid.UUID) ([]*models.Subscription, error)
+	Subscribe(ctx context.Context, workspaceID uuid.UUID, req *request.CreateSubscriptionRequest) (*models.Subscription, error)
+	Unsubscribe(ctx context.Context, workspaceID, subscriberID, topicID uuid.UUID) error
+	ConfirmSubscription(ctx context.Context, workspaceID, id uuid.UUID) (*models.Subscription, error)
+	ExpirePendingConfirmations(ctx context.Context, now time.Time) (int, error)
+	GetSubscription(ctx context.Context, workspaceID, id uuid.UUID) (*models.Subscription, error)
+	ListSubscriberTopics(ctx context.Context, workspaceID, subscriberID uuid.UUID) ([]*models.Subscription, error)
+	ListTopicSubscribers(ctx context.Context, workspaceID, topicID uuid.UUID) ([]*models.Subscription, error)
 }
 
 // ContentService defines the interface for content business logic
 type ContentService interface {
-	CreateContent(ctx context.Context, req *request.CreateContentRequest) (*models.Content, error)
-	GetContent(ctx context.Context, id uuid.UUID) (*models.Content, error)
-	ListContent(ctx context.Context, limit, offset int) ([]*models.Content, error)
-	ListContentByTopic(ctx context.Context, topicID uuid.UUID, limit, offset int) ([]*models.Content, error)
-	UpdateContent(ctx context.Context, id uuid.UUID, req *request.UpdateContentRequest) (*models.Content, error)
-	DeleteContent(ctx context.Context, id uuid.UUID) error
-	ScheduleContent(ctx context.Context, contentID uuid.UUID) error
+	CreateContent(ctx context.Context, workspaceID uuid.UUID, req *request.CreateContentRequest) (*models.Content, error)
+	GetContent(ctx context.Context, workspaceID, id uuid.UUID) (*models.Content, error)
+	ListContent(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.Content, error)
+	ListContentByTopic(ctx context.Context, workspaceID, topicID uuid.UUID, limit, offset int) ([]*models.Content, error)
+	UpdateContent(ctx context.Context, workspaceID, id uuid.UUID, req *request.UpdateContentRequest) (*models.Content, error)
+	RescheduleContent(ctx context.Context, workspaceID, id uuid.UUID, req *request.RescheduleContentRequest) (*models.Content, error)
+	CancelContent(ctx context.Context, workspaceID, id uuid.UUID) (*models.Content, error)
+	DeleteContent(ctx context.Context, workspaceID, id uuid.UUID) error
+	ScheduleContent(ctx context.Context, workspaceID, contentID uuid.UUID) error
 }
 
+// RecurringScheduleService defines the interface for recurring schedule business logic
+type RecurringScheduleService interface {
+	CreateSchedule(ctx context.Context, workspaceID uuid.UUID, req *request.CreateRecurringScheduleRequest) (*models.RecurringSchedule, error)
+	GetSchedule(ctx context.Context, workspaceID, id uuid.UUID) (*models.RecurringSchedule, error)
+	ListSchedules(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.RecurringSchedule, error)
+	UpdateSchedule(ctx context.Context, workspaceID, id uuid.UUID, req *request.UpdateRecurringScheduleRequest) (*models.RecurringSchedule, error)
+	DeleteSchedule(ctx context.Context, workspaceID, id uuid.UUID) error
+	PauseSchedule(ctx context.Context, workspaceID, id uuid.UUID) (*models.RecurringSchedule, error)
+	ResumeSchedule(ctx context.Context, workspaceID, id uuid.UUID) (*models.RecurringSchedule, error)
+	PreviewSchedule(ctx context.Context, workspaceID, id uuid.UUID, count int) ([]time.Time, error)
+	PreviewExpression(expression, timezone string, count int) ([]time.Time, error)
+	ExpandDue(ctx context.Context, until time.Time, limit int) (int, error)
+}
+
+// SuppressionService defines the interface for suppression list management
+type SuppressionService interface {
+	CreateSuppression(ctx context.Context, workspaceID uuid.UUID, req *request.CreateSuppressionRequest) (*models.Suppression, error)
+	ImportSuppressions(ctx context.Context, workspaceID uuid.UUID, req *request.ImportSuppressionsRequest) (int, error)
+	GetSuppression(ctx context.Context, workspaceID, id uuid.UUID) (*models.Suppression, error)
+	ListSuppressions(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.Suppression, error)
+	DeleteSuppression(ctx context.Context, workspaceID, id uuid.UUID) error
+}
+
+// DeliveryService defines the interface for delivery listings and reports
+type DeliveryService interface {
+	ListContentDeliveries(ctx context.Context, workspaceID, contentID uuid.UUID, statuses []string, after uuid.UUID, limit int) ([]*models.Delivery, error)
+	GetContentReport(ctx context.Context, workspaceID, contentID uuid.UUID) (*models.DeliveryReport, error)
+}
+
+// JobService defines the interface for inspecting and manually intervening on jobs
+type JobService interface {
+	ListJobs(ctx context.Context, workspaceID uuid.UUID, filter request.JobFilter, limit, offset int) ([]*models.JobScheduler, error)
+	GetJob(ctx context.Context, workspaceID, id uuid.UUID) (*models.JobDetails, error)
+	RetryJob(ctx context.Context, workspaceID, id uuid.UUID, actorID *uuid.UUID, req *request.RetryJobRequest) (*models.JobScheduler, error)
+	FailJob(ctx context.Context, workspaceID, id uuid.UUID, actorID *uuid.UUID, req *request.FailJobRequest) (*models.JobScheduler, error)
+	DeleteJob(ctx context.Context, workspaceID, id uuid.UUID, actorID *uuid.UUID) error
+	FinalizeIfLastJob(ctx context.Context, workspaceID, contentID uuid.UUID)
+}
+
+// DeliveryEventService defines the interface for processing email provider events
+type DeliveryEventService interface {
+	ProcessBrevoEvent(ctx context.Context, event *request.BrevoWebhookEvent) error
+	RecordOpen(ctx context.Context, workspaceID, deliveryID uuid.UUID, userAgent string) error
+	RecordClick(ctx context.Context, workspaceID, deliveryID uuid.UUID, url, userAgent string) error
+}
+
+// APIKeyService defines the interface for API key management and authentication
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, workspaceID uuid.UUID, req *request.CreateAPIKeyRequest) (*models.APIKey, string, error)
+	ListAPIKeys(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, workspaceID, id uuid.UUID) error
+	EnsureAPIKey(ctx context.Context, workspaceID uuid.UUID, name, rawKey, role string) error
+	Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error)
+}
+
+// WorkspaceService defines the interface for workspace management
+type WorkspaceService interface {
+	CreateWorkspace(ctx context.Context, req *request.CreateWorkspaceRequest) (*models.Workspace, *models.APIKey, string, error)
+	GetWorkspace(ctx context.Context, id uuid.UUID) (*models.Workspace, error)
+	UpdateWorkspace(ctx context.Context, id uuid.UUID, req *request.UpdateWorkspaceRequest) (*models.Workspace, error)
+}
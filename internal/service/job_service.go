@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/db"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/queue"
+	"newsletter-assignment/internal/repo"
+	"newsletter-assignment/internal/request"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// errJobRunning is returned when a job's task is being processed by a worker
+var errJobRunning = apperr.Conflict("job is running")
+
+type jobService struct {
+	jobRepo      repo.JobRepository
+	jobAuditRepo repo.JobAuditRepository
+	contentRepo  repo.ContentRepository
+	deliveryRepo repo.DeliveryRepository
+	queue        queue.Queue
+	db           *db.DB
+	logger       *zap.Logger
+}
+
+func NewJobService(
+	jobRepo repo.JobRepository,
+	jobAuditRepo repo.JobAuditRepository,
+	contentRepo repo.ContentRepository,
+	deliveryRepo repo.DeliveryRepository,
+	jobQueue queue.Queue,
+	database *db.DB,
+	logger *zap.Logger,
+) JobService {
+	return &jobService{
+		jobRepo:      jobRepo,
+		jobAuditRepo: jobAuditRepo,
+		contentRepo:  contentRepo,
+		deliveryRepo: deliveryRepo,
+		queue:        jobQueue,
+		db:           database,
+		logger:       logger,
+	}
+}
+
+func (s *jobService) ListJobs(ctx context.Context, workspaceID uuid.UUID, filter request.JobFilter, limit, offset int) ([]*models.JobScheduler, error) {
+	if filter.Status != "" && !slices.Contains(constants.JobStatuses, filter.Status) {
+		return nil, apperr.Validation(fmt.Sprintf("unknown job status '%s'", filter.Status), apperr.Field("status", "is not a job status"))
+	}
+	if filter.JobType != "" && !slices.Contains(constants.JobTypes, filter.JobType) {
+		return nil, apperr.Validation(fmt.Sprintf("unknown job type '%s'", filter.JobType), apperr.Field("job_type", "is not a job type"))
+	}
+
+	if limit <= 0 {
+		limit = constants.DefaultLimit
+	}
+	if limit > constants.MaxLimit {
+		limit = constants.MaxLimit
+	}
+	if offset < 0 {
+		offset = constants.DefaultOffset
+	}
+
+	return s.jobRepo.List(ctx, workspaceID, filter, limit, offset)
+}
+
+// GetJob returns a job of the workspace with its error history and audit log
+func (s *jobService) GetJob(ctx context.Context, workspaceID, id uuid.UUID) (*models.JobDetails, error) {
+	job, err := s.getJob(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	jobErrors, err := s.jobRepo.ListErrors(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	auditLog, err := s.jobAuditRepo.ListByJob(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.JobDetails{JobScheduler: job, Errors: jobErrors, AuditLog: auditLog}, nil
+}
+
+// RetryJob returns a failed job to pending so that the scheduler enqueues it again.
+// A job that used all of its attempts is only retried with override, which grants
+// it one more attempt.
+func (s *jobService) RetryJob(ctx context.Context, workspaceID, id uuid.UUID, actorID *uuid.UUID, req *request.RetryJobRequest) (*models.JobScheduler, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	job, err := s.jobRepo.GetByIDForUpdateTx(ctx, tx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status != constants.JobStatusFailed {
+		return nil, apperr.PreconditionFailed("only failed jobs can be retried")
+	}
+
+	maxAttempts := job.MaxAttempts
+	if job.Attempts >= job.MaxAttempts {
+		if !req.Override {
+			return nil, apperr.PreconditionFailed("job has used all of its attempts",
+				apperr.Field("override", "must be true to retry a job with no attempts left"))
+		}
+		maxAttempts = job.Attempts + 1
+	}
+
+	// The content or delivery must be processable again or the retry would be skipped
+	switch job.JobType {
+	case constants.JobTypeSendNewsletter:
+		if err := s.reopenContentTx(ctx, tx, job); err != nil {
+			return nil, err
+		}
+	case constants.JobTypeRetryDelivery:
+		if job.DeliveryID != nil {
+			if err := s.deliveryRepo.RearmRetryTx(ctx, tx, workspaceID, *job.DeliveryID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// A failed job with attempts left may still have an Asynq retry waiting
+	if err := s.revokeTask(job); err != nil {
+		return nil, err
+	}
+
+	if err := s.jobRepo.ResetForRetryTx(ctx, tx, id, maxAttempts); err != nil {
+		return nil, err
+	}
+
+	if err := s.auditTx(ctx, tx, job, constants.JobAuditActionRetry, actorID, constants.JobStatusPending, req.Reason); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logger.Info("Job retried manually",
+		zap.String("job_id", id.String()),
+		zap.Int("attempts", job.Attempts),
+		zap.Int("max_attempts", maxAttempts),
+	)
+
+	return s.jobRepo.GetByID(ctx, id)
+}
+
+// FailJob marks an unfinished job as failed with no attempts left. Its queued task
+// is revoked; a job that is being processed cannot be failed.
+func (s *jobService) FailJob(ctx context.Context, workspaceID, id uuid.UUID, actorID *uuid.UUID, req *request.FailJobRequest) (*models.JobScheduler, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	job, err := s.jobRepo.GetByIDForUpdateTx(ctx, tx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isUnfinished(job) {
+		return nil, apperr.PreconditionFailed("job has already finished")
+	}
+
+	if err := s.revokeTask(job); err != nil {
+		return nil, err
+	}
+
+	if err := s.jobRepo.FailTx(ctx, tx, id, "Failed manually: "+req.Reason); err != nil {
+		return nil, err
+	}
+
+	if err := s.auditTx(ctx, tx, job, constants.JobAuditActionFail, actorID, constants.JobStatusFailed, &req.Reason); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logger.Info("Job failed manually", zap.String("job_id", id.String()))
+
+	if job.JobType == constants.JobTypeSendNewsletter {
+		s.FinalizeIfLastJob(ctx, workspaceID, job.ContentID)
+	}
+
+	return s.jobRepo.GetByID(ctx, id)
+}
+
+// DeleteJob deletes a job, revoking its queued task. A job that is being processed
+// cannot be deleted.
+func (s *jobService) DeleteJob(ctx context.Context, workspaceID, id uuid.UUID, actorID *uuid.UUID) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	job, err := s.jobRepo.GetByIDForUpdateTx(ctx, tx, workspaceID, id)
+	if err != nil {
+		return err
+	}
+
+	unfinished := isUnfinished(job)
+	if unfinished {
+		if err := s.revokeTask(job); err != nil {
+			return err
+		}
+	}
+
+	if err := s.auditTx(ctx, tx, job, constants.JobAuditActionDelete, actorID, "", nil); err != nil {
+		return err
+	}
+
+	if err := s.jobRepo.DeleteTx(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logger.Info("Job deleted manually", zap.String("job_id", id.String()))
+
+	if unfinished && job.JobType == constants.JobTypeSendNewsletter {
+		s.FinalizeIfLastJob(ctx, workspaceID, job.ContentID)
+	}
+
+	return nil
+}
+
+// getJob returns a job only if it belongs to the workspace
+func (s *jobService) getJob(ctx context.Context, workspaceID, id uuid.UUID) (*models.JobScheduler, error) {
+	job, err := s.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.WorkspaceID != workspaceID {
+		return nil, apperr.NotFound("job not found")
+	}
+
+	return job, nil
+}
+
+// isUnfinished reports whether a job is waiting, running or due for another attempt
+func isUnfinished(job *models.JobScheduler) bool {
+	switch job.Status {
+	case constants.JobStatusPending, constants.JobStatusEnqueued:
+		return true
+	case constants.JobStatusFailed:
+		return job.Attempts < job.MaxAttempts
+	}
+	return false
+}
+
+// revokeTask removes the Asynq task of a job from the queue. A task that is gone is
+// ignored; one that is running means the job is running, whatever its status says,
+// since a failed job is retried without its status changing first.
+func (s *jobService) revokeTask(job *models.JobScheduler) error {
+	if job.Status == constants.JobStatusPending || job.TaskID == nil {
+		return nil
+	}
+
+	err := s.queue.RevokeTask(*job.TaskID)
+	switch {
+	case err == nil, errors.Is(err, queue.ErrTaskNotFound):
+		return nil
+	case errors.Is(err, queue.ErrTaskNotRevocable):
+		return errJobRunning
+	default:
+		return fmt.Errorf("failed to revoke task: %w", err)
+	}
+}
+
+// reopenContentTx returns finished content to scheduled for a retried send job
+func (s *jobService) reopenContentTx(ctx context.Context, tx pgx.Tx, job *models.JobScheduler) error {
+	content, err := s.contentRepo.GetByID(ctx, job.WorkspaceID, job.ContentID)
+	if err != nil {
+		return err
+	}
+
+	switch content.Status {
+	case constants.ContentStatusScheduled:
+		return nil
+	case constants.ContentStatusCancelled:
+		return apperr.PreconditionFailed("content was cancelled")
+	}
+
+	return s.contentRepo.ReopenTx(ctx, tx, job.WorkspaceID, job.ContentID)
+}
+
+func (s *jobService) auditTx(ctx context.Context, tx pgx.Tx, job *models.JobScheduler, action string, actorID *uuid.UUID, newStatus string, reason *string) error {
+	entry := &models.JobAuditEntry{
+		WorkspaceID:    job.WorkspaceID,
+		JobID:          job.ID,
+		Action:         action,
+		APIKeyID:       actorID,
+		PreviousStatus: job.Status,
+		Reason:         reason,
+	}
+	if newStatus != "" {
+		entry.NewStatus = &newStatus
+	}
+
+	_, err := s.jobAuditRepo.CreateTx(ctx, tx, entry)
+	return err
+}
+
+// FinalizeIfLastJob sets the final status of scheduled content once none of its
+// send jobs is left to run. Content that delivered nothing is failed.
+func (s *jobService) FinalizeIfLastJob(ctx context.Context, workspaceID, contentID uuid.UUID) {
+	content, err := s.contentRepo.GetByID(ctx, workspaceID, contentID)
+	if err != nil || content.Status != constants.ContentStatusScheduled {
+		return
+	}
+
+	unfinished, err := s.jobRepo.CountUnfinishedSendJobs(ctx, workspaceID, contentID)
+	if err != nil {
+		s.logger.Error("Failed to count unfinished send jobs", zap.String("content_id", contentID.String()), zap.Error(err))
+		return
+	}
+	if unfinished > 0 {
+		return
+	}
+
+	totals, err := s.deliveryRepo.CountByContent(ctx, workspaceID, contentID)
+	if err != nil {
+		s.logger.Error("Failed to count deliveries", zap.String("content_id", contentID.String()), zap.Error(err))
+		return
+	}
+
+	if err := s.contentRepo.UpdateStatusWithCounts(ctx, workspaceID, contentID, totals.ContentStatus(), totals); err != nil {
+		s.logger.Error("Failed to update content status", zap.String("content_id", contentID.String()), zap.Error(err))
+	}
+}
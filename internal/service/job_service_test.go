@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/queue"
+	"newsletter-assignment/internal/repo"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakeQueue answers RevokeTask with a fixed error
+type fakeQueue struct {
+	queue.Queue
+	revokeErr error
+	revoked   []string
+}
+
+func (q *fakeQueue) RevokeTask(taskID string) error {
+	q.revoked = append(q.revoked, taskID)
+	return q.revokeErr
+}
+
+func TestRevokeTask(t *testing.T) {
+	taskID := "task-1"
+	boom := errors.New("redis unavailable")
+
+	tests := []struct {
+		name      string
+		status    string
+		taskID    *string
+		revokeErr error
+		wantErr   error
+		wantCall  bool
+	}{
+		{name: "pending job has no task", status: constants.JobStatusPending, taskID: &taskID},
+		{name: "job without task", status: constants.JobStatusEnqueued},
+		{name: "enqueued job revoked", status: constants.JobStatusEnqueued, taskID: &taskID, wantCall: true},
+		{name: "task already gone", status: constants.JobStatusEnqueued, taskID: &taskID, revokeErr: queue.ErrTaskNotFound, wantCall: true},
+		{name: "enqueued job running", status: constants.JobStatusEnqueued, taskID: &taskID, revokeErr: queue.ErrTaskNotRevocable, wantErr: errJobRunning, wantCall: true},
+		{name: "failed job running its retry", status: constants.JobStatusFailed, taskID: &taskID, revokeErr: queue.ErrTaskNotRevocable, wantErr: errJobRunning, wantCall: true},
+		{name: "completed job still running", status: constants.JobStatusCompleted, taskID: &taskID, revokeErr: queue.ErrTaskNotRevocable, wantErr: errJobRunning, wantCall: true},
+		{name: "queue error", status: constants.JobStatusFailed, taskID: &taskID, revokeErr: boom, wantErr: boom, wantCall: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &fakeQueue{revokeErr: tt.revokeErr}
+			s := &jobService{queue: q, logger: zap.NewNop()}
+
+			err := s.revokeTask(&models.JobScheduler{ID: uuid.New(), Status: tt.status, TaskID: tt.taskID})
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("revokeTask() error = %v, want %v", err, tt.wantErr)
+			}
+			if called := len(q.revoked) > 0; called != tt.wantCall {
+				t.Errorf("RevokeTask called = %t, want %t", called, tt.wantCall)
+			}
+		})
+	}
+}
+
+type finalizeContentRepo struct {
+	repo.ContentRepository
+	content *models.Content
+}
+
+func (r *finalizeContentRepo) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Content, error) {
+	content := *r.content
+	return &content, nil
+}
+
+func (r *finalizeContentRepo) UpdateStatusWithCounts(ctx context.Context, workspaceID, id uuid.UUID, status string, counts models.DeliveryCounts) error {
+	r.content.Status = status
+	return nil
+}
+
+type finalizeJobRepo struct {
+	repo.JobRepository
+	unfinished int
+}
+
+func (r *finalizeJobRepo) CountUnfinishedSendJobs(ctx context.Context, workspaceID, contentID uuid.UUID) (int, error) {
+	return r.unfinished, nil
+}
+
+type finalizeDeliveryRepo struct {
+	repo.DeliveryRepository
+	counts models.DeliveryCounts
+}
+
+func (r *finalizeDeliveryRepo) CountByContent(ctx context.Context, workspaceID, contentID uuid.UUID) (models.DeliveryCounts, error) {
+	return r.counts, nil
+}
+
+func TestFinalizeIfLastJob(t *testing.T) {
+	tests := []struct {
+		name       string
+		unfinished int
+		counts     models.DeliveryCounts
+		want       string
+	}{
+		{name: "other jobs left", unfinished: 1, counts: models.DeliveryCounts{Sent: 2}, want: constants.ContentStatusScheduled},
+		{name: "all sent", counts: models.DeliveryCounts{Sent: 2}, want: constants.ContentStatusSent},
+		{name: "nothing left to send", counts: models.DeliveryCounts{Skipped: 2}, want: constants.ContentStatusSent},
+		{name: "some failed", counts: models.DeliveryCounts{Sent: 2, Failed: 1}, want: constants.ContentStatusPartiallySent},
+		{name: "all failed", counts: models.DeliveryCounts{Failed: 2}, want: constants.ContentStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := &models.Content{ID: uuid.New(), WorkspaceID: uuid.New(), Status: constants.ContentStatusScheduled}
+			s := &jobService{
+				jobRepo:      &finalizeJobRepo{unfinished: tt.unfinished},
+				contentRepo:  &finalizeContentRepo{content: content},
+				deliveryRepo: &finalizeDeliveryRepo{counts: tt.counts},
+				logger:       zap.NewNop(),
+			}
+
+			s.FinalizeIfLastJob(context.Background(), content.WorkspaceID, content.ID)
+			if content.Status != tt.want {
+				t.Errorf("content status = %q, want %q", content.Status, tt.want)
+			}
+		})
+	}
+}
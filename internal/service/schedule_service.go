@@ -0,0 +1,448 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/db"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/recurrence"
+	"newsletter-assignment/internal/repo"
+	"newsletter-assignment/internal/request"
+	"newsletter-assignment/internal/templating"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxOccurrencesPerRun bounds how many occurrences one schedule may generate per scheduler tick
+const maxOccurrencesPerRun = 100
+
+type recurringScheduleService struct {
+	scheduleRepo repo.RecurringScheduleRepository
+	contentRepo  repo.ContentRepository
+	topicRepo    repo.TopicRepository
+	jobRepo      repo.JobRepository
+	db           *db.DB
+	logger       *zap.Logger
+}
+
+func NewRecurringScheduleService(
+	scheduleRepo repo.RecurringScheduleRepository,
+	contentRepo repo.ContentRepository,
+	topicRepo repo.TopicRepository,
+	jobRepo repo.JobRepository,
+	database *db.DB,
+	logger *zap.Logger,
+) RecurringScheduleService {
+	return &recurringScheduleService{
+		scheduleRepo: scheduleRepo,
+		contentRepo:  contentRepo,
+		topicRepo:    topicRepo,
+		jobRepo:      jobRepo,
+		db:           database,
+		logger:       logger,
+	}
+}
+
+func (s *recurringScheduleService) CreateSchedule(ctx context.Context, workspaceID uuid.UUID, req *request.CreateRecurringScheduleRequest) (*models.RecurringSchedule, error) {
+	req.CronExpression = strings.TrimSpace(req.CronExpression)
+	req.Timezone = normalizeTimezone(req.Timezone)
+
+	parsed, err := recurrence.Parse(req.CronExpression, req.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	var topicID uuid.UUID
+	if req.ContentID != nil {
+		// Content-level schedule: subject and body are taken from the content on every run
+		if req.Subject != nil || req.Body != nil {
+			return nil, apperr.Validation("subject and body cannot be combined with content_id")
+		}
+
+		content, err := s.contentRepo.GetByID(ctx, workspaceID, *req.ContentID)
+		if err != nil {
+			return nil, err
+		}
+
+		if req.TopicID != nil && *req.TopicID != content.TopicID {
+			return nil, apperr.Validation("content does not belong to topic", apperr.Field("topic_id", "does not match the topic of content_id"))
+		}
+		topicID = content.TopicID
+	} else {
+		// Topic-level schedule: the schedule carries its own subject and body
+		if req.TopicID == nil {
+			return nil, apperr.Validation("either content_id or topic_id is required")
+		}
+		if err := validateScheduleTemplate(req.Subject, req.Body); err != nil {
+			return nil, err
+		}
+
+		if _, err := s.topicRepo.GetByID(ctx, workspaceID, *req.TopicID); err != nil {
+			s.logger.Error("Topic not found for schedule", zap.Error(err), zap.String("topic_id", req.TopicID.String()))
+			return nil, err
+		}
+		topicID = *req.TopicID
+	}
+
+	nextRunAt, err := firstRun(parsed, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.scheduleRepo.Create(ctx, workspaceID, topicID, req, nextRunAt)
+	if err != nil {
+		s.logger.Error("Failed to create schedule", zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Recurring schedule created",
+		zap.String("id", schedule.ID.String()),
+		zap.String("cron_expression", schedule.CronExpression),
+		zap.String("timezone", schedule.Timezone),
+		zap.Time("next_run_at", schedule.NextRunAt),
+	)
+
+	return schedule, nil
+}
+
+func (s *recurringScheduleService) GetSchedule(ctx context.Context, workspaceID, id uuid.UUID) (*models.RecurringSchedule, error) {
+	schedule, err := s.scheduleRepo.GetByID(ctx, workspaceID, id)
+	if err != nil {
+		s.logger.Error("Failed to get schedule", zap.Error(err), zap.String("id", id.String()))
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+func (s *recurringScheduleService) ListSchedules(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.RecurringSchedule, error) {
+	// Set default and max limits
+	if limit <= 0 {
+		limit = constants.DefaultLimit
+	}
+	if limit > constants.MaxLimit {
+		limit = constants.MaxLimit
+	}
+	if offset < 0 {
+		offset = constants.DefaultOffset
+	}
+
+	schedules, err := s.scheduleRepo.List(ctx, workspaceID, limit, offset)
+	if err != nil {
+		s.logger.Error("Failed to list schedules", zap.Error(err))
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+func (s *recurringScheduleService) UpdateSchedule(ctx context.Context, workspaceID, id uuid.UUID, req *request.UpdateRecurringScheduleRequest) (*models.RecurringSchedule, error) {
+	req.CronExpression = strings.TrimSpace(req.CronExpression)
+	req.Timezone = normalizeTimezone(req.Timezone)
+
+	parsed, err := recurrence.Parse(req.CronExpression, req.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.scheduleRepo.GetByID(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.ContentID != nil && (req.Subject != nil || req.Body != nil) {
+		return nil, apperr.Validation("subject and body cannot be combined with content_id")
+	}
+	if existing.ContentID == nil {
+		subject, body := existing.Subject, existing.Body
+		if req.Subject != nil {
+			subject = req.Subject
+		}
+		if req.Body != nil {
+			body = req.Body
+		}
+		if err := validateScheduleTemplate(subject, body); err != nil {
+			return nil, err
+		}
+	}
+
+	nextRunAt, err := firstRun(parsed, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// Occurrences generated under the old definition are regenerated
+	s.discardPendingOccurrences(ctx, workspaceID, id)
+
+	schedule, err := s.scheduleRepo.Update(ctx, workspaceID, id, req, nextRunAt)
+	if err != nil {
+		s.logger.Error("Failed to update schedule", zap.Error(err), zap.String("id", id.String()))
+		return nil, err
+	}
+
+	s.logger.Info("Recurring schedule updated",
+		zap.String("id", schedule.ID.String()),
+		zap.String("cron_expression", schedule.CronExpression),
+		zap.Time("next_run_at", schedule.NextRunAt),
+	)
+
+	return schedule, nil
+}
+
+func (s *recurringScheduleService) DeleteSchedule(ctx context.Context, workspaceID, id uuid.UUID) error {
+	s.logger.Info("Deleting schedule", zap.String("id", id.String()))
+
+	s.discardPendingOccurrences(ctx, workspaceID, id)
+
+	if err := s.scheduleRepo.Delete(ctx, workspaceID, id); err != nil {
+		s.logger.Error("Failed to delete schedule", zap.Error(err), zap.String("id", id.String()))
+		return err
+	}
+
+	return nil
+}
+
+func (s *recurringScheduleService) PauseSchedule(ctx context.Context, workspaceID, id uuid.UUID) (*models.RecurringSchedule, error) {
+	schedule, err := s.scheduleRepo.GetByID(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if schedule.Status == constants.RecurringScheduleStatusPaused {
+		return nil, apperr.Conflict("schedule is already paused")
+	}
+
+	if err := s.scheduleRepo.UpdateStatus(ctx, workspaceID, id, constants.RecurringScheduleStatusPaused, schedule.NextRunAt); err != nil {
+		return nil, err
+	}
+
+	// Upcoming occurrences that were generated ahead of time must not go out while paused
+	s.discardPendingOccurrences(ctx, workspaceID, id)
+
+	s.logger.Info("Recurring schedule paused", zap.String("id", id.String()))
+	return s.scheduleRepo.GetByID(ctx, workspaceID, id)
+}
+
+func (s *recurringScheduleService) ResumeSchedule(ctx context.Context, workspaceID, id uuid.UUID) (*models.RecurringSchedule, error) {
+	schedule, err := s.scheduleRepo.GetByID(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if schedule.Status == constants.RecurringScheduleStatusActive {
+		return nil, apperr.Conflict("schedule is already active")
+	}
+
+	parsed, err := recurrence.Parse(schedule.CronExpression, schedule.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	// Runs missed while paused are skipped
+	nextRunAt, err := firstRun(parsed, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduleRepo.UpdateStatus(ctx, workspaceID, id, constants.RecurringScheduleStatusActive, nextRunAt); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Recurring schedule resumed", zap.String("id", id.String()), zap.Time("next_run_at", nextRunAt))
+	return s.scheduleRepo.GetByID(ctx, workspaceID, id)
+}
+
+func (s *recurringScheduleService) PreviewSchedule(ctx context.Context, workspaceID, id uuid.UUID, count int) ([]time.Time, error) {
+	schedule, err := s.scheduleRepo.GetByID(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.PreviewExpression(schedule.CronExpression, schedule.Timezone, count)
+}
+
+func (s *recurringScheduleService) PreviewExpression(expression, timezone string, count int) ([]time.Time, error) {
+	if count <= 0 {
+		count = constants.DefaultSchedulePreviewCount
+	}
+	if count > constants.MaxSchedulePreviewCount {
+		count = constants.MaxSchedulePreviewCount
+	}
+
+	parsed, err := recurrence.Parse(strings.TrimSpace(expression), normalizeTimezone(timezone))
+	if err != nil {
+		return nil, err
+	}
+
+	return parsed.NextN(time.Now(), count), nil
+}
+
+// ExpandDue generates content and send jobs for every active schedule run up to the given time.
+// It covers all workspaces; each occurrence is created in the workspace of its schedule.
+func (s *recurringScheduleService) ExpandDue(ctx context.Context, until time.Time, limit int) (int, error) {
+	schedules, err := s.scheduleRepo.ListDue(ctx, until, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, schedule := range schedules {
+		n, err := s.expandSchedule(ctx, schedule, until)
+		created += n
+		if err != nil {
+			s.logger.Error("Failed to expand recurring schedule",
+				zap.String("schedule_id", schedule.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return created, nil
+}
+
+// expandSchedule materializes the runs of one schedule that fall before until
+func (s *recurringScheduleService) expandSchedule(ctx context.Context, schedule *models.RecurringSchedule, until time.Time) (int, error) {
+	parsed, err := recurrence.Parse(schedule.CronExpression, schedule.Timezone)
+	if err != nil {
+		return 0, err
+	}
+
+	subject, body, err := s.occurrenceTemplate(ctx, schedule)
+	if err != nil {
+		return 0, err
+	}
+
+	// Runs missed while the scheduler was down collapse into a single catch-up send
+	now := time.Now()
+	runAt := schedule.NextRunAt
+	for next := parsed.Next(runAt); !next.IsZero() && !next.After(now); next = parsed.Next(next) {
+		runAt = next
+	}
+	if !runAt.Equal(schedule.NextRunAt) {
+		s.logger.Warn("Skipping missed recurring schedule runs",
+			zap.String("schedule_id", schedule.ID.String()),
+			zap.Time("missed_from", schedule.NextRunAt),
+			zap.Time("catch_up_at", runAt),
+		)
+	}
+
+	expected := schedule.NextRunAt
+	created := 0
+	for created < maxOccurrencesPerRun && !runAt.After(until) {
+		next := parsed.Next(runAt)
+		if next.IsZero() {
+			return created, fmt.Errorf("schedule has no further runs")
+		}
+
+		ok, err := s.materializeOccurrence(ctx, schedule, subject, body, expected, runAt, next)
+		if err != nil || !ok {
+			return created, err
+		}
+
+		created++
+		expected, runAt = next, next
+	}
+
+	return created, nil
+}
+
+// materializeOccurrence creates the content and send job for one run and advances the schedule
+func (s *recurringScheduleService) materializeOccurrence(ctx context.Context, schedule *models.RecurringSchedule, subject, body string, expected, runAt, next time.Time) (bool, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	advanced, err := s.scheduleRepo.AdvanceTx(ctx, tx, schedule.ID, expected, next)
+	if err != nil {
+		return false, err
+	}
+	if !advanced {
+		// Another scheduler instance handled this run, or the schedule changed meanwhile
+		return false, nil
+	}
+
+	content, err := s.contentRepo.CreateOccurrenceTx(ctx, tx, schedule, subject, body, runAt)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := s.jobRepo.CreateTx(ctx, tx, schedule.WorkspaceID, content.ID, constants.JobTypeSendNewsletter, content.SendAt); err != nil {
+		return false, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logger.Info("Recurring schedule occurrence created",
+		zap.String("schedule_id", schedule.ID.String()),
+		zap.String("content_id", content.ID.String()),
+		zap.Time("send_at", content.SendAt),
+	)
+
+	return true, nil
+}
+
+// occurrenceTemplate returns the subject and body used for the next run of a schedule
+func (s *recurringScheduleService) occurrenceTemplate(ctx context.Context, schedule *models.RecurringSchedule) (string, string, error) {
+	if schedule.ContentID == nil {
+		return *schedule.Subject, *schedule.Body, nil
+	}
+
+	content, err := s.contentRepo.GetByID(ctx, schedule.WorkspaceID, *schedule.ContentID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return content.Subject, content.Body, nil
+}
+
+// discardPendingOccurrences removes generated content that has not started sending
+func (s *recurringScheduleService) discardPendingOccurrences(ctx context.Context, workspaceID, id uuid.UUID) {
+	removed, err := s.contentRepo.DeletePendingOccurrences(ctx, workspaceID, id)
+	if err != nil {
+		s.logger.Error("Failed to discard pending occurrences", zap.Error(err), zap.String("schedule_id", id.String()))
+		return
+	}
+
+	if removed > 0 {
+		s.logger.Info("Discarded pending occurrences", zap.String("schedule_id", id.String()), zap.Int64("count", removed))
+	}
+}
+
+// validateScheduleTemplate checks the subject and body of a topic-level schedule
+func validateScheduleTemplate(subject, body *string) error {
+	if subject == nil || strings.TrimSpace(*subject) == "" {
+		return apperr.Validation("subject cannot be empty", apperr.Field("subject", "cannot be empty"))
+	}
+	if body == nil || strings.TrimSpace(*body) == "" {
+		return apperr.Validation("body cannot be empty", apperr.Field("body", "cannot be empty"))
+	}
+
+	return templating.Validate(*subject, *body)
+}
+
+// firstRun returns the first run of a schedule after the given time
+func firstRun(schedule *recurrence.Schedule, after time.Time) (time.Time, error) {
+	next := schedule.Next(after)
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("%w: expression never fires", recurrence.ErrInvalidSchedule)
+	}
+	return next, nil
+}
+
+func normalizeTimezone(timezone string) string {
+	timezone = strings.TrimSpace(timezone)
+	if timezone == "" {
+		return constants.DefaultScheduleTimezone
+	}
+	return timezone
+}
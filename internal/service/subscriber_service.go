@@ -2,9 +2,10 @@ package service
 
 import (
 	"context"
-	"fmt"
 	"strings"
+	"time"
 
+	"newsletter-assignment/internal/apperr"
 	"newsletter-assignment/internal/constants"
 	"newsletter-assignment/internal/models"
 	"newsletter-assignment/internal/repo"
@@ -26,11 +27,11 @@ func NewSubscriberService(subscriberRepo repo.SubscriberRepository, logger *zap.
 	}
 }
 
-func (s *subscriberService) CreateSubscriber(ctx context.Context, req *request.CreateSubscriberRequest) (*models.Subscriber, error) {
+func (s *subscriberService) CreateSubscriber(ctx context.Context, workspaceID uuid.UUID, req *request.CreateSubscriberRequest) (*models.Subscriber, error) {
 	// Validate and sanitize input
 	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
 	if req.Email == "" {
-		return nil, fmt.Errorf("email cannot be empty")
+		return nil, apperr.Validation("email cannot be empty", apperr.Field("email", "cannot be empty"))
 	}
 
 	if req.Name != nil {
@@ -41,9 +42,15 @@ func (s *subscriberService) CreateSubscriber(ctx context.Context, req *request.C
 		}
 	}
 
+	timezone, err := normalizeSubscriberTimezone(req.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	req.Timezone = timezone
+
 	s.logger.Info("Creating subscriber", zap.String("email", req.Email))
 
-	subscriber, err := s.subscriberRepo.Create(ctx, req)
+	subscriber, err := s.subscriberRepo.Create(ctx, workspaceID, req)
 	if err != nil {
 		s.logger.Error("Failed to create subscriber", zap.Error(err), zap.String("email", req.Email))
 		return nil, err
@@ -57,8 +64,8 @@ func (s *subscriberService) CreateSubscriber(ctx context.Context, req *request.C
 	return subscriber, nil
 }
 
-func (s *subscriberService) GetSubscriber(ctx context.Context, id uuid.UUID) (*models.Subscriber, error) {
-	subscriber, err := s.subscriberRepo.GetByID(ctx, id)
+func (s *subscriberService) GetSubscriber(ctx context.Context, workspaceID, id uuid.UUID) (*models.Subscriber, error) {
+	subscriber, err := s.subscriberRepo.GetByID(ctx, workspaceID, id)
 	if err != nil {
 		s.logger.Error("Failed to get subscriber", zap.Error(err), zap.String("id", id.String()))
 		return nil, err
@@ -67,9 +74,9 @@ func (s *subscriberService) GetSubscriber(ctx context.Context, id uuid.UUID) (*m
 	return subscriber, nil
 }
 
-func (s *subscriberService) GetSubscriberByEmail(ctx context.Context, email string) (*models.Subscriber, error) {
+func (s *subscriberService) GetSubscriberByEmail(ctx context.Context, workspaceID uuid.UUID, email string) (*models.Subscriber, error) {
 	email = strings.TrimSpace(strings.ToLower(email))
-	subscriber, err := s.subscriberRepo.GetByEmail(ctx, email)
+	subscriber, err := s.subscriberRepo.GetByEmail(ctx, workspaceID, email)
 	if err != nil {
 		s.logger.Error("Failed to get subscriber by email", zap.Error(err), zap.String("email", email))
 		return nil, err
@@ -78,7 +85,7 @@ func (s *subscriberService) GetSubscriberByEmail(ctx context.Context, email stri
 	return subscriber, nil
 }
 
-func (s *subscriberService) ListSubscribers(ctx context.Context, limit, offset int) ([]*models.Subscriber, error) {
+func (s *subscriberService) ListSubscribers(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.Subscriber, error) {
 	// Set default and max limits
 	if limit <= 0 {
 		limit = constants.DefaultLimit
@@ -90,7 +97,7 @@ func (s *subscriberService) ListSubscribers(ctx context.Context, limit, offset i
 		offset = constants.DefaultOffset
 	}
 
-	subscribers, err := s.subscriberRepo.List(ctx, limit, offset)
+	subscribers, err := s.subscriberRepo.List(ctx, workspaceID, limit, offset)
 	if err != nil {
 		s.logger.Error("Failed to list subscribers", zap.Error(err))
 		return nil, err
@@ -99,11 +106,11 @@ func (s *subscriberService) ListSubscribers(ctx context.Context, limit, offset i
 	return subscribers, nil
 }
 
-func (s *subscriberService) UpdateSubscriber(ctx context.Context, id uuid.UUID, req *request.UpdateSubscriberRequest) (*models.Subscriber, error) {
+func (s *subscriberService) UpdateSubscriber(ctx context.Context, workspaceID, id uuid.UUID, req *request.UpdateSubscriberRequest) (*models.Subscriber, error) {
 	// Validate and sanitize input
 	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
 	if req.Email == "" {
-		return nil, fmt.Errorf("email cannot be empty")
+		return nil, apperr.Validation("email cannot be empty", apperr.Field("email", "cannot be empty"))
 	}
 
 	if req.Name != nil {
@@ -114,9 +121,15 @@ func (s *subscriberService) UpdateSubscriber(ctx context.Context, id uuid.UUID,
 		}
 	}
 
+	timezone, err := normalizeSubscriberTimezone(req.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	req.Timezone = timezone
+
 	s.logger.Info("Updating subscriber", zap.String("id", id.String()), zap.String("email", req.Email))
 
-	subscriber, err := s.subscriberRepo.Update(ctx, id, req)
+	subscriber, err := s.subscriberRepo.Update(ctx, workspaceID, id, req)
 	if err != nil {
 		s.logger.Error("Failed to update subscriber", zap.Error(err), zap.String("id", id.String()))
 		return nil, err
@@ -130,10 +143,10 @@ func (s *subscriberService) UpdateSubscriber(ctx context.Context, id uuid.UUID,
 	return subscriber, nil
 }
 
-func (s *subscriberService) DeleteSubscriber(ctx context.Context, id uuid.UUID) error {
+func (s *subscriberService) DeleteSubscriber(ctx context.Context, workspaceID, id uuid.UUID) error {
 	s.logger.Info("Deleting subscriber", zap.String("id", id.String()))
 
-	err := s.subscriberRepo.Delete(ctx, id)
+	err := s.subscriberRepo.Delete(ctx, workspaceID, id)
 	if err != nil {
 		s.logger.Error("Failed to delete subscriber", zap.Error(err), zap.String("id", id.String()))
 		return err
@@ -142,3 +155,25 @@ func (s *subscriberService) DeleteSubscriber(ctx context.Context, id uuid.UUID)
 	s.logger.Info("Subscriber deleted successfully", zap.String("id", id.String()))
 	return nil
 }
+
+// normalizeSubscriberTimezone trims the time zone and checks that it is a known IANA name.
+// An empty time zone is stored as NULL.
+func normalizeSubscriberTimezone(timezone *string) (*string, error) {
+	if timezone == nil {
+		return nil, nil
+	}
+
+	name := strings.TrimSpace(*timezone)
+	if name == "" {
+		return nil, nil
+	}
+
+	if name == "Local" {
+		return nil, apperr.Validation("invalid timezone", apperr.Field("timezone", "must be an IANA time zone name"))
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return nil, apperr.Validation("invalid timezone", apperr.Field("timezone", "must be an IANA time zone name"))
+	}
+
+	return &name, nil
+}
@@ -2,8 +2,16 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"html/template"
+	"strings"
+	"time"
 
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/email"
+	"newsletter-assignment/internal/links"
 	"newsletter-assignment/internal/models"
 	"newsletter-assignment/internal/repo"
 	"newsletter-assignment/internal/request"
@@ -12,10 +20,31 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrSubscriptionInactive is returned when unsubscribing from an inactive subscription
+var ErrSubscriptionInactive = apperr.Conflict("subscription is already inactive")
+
+// ErrConfirmationExpired is returned when a double opt-in link is used after its window
+var ErrConfirmationExpired = apperr.PreconditionFailed("confirmation link has expired")
+
+var confirmationEmail = template.Must(template.New("confirmation").Parse(`<!DOCTYPE html>
+<html>
+<body>
+<p>Please confirm that you want to receive {{.Topic}}.</p>
+<p><a href="{{.URL}}">Confirm subscription</a></p>
+<p>If you did not subscribe, you can ignore this email.</p>
+</body>
+</html>
+`))
+
 type subscriptionService struct {
 	subscriptionRepo repo.SubscriptionRepository
 	subscriberRepo   repo.SubscriberRepository
 	topicRepo        repo.TopicRepository
+	workspaceRepo    repo.WorkspaceRepository
+	suppressionRepo  repo.SuppressionRepository
+	emailSender      email.EmailSender
+	links            *links.Builder
+	confirmationTTL  time.Duration
 	logger           *zap.Logger
 }
 
@@ -23,79 +52,206 @@ func NewSubscriptionService(
 	subscriptionRepo repo.SubscriptionRepository,
 	subscriberRepo repo.SubscriberRepository,
 	topicRepo repo.TopicRepository,
+	workspaceRepo repo.WorkspaceRepository,
+	suppressionRepo repo.SuppressionRepository,
+	emailSender email.EmailSender,
+	linkBuilder *links.Builder,
+	confirmationTTL time.Duration,
 	logger *zap.Logger,
 ) SubscriptionService {
+	if confirmationTTL <= 0 {
+		confirmationTTL = constants.DefaultSubscriptionConfirmationTTL
+	}
+
 	return &subscriptionService{
 		subscriptionRepo: subscriptionRepo,
 		subscriberRepo:   subscriberRepo,
 		topicRepo:        topicRepo,
+		workspaceRepo:    workspaceRepo,
+		suppressionRepo:  suppressionRepo,
+		emailSender:      emailSender,
+		links:            linkBuilder,
+		confirmationTTL:  confirmationTTL,
 		logger:           logger,
 	}
 }
 
-func (s *subscriptionService) Subscribe(ctx context.Context, req *request.CreateSubscriptionRequest) (*models.Subscription, error) {
+// Subscribe activates a subscription, or for double opt-in topics leaves it awaiting
+// confirmation and emails the subscriber a confirmation link
+func (s *subscriptionService) Subscribe(ctx context.Context, workspaceID uuid.UUID, req *request.CreateSubscriptionRequest) (*models.Subscription, error) {
 	// Validate that subscriber exists
-	subscriber, err := s.subscriberRepo.GetByID(ctx, req.SubscriberID)
+	subscriber, err := s.subscriberRepo.GetByID(ctx, workspaceID, req.SubscriberID)
 	if err != nil {
 		s.logger.Error("Subscriber not found for subscription", zap.Error(err), zap.String("subscriber_id", req.SubscriberID.String()))
-		return nil, fmt.Errorf("subscriber not found")
+		return nil, err
 	}
 
 	if !subscriber.IsActive {
-		return nil, fmt.Errorf("subscriber is not active")
+		return nil, apperr.PreconditionFailed("subscriber is not active")
 	}
 
 	// Validate that topic exists
-	topic, err := s.topicRepo.GetByID(ctx, req.TopicID)
+	topic, err := s.topicRepo.GetByID(ctx, workspaceID, req.TopicID)
 	if err != nil {
 		s.logger.Error("Topic not found for subscription", zap.Error(err), zap.String("topic_id", req.TopicID.String()))
-		return nil, fmt.Errorf("topic not found")
+		return nil, err
+	}
+
+	// Double opt-in topics only activate the subscription once it is confirmed
+	status := constants.SubscriptionStatusActive
+	var confirmationExpiresAt *time.Time
+	if topic.DoubleOptIn {
+		// A suppressed address could never receive the confirmation email
+		_, err := s.suppressionRepo.GetByEmail(ctx, workspaceID, subscriber.Email)
+		if err == nil {
+			return nil, apperr.PreconditionFailed("subscriber email address is suppressed")
+		}
+		if !errors.Is(err, apperr.ErrNotFound) {
+			return nil, err
+		}
+
+		status = constants.SubscriptionStatusPendingConfirmation
+		expiresAt := time.Now().Add(s.confirmationTTL)
+		confirmationExpiresAt = &expiresAt
 	}
 
 	// Check if subscription already exists
-	existingSubscription, err := s.subscriptionRepo.GetBySubscriberAndTopic(ctx, req.SubscriberID, req.TopicID)
-	if err == nil {
+	subscription, err := s.subscriptionRepo.GetBySubscriberAndTopic(ctx, workspaceID, req.SubscriberID, req.TopicID)
+	switch {
+	case err == nil:
 		// Subscription exists
-		if existingSubscription.IsActive {
-			return nil, fmt.Errorf("subscriber is already subscribed to this topic")
+		if subscription.IsActive {
+			return nil, apperr.Conflict("subscriber is already subscribed to this topic")
 		}
-		// Reactivate existing subscription
+		// Reactivate existing subscription; a pending one gets a fresh confirmation link
 		s.logger.Info("Reactivating existing subscription",
 			zap.String("subscriber_id", req.SubscriberID.String()),
 			zap.String("topic_id", req.TopicID.String()),
+			zap.String("status", status),
+		)
+		subscription, err = s.subscriptionRepo.UpdateStatus(ctx, workspaceID, subscription.ID, status, confirmationExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+	case errors.Is(err, apperr.ErrNotFound):
+		s.logger.Info("Creating new subscription",
+			zap.String("subscriber_email", subscriber.Email),
+			zap.String("topic_name", topic.Name),
+			zap.String("status", status),
 		)
-		return s.subscriptionRepo.Update(ctx, existingSubscription.ID, true)
+
+		subscription, err = s.subscriptionRepo.Create(ctx, workspaceID, req, status, confirmationExpiresAt)
+		if err != nil {
+			s.logger.Error("Failed to create subscription", zap.Error(err))
+			return nil, err
+		}
+
+		s.logger.Info("Subscription created successfully",
+			zap.String("id", subscription.ID.String()),
+			zap.String("subscriber_email", subscriber.Email),
+			zap.String("topic_name", topic.Name),
+		)
+	default:
+		return nil, err
 	}
 
-	s.logger.Info("Creating new subscription",
-		zap.String("subscriber_email", subscriber.Email),
-		zap.String("topic_name", topic.Name),
-	)
+	if subscription.Status == constants.SubscriptionStatusPendingConfirmation {
+		if err := s.sendConfirmation(ctx, subscriber, topic, subscription); err != nil {
+			return nil, err
+		}
+	}
+
+	return subscription, nil
+}
 
-	subscription, err := s.subscriptionRepo.Create(ctx, req)
+// ConfirmSubscription activates a subscription from its double opt-in link.
+// Confirming an already active subscription succeeds without changes.
+func (s *subscriptionService) ConfirmSubscription(ctx context.Context, workspaceID, id uuid.UUID) (*models.Subscription, error) {
+	subscription, err := s.subscriptionRepo.GetByID(ctx, workspaceID, id)
 	if err != nil {
-		s.logger.Error("Failed to create subscription", zap.Error(err))
 		return nil, err
 	}
 
-	s.logger.Info("Subscription created successfully",
-		zap.String("id", subscription.ID.String()),
-		zap.String("subscriber_email", subscriber.Email),
-		zap.String("topic_name", topic.Name),
-	)
+	switch subscription.Status {
+	case constants.SubscriptionStatusActive:
+		return subscription, nil
+	case constants.SubscriptionStatusExpired:
+		return nil, ErrConfirmationExpired
+	case constants.SubscriptionStatusPendingConfirmation:
+		if subscription.ConfirmationExpiresAt != nil && !time.Now().Before(*subscription.ConfirmationExpiresAt) {
+			return nil, ErrConfirmationExpired
+		}
+	default:
+		return nil, apperr.PreconditionFailed("subscription is not awaiting confirmation")
+	}
 
+	subscription, err = s.subscriptionRepo.Confirm(ctx, workspaceID, id)
+	if err != nil {
+		s.logger.Error("Failed to confirm subscription", zap.Error(err), zap.String("id", id.String()))
+		return nil, err
+	}
+
+	s.logger.Info("Subscription confirmed", zap.String("id", subscription.ID.String()))
 	return subscription, nil
 }
 
-func (s *subscriptionService) Unsubscribe(ctx context.Context, subscriberID, topicID uuid.UUID) error {
-	subscription, err := s.subscriptionRepo.GetBySubscriberAndTopic(ctx, subscriberID, topicID)
+// ExpirePendingConfirmations expires subscriptions that were not confirmed in time
+func (s *subscriptionService) ExpirePendingConfirmations(ctx context.Context, now time.Time) (int, error) {
+	return s.subscriptionRepo.ExpirePending(ctx, now)
+}
+
+// sendConfirmation emails the double opt-in link using the workspace sender identity
+func (s *subscriptionService) sendConfirmation(ctx context.Context, subscriber *models.Subscriber, topic *models.Topic, subscription *models.Subscription) error {
+	workspace, err := s.workspaceRepo.GetByID(ctx, subscription.WorkspaceID)
+	if err != nil {
+		return err
+	}
+
+	confirmURL := s.links.ConfirmSubscriptionURL(subscription.WorkspaceID, subscription.ID)
+
+	var htmlBody strings.Builder
+	if err := confirmationEmail.Execute(&htmlBody, map[string]string{"Topic": topic.Name, "URL": confirmURL}); err != nil {
+		return fmt.Errorf("failed to render confirmation email: %w", err)
+	}
+
+	emailReq := &email.EmailRequest{
+		To:       subscriber.Email,
+		Subject:  fmt.Sprintf("Confirm your subscription to %s", topic.Name),
+		HTMLBody: htmlBody.String(),
+		TextBody: fmt.Sprintf("Please confirm that you want to receive %s:\n\n%s\n\nIf you did not subscribe, you can ignore this email.\n", topic.Name, confirmURL),
+	}
+	if workspace.FromEmail != nil {
+		emailReq.FromEmail = *workspace.FromEmail
+	}
+	if workspace.FromName != nil {
+		emailReq.FromName = *workspace.FromName
+	}
+
+	if _, err := s.emailSender.Send(emailReq); err != nil {
+		s.logger.Error("Failed to send confirmation email",
+			zap.String("subscription_id", subscription.ID.String()),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to send confirmation email: %w", err)
+	}
+
+	s.logger.Info("Confirmation email sent",
+		zap.String("subscription_id", subscription.ID.String()),
+		zap.Time("expires_at", *subscription.ConfirmationExpiresAt),
+	)
+	return nil
+}
+
+func (s *subscriptionService) Unsubscribe(ctx context.Context, workspaceID, subscriberID, topicID uuid.UUID) error {
+	subscription, err := s.subscriptionRepo.GetBySubscriberAndTopic(ctx, workspaceID, subscriberID, topicID)
 	if err != nil {
 		s.logger.Error("Subscription not found for unsubscribe", zap.Error(err))
-		return fmt.Errorf("subscription not found")
+		return err
 	}
 
-	if !subscription.IsActive {
-		return fmt.Errorf("subscription is already inactive")
+	// Pending subscriptions can be withdrawn before they are confirmed
+	if subscription.Status == constants.SubscriptionStatusUnsubscribed || subscription.Status == constants.SubscriptionStatusExpired {
+		return ErrSubscriptionInactive
 	}
 
 	s.logger.Info("Unsubscribing",
@@ -105,7 +261,7 @@ func (s *subscriptionService) Unsubscribe(ctx context.Context, subscriberID, top
 	)
 
 	// Deactivate subscription instead of deleting
-	_, err = s.subscriptionRepo.Update(ctx, subscription.ID, false)
+	_, err = s.subscriptionRepo.UpdateStatus(ctx, workspaceID, subscription.ID, constants.SubscriptionStatusUnsubscribed, nil)
 	if err != nil {
 		s.logger.Error("Failed to unsubscribe", zap.Error(err))
 		return err
@@ -115,8 +271,8 @@ func (s *subscriptionService) Unsubscribe(ctx context.Context, subscriberID, top
 	return nil
 }
 
-func (s *subscriptionService) GetSubscription(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
-	subscription, err := s.subscriptionRepo.GetByID(ctx, id)
+func (s *subscriptionService) GetSubscription(ctx context.Context, workspaceID, id uuid.UUID) (*models.Subscription, error) {
+	subscription, err := s.subscriptionRepo.GetByID(ctx, workspaceID, id)
 	if err != nil {
 		s.logger.Error("Failed to get subscription", zap.Error(err), zap.String("id", id.String()))
 		return nil, err
@@ -125,15 +281,15 @@ func (s *subscriptionService) GetSubscription(ctx context.Context, id uuid.UUID)
 	return subscription, nil
 }
 
-func (s *subscriptionService) ListSubscriberTopics(ctx context.Context, subscriberID uuid.UUID) ([]*models.Subscription, error) {
+func (s *subscriptionService) ListSubscriberTopics(ctx context.Context, workspaceID, subscriberID uuid.UUID) ([]*models.Subscription, error) {
 	// Validate that subscriber exists
-	_, err := s.subscriberRepo.GetByID(ctx, subscriberID)
+	_, err := s.subscriberRepo.GetByID(ctx, workspaceID, subscriberID)
 	if err != nil {
 		s.logger.Error("Subscriber not found", zap.Error(err), zap.String("subscriber_id", subscriberID.String()))
-		return nil, fmt.Errorf("subscriber not found")
+		return nil, err
 	}
 
-	subscriptions, err := s.subscriptionRepo.ListBySubscriber(ctx, subscriberID)
+	subscriptions, err := s.subscriptionRepo.ListBySubscriber(ctx, workspaceID, subscriberID)
 	if err != nil {
 		s.logger.Error("Failed to list subscriber topics", zap.Error(err))
 		return nil, err
@@ -142,15 +298,15 @@ func (s *subscriptionService) ListSubscriberTopics(ctx context.Context, subscrib
 	return subscriptions, nil
 }
 
-func (s *subscriptionService) ListTopicSubscribers(ctx context.Context, topicID uuid.UUID) ([]*models.Subscription, error) {
+func (s *subscriptionService) ListTopicSubscribers(ctx context.Context, workspaceID, topicID uuid.UUID) ([]*models.Subscription, error) {
 	// Validate that topic exists
-	_, err := s.topicRepo.GetByID(ctx, topicID)
+	_, err := s.topicRepo.GetByID(ctx, workspaceID, topicID)
 	if err != nil {
 		s.logger.Error("Topic not found", zap.Error(err), zap.String("topic_id", topicID.String()))
-		return nil, fmt.Errorf("topic not found")
+		return nil, err
 	}
 
-	subscriptions, err := s.subscriptionRepo.ListByTopic(ctx, topicID)
+	subscriptions, err := s.subscriptionRepo.ListByTopic(ctx, workspaceID, topicID)
 	if err != nil {
 		s.logger.Error("Failed to list topic subscribers", zap.Error(err))
 		return nil, err
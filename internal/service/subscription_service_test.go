@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/repo"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// confirmSubscriptionRepo holds a single subscription and records confirmations
+type confirmSubscriptionRepo struct {
+	repo.SubscriptionRepository
+	subscription *models.Subscription
+	confirmed    bool
+}
+
+func (r *confirmSubscriptionRepo) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Subscription, error) {
+	subscription := *r.subscription
+	return &subscription, nil
+}
+
+func (r *confirmSubscriptionRepo) Confirm(ctx context.Context, workspaceID, id uuid.UUID) (*models.Subscription, error) {
+	r.confirmed = true
+	now := time.Now()
+	r.subscription.Status = constants.SubscriptionStatusActive
+	r.subscription.IsActive = true
+	r.subscription.ConfirmedAt = &now
+	subscription := *r.subscription
+	return &subscription, nil
+}
+
+func TestConfirmSubscription(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name          string
+		status        string
+		expiresAt     *time.Time
+		wantErr       error
+		wantConfirmed bool
+	}{
+		{name: "pending", status: constants.SubscriptionStatusPendingConfirmation, expiresAt: &future, wantConfirmed: true},
+		{name: "pending without deadline", status: constants.SubscriptionStatusPendingConfirmation, wantConfirmed: true},
+		{name: "link expired before the sweep", status: constants.SubscriptionStatusPendingConfirmation, expiresAt: &past, wantErr: ErrConfirmationExpired},
+		{name: "expired", status: constants.SubscriptionStatusExpired, wantErr: ErrConfirmationExpired},
+		{name: "already active", status: constants.SubscriptionStatusActive},
+		{name: "unsubscribed", status: constants.SubscriptionStatusUnsubscribed, wantErr: apperr.ErrPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &confirmSubscriptionRepo{subscription: &models.Subscription{
+				ID:                    uuid.New(),
+				WorkspaceID:           uuid.New(),
+				Status:                tt.status,
+				IsActive:              tt.status == constants.SubscriptionStatusActive,
+				ConfirmationExpiresAt: tt.expiresAt,
+			}}
+			s := &subscriptionService{subscriptionRepo: r, logger: zap.NewNop()}
+
+			subscription, err := s.ConfirmSubscription(context.Background(), r.subscription.WorkspaceID, r.subscription.ID)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("ConfirmSubscription() error = %v, want %v", err, tt.wantErr)
+			}
+			if r.confirmed != tt.wantConfirmed {
+				t.Errorf("Confirm called = %t, want %t", r.confirmed, tt.wantConfirmed)
+			}
+			if err == nil && subscription.Status != constants.SubscriptionStatusActive {
+				t.Errorf("subscription status = %q, want %q", subscription.Status, constants.SubscriptionStatusActive)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/repo"
+	"newsletter-assignment/internal/request"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type suppressionService struct {
+	suppressionRepo repo.SuppressionRepository
+	logger          *zap.Logger
+}
+
+func NewSuppressionService(suppressionRepo repo.SuppressionRepository, logger *zap.Logger) SuppressionService {
+	return &suppressionService{
+		suppressionRepo: suppressionRepo,
+		logger:          logger,
+	}
+}
+
+func (s *suppressionService) CreateSuppression(ctx context.Context, workspaceID uuid.UUID, req *request.CreateSuppressionRequest) (*models.Suppression, error) {
+	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
+	if req.Email == "" {
+		return nil, apperr.Validation("email cannot be empty", apperr.Field("email", "cannot be empty"))
+	}
+
+	suppression, err := s.suppressionRepo.Create(ctx, workspaceID, req.Email, req.Reason, constants.SuppressionSourceAPI)
+	if err != nil {
+		s.logger.Error("Failed to create suppression", zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Address suppressed",
+		zap.String("id", suppression.ID.String()),
+		zap.String("email", suppression.Email),
+		zap.String("reason", suppression.Reason),
+	)
+
+	return suppression, nil
+}
+
+// ImportSuppressions suppresses many addresses at once and returns how many were
+// newly suppressed. Addresses that are already suppressed are skipped.
+func (s *suppressionService) ImportSuppressions(ctx context.Context, workspaceID uuid.UUID, req *request.ImportSuppressionsRequest) (int, error) {
+	if len(req.Suppressions) > constants.MaxSuppressionImportSize {
+		return 0, apperr.Validation("too many suppressions",
+			apperr.Field("suppressions", "must contain at most 1000 entries"))
+	}
+
+	// Duplicates within one import would make the insert ambiguous; the first entry wins
+	seen := make(map[string]bool, len(req.Suppressions))
+	emails := make([]string, 0, len(req.Suppressions))
+	reasons := make([]string, 0, len(req.Suppressions))
+	for _, entry := range req.Suppressions {
+		email := strings.TrimSpace(strings.ToLower(entry.Email))
+		if email == "" || seen[email] {
+			continue
+		}
+		seen[email] = true
+		emails = append(emails, email)
+		reasons = append(reasons, entry.Reason)
+	}
+
+	imported, err := s.suppressionRepo.Import(ctx, workspaceID, emails, reasons, constants.SuppressionSourceImport)
+	if err != nil {
+		s.logger.Error("Failed to import suppressions", zap.Error(err))
+		return 0, err
+	}
+
+	s.logger.Info("Suppressions imported",
+		zap.Int("submitted", len(req.Suppressions)),
+		zap.Int("imported", imported),
+	)
+
+	return imported, nil
+}
+
+func (s *suppressionService) GetSuppression(ctx context.Context, workspaceID, id uuid.UUID) (*models.Suppression, error) {
+	return s.suppressionRepo.GetByID(ctx, workspaceID, id)
+}
+
+func (s *suppressionService) ListSuppressions(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.Suppression, error) {
+	if limit <= 0 {
+		limit = constants.DefaultLimit
+	}
+	if limit > constants.MaxLimit {
+		limit = constants.MaxLimit
+	}
+	if offset < 0 {
+		offset = constants.DefaultOffset
+	}
+
+	return s.suppressionRepo.List(ctx, workspaceID, limit, offset)
+}
+
+// DeleteSuppression lifts a suppression so that the address can be mailed again
+func (s *suppressionService) DeleteSuppression(ctx context.Context, workspaceID, id uuid.UUID) error {
+	if err := s.suppressionRepo.Delete(ctx, workspaceID, id); err != nil {
+		s.logger.Error("Failed to delete suppression", zap.Error(err), zap.String("id", id.String()))
+		return err
+	}
+
+	s.logger.Info("Suppression lifted", zap.String("id", id.String()))
+	return nil
+}
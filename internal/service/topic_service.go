@@ -2,9 +2,9 @@ package service
 
 import (
 	"context"
-	"fmt"
 	"strings"
 
+	"newsletter-assignment/internal/apperr"
 	"newsletter-assignment/internal/models"
 	"newsletter-assignment/internal/repo"
 	"newsletter-assignment/internal/request"
@@ -25,11 +25,11 @@ func NewTopicService(topicRepo repo.TopicRepository, logger *zap.Logger) TopicSe
 	}
 }
 
-func (s *topicService) CreateTopic(ctx context.Context, req *request.CreateTopicRequest) (*models.Topic, error) {
+func (s *topicService) CreateTopic(ctx context.Context, workspaceID uuid.UUID, req *request.CreateTopicRequest) (*models.Topic, error) {
 	// Validate and sanitize input
 	req.Name = strings.TrimSpace(req.Name)
 	if req.Name == "" {
-		return nil, fmt.Errorf("topic name cannot be empty")
+		return nil, apperr.Validation("topic name cannot be empty", apperr.Field("name", "cannot be empty"))
 	}
 
 	if req.Description != nil {
@@ -42,7 +42,7 @@ func (s *topicService) CreateTopic(ctx context.Context, req *request.CreateTopic
 
 	s.logger.Info("Creating topic", zap.String("name", req.Name))
 
-	topic, err := s.topicRepo.Create(ctx, req)
+	topic, err := s.topicRepo.Create(ctx, workspaceID, req)
 	if err != nil {
 		s.logger.Error("Failed to create topic", zap.Error(err), zap.String("name", req.Name))
 		return nil, err
@@ -56,8 +56,8 @@ func (s *topicService) CreateTopic(ctx context.Context, req *request.CreateTopic
 	return topic, nil
 }
 
-func (s *topicService) GetTopic(ctx context.Context, id uuid.UUID) (*models.Topic, error) {
-	topic, err := s.topicRepo.GetByID(ctx, id)
+func (s *topicService) GetTopic(ctx context.Context, workspaceID, id uuid.UUID) (*models.Topic, error) {
+	topic, err := s.topicRepo.GetByID(ctx, workspaceID, id)
 	if err != nil {
 		s.logger.Error("Failed to get topic", zap.Error(err), zap.String("id", id.String()))
 		return nil, err
@@ -66,8 +66,8 @@ func (s *topicService) GetTopic(ctx context.Context, id uuid.UUID) (*models.Topi
 	return topic, nil
 }
 
-func (s *topicService) GetTopicByName(ctx context.Context, name string) (*models.Topic, error) {
-	topic, err := s.topicRepo.GetByName(ctx, name)
+func (s *topicService) GetTopicByName(ctx context.Context, workspaceID uuid.UUID, name string) (*models.Topic, error) {
+	topic, err := s.topicRepo.GetByName(ctx, workspaceID, name)
 	if err != nil {
 		s.logger.Error("Failed to get topic by name", zap.Error(err), zap.String("name", name))
 		return nil, err
@@ -76,7 +76,7 @@ func (s *topicService) GetTopicByName(ctx context.Context, name string) (*models
 	return topic, nil
 }
 
-func (s *topicService) ListTopics(ctx context.Context, limit, offset int) ([]*models.Topic, error) {
+func (s *topicService) ListTopics(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*models.Topic, error) {
 	// Set default and max limits
 	if limit <= 0 {
 		limit = 10
@@ -88,7 +88,7 @@ func (s *topicService) ListTopics(ctx context.Context, limit, offset int) ([]*mo
 		offset = 0
 	}
 
-	topics, err := s.topicRepo.List(ctx, limit, offset)
+	topics, err := s.topicRepo.List(ctx, workspaceID, limit, offset)
 	if err != nil {
 		s.logger.Error("Failed to list topics", zap.Error(err))
 		return nil, err
@@ -97,11 +97,11 @@ func (s *topicService) ListTopics(ctx context.Context, limit, offset int) ([]*mo
 	return topics, nil
 }
 
-func (s *topicService) UpdateTopic(ctx context.Context, id uuid.UUID, req *request.UpdateTopicRequest) (*models.Topic, error) {
+func (s *topicService) UpdateTopic(ctx context.Context, workspaceID, id uuid.UUID, req *request.UpdateTopicRequest) (*models.Topic, error) {
 	// Validate and sanitize input
 	req.Name = strings.TrimSpace(req.Name)
 	if req.Name == "" {
-		return nil, fmt.Errorf("topic name cannot be empty")
+		return nil, apperr.Validation("topic name cannot be empty", apperr.Field("name", "cannot be empty"))
 	}
 
 	if req.Description != nil {
@@ -114,7 +114,7 @@ func (s *topicService) UpdateTopic(ctx context.Context, id uuid.UUID, req *reque
 
 	s.logger.Info("Updating topic", zap.String("id", id.String()), zap.String("name", req.Name))
 
-	topic, err := s.topicRepo.Update(ctx, id, req)
+	topic, err := s.topicRepo.Update(ctx, workspaceID, id, req)
 	if err != nil {
 		s.logger.Error("Failed to update topic", zap.Error(err), zap.String("id", id.String()))
 		return nil, err
@@ -128,10 +128,10 @@ func (s *topicService) UpdateTopic(ctx context.Context, id uuid.UUID, req *reque
 	return topic, nil
 }
 
-func (s *topicService) DeleteTopic(ctx context.Context, id uuid.UUID) error {
+func (s *topicService) DeleteTopic(ctx context.Context, workspaceID, id uuid.UUID) error {
 	s.logger.Info("Deleting topic", zap.String("id", id.String()))
 
-	err := s.topicRepo.Delete(ctx, id)
+	err := s.topicRepo.Delete(ctx, workspaceID, id)
 	if err != nil {
 		s.logger.Error("Failed to delete topic", zap.Error(err), zap.String("id", id.String()))
 		return err
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/repo"
+	"newsletter-assignment/internal/request"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type workspaceService struct {
+	workspaceRepo repo.WorkspaceRepository
+	apiKeyService APIKeyService
+	logger        *zap.Logger
+}
+
+func NewWorkspaceService(workspaceRepo repo.WorkspaceRepository, apiKeyService APIKeyService, logger *zap.Logger) WorkspaceService {
+	return &workspaceService{
+		workspaceRepo: workspaceRepo,
+		apiKeyService: apiKeyService,
+		logger:        logger,
+	}
+}
+
+// CreateWorkspace creates a workspace together with its first admin key, since
+// keys are the only way to act inside a workspace. The plaintext key is returned only here.
+func (s *workspaceService) CreateWorkspace(ctx context.Context, req *request.CreateWorkspaceRequest) (*models.Workspace, *models.APIKey, string, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, nil, "", apperr.Validation("workspace name cannot be empty", apperr.Field("name", "cannot be empty"))
+	}
+
+	req.Slug = strings.ToLower(strings.TrimSpace(req.Slug))
+	if !validSlug(req.Slug) {
+		return nil, nil, "", apperr.Validation("invalid workspace slug",
+			apperr.Field("slug", "must contain only lowercase letters, digits and single hyphens"))
+	}
+
+	req.FromEmail = trimOptional(req.FromEmail)
+	req.FromName = trimOptional(req.FromName)
+
+	workspace, err := s.workspaceRepo.Create(ctx, req)
+	if err != nil {
+		s.logger.Error("Failed to create workspace", zap.Error(err), zap.String("slug", req.Slug))
+		return nil, nil, "", err
+	}
+
+	key, rawKey, err := s.apiKeyService.CreateAPIKey(ctx, workspace.ID, &request.CreateAPIKeyRequest{
+		Name: constants.WorkspaceAdminKeyName,
+		Role: constants.RoleAdmin,
+	})
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	s.logger.Info("Workspace created",
+		zap.String("id", workspace.ID.String()),
+		zap.String("slug", workspace.Slug),
+	)
+
+	return workspace, key, rawKey, nil
+}
+
+func (s *workspaceService) GetWorkspace(ctx context.Context, id uuid.UUID) (*models.Workspace, error) {
+	return s.workspaceRepo.GetByID(ctx, id)
+}
+
+func (s *workspaceService) UpdateWorkspace(ctx context.Context, id uuid.UUID, req *request.UpdateWorkspaceRequest) (*models.Workspace, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, apperr.Validation("workspace name cannot be empty", apperr.Field("name", "cannot be empty"))
+	}
+
+	req.FromEmail = trimOptional(req.FromEmail)
+	req.FromName = trimOptional(req.FromName)
+
+	workspace, err := s.workspaceRepo.Update(ctx, id, req)
+	if err != nil {
+		s.logger.Error("Failed to update workspace", zap.Error(err), zap.String("id", id.String()))
+		return nil, err
+	}
+
+	s.logger.Info("Workspace updated", zap.String("id", workspace.ID.String()))
+	return workspace, nil
+}
+
+// validSlug accepts lowercase alphanumeric words separated by single hyphens
+func validSlug(slug string) bool {
+	if slug == "" || len(slug) > constants.MaxWorkspaceSlugLength {
+		return false
+	}
+	if slug[0] == '-' || slug[len(slug)-1] == '-' || strings.Contains(slug, "--") {
+		return false
+	}
+	for _, r := range slug {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// trimOptional trims an optional string, treating blank values as unset
+func trimOptional(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
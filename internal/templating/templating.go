@@ -0,0 +1,151 @@
+package templating
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidTemplate is returned when a subject or body cannot be parsed or rendered
+var ErrInvalidTemplate = apperr.Validation("invalid template")
+
+// SubscriberData exposes subscriber fields to templates
+type SubscriberData struct {
+	ID    string
+	Name  string
+	Email string
+}
+
+// TopicData exposes topic fields to templates
+type TopicData struct {
+	ID          string
+	Name        string
+	Description string
+}
+
+// Data is the set of variables available to subject and body templates
+type Data struct {
+	Subscriber     SubscriberData
+	Topic          TopicData
+	DeliveryID     string
+	UnsubscribeURL string
+}
+
+// Rendered holds the per-recipient output of a content template
+type Rendered struct {
+	Subject  string
+	HTMLBody string
+	TextBody string
+}
+
+// Template is a parsed content subject/body pair ready for per-recipient rendering
+type Template struct {
+	subject  *texttemplate.Template
+	htmlBody *htmltemplate.Template
+	textBody *texttemplate.Template
+}
+
+// Parse parses the subject and body of a content item
+func Parse(subject, body string) (*Template, error) {
+	subjectTmpl, err := texttemplate.New("subject").Option("missingkey=error").Parse(subject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: subject: %v", ErrInvalidTemplate, err)
+	}
+
+	htmlTmpl, err := htmltemplate.New("html_body").Option("missingkey=error").Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: body: %v", ErrInvalidTemplate, err)
+	}
+
+	textTmpl, err := texttemplate.New("text_body").Option("missingkey=error").Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: body: %v", ErrInvalidTemplate, err)
+	}
+
+	return &Template{
+		subject:  subjectTmpl,
+		htmlBody: htmlTmpl,
+		textBody: textTmpl,
+	}, nil
+}
+
+// Validate parses the templates and renders them once against sample data so
+// that unknown variables and HTML escaping errors surface before send time
+func Validate(subject, body string) error {
+	tmpl, err := Parse(subject, body)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmpl.Render(sampleData()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	return nil
+}
+
+// Render executes the templates for a single recipient
+func (t *Template) Render(data *Data) (*Rendered, error) {
+	var subject, htmlBody, textBody bytes.Buffer
+
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("failed to render subject: %w", err)
+	}
+
+	if err := t.htmlBody.Execute(&htmlBody, data); err != nil {
+		return nil, fmt.Errorf("failed to render body: %w", err)
+	}
+
+	if err := t.textBody.Execute(&textBody, data); err != nil {
+		return nil, fmt.Errorf("failed to render body: %w", err)
+	}
+
+	return &Rendered{
+		Subject:  subject.String(),
+		HTMLBody: htmlBody.String(),
+		TextBody: textBody.String(),
+	}, nil
+}
+
+// NewData builds template variables for a recipient of a content item
+func NewData(subscriber *models.Subscriber, topic *models.Topic, deliveryID uuid.UUID) *Data {
+	data := &Data{
+		Subscriber: SubscriberData{
+			ID:    subscriber.ID.String(),
+			Email: subscriber.Email,
+		},
+		Topic: TopicData{
+			ID:   topic.ID.String(),
+			Name: topic.Name,
+		},
+		DeliveryID: deliveryID.String(),
+	}
+
+	if subscriber.Name != nil {
+		data.Subscriber.Name = *subscriber.Name
+	}
+	if topic.Description != nil {
+		data.Topic.Description = *topic.Description
+	}
+
+	return data
+}
+
+// sampleData returns placeholder variables used for template validation
+func sampleData() *Data {
+	name := "Jane Doe"
+	description := "Sample topic"
+	data := NewData(
+		&models.Subscriber{ID: uuid.New(), Email: "jane@example.com", Name: &name},
+		&models.Topic{ID: uuid.New(), Name: "Sample", Description: &description},
+		uuid.New(),
+	)
+	data.UnsubscribeURL = "https://example.com/unsubscribe"
+	return data
+}
@@ -0,0 +1,36 @@
+package token
+
+import (
+	"github.com/google/uuid"
+)
+
+const purposeConfirmSubscription = "confirm_subscription"
+
+// SignConfirmSubscription creates a token confirming a double opt-in subscription
+func (s *Signer) SignConfirmSubscription(workspaceID, subscriptionID uuid.UUID) string {
+	return s.Sign(purposeConfirmSubscription, workspaceID.String(), subscriptionID.String())
+}
+
+// VerifyConfirmSubscription validates a confirmation token and returns the workspace and subscription IDs
+func (s *Signer) VerifyConfirmSubscription(token string) (uuid.UUID, uuid.UUID, error) {
+	fields, err := s.Verify(purposeConfirmSubscription, token)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	if len(fields) != 2 {
+		return uuid.Nil, uuid.Nil, ErrInvalidToken
+	}
+
+	workspaceID, err := uuid.Parse(fields[0])
+	if err != nil {
+		return uuid.Nil, uuid.Nil, ErrInvalidToken
+	}
+
+	subscriptionID, err := uuid.Parse(fields[1])
+	if err != nil {
+		return uuid.Nil, uuid.Nil, ErrInvalidToken
+	}
+
+	return workspaceID, subscriptionID, nil
+}
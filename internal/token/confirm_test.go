@@ -0,0 +1,46 @@
+package token
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestConfirmSubscriptionRoundTrip(t *testing.T) {
+	s := NewSigner("secret")
+	workspaceID, subscriptionID := uuid.New(), uuid.New()
+
+	gotWorkspace, gotSubscription, err := s.VerifyConfirmSubscription(s.SignConfirmSubscription(workspaceID, subscriptionID))
+	if err != nil {
+		t.Fatalf("VerifyConfirmSubscription() error = %v", err)
+	}
+	if gotWorkspace != workspaceID || gotSubscription != subscriptionID {
+		t.Errorf("VerifyConfirmSubscription() = %s, %s, want %s, %s", gotWorkspace, gotSubscription, workspaceID, subscriptionID)
+	}
+}
+
+func TestVerifyConfirmSubscriptionRejectsInvalidTokens(t *testing.T) {
+	s := NewSigner("secret")
+	id := uuid.New().String()
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "malformed", token: "not-a-token"},
+		{name: "tampered signature", token: NewSigner("other").SignConfirmSubscription(uuid.New(), uuid.New())},
+		{name: "wrong purpose", token: s.SignUnsubscribe(uuid.New(), uuid.New(), uuid.New())},
+		{name: "too few fields", token: s.Sign(purposeConfirmSubscription, id)},
+		{name: "workspace not a UUID", token: s.Sign(purposeConfirmSubscription, "workspace", id)},
+		{name: "subscription not a UUID", token: s.Sign(purposeConfirmSubscription, id, "subscription")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := s.VerifyConfirmSubscription(tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("VerifyConfirmSubscription() error = %v, want %v", err, ErrInvalidToken)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package token
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSignerRoundTrip(t *testing.T) {
+	s := NewSigner("secret")
+
+	fields, err := s.Verify("test", s.Sign("test", "a", "b", ""))
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if want := []string{"a", "b", ""}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("Verify() fields = %q, want %q", fields, want)
+	}
+}
+
+func TestSignerRejectsInvalidTokens(t *testing.T) {
+	s := NewSigner("secret")
+	valid := s.Sign("test", "a", "b")
+	encoded, signature, _ := strings.Cut(valid, ".")
+
+	// Re-signing a payload without the secret only produces a signature of its own
+	forged := base64.RawURLEncoding.EncodeToString([]byte("test|a|c"))
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "no signature", token: encoded},
+		{name: "empty signature", token: encoded + "."},
+		{name: "tampered signature", token: encoded + "." + flipFirstChar(signature)},
+		{name: "tampered payload", token: forged + "." + signature},
+		{name: "signature not base64", token: encoded + ".!!!"},
+		{name: "payload not base64", token: "!!!." + base64.RawURLEncoding.EncodeToString(s.mac("!!!"))},
+		{name: "other secret", token: NewSigner("other").Sign("test", "a", "b")},
+		{name: "wrong purpose", token: s.Sign("other", "a", "b")},
+		{name: "purpose as prefix", token: s.Sign("test_more", "a", "b")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Verify("test", tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify() error = %v, want %v", err, ErrInvalidToken)
+			}
+		})
+	}
+}
+
+// flipFirstChar changes the first character of a base64 string to another valid one.
+// Unlike the last one, it carries no padding bits that decoding would ignore.
+func flipFirstChar(s string) string {
+	if s[0] == 'A' {
+		return "B" + s[1:]
+	}
+	return "A" + s[1:]
+}
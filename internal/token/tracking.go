@@ -0,0 +1,76 @@
+package token
+
+import (
+	"encoding/base64"
+
+	"github.com/google/uuid"
+)
+
+const (
+	purposeTrackOpen  = "track_open"
+	purposeTrackClick = "track_click"
+)
+
+// SignTrackOpen creates a token identifying the delivery a tracking pixel belongs to
+func (s *Signer) SignTrackOpen(workspaceID, deliveryID uuid.UUID) string {
+	return s.Sign(purposeTrackOpen, workspaceID.String(), deliveryID.String())
+}
+
+// VerifyTrackOpen validates a tracking pixel token and returns the workspace and delivery IDs
+func (s *Signer) VerifyTrackOpen(token string) (uuid.UUID, uuid.UUID, error) {
+	fields, err := s.Verify(purposeTrackOpen, token)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	if len(fields) != 2 {
+		return uuid.Nil, uuid.Nil, ErrInvalidToken
+	}
+
+	return parseDeliveryFields(fields)
+}
+
+// SignTrackClick creates a token binding a link in a delivery to its target URL,
+// so that the redirect cannot be pointed anywhere else
+func (s *Signer) SignTrackClick(workspaceID, deliveryID uuid.UUID, target string) string {
+	// The URL is encoded because it may contain the field separator
+	return s.Sign(purposeTrackClick, workspaceID.String(), deliveryID.String(), base64.RawURLEncoding.EncodeToString([]byte(target)))
+}
+
+// VerifyTrackClick validates a click token and returns the workspace and delivery IDs and the target URL
+func (s *Signer) VerifyTrackClick(token string) (uuid.UUID, uuid.UUID, string, error) {
+	fields, err := s.Verify(purposeTrackClick, token)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, "", err
+	}
+
+	if len(fields) != 3 {
+		return uuid.Nil, uuid.Nil, "", ErrInvalidToken
+	}
+
+	workspaceID, deliveryID, err := parseDeliveryFields(fields[:2])
+	if err != nil {
+		return uuid.Nil, uuid.Nil, "", err
+	}
+
+	target, err := base64.RawURLEncoding.DecodeString(fields[2])
+	if err != nil {
+		return uuid.Nil, uuid.Nil, "", ErrInvalidToken
+	}
+
+	return workspaceID, deliveryID, string(target), nil
+}
+
+func parseDeliveryFields(fields []string) (uuid.UUID, uuid.UUID, error) {
+	workspaceID, err := uuid.Parse(fields[0])
+	if err != nil {
+		return uuid.Nil, uuid.Nil, ErrInvalidToken
+	}
+
+	deliveryID, err := uuid.Parse(fields[1])
+	if err != nil {
+		return uuid.Nil, uuid.Nil, ErrInvalidToken
+	}
+
+	return workspaceID, deliveryID, nil
+}
@@ -0,0 +1,84 @@
+package token
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestTrackClickRoundTrip(t *testing.T) {
+	s := NewSigner("secret")
+	workspaceID, deliveryID := uuid.New(), uuid.New()
+	// The separator in the target must not split it into fields
+	target := "https://example.com/path?a=1|2&b=ü#frag"
+
+	gotWorkspace, gotDelivery, gotTarget, err := s.VerifyTrackClick(s.SignTrackClick(workspaceID, deliveryID, target))
+	if err != nil {
+		t.Fatalf("VerifyTrackClick() error = %v", err)
+	}
+	if gotWorkspace != workspaceID || gotDelivery != deliveryID || gotTarget != target {
+		t.Errorf("VerifyTrackClick() = %s, %s, %q, want %s, %s, %q",
+			gotWorkspace, gotDelivery, gotTarget, workspaceID, deliveryID, target)
+	}
+}
+
+func TestVerifyTrackClickRejectsTamperedTarget(t *testing.T) {
+	s := NewSigner("secret")
+	workspaceID, deliveryID := uuid.New(), uuid.New()
+	token := s.SignTrackClick(workspaceID, deliveryID, "https://example.com/")
+
+	// Point the signed payload at another site but keep the original signature
+	_, signature, _ := strings.Cut(token, ".")
+	evil := base64.RawURLEncoding.EncodeToString([]byte("https://evil.example/"))
+	payload := strings.Join([]string{purposeTrackClick, workspaceID.String(), deliveryID.String(), evil}, fieldSeparator)
+	tampered := base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + signature
+
+	if _, _, target, err := s.VerifyTrackClick(tampered); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("VerifyTrackClick() = %q, %v, want %v", target, err, ErrInvalidToken)
+	}
+}
+
+func TestVerifyTrackClickRejectsInvalidTokens(t *testing.T) {
+	s := NewSigner("secret")
+	id := uuid.New().String()
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "malformed", token: "not-a-token"},
+		{name: "other secret", token: NewSigner("other").SignTrackClick(uuid.New(), uuid.New(), "https://example.com/")},
+		{name: "open token", token: s.SignTrackOpen(uuid.New(), uuid.New())},
+		{name: "missing target", token: s.Sign(purposeTrackClick, id, id)},
+		{name: "target not base64", token: s.Sign(purposeTrackClick, id, id, "https://example.com/")},
+		{name: "delivery not a UUID", token: s.Sign(purposeTrackClick, id, "delivery", "aHR0cHM6Ly9leGFtcGxlLmNvbS8")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := s.VerifyTrackClick(tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("VerifyTrackClick() error = %v, want %v", err, ErrInvalidToken)
+			}
+		})
+	}
+}
+
+func TestTrackOpenRoundTrip(t *testing.T) {
+	s := NewSigner("secret")
+	workspaceID, deliveryID := uuid.New(), uuid.New()
+
+	gotWorkspace, gotDelivery, err := s.VerifyTrackOpen(s.SignTrackOpen(workspaceID, deliveryID))
+	if err != nil {
+		t.Fatalf("VerifyTrackOpen() error = %v", err)
+	}
+	if gotWorkspace != workspaceID || gotDelivery != deliveryID {
+		t.Errorf("VerifyTrackOpen() = %s, %s, want %s, %s", gotWorkspace, gotDelivery, workspaceID, deliveryID)
+	}
+
+	if _, _, err := s.VerifyTrackOpen(s.SignConfirmSubscription(workspaceID, deliveryID)); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("VerifyTrackOpen() with a confirmation token error = %v, want %v", err, ErrInvalidToken)
+	}
+}
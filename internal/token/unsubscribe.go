@@ -0,0 +1,41 @@
+package token
+
+import (
+	"newsletter-assignment/internal/constants"
+
+	"github.com/google/uuid"
+)
+
+const purposeUnsubscribe = "unsubscribe"
+
+// SignUnsubscribe creates a token allowing a subscriber to leave a topic
+func (s *Signer) SignUnsubscribe(workspaceID, subscriberID, topicID uuid.UUID) string {
+	return s.Sign(purposeUnsubscribe, workspaceID.String(), subscriberID.String(), topicID.String())
+}
+
+// VerifyUnsubscribe validates an unsubscribe token and returns the workspace, subscriber and topic IDs.
+// Tokens issued before workspaces existed carry no workspace and resolve to the default workspace.
+func (s *Signer) VerifyUnsubscribe(token string) (uuid.UUID, uuid.UUID, uuid.UUID, error) {
+	fields, err := s.Verify(purposeUnsubscribe, token)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, err
+	}
+
+	switch len(fields) {
+	case 2:
+		fields = append([]string{constants.DefaultWorkspaceID}, fields...)
+	case 3:
+	default:
+		return uuid.Nil, uuid.Nil, uuid.Nil, ErrInvalidToken
+	}
+
+	ids := make([]uuid.UUID, len(fields))
+	for i, field := range fields {
+		ids[i], err = uuid.Parse(field)
+		if err != nil {
+			return uuid.Nil, uuid.Nil, uuid.Nil, ErrInvalidToken
+		}
+	}
+
+	return ids[0], ids[1], ids[2], nil
+}
@@ -0,0 +1,66 @@
+package token
+
+import (
+	"errors"
+	"testing"
+
+	"newsletter-assignment/internal/constants"
+
+	"github.com/google/uuid"
+)
+
+func TestUnsubscribeRoundTrip(t *testing.T) {
+	s := NewSigner("secret")
+	workspaceID, subscriberID, topicID := uuid.New(), uuid.New(), uuid.New()
+
+	gotWorkspace, gotSubscriber, gotTopic, err := s.VerifyUnsubscribe(s.SignUnsubscribe(workspaceID, subscriberID, topicID))
+	if err != nil {
+		t.Fatalf("VerifyUnsubscribe() error = %v", err)
+	}
+	if gotWorkspace != workspaceID || gotSubscriber != subscriberID || gotTopic != topicID {
+		t.Errorf("VerifyUnsubscribe() = %s, %s, %s, want %s, %s, %s",
+			gotWorkspace, gotSubscriber, gotTopic, workspaceID, subscriberID, topicID)
+	}
+}
+
+func TestUnsubscribeTokenWithoutWorkspace(t *testing.T) {
+	s := NewSigner("secret")
+	subscriberID, topicID := uuid.New(), uuid.New()
+
+	// Tokens mailed before workspaces existed only carry the subscriber and topic
+	workspaceID, gotSubscriber, gotTopic, err := s.VerifyUnsubscribe(s.Sign(purposeUnsubscribe, subscriberID.String(), topicID.String()))
+	if err != nil {
+		t.Fatalf("VerifyUnsubscribe() error = %v", err)
+	}
+	if workspaceID.String() != constants.DefaultWorkspaceID {
+		t.Errorf("workspace = %s, want the default workspace %s", workspaceID, constants.DefaultWorkspaceID)
+	}
+	if gotSubscriber != subscriberID || gotTopic != topicID {
+		t.Errorf("VerifyUnsubscribe() = %s, %s, want %s, %s", gotSubscriber, gotTopic, subscriberID, topicID)
+	}
+}
+
+func TestVerifyUnsubscribeRejectsInvalidTokens(t *testing.T) {
+	s := NewSigner("secret")
+	id := uuid.New().String()
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "malformed", token: "not-a-token"},
+		{name: "tampered signature", token: NewSigner("other").SignUnsubscribe(uuid.New(), uuid.New(), uuid.New())},
+		{name: "wrong purpose", token: s.SignConfirmSubscription(uuid.New(), uuid.New())},
+		{name: "too few fields", token: s.Sign(purposeUnsubscribe, id)},
+		{name: "too many fields", token: s.Sign(purposeUnsubscribe, id, id, id, id)},
+		{name: "field not a UUID", token: s.Sign(purposeUnsubscribe, id, "subscriber", id)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := s.VerifyUnsubscribe(tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("VerifyUnsubscribe() error = %v, want %v", err, ErrInvalidToken)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package tracking
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// anchorHrefPattern matches the href attribute of an anchor tag up to its quoted value
+var anchorHrefPattern = regexp.MustCompile(`(?is)(<a\b[^>]*?\shref\s*=\s*)("[^"]*"|'[^']*')`)
+
+// bodyClosePattern matches the closing body tag the tracking pixel is placed before
+var bodyClosePattern = regexp.MustCompile(`(?i)</body\s*>`)
+
+// RewriteLinks replaces the target of every http(s) link in an HTML body with the
+// URL returned by clickURL. Targets listed in skip, such as the unsubscribe link,
+// are left untouched.
+func RewriteLinks(body string, clickURL func(target string) string, skip ...string) string {
+	return anchorHrefPattern.ReplaceAllStringFunc(body, func(match string) string {
+		parts := anchorHrefPattern.FindStringSubmatch(match)
+		prefix, quoted := parts[1], parts[2]
+
+		target := strings.TrimSpace(html.UnescapeString(quoted[1 : len(quoted)-1]))
+		if !isTrackable(target, skip) {
+			return match
+		}
+
+		return prefix + `"` + html.EscapeString(clickURL(target)) + `"`
+	})
+}
+
+// AppendPixel adds a 1x1 tracking image to an HTML body, just before </body> when
+// the body has one
+func AppendPixel(body, pixelURL string) string {
+	pixel := fmt.Sprintf(`<img src="%s" width="1" height="1" alt="" style="display:block;border:0;width:1px;height:1px">`, html.EscapeString(pixelURL))
+
+	locs := bodyClosePattern.FindAllStringIndex(body, -1)
+	if len(locs) == 0 {
+		return body + pixel
+	}
+	at := locs[len(locs)-1][0]
+	return body[:at] + pixel + body[at:]
+}
+
+func isTrackable(target string, skip []string) bool {
+	lower := strings.ToLower(target)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+		return false
+	}
+	for _, s := range skip {
+		if target == s {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,124 @@
+package tracking
+
+import (
+	"net/url"
+	"testing"
+)
+
+const unsubscribeURL = "https://newsletter.example.com/unsubscribe?token=abc.def"
+
+// clickURL wraps a target the way the link builder does, with characters that need escaping
+func clickURL(target string) string {
+	return "https://newsletter.example.com/t/c?token=tok&u=" + url.QueryEscape(target)
+}
+
+func TestRewriteLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "double quoted",
+			body: `<a href="https://example.com/a">A</a>`,
+			want: `<a href="https://newsletter.example.com/t/c?token=tok&amp;u=https%3A%2F%2Fexample.com%2Fa">A</a>`,
+		},
+		{
+			name: "single quoted",
+			body: `<a class='x' href='http://example.com/b'>B</a>`,
+			want: `<a class='x' href="https://newsletter.example.com/t/c?token=tok&amp;u=http%3A%2F%2Fexample.com%2Fb">B</a>`,
+		},
+		{
+			name: "entities in href",
+			body: `<a href="https://example.com/?a=1&amp;b=2">C</a>`,
+			want: `<a href="https://newsletter.example.com/t/c?token=tok&amp;u=https%3A%2F%2Fexample.com%2F%3Fa%3D1%26b%3D2">C</a>`,
+		},
+		{
+			name: "upper case tag and scheme, spaces around the value",
+			body: `<A HREF = " HTTPS://example.com/d ">D</A>`,
+			want: `<A HREF = "https://newsletter.example.com/t/c?token=tok&amp;u=HTTPS%3A%2F%2Fexample.com%2Fd">D</A>`,
+		},
+		{
+			name: "attribute spanning lines",
+			body: "<a\n  href=\"https://example.com/e\">E</a>",
+			want: "<a\n  href=\"https://newsletter.example.com/t/c?token=tok&amp;u=https%3A%2F%2Fexample.com%2Fe\">E</a>",
+		},
+		{
+			name: "mailto left alone",
+			body: `<a href="mailto:jane@example.com">Mail</a>`,
+			want: `<a href="mailto:jane@example.com">Mail</a>`,
+		},
+		{
+			name: "javascript left alone",
+			body: `<a href="javascript:alert(1)">JS</a>`,
+			want: `<a href="javascript:alert(1)">JS</a>`,
+		},
+		{
+			name: "relative and fragment links left alone",
+			body: `<a href="/about">About</a><a href="#top">Top</a>`,
+			want: `<a href="/about">About</a><a href="#top">Top</a>`,
+		},
+		{
+			name: "unsubscribe link left alone",
+			body: `<a href="https://newsletter.example.com/unsubscribe?token=abc.def">Unsubscribe</a>`,
+			want: `<a href="https://newsletter.example.com/unsubscribe?token=abc.def">Unsubscribe</a>`,
+		},
+		{
+			name: "escaped unsubscribe link left alone",
+			body: `<a href='https://newsletter.example.com/unsubscribe?token=abc&#46;def'>Unsubscribe</a>`,
+			want: `<a href='https://newsletter.example.com/unsubscribe?token=abc&#46;def'>Unsubscribe</a>`,
+		},
+		{
+			name: "other tags left alone",
+			body: `<link href="https://example.com/style.css"><base href="https://example.com/">`,
+			want: `<link href="https://example.com/style.css"><base href="https://example.com/">`,
+		},
+		{
+			name: "data-href is not href",
+			body: `<a data-href="https://example.com/f">F</a>`,
+			want: `<a data-href="https://example.com/f">F</a>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RewriteLinks(tt.body, clickURL, unsubscribeURL); got != tt.want {
+				t.Errorf("RewriteLinks()\n got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendPixel(t *testing.T) {
+	const pixel = `<img src="https://newsletter.example.com/t/o?token=a&amp;b" width="1" height="1" alt="" style="display:block;border:0;width:1px;height:1px">`
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "before closing body",
+			body: `<html><body><p>Hi</p></body></html>`,
+			want: `<html><body><p>Hi</p>` + pixel + `</body></html>`,
+		},
+		{
+			name: "before the last closing body",
+			body: `<body><pre>&lt;/body&gt; and </body> in text</pre></BODY >`,
+			want: `<body><pre>&lt;/body&gt; and </body> in text</pre>` + pixel + `</BODY >`,
+		},
+		{
+			name: "appended without closing body",
+			body: `<p>Hi</p>`,
+			want: `<p>Hi</p>` + pixel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AppendPixel(tt.body, "https://newsletter.example.com/t/o?token=a&b"); got != tt.want {
+				t.Errorf("AppendPixel()\n got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
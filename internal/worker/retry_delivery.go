@@ -0,0 +1,203 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"newsletter-assignment/internal/apperr"
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/email"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/templating"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+// HandleRetryDelivery processes a retry of a single failed delivery
+func (w *SendContentWorker) HandleRetryDelivery(ctx context.Context, task *asynq.Task) error {
+	var payload struct {
+		DeliveryID string `json:"delivery_id"`
+		JobID      string `json:"job_id"`
+	}
+
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		w.logger.Error("Failed to unmarshal task payload", zap.Error(err))
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	deliveryID, err := uuid.Parse(payload.DeliveryID)
+	if err != nil {
+		w.logger.Error("Invalid delivery ID", zap.String("delivery_id", payload.DeliveryID), zap.Error(err))
+		return fmt.Errorf("invalid delivery ID: %w", err)
+	}
+
+	jobID, err := uuid.Parse(payload.JobID)
+	if err != nil {
+		w.logger.Error("Invalid job ID", zap.String("job_id", payload.JobID), zap.Error(err))
+		return fmt.Errorf("invalid job ID: %w", err)
+	}
+
+	w.logger.Info("Processing retry delivery task",
+		zap.String("delivery_id", deliveryID.String()),
+		zap.String("job_id", jobID.String()),
+	)
+
+	// The retry job carries the workspace of the delivery
+	job, err := w.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch job: %w", err)
+	}
+
+	stopHeartbeat := w.startHeartbeat(ctx, jobID)
+	defer stopHeartbeat()
+
+	// Claim the delivery; it may have been delivered or given up in the meantime
+	delivery, claimed, err := w.deliveryRepo.ClaimRetry(ctx, job.WorkspaceID, deliveryID)
+	if err != nil {
+		return fmt.Errorf("failed to claim delivery retry: %w", err)
+	}
+
+	if !claimed {
+		w.logger.Info("Delivery no longer awaiting retry, skipping", zap.String("delivery_id", deliveryID.String()))
+		w.completeRetryJob(ctx, jobID, models.DeliveryCounts{Skipped: 1})
+		return nil
+	}
+
+	workspace, content, topic, tmpl, subscriber, err := w.loadRetryContext(ctx, delivery)
+	if err != nil {
+		// Release the delivery so that it is not left pending forever
+		w.recordFailure(ctx, delivery, err)
+
+		errorMsg := err.Error()
+		if updateErr := w.jobRepo.UpdateResult(ctx, jobID, constants.JobStatusFailed, 1, &errorMsg, models.DeliveryCounts{Failed: 1}); updateErr != nil {
+			w.logger.Error("Failed to update job status", zap.Error(updateErr))
+		}
+		return fmt.Errorf("failed to load delivery context: %w", err)
+	}
+
+	// The address may have been suppressed since the first attempt
+	suppression, err := w.suppressionRepo.GetByEmail(ctx, delivery.WorkspaceID, delivery.Email)
+	if err != nil && !errors.Is(err, apperr.ErrNotFound) {
+		w.recordFailure(ctx, delivery, err)
+
+		errorMsg := err.Error()
+		if updateErr := w.jobRepo.UpdateResult(ctx, jobID, constants.JobStatusFailed, 1, &errorMsg, models.DeliveryCounts{Failed: 1}); updateErr != nil {
+			w.logger.Error("Failed to update job status", zap.Error(updateErr))
+		}
+		return fmt.Errorf("failed to look up suppression: %w", err)
+	}
+
+	var counts models.DeliveryCounts
+	if suppression != nil {
+		w.recordSuppressed(ctx, delivery, suppression.Reason)
+		counts.Skipped = 1
+	} else {
+		switch w.deliver(ctx, workspace, content, topic, tmpl, subscriber, delivery) {
+		case outcomeSent:
+			counts.Sent = 1
+		default:
+			counts.Failed = 1
+		}
+	}
+
+	// Content that already finished reflects the outcome of late retries
+	if content.Status != constants.ContentStatusScheduled {
+		w.finalizeContent(ctx, content.WorkspaceID, content.ID)
+	}
+
+	w.completeRetryJob(ctx, jobID, counts)
+	return nil
+}
+
+// loadRetryContext fetches everything needed to render and send a delivery again
+func (w *SendContentWorker) loadRetryContext(ctx context.Context, delivery *models.Delivery) (*models.Workspace, *models.Content, *models.Topic, *templating.Template, *models.Subscriber, error) {
+	workspace, err := w.workspaceRepo.GetByID(ctx, delivery.WorkspaceID)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+
+	content, err := w.contentRepo.GetByID(ctx, delivery.WorkspaceID, delivery.ContentID)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+
+	if content.Status == constants.ContentStatusCancelled {
+		return nil, nil, nil, nil, nil, &email.SendError{Permanent: true, Err: fmt.Errorf("content was cancelled")}
+	}
+
+	topic, err := w.topicRepo.GetByID(ctx, delivery.WorkspaceID, content.TopicID)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+
+	tmpl, err := templating.Parse(content.Subject, content.Body)
+	if err != nil {
+		return nil, nil, nil, nil, nil, &email.SendError{Permanent: true, Err: err}
+	}
+
+	subscriber, err := w.subscriberRepo.GetByID(ctx, delivery.WorkspaceID, delivery.SubscriberID)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+
+	return workspace, content, topic, tmpl, subscriber, nil
+}
+
+// completeRetryJob marks a retry job as completed with its outcome
+func (w *SendContentWorker) completeRetryJob(ctx context.Context, jobID uuid.UUID, counts models.DeliveryCounts) {
+	if err := w.jobRepo.UpdateResult(ctx, jobID, constants.JobStatusCompleted, 1, nil, counts); err != nil {
+		w.logger.Error("Failed to update job status", zap.Error(err))
+	}
+}
+
+// recordFailure stores a failed attempt. Transient failures are scheduled for
+// retry with exponential backoff until the attempt limit is reached; permanent
+// failures and exhausted retries become undeliverable. So do failures that may have
+// been delivered after all, which a retry could deliver twice.
+func (w *SendContentWorker) recordFailure(ctx context.Context, delivery *models.Delivery, sendErr error) {
+	errorMsg := sendErr.Error()
+
+	if email.IsPermanent(sendErr) || email.IsOutcomeUnknown(sendErr) || delivery.Attempts >= w.options.RetryMaxAttempts {
+		if err := w.deliveryRepo.UpdateDeliveryFailure(ctx, delivery.WorkspaceID, delivery.ID, constants.DeliveryStatusUndeliverable, &errorMsg, nil); err != nil {
+			w.logger.Error("Failed to update delivery status to undeliverable", zap.Error(err))
+		}
+		return
+	}
+
+	nextRetryAt := time.Now().Add(w.retryDelay(delivery.Attempts))
+	if err := w.deliveryRepo.UpdateDeliveryFailure(ctx, delivery.WorkspaceID, delivery.ID, constants.DeliveryStatusFailed, &errorMsg, &nextRetryAt); err != nil {
+		w.logger.Error("Failed to update delivery status to failed", zap.Error(err))
+		return
+	}
+
+	if _, err := w.jobRepo.CreateRetryJob(ctx, delivery.WorkspaceID, delivery.ContentID, delivery.ID, nextRetryAt); err != nil {
+		w.logger.Error("Failed to schedule delivery retry",
+			zap.String("delivery_id", delivery.ID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	w.logger.Info("Delivery retry scheduled",
+		zap.String("delivery_id", delivery.ID.String()),
+		zap.Int("attempts", delivery.Attempts),
+		zap.Time("next_retry_at", nextRetryAt),
+	)
+}
+
+// retryDelay returns the exponential backoff delay after the given number of attempts
+func (w *SendContentWorker) retryDelay(attempts int) time.Duration {
+	delay := w.options.RetryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= w.options.RetryMaxDelay {
+			return w.options.RetryMaxDelay
+		}
+	}
+	return delay
+}
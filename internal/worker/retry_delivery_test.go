@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/email"
+	"newsletter-assignment/internal/models"
+)
+
+func TestRetryDelay(t *testing.T) {
+	w := &SendContentWorker{options: SendContentOptions{
+		RetryBaseDelay: time.Minute,
+		RetryMaxDelay:  10 * time.Minute,
+	}}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: time.Minute},
+		{attempts: 1, want: time.Minute},
+		{attempts: 2, want: 2 * time.Minute},
+		{attempts: 3, want: 4 * time.Minute},
+		{attempts: 4, want: 8 * time.Minute},
+		{attempts: 5, want: 10 * time.Minute},
+		{attempts: 50, want: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("attempts=%d", tt.attempts), func(t *testing.T) {
+			if got := w.retryDelay(tt.attempts); got != tt.want {
+				t.Errorf("retryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordFailure(t *testing.T) {
+	transient := &email.SendError{Err: errors.New("service unavailable")}
+	permanent := &email.SendError{Permanent: true, Err: errors.New("invalid recipient")}
+	unconfirmed := &email.SendError{OutcomeUnknown: true, Err: errors.New("connection reset after data")}
+
+	tests := []struct {
+		name     string
+		attempts int
+		err      error
+		want     string
+		retry    bool
+	}{
+		{name: "transient first attempt", attempts: 1, err: transient, want: constants.DeliveryStatusFailed, retry: true},
+		{name: "transient before last attempt", attempts: constants.DefaultDeliveryRetryMaxAttempts - 1, err: transient, want: constants.DeliveryStatusFailed, retry: true},
+		{name: "transient last attempt", attempts: constants.DefaultDeliveryRetryMaxAttempts, err: transient, want: constants.DeliveryStatusUndeliverable},
+		{name: "unclassified error", attempts: 1, err: errors.New("connection refused"), want: constants.DeliveryStatusFailed, retry: true},
+		{name: "permanent", attempts: 1, err: permanent, want: constants.DeliveryStatusUndeliverable},
+		{name: "outcome unknown", attempts: 1, err: unconfirmed, want: constants.DeliveryStatusUndeliverable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(1)
+			subscriber := s.subscribers[0]
+			d := &storedDelivery{Delivery: models.Delivery{
+				ID:           uuid.New(),
+				WorkspaceID:  s.workspace.ID,
+				ContentID:    s.content.ID,
+				SubscriberID: subscriber.ID,
+				Email:        subscriber.Email,
+				Status:       constants.DeliveryStatusPending,
+				Attempts:     tt.attempts,
+			}}
+			s.deliveries[subscriber.ID] = d
+
+			w := newTestWorker(&process{store: s, crashAfter: -1}, false, 1)
+			before := time.Now()
+			w.recordFailure(context.Background(), &d.Delivery, tt.err)
+
+			if d.Status != tt.want {
+				t.Errorf("delivery status = %q, want %q", d.Status, tt.want)
+			}
+			if d.ErrorMessage == nil || *d.ErrorMessage != tt.err.Error() {
+				t.Errorf("delivery error = %v, want %q", d.ErrorMessage, tt.err.Error())
+			}
+			switch {
+			case !tt.retry && d.NextRetryAt != nil:
+				t.Errorf("next retry at %v, want none", d.NextRetryAt)
+			case tt.retry && d.NextRetryAt == nil:
+				t.Error("no retry scheduled")
+			case tt.retry && d.NextRetryAt.Before(before.Add(w.retryDelay(tt.attempts))):
+				t.Errorf("next retry at %v, want at least %v from now", d.NextRetryAt, w.retryDelay(tt.attempts))
+			}
+		})
+	}
+}
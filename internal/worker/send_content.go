@@ -9,49 +9,116 @@ import (
 
 	"newsletter-assignment/internal/constants"
 	"newsletter-assignment/internal/email"
+	"newsletter-assignment/internal/links"
 	"newsletter-assignment/internal/models"
 	"newsletter-assignment/internal/repo"
+	"newsletter-assignment/internal/templating"
+	"newsletter-assignment/internal/tracking"
 
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 	"go.uber.org/zap"
 )
 
-// subscriberData holds subscriber information for email sending
-type subscriberData struct {
-	ID    uuid.UUID
-	Email string
+// sendOutcome is the result of attempting to mail a single subscriber
+type sendOutcome int
+
+const (
+	outcomeSent sendOutcome = iota
+	outcomeFailed
+	// outcomeSkipped means the delivery was already completed by an earlier attempt
+	outcomeSkipped
+	// outcomeSuppressed means the address is on the suppression list and was not mailed
+	outcomeSuppressed
+	// outcomeDeferred means another run of the content holds the delivery
+	outcomeDeferred
+)
+
+// recipient is an audience member together with the reason their address is
+// suppressed, if it is
+type recipient struct {
+	subscriber        *models.Subscriber
+	suppressionReason string
 }
 
 // SendContentWorker handles sending newsletter content to subscribers
 type SendContentWorker struct {
-	contentRepo      repo.ContentRepository
-	subscriptionRepo repo.SubscriptionRepository
-	subscriberRepo   repo.SubscriberRepository
-	jobRepo          repo.JobRepository
-	deliveryRepo     repo.DeliveryRepository
-	emailSender      email.EmailSender
-	logger           *zap.Logger
+	workspaceRepo   repo.WorkspaceRepository
+	contentRepo     repo.ContentRepository
+	topicRepo       repo.TopicRepository
+	subscriberRepo  repo.SubscriberRepository
+	jobRepo         repo.JobRepository
+	deliveryRepo    repo.DeliveryRepository
+	suppressionRepo repo.SuppressionRepository
+	emailSender     email.EmailSender
+	links           *links.Builder
+	options         SendContentOptions
+	logger          *zap.Logger
+}
+
+// SendContentOptions tunes how the worker resolves and mails the audience
+type SendContentOptions struct {
+	// Concurrency is the number of emails sent in parallel
+	Concurrency int
+	// BatchSize is the number of subscribers fetched per audience page
+	BatchSize int
+	// FailureThreshold is the share of failed sends (0-1) above which the job fails
+	FailureThreshold float64
+	// RetryMaxAttempts is the number of attempts per delivery before it is given up
+	RetryMaxAttempts int
+	// RetryBaseDelay is the delay before the first retry; it doubles on every attempt
+	RetryBaseDelay time.Duration
+	// RetryMaxDelay caps the delay between retries
+	RetryMaxDelay time.Duration
+	// HeartbeatInterval is how often a running job records that it is still alive
+	HeartbeatInterval time.Duration
 }
 
 // NewSendContentWorker creates a new send content worker
 func NewSendContentWorker(
+	workspaceRepo repo.WorkspaceRepository,
 	contentRepo repo.ContentRepository,
-	subscriptionRepo repo.SubscriptionRepository,
+	topicRepo repo.TopicRepository,
 	subscriberRepo repo.SubscriberRepository,
 	jobRepo repo.JobRepository,
 	deliveryRepo repo.DeliveryRepository,
+	suppressionRepo repo.SuppressionRepository,
 	emailSender email.EmailSender,
+	linkBuilder *links.Builder,
+	options SendContentOptions,
 	logger *zap.Logger,
 ) *SendContentWorker {
+	if options.Concurrency <= 0 {
+		options.Concurrency = constants.DefaultWorkerConcurrency
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = constants.DefaultWorkerAudienceBatchSize
+	}
+	if options.RetryMaxAttempts <= 0 {
+		options.RetryMaxAttempts = constants.DefaultDeliveryRetryMaxAttempts
+	}
+	if options.RetryBaseDelay <= 0 {
+		options.RetryBaseDelay = constants.DefaultDeliveryRetryBaseDelay
+	}
+	if options.RetryMaxDelay <= 0 {
+		options.RetryMaxDelay = constants.DefaultDeliveryRetryMaxDelay
+	}
+	if options.HeartbeatInterval <= 0 {
+		options.HeartbeatInterval = constants.DefaultWorkerHeartbeatInterval
+	}
+
 	return &SendContentWorker{
-		contentRepo:      contentRepo,
-		subscriptionRepo: subscriptionRepo,
-		subscriberRepo:   subscriberRepo,
-		jobRepo:          jobRepo,
-		deliveryRepo:     deliveryRepo,
-		emailSender:      emailSender,
-		logger:           logger,
+		workspaceRepo:   workspaceRepo,
+		contentRepo:     contentRepo,
+		topicRepo:       topicRepo,
+		subscriberRepo:  subscriberRepo,
+		jobRepo:         jobRepo,
+		deliveryRepo:    deliveryRepo,
+		suppressionRepo: suppressionRepo,
+		emailSender:     emailSender,
+		links:           linkBuilder,
+		options:         options,
+		logger:          logger,
 	}
 }
 
@@ -84,8 +151,19 @@ func (w *SendContentWorker) HandleSendContent(ctx context.Context, task *asynq.T
 		zap.String("job_id", jobID.String()),
 	)
 
-	// Fetch content
-	content, err := w.contentRepo.GetByID(ctx, contentID)
+	// Fetch job to track attempts across retries
+	job, err := w.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		w.logger.Error("Failed to fetch job", zap.String("job_id", jobID.String()), zap.Error(err))
+		return fmt.Errorf("failed to fetch job: %w", err)
+	}
+
+	// Keep the scheduler's reaper away while the audience is being mailed
+	stopHeartbeat := w.startHeartbeat(ctx, jobID)
+	defer stopHeartbeat()
+
+	// Fetch content from the workspace the job belongs to
+	content, err := w.contentRepo.GetByID(ctx, job.WorkspaceID, contentID)
 	if err != nil {
 		w.logger.Error("Failed to fetch content", zap.String("content_id", contentID.String()), zap.Error(err))
 
@@ -96,166 +174,631 @@ func (w *SendContentWorker) HandleSendContent(ctx context.Context, task *asynq.T
 		return fmt.Errorf("failed to fetch content: %w", err)
 	}
 
-	// Fetch active subscriptions for this topic
-	subscriptions, err := w.subscriptionRepo.ListByTopic(ctx, content.TopicID)
+	// A retried task for content that already finished has nothing left to do
+	if content.Status != constants.ContentStatusScheduled {
+		w.logger.Info("Content already processed, skipping",
+			zap.String("content_id", contentID.String()),
+			zap.String("job_id", jobID.String()),
+			zap.String("status", content.Status),
+		)
+		// A job that failed for good keeps its result
+		if job.Status == constants.JobStatusFailed && job.Attempts >= job.MaxAttempts {
+			return nil
+		}
+		if err := w.jobRepo.UpdateStatus(ctx, jobID, constants.JobStatusCompleted); err != nil {
+			w.logger.Error("Failed to update job status", zap.Error(err))
+		}
+		return nil
+	}
+
+	// Fetch topic for template variables
+	topic, err := w.topicRepo.GetByID(ctx, content.WorkspaceID, content.TopicID)
+	if err != nil {
+		w.logger.Error("Failed to fetch topic", zap.String("topic_id", content.TopicID.String()), zap.Error(err))
+
+		// Update job status to failed
+		errorMsg := fmt.Sprintf("Failed to fetch topic: %v", err)
+		w.jobRepo.UpdateStatusWithError(ctx, jobID, constants.JobStatusFailed, 1, &errorMsg)
+
+		return fmt.Errorf("failed to fetch topic: %w", err)
+	}
+
+	// Fetch workspace for its sender identity
+	workspace, err := w.workspaceRepo.GetByID(ctx, content.WorkspaceID)
 	if err != nil {
-		w.logger.Error("Failed to fetch subscriptions",
+		w.logger.Error("Failed to fetch workspace", zap.String("workspace_id", content.WorkspaceID.String()), zap.Error(err))
+
+		// Update job status to failed
+		errorMsg := fmt.Sprintf("Failed to fetch workspace: %v", err)
+		w.jobRepo.UpdateStatusWithError(ctx, jobID, constants.JobStatusFailed, 1, &errorMsg)
+
+		return fmt.Errorf("failed to fetch workspace: %w", err)
+	}
+
+	// Parse content templates once for all recipients
+	tmpl, err := templating.Parse(content.Subject, content.Body)
+	if err != nil {
+		w.logger.Error("Failed to parse content templates", zap.String("content_id", contentID.String()), zap.Error(err))
+
+		// Update job status to failed
+		errorMsg := fmt.Sprintf("Failed to parse content templates: %v", err)
+		w.jobRepo.UpdateStatusWithError(ctx, jobID, constants.JobStatusFailed, 1, &errorMsg)
+
+		return fmt.Errorf("failed to parse content templates: %w", err)
+	}
+
+	// Local-time content is split into one job per time zone
+	filter, err := w.audienceFilter(ctx, content, job)
+	if err != nil {
+		w.logger.Error("Failed to resolve audience time zones", zap.String("content_id", contentID.String()), zap.Error(err))
+
+		// Update job status to failed
+		errorMsg := fmt.Sprintf("Failed to resolve audience time zones: %v", err)
+		w.jobRepo.UpdateStatusWithError(ctx, jobID, constants.JobStatusFailed, 1, &errorMsg)
+
+		return fmt.Errorf("failed to resolve audience time zones: %w", err)
+	}
+
+	// Stream the active audience in batches into the sender pool
+	counts, recipients, deferred, err := w.sendToAudience(ctx, workspace, content, topic, tmpl, filter)
+	if err != nil && ctx.Err() == nil {
+		w.logger.Error("Failed to resolve audience",
 			zap.String("topic_id", content.TopicID.String()),
 			zap.Error(err),
 		)
 
 		// Update job status to failed
-		errorMsg := fmt.Sprintf("Failed to fetch subscriptions: %v", err)
+		errorMsg := fmt.Sprintf("Failed to resolve audience: %v", err)
 		w.jobRepo.UpdateStatusWithError(ctx, jobID, constants.JobStatusFailed, 1, &errorMsg)
 
-		return fmt.Errorf("failed to fetch subscriptions: %w", err)
+		return fmt.Errorf("failed to resolve audience: %w", err)
 	}
 
-	// Filter active subscriptions and collect subscriber data
-	activeSubscribers := 0
-	subscribersData := make([]subscriberData, 0)
+	// If the task was interrupted, leave content scheduled so a retry resumes
+	// from the deliveries that are not yet sent
+	if err := ctx.Err(); err != nil {
+		w.logger.Warn("Send content task interrupted",
+			zap.String("content_id", contentID.String()),
+			zap.String("job_id", jobID.String()),
+			zap.Error(err),
+		)
+		return fmt.Errorf("send content task interrupted: %w", err)
+	}
 
-	for _, subscription := range subscriptions {
-		if subscription.IsActive {
-			subscriber, err := w.subscriberRepo.GetByID(ctx, subscription.SubscriberID)
-			if err != nil {
-				w.logger.Warn("Failed to fetch subscriber",
-					zap.String("subscriber_id", subscription.SubscriberID.String()),
-					zap.Error(err),
-				)
-				continue
-			}
-			activeSubscribers++
-			subscribersData = append(subscribersData, subscriberData{
-				ID:    subscriber.ID,
-				Email: subscriber.Email,
-			})
-		}
+	// Deliveries still held by another run are neither sent nor failed yet. Leave
+	// the job as it is and let the task come back once their claims have expired.
+	if deferred > 0 {
+		w.logger.Warn("Deliveries claimed by another run, retrying later",
+			zap.String("content_id", contentID.String()),
+			zap.String("job_id", jobID.String()),
+			zap.Int("deferred", deferred),
+		)
+		return fmt.Errorf("%d deliveries are claimed by another run", deferred)
 	}
 
-	// Send emails in parallel with actual SMTP sending
-	w.sendEmailsInParallel(ctx, content, subscribersData)
+	attempts := job.Attempts + 1
+	jobFailed := w.exceedsFailureThreshold(counts)
 
-	// Simulate processing time and success
-	w.logger.Info("Content processing completed successfully",
+	w.logger.Info("Content processing completed",
 		zap.String("content_id", content.ID.String()),
-		zap.Int("emails_sent", activeSubscribers),
+		zap.Int("recipients", recipients),
+		zap.Int("sent", counts.Sent),
+		zap.Int("failed", counts.Failed),
+		zap.Int("skipped", counts.Skipped),
+		zap.Int("attempt", attempts),
 	)
 
-	// Update content status to sent
-	if err := w.contentRepo.UpdateStatus(ctx, contentID, constants.ContentStatusSent); err != nil {
-		w.logger.Error("Failed to update content status", zap.Error(err))
-		// Don't return error here as the main processing was successful
+	if jobFailed {
+		// The job is not run again: transient failures are retried delivery by delivery
+		// and permanent ones would fail again, so a rerun would find nothing to send.
+		// Like a manually failed job, it has no attempts left.
+		errorMsg := fmt.Sprintf("%d of %d emails failed", counts.Failed, counts.Sent+counts.Failed)
+		if err := w.jobRepo.UpdateResult(ctx, jobID, constants.JobStatusFailed, max(attempts, job.MaxAttempts), &errorMsg, counts); err != nil {
+			w.logger.Error("Failed to update job status", zap.Error(err))
+		}
+
+		w.finalizeIfLastJob(ctx, content.WorkspaceID, contentID)
+
+		w.logger.Warn("Send content task exceeded failure threshold",
+			zap.String("content_id", contentID.String()),
+			zap.String("job_id", jobID.String()),
+			zap.Float64("failure_threshold", w.options.FailureThreshold),
+		)
+
+		return fmt.Errorf("%s: %w", errorMsg, asynq.SkipRetry)
 	}
 
 	// Update job status to completed
-	if err := w.jobRepo.UpdateStatus(ctx, jobID, constants.JobStatusCompleted); err != nil {
+	if err := w.jobRepo.UpdateResult(ctx, jobID, constants.JobStatusCompleted, attempts, nil, counts); err != nil {
 		w.logger.Error("Failed to update job status", zap.Error(err))
 		// Don't return error here as the main processing was successful
 	}
 
+	w.finalizeIfLastJob(ctx, content.WorkspaceID, contentID)
+
 	w.logger.Info("Send content task completed successfully",
 		zap.String("content_id", contentID.String()),
 		zap.String("job_id", jobID.String()),
-		zap.Int("subscribers_notified", activeSubscribers),
+		zap.Int("subscribers_notified", counts.Sent),
 	)
 
 	return nil
 }
 
-// sendEmailsInParallel sends emails to multiple subscribers concurrently
-func (w *SendContentWorker) sendEmailsInParallel(ctx context.Context, content *models.Content, subscribers []subscriberData) {
-	const maxConcurrency = 20 // Increased concurrency for better performance
+// startHeartbeat records a heartbeat for the job now and then every heartbeat
+// interval until the returned function is called
+func (w *SendContentWorker) startHeartbeat(ctx context.Context, jobID uuid.UUID) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(w.options.HeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := w.jobRepo.Heartbeat(ctx, jobID); err != nil && ctx.Err() == nil {
+				w.logger.Warn("Failed to record job heartbeat", zap.String("job_id", jobID.String()), zap.Error(err))
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// exceedsFailureThreshold reports whether the share of failed sends in a run is
+// above the configured threshold
+func (w *SendContentWorker) exceedsFailureThreshold(counts models.DeliveryCounts) bool {
+	attempted := counts.Sent + counts.Failed
+	if attempted == 0 || counts.Failed == 0 {
+		return false
+	}
+	return float64(counts.Failed)/float64(attempted) > w.options.FailureThreshold
+}
+
+// finalizeIfLastJob finalizes the content once none of its send jobs is still
+// pending, running or due for another attempt. The job result must be recorded
+// before calling so that concurrent time-zone jobs do not wait on each other.
+func (w *SendContentWorker) finalizeIfLastJob(ctx context.Context, workspaceID, contentID uuid.UUID) {
+	unfinished, err := w.jobRepo.CountUnfinishedSendJobs(ctx, workspaceID, contentID)
+	if err != nil {
+		w.logger.Error("Failed to count unfinished send jobs", zap.String("content_id", contentID.String()), zap.Error(err))
+		return
+	}
+
+	if unfinished > 0 {
+		w.logger.Info("Content has send jobs left, not finalizing yet",
+			zap.String("content_id", contentID.String()),
+			zap.Int("unfinished_jobs", unfinished),
+		)
+		return
+	}
 
-	// Create a semaphore to limit concurrency
-	semaphore := make(chan struct{}, maxConcurrency)
+	w.finalizeContent(ctx, workspaceID, contentID)
+}
+
+// audienceFilter returns the part of the audience a send job is responsible for.
+// Time-zone jobs of local-time content target their zone; the default job targets
+// everyone whose zone has no job of its own.
+func (w *SendContentWorker) audienceFilter(ctx context.Context, content *models.Content, job *models.JobScheduler) (repo.AudienceFilter, error) {
+	if content.DeliveryMode != constants.DeliveryModeLocalTime {
+		return repo.AudienceFilter{}, nil
+	}
+
+	if job.Timezone != nil {
+		return repo.AudienceFilter{Timezone: job.Timezone}, nil
+	}
+
+	timezones, err := w.jobRepo.ListTimezonesByContent(ctx, content.WorkspaceID, content.ID)
+	if err != nil {
+		return repo.AudienceFilter{}, err
+	}
+
+	return repo.AudienceFilter{ExcludeTimezones: timezones}, nil
+}
+
+// finalizeContent sets the content status and totals from all of its deliveries
+func (w *SendContentWorker) finalizeContent(ctx context.Context, workspaceID, contentID uuid.UUID) {
+	totals, err := w.deliveryRepo.CountByContent(ctx, workspaceID, contentID)
+	if err != nil {
+		w.logger.Error("Failed to count deliveries", zap.String("content_id", contentID.String()), zap.Error(err))
+		return
+	}
+
+	if err := w.contentRepo.UpdateStatusWithCounts(ctx, workspaceID, contentID, totals.ContentStatus(), totals); err != nil {
+		w.logger.Error("Failed to update content status", zap.Error(err))
+	}
+}
+
+// sendToAudience pages through the active subscribers of the content's topic and
+// feeds them to a fixed pool of senders. It returns the outcome counts of the run, the
+// number of recipients resolved and the number of deliveries left to another run.
+func (w *SendContentWorker) sendToAudience(ctx context.Context, workspace *models.Workspace, content *models.Content, topic *models.Topic, tmpl *templating.Template, filter repo.AudienceFilter) (models.DeliveryCounts, int, int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	recipients := make(chan recipient, w.options.BatchSize)
 	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var counts models.DeliveryCounts
+	deferred := 0
+
+	record := func(outcome sendOutcome) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch outcome {
+		case outcomeSent:
+			counts.Sent++
+		case outcomeFailed:
+			counts.Failed++
+		case outcomeSkipped, outcomeSuppressed:
+			counts.Skipped++
+		case outcomeDeferred:
+			deferred++
+		}
+	}
+
+	batchSender, sendBatchSize := w.batchSender()
 
 	w.logger.Info("Starting parallel email sending",
-		zap.Int("total_emails", len(subscribers)),
-		zap.Int("max_concurrency", maxConcurrency),
+		zap.String("content_id", content.ID.String()),
+		zap.Int("batch_size", w.options.BatchSize),
+		zap.Int("send_batch_size", sendBatchSize),
+		zap.Int("max_concurrency", w.options.Concurrency),
 	)
 
-	for i, subscriber := range subscribers {
+	for i := 0; i < w.options.Concurrency; i++ {
 		wg.Add(1)
-
-		go func(sub subscriberData, index int) {
+		go func() {
 			defer wg.Done()
 
-			// Acquire semaphore (limit concurrency)
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
+			// Providers that support batching get the recipients in chunks
+			var batch []recipient
+			flush := func() {
+				for _, outcome := range w.sendBatch(ctx, batchSender, workspace, content, topic, tmpl, batch) {
+					record(outcome)
+				}
+				batch = batch[:0]
+			}
+
+			for r := range recipients {
+				// Stop claiming new deliveries once the task is cancelled
+				if ctx.Err() != nil {
+					continue
+				}
+				if batchSender == nil {
+					record(w.sendSingleEmail(ctx, workspace, content, topic, tmpl, r))
+					continue
+				}
+
+				batch = append(batch, r)
+				if len(batch) >= sendBatchSize {
+					flush()
+				}
+			}
 
-			// Send email with actual SMTP
-			w.sendSingleEmail(ctx, content, sub.Email, sub.ID, index)
-		}(subscriber, i)
+			if len(batch) > 0 && ctx.Err() == nil {
+				flush()
+			}
+		}()
+	}
+
+	total, err := w.streamAudience(ctx, content.WorkspaceID, content.TopicID, filter, recipients)
+	close(recipients)
+	if err != nil {
+		cancel()
 	}
 
-	// Wait for all emails to be sent
+	// Wait for in-flight emails to finish
 	wg.Wait()
 
 	w.logger.Info("Parallel email sending completed",
-		zap.Int("total_emails", len(subscribers)),
+		zap.String("content_id", content.ID.String()),
+		zap.Int("total_emails", total),
 	)
+
+	return counts, total, deferred, err
+}
+
+// streamAudience fetches active subscribers with keyset pagination, looks up which
+// of them are suppressed, and pushes them onto the recipients channel
+func (w *SendContentWorker) streamAudience(ctx context.Context, workspaceID, topicID uuid.UUID, filter repo.AudienceFilter, recipients chan<- recipient) (int, error) {
+	total := 0
+	after := uuid.Nil
+
+	for {
+		batch, err := w.subscriberRepo.ListActiveByTopic(ctx, workspaceID, topicID, filter, after, w.options.BatchSize)
+		if err != nil {
+			return total, err
+		}
+
+		emails := make([]string, len(batch))
+		for i, subscriber := range batch {
+			emails[i] = subscriber.Email
+		}
+		suppressed, err := w.suppressionRepo.ReasonsByEmail(ctx, workspaceID, emails)
+		if err != nil {
+			return total, err
+		}
+
+		for _, subscriber := range batch {
+			select {
+			case recipients <- recipient{subscriber: subscriber, suppressionReason: suppressed[subscriber.Email]}:
+				total++
+			case <-ctx.Done():
+				return total, ctx.Err()
+			}
+		}
+
+		if len(batch) < w.options.BatchSize {
+			return total, nil
+		}
+		after = batch[len(batch)-1].ID
+	}
+}
+
+// batchSender returns the sender to use for batches and the batch size, or nil when
+// the provider cannot batch
+func (w *SendContentWorker) batchSender() (email.BatchSender, int) {
+	batchSender, ok := w.emailSender.(email.BatchSender)
+	if !ok {
+		return nil, 1
+	}
+
+	size := batchSender.MaxBatchSize()
+	if size > w.options.BatchSize {
+		size = w.options.BatchSize
+	}
+	if size < 2 {
+		return nil, 1
+	}
+	return batchSender, size
 }
 
 // sendSingleEmail sends an email to a single subscriber and tracks delivery
-func (w *SendContentWorker) sendSingleEmail(ctx context.Context, content *models.Content, subscriberEmail string, subscriberID uuid.UUID, index int) {
+func (w *SendContentWorker) sendSingleEmail(ctx context.Context, workspace *models.Workspace, content *models.Content, topic *models.Topic, tmpl *templating.Template, r recipient) sendOutcome {
+	delivery, outcome, ok := w.claimRecipient(ctx, content, r)
+	if !ok {
+		return outcome
+	}
+
+	return w.deliver(ctx, workspace, content, topic, tmpl, r.subscriber, delivery)
+}
+
+// sendBatch claims and renders the deliveries of a chunk of recipients, sends them in
+// one provider call and records the result of each. It returns one outcome per recipient.
+func (w *SendContentWorker) sendBatch(ctx context.Context, batchSender email.BatchSender, workspace *models.Workspace, content *models.Content, topic *models.Topic, tmpl *templating.Template, rs []recipient) []sendOutcome {
 	start := time.Now()
+	outcomes := make([]sendOutcome, 0, len(rs))
+
+	var deliveries []*models.Delivery
+	var reqs []*email.EmailRequest
+	for _, r := range rs {
+		delivery, outcome, ok := w.claimRecipient(ctx, content, r)
+		if !ok {
+			outcomes = append(outcomes, outcome)
+			continue
+		}
+
+		emailReq, ok := w.prepareEmail(ctx, workspace, content, topic, tmpl, r.subscriber, delivery)
+		if !ok {
+			outcomes = append(outcomes, outcomeFailed)
+			continue
+		}
+
+		deliveries = append(deliveries, delivery)
+		reqs = append(reqs, emailReq)
+	}
+
+	if len(reqs) == 0 {
+		return outcomes
+	}
+
+	// The sender narrows a rejected batch down to the emails that caused it; an error
+	// here means the whole batch failed
+	results, err := batchSender.SendBatch(reqs)
+	for i, delivery := range deliveries {
+		var result *email.SendResult
+		sendErr := err
+		switch {
+		case err != nil:
+		case i < len(results):
+			result, sendErr = results[i].Result, results[i].Err
+		default:
+			sendErr = fmt.Errorf("provider returned no result for this email")
+		}
+		outcomes = append(outcomes, w.recordResult(ctx, content, delivery, result, sendErr, start))
+	}
 
-	// Create delivery record
-	delivery, err := w.deliveryRepo.CreateDelivery(ctx, content.ID, subscriberID, subscriberEmail, constants.DeliveryStatusPending)
+	w.logger.Info("Email batch processed",
+		zap.String("content_id", content.ID.String()),
+		zap.Int("emails", len(reqs)),
+		zap.Duration("send_duration", time.Since(start)),
+	)
+	return outcomes
+}
+
+// claimRecipient claims the delivery record of a recipient. It returns false with the
+// outcome when the recipient must not be mailed: deliveries already sent by a previous
+// attempt are skipped, deliveries another run holds are deferred and suppressed
+// addresses are recorded as such.
+func (w *SendContentWorker) claimRecipient(ctx context.Context, content *models.Content, r recipient) (*models.Delivery, sendOutcome, bool) {
+	subscriber := r.subscriber
+	subscriberEmail := subscriber.Email
+
+	delivery, claimed, err := w.deliveryRepo.ClaimDelivery(ctx, content.WorkspaceID, content.ID, subscriber.ID, subscriberEmail)
 	if err != nil {
-		w.logger.Error("Failed to create delivery record",
+		w.logger.Error("Failed to claim delivery record",
 			zap.String("content_id", content.ID.String()),
 			zap.String("subscriber_email", subscriberEmail),
 			zap.Error(err),
 		)
-		return
+		return nil, outcomeFailed, false
+	}
+
+	if !claimed && delivery == nil {
+		w.logger.Warn("Delivery record belongs to another workspace, skipping",
+			zap.String("content_id", content.ID.String()),
+			zap.String("recipient", subscriberEmail),
+		)
+		return nil, outcomeSkipped, false
+	}
+
+	if !claimed && delivery.Status == constants.DeliveryStatusPending {
+		w.logger.Debug("Delivery claimed by another run, deferring",
+			zap.String("content_id", content.ID.String()),
+			zap.String("recipient", subscriberEmail),
+		)
+		return nil, outcomeDeferred, false
+	}
+
+	if !claimed {
+		w.logger.Debug("Delivery already completed, skipping",
+			zap.String("content_id", content.ID.String()),
+			zap.String("recipient", subscriberEmail),
+		)
+		return nil, outcomeSkipped, false
+	}
+
+	if r.suppressionReason != "" {
+		w.recordSuppressed(ctx, delivery, r.suppressionReason)
+		return nil, outcomeSuppressed, false
+	}
+
+	return delivery, outcomeSent, true
+}
+
+// deliver renders and sends a claimed delivery and records the result
+func (w *SendContentWorker) deliver(ctx context.Context, workspace *models.Workspace, content *models.Content, topic *models.Topic, tmpl *templating.Template, subscriber *models.Subscriber, delivery *models.Delivery) sendOutcome {
+	start := time.Now()
+
+	emailReq, ok := w.prepareEmail(ctx, workspace, content, topic, tmpl, subscriber, delivery)
+	if !ok {
+		return outcomeFailed
+	}
+
+	result, err := w.emailSender.Send(emailReq)
+	return w.recordResult(ctx, content, delivery, result, err, start)
+}
+
+// prepareEmail renders the email of a claimed delivery. A rendering failure is
+// recorded on the delivery and reported as false.
+func (w *SendContentWorker) prepareEmail(ctx context.Context, workspace *models.Workspace, content *models.Content, topic *models.Topic, tmpl *templating.Template, subscriber *models.Subscriber, delivery *models.Delivery) (*email.EmailRequest, bool) {
+	subscriberEmail := subscriber.Email
+
+	unsubscribeURL := w.links.UnsubscribeURL(workspace.ID, subscriber.ID, topic.ID)
+
+	// Render subject and body for this recipient
+	data := templating.NewData(subscriber, topic, delivery.ID)
+	data.UnsubscribeURL = unsubscribeURL
+	rendered, err := tmpl.Render(data)
+	if err != nil {
+		// Rendering is deterministic, so retrying would fail the same way
+		w.recordFailure(ctx, delivery, &email.SendError{Permanent: true, Err: err})
+
+		w.logger.Error("Failed to render email",
+			zap.String("content_id", content.ID.String()),
+			zap.String("recipient", subscriberEmail),
+			zap.String("delivery_id", delivery.ID.String()),
+			zap.Error(err),
+		)
+		return nil, false
+	}
+
+	// Opens and clicks are only tracked where the topic or the content opted in
+	if trackingEnabled(content, topic) {
+		rendered.HTMLBody = tracking.RewriteLinks(rendered.HTMLBody, func(target string) string {
+			return w.links.TrackClickURL(workspace.ID, delivery.ID, target)
+		}, unsubscribeURL)
+		rendered.HTMLBody = tracking.AppendPixel(rendered.HTMLBody, w.links.TrackOpenURL(workspace.ID, delivery.ID))
 	}
 
 	// Prepare email request
 	emailReq := &email.EmailRequest{
 		To:       subscriberEmail,
-		Subject:  content.Subject,
-		HTMLBody: content.Body, // Assuming content.Body contains HTML
-		TextBody: content.Body, // For now, use same content for text
+		Subject:  rendered.Subject,
+		HTMLBody: rendered.HTMLBody,
+		TextBody: rendered.TextBody,
+
+		ListUnsubscribeURL: unsubscribeURL,
+	}
+	if workspace.FromEmail != nil {
+		emailReq.FromEmail = *workspace.FromEmail
+	}
+	if workspace.FromName != nil {
+		emailReq.FromName = *workspace.FromName
 	}
 
-	// Send email via SMTP
-	err = w.emailSender.Send(emailReq)
+	return emailReq, true
+}
+
+// trackingEnabled reports whether open and click tracking applies to content; the
+// content's own setting wins over its topic's
+func trackingEnabled(content *models.Content, topic *models.Topic) bool {
+	if content.TrackingEnabled != nil {
+		return *content.TrackingEnabled
+	}
+	return topic.TrackingEnabled
+}
 
-	// Update delivery status based on result
+// recordResult updates a delivery with the result of sending it
+func (w *SendContentWorker) recordResult(ctx context.Context, content *models.Content, delivery *models.Delivery, result *email.SendResult, err error, start time.Time) sendOutcome {
 	now := time.Now()
 	if err != nil {
 		// Email failed
-		errorMsg := err.Error()
-		updateErr := w.deliveryRepo.UpdateDeliveryStatus(ctx, delivery.ID, constants.DeliveryStatusFailed, nil, &errorMsg)
-		if updateErr != nil {
-			w.logger.Error("Failed to update delivery status to failed", zap.Error(updateErr))
-		}
+		w.recordFailure(ctx, delivery, err)
 
 		w.logger.Error("Failed to send email",
 			zap.String("content_id", content.ID.String()),
-			zap.String("recipient", subscriberEmail),
+			zap.String("recipient", delivery.Email),
 			zap.String("delivery_id", delivery.ID.String()),
-			zap.Int("email_index", index),
 			zap.Duration("send_duration", time.Since(start)),
 			zap.Error(err),
 		)
-	} else {
-		// Email sent successfully
-		updateErr := w.deliveryRepo.UpdateDeliveryStatus(ctx, delivery.ID, constants.DeliveryStatusSent, &now, nil)
-		if updateErr != nil {
-			w.logger.Error("Failed to update delivery status to sent", zap.Error(updateErr))
-		}
+		return outcomeFailed
+	}
 
-		w.logger.Info("Email sent successfully",
-			zap.String("content_id", content.ID.String()),
-			zap.String("recipient", subscriberEmail),
-			zap.String("delivery_id", delivery.ID.String()),
-			zap.Int("email_index", index),
-			zap.Duration("send_duration", time.Since(start)),
-		)
+	// Email sent successfully; the message ID lets provider webhooks find this delivery
+	var providerMessageID *string
+	if result != nil && result.MessageID != "" {
+		providerMessageID = &result.MessageID
+	}
+	updateErr := w.deliveryRepo.MarkSent(ctx, delivery.WorkspaceID, delivery.ID, now, providerMessageID)
+	if updateErr != nil {
+		w.logger.Error("Failed to update delivery status to sent", zap.Error(updateErr))
 	}
+
+	w.logger.Info("Email sent successfully",
+		zap.String("content_id", content.ID.String()),
+		zap.String("recipient", delivery.Email),
+		zap.String("delivery_id", delivery.ID.String()),
+		zap.Duration("send_duration", time.Since(start)),
+	)
+	return outcomeSent
+}
+
+// recordSuppressed marks a claimed delivery as suppressed so that reports show why
+// the address was not mailed
+func (w *SendContentWorker) recordSuppressed(ctx context.Context, delivery *models.Delivery, reason string) {
+	errorMsg := fmt.Sprintf("address is suppressed: %s", reason)
+	if err := w.deliveryRepo.UpdateDeliveryStatus(ctx, delivery.WorkspaceID, delivery.ID, constants.DeliveryStatusSuppressed, nil, &errorMsg); err != nil {
+		w.logger.Error("Failed to update delivery status to suppressed", zap.Error(err))
+		return
+	}
+
+	w.logger.Info("Skipped suppressed address",
+		zap.String("content_id", delivery.ContentID.String()),
+		zap.String("delivery_id", delivery.ID.String()),
+		zap.String("reason", reason),
+	)
 }
@@ -0,0 +1,739 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"newsletter-assignment/internal/constants"
+	"newsletter-assignment/internal/email"
+	"newsletter-assignment/internal/links"
+	"newsletter-assignment/internal/models"
+	"newsletter-assignment/internal/repo"
+	"newsletter-assignment/internal/token"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+// errCrashed is returned by the fakes once the run they belong to has crashed
+var errCrashed = errors.New("process crashed")
+
+// store is the state shared by all runs of a test: the database and the mailboxes
+type store struct {
+	mu  sync.Mutex
+	now time.Time
+
+	workspace   *models.Workspace
+	topic       *models.Topic
+	content     *models.Content
+	job         *models.JobScheduler
+	subscribers []*models.Subscriber
+
+	deliveries map[uuid.UUID]*storedDelivery
+	// mailbox counts the emails each address received
+	mailbox map[string]int
+	// rejected is an address the provider refuses
+	rejected string
+	// unconfirmed is an address whose emails arrive although the provider reports
+	// the send as failed with unknown outcome
+	unconfirmed string
+	// unavailable holds addresses the provider fails to take transiently
+	unavailable map[string]bool
+	// suppressed maps suppressed addresses to their reason
+	suppressed map[string]string
+}
+
+type storedDelivery struct {
+	models.Delivery
+	claimedAt *time.Time
+}
+
+func newStore(subscriberCount int) *store {
+	workspaceID := uuid.New()
+	topic := &models.Topic{ID: uuid.New(), WorkspaceID: workspaceID, Name: "News"}
+	s := &store{
+		now:       time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC),
+		workspace: &models.Workspace{ID: workspaceID, Name: "Acme"},
+		topic:     topic,
+		content: &models.Content{
+			ID:           uuid.New(),
+			WorkspaceID:  workspaceID,
+			TopicID:      topic.ID,
+			Subject:      "Hello {{.Subscriber.Email}}",
+			Body:         "<p>News for {{.Subscriber.Email}}</p>",
+			Status:       constants.ContentStatusScheduled,
+			DeliveryMode: constants.DeliveryModeAbsolute,
+		},
+		deliveries: make(map[uuid.UUID]*storedDelivery),
+		mailbox:    make(map[string]int),
+	}
+	s.job = &models.JobScheduler{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		ContentID:   s.content.ID,
+		JobType:     constants.JobTypeSendNewsletter,
+		Status:      constants.JobStatusEnqueued,
+		MaxAttempts: 3,
+	}
+
+	for i := 0; i < subscriberCount; i++ {
+		s.subscribers = append(s.subscribers, &models.Subscriber{
+			ID:          uuid.New(),
+			WorkspaceID: workspaceID,
+			Email:       fmt.Sprintf("subscriber%d@example.com", i),
+			IsActive:    true,
+		})
+	}
+	sort.Slice(s.subscribers, func(i, j int) bool {
+		return s.subscribers[i].ID.String() < s.subscribers[j].ID.String()
+	})
+
+	return s
+}
+
+// expireClaims moves the clock past the claim lease
+func (s *store) expireClaims() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(constants.DeliveryClaimLease + time.Second)
+}
+
+func (s *store) mailed() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mailed := make(map[string]int, len(s.mailbox))
+	for address, count := range s.mailbox {
+		mailed[address] = count
+	}
+	return mailed
+}
+
+// inDoubt returns the addresses that were mailed but whose delivery is still pending,
+// i.e. the run crashed between handing the email over and recording it
+func (s *store) inDoubt() map[string]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doubtful := make(map[string]bool)
+	for _, d := range s.deliveries {
+		if d.Status == constants.DeliveryStatusPending && s.mailbox[d.Email] > 0 {
+			doubtful[d.Email] = true
+		}
+	}
+	return doubtful
+}
+
+// process is one run of the worker. After crashAfter side effects it crashes: the
+// effects it attempts from then on are lost, as they would be with a dead process.
+type process struct {
+	store      *store
+	crashAfter int
+	effects    int
+	crashed    bool
+}
+
+// apply runs a side effect against the store unless the process has crashed
+func (p *process) apply(effect func(s *store) error) error {
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+
+	if p.crashed {
+		return errCrashed
+	}
+	if p.crashAfter >= 0 && p.effects >= p.crashAfter {
+		p.crashed = true
+		return errCrashed
+	}
+	p.effects++
+	return effect(p.store)
+}
+
+// read runs a query against the store unless the process has crashed
+func (p *process) read(query func(s *store) error) error {
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+
+	if p.crashed {
+		return errCrashed
+	}
+	return query(p.store)
+}
+
+type fakeWorkspaceRepo struct {
+	repo.WorkspaceRepository
+	p *process
+}
+
+func (r *fakeWorkspaceRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Workspace, error) {
+	return r.p.store.workspace, nil
+}
+
+type fakeTopicRepo struct {
+	repo.TopicRepository
+	p *process
+}
+
+func (r *fakeTopicRepo) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Topic, error) {
+	return r.p.store.topic, nil
+}
+
+type fakeContentRepo struct {
+	repo.ContentRepository
+	p *process
+}
+
+func (r *fakeContentRepo) GetByID(ctx context.Context, workspaceID, id uuid.UUID) (*models.Content, error) {
+	var content models.Content
+	err := r.p.read(func(s *store) error {
+		content = *s.content
+		return nil
+	})
+	return &content, err
+}
+
+func (r *fakeContentRepo) UpdateStatusWithCounts(ctx context.Context, workspaceID, id uuid.UUID, status string, counts models.DeliveryCounts) error {
+	return r.p.apply(func(s *store) error {
+		s.content.Status = status
+		s.content.SentCount = counts.Sent
+		s.content.FailedCount = counts.Failed
+		s.content.SkippedCount = counts.Skipped
+		return nil
+	})
+}
+
+type fakeSubscriberRepo struct {
+	repo.SubscriberRepository
+	p *process
+}
+
+func (r *fakeSubscriberRepo) ListActiveByTopic(ctx context.Context, workspaceID, topicID uuid.UUID, filter repo.AudienceFilter, afterID uuid.UUID, limit int) ([]*models.Subscriber, error) {
+	var page []*models.Subscriber
+	err := r.p.read(func(s *store) error {
+		for _, subscriber := range s.subscribers {
+			if subscriber.ID.String() > afterID.String() && len(page) < limit {
+				page = append(page, subscriber)
+			}
+		}
+		return nil
+	})
+	return page, err
+}
+
+type fakeSuppressionRepo struct {
+	repo.SuppressionRepository
+	p *process
+}
+
+func (r *fakeSuppressionRepo) ReasonsByEmail(ctx context.Context, workspaceID uuid.UUID, emails []string) (map[string]string, error) {
+	reasons := make(map[string]string)
+	err := r.p.read(func(s *store) error {
+		for _, address := range emails {
+			if reason, ok := s.suppressed[address]; ok {
+				reasons[address] = reason
+			}
+		}
+		return nil
+	})
+	return reasons, err
+}
+
+type fakeJobRepo struct {
+	repo.JobRepository
+	p *process
+}
+
+func (r *fakeJobRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.JobScheduler, error) {
+	var job models.JobScheduler
+	err := r.p.read(func(s *store) error {
+		job = *s.job
+		return nil
+	})
+	return &job, err
+}
+
+func (r *fakeJobRepo) Heartbeat(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func (r *fakeJobRepo) CountUnfinishedSendJobs(ctx context.Context, workspaceID, contentID uuid.UUID) (int, error) {
+	unfinished := 0
+	err := r.p.read(func(s *store) error {
+		if s.job.Status != constants.JobStatusCompleted && s.job.Status != constants.JobStatusFailed {
+			unfinished++
+		}
+		return nil
+	})
+	return unfinished, err
+}
+
+func (r *fakeJobRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
+	return r.p.apply(func(s *store) error {
+		s.job.Status = status
+		return nil
+	})
+}
+
+func (r *fakeJobRepo) UpdateStatusWithError(ctx context.Context, id uuid.UUID, status string, attempts int, errorMessage *string) error {
+	return r.p.apply(func(s *store) error {
+		s.job.Status = status
+		s.job.Attempts = attempts
+		s.job.ErrorMessage = errorMessage
+		return nil
+	})
+}
+
+func (r *fakeJobRepo) UpdateResult(ctx context.Context, id uuid.UUID, status string, attempts int, errorMessage *string, counts models.DeliveryCounts) error {
+	return r.p.apply(func(s *store) error {
+		s.job.Status = status
+		s.job.Attempts = attempts
+		s.job.ErrorMessage = errorMessage
+		return nil
+	})
+}
+
+func (r *fakeJobRepo) CreateRetryJob(ctx context.Context, workspaceID, contentID, deliveryID uuid.UUID, scheduledAt time.Time) (*models.JobScheduler, error) {
+	return &models.JobScheduler{ID: uuid.New()}, r.p.apply(func(s *store) error { return nil })
+}
+
+// fakeDeliveryRepo mirrors the claim rules of the SQL in deliveryRepo
+type fakeDeliveryRepo struct {
+	repo.DeliveryRepository
+	p *process
+}
+
+func (r *fakeDeliveryRepo) ClaimDelivery(ctx context.Context, workspaceID, contentID, subscriberID uuid.UUID, address string) (*models.Delivery, bool, error) {
+	var delivery models.Delivery
+	claimed := false
+	err := r.p.apply(func(s *store) error {
+		d, ok := s.deliveries[subscriberID]
+		if !ok {
+			d = &storedDelivery{Delivery: models.Delivery{
+				ID:           uuid.New(),
+				WorkspaceID:  workspaceID,
+				ContentID:    contentID,
+				SubscriberID: subscriberID,
+				Email:        address,
+			}}
+			s.deliveries[subscriberID] = d
+		} else if d.WorkspaceID != workspaceID {
+			// The upsert does not touch the row and the lookup finds none in the workspace
+			return nil
+		} else {
+			leaseExpired := d.claimedAt == nil || d.claimedAt.Before(s.now.Add(-constants.DeliveryClaimLease))
+			reclaimable := (d.Status == constants.DeliveryStatusPending && leaseExpired) ||
+				(d.Status == constants.DeliveryStatusFailed && d.NextRetryAt == nil)
+			if !reclaimable {
+				delivery = d.Delivery
+				return nil
+			}
+		}
+
+		now := s.now
+		d.Status = constants.DeliveryStatusPending
+		d.ErrorMessage = nil
+		d.Attempts++
+		d.claimedAt = &now
+		delivery = d.Delivery
+		claimed = true
+		return nil
+	})
+	if err != nil || (!claimed && delivery.ID == uuid.Nil) {
+		return nil, false, err
+	}
+	return &delivery, claimed, nil
+}
+
+func (r *fakeDeliveryRepo) update(workspaceID, id uuid.UUID, update func(d *storedDelivery)) error {
+	return r.p.apply(func(s *store) error {
+		for _, d := range s.deliveries {
+			if d.WorkspaceID == workspaceID && d.ID == id {
+				update(d)
+				return nil
+			}
+		}
+		return fmt.Errorf("delivery %s not found", id)
+	})
+}
+
+func (r *fakeDeliveryRepo) MarkSent(ctx context.Context, workspaceID, id uuid.UUID, sentAt time.Time, providerMessageID *string) error {
+	return r.update(workspaceID, id, func(d *storedDelivery) {
+		d.Status = constants.DeliveryStatusSent
+		d.SentAt = &sentAt
+		d.ProviderMessageID = providerMessageID
+	})
+}
+
+func (r *fakeDeliveryRepo) UpdateDeliveryStatus(ctx context.Context, workspaceID, id uuid.UUID, status string, sentAt *time.Time, errorMessage *string) error {
+	return r.update(workspaceID, id, func(d *storedDelivery) {
+		d.Status = status
+		d.SentAt = sentAt
+		d.ErrorMessage = errorMessage
+	})
+}
+
+func (r *fakeDeliveryRepo) UpdateDeliveryFailure(ctx context.Context, workspaceID, id uuid.UUID, status string, errorMessage *string, nextRetryAt *time.Time) error {
+	return r.update(workspaceID, id, func(d *storedDelivery) {
+		d.Status = status
+		d.ErrorMessage = errorMessage
+		d.NextRetryAt = nextRetryAt
+	})
+}
+
+func (r *fakeDeliveryRepo) CountByContent(ctx context.Context, workspaceID, contentID uuid.UUID) (models.DeliveryCounts, error) {
+	var counts models.DeliveryCounts
+	err := r.p.read(func(s *store) error {
+		for _, d := range s.deliveries {
+			switch d.Status {
+			case constants.DeliveryStatusSent:
+				counts.Sent++
+			case constants.DeliveryStatusFailed, constants.DeliveryStatusUndeliverable:
+				counts.Failed++
+			case constants.DeliveryStatusBounced:
+				counts.Bounced++
+			case constants.DeliveryStatusSuppressed:
+				counts.Skipped++
+			}
+		}
+		return nil
+	})
+	return counts, err
+}
+
+// fakeSender hands emails over to the store's mailboxes
+type fakeSender struct {
+	p *process
+}
+
+func (f *fakeSender) Send(req *email.EmailRequest) (*email.SendResult, error) {
+	err := f.p.apply(func(s *store) error {
+		if req.To == s.rejected {
+			return &email.SendError{Permanent: true, Err: errors.New("invalid recipient")}
+		}
+		if s.unavailable[req.To] {
+			return &email.SendError{Err: errors.New("service unavailable")}
+		}
+		s.mailbox[req.To]++
+		if req.To == s.unconfirmed {
+			return &email.SendError{OutcomeUnknown: true, Err: errors.New("connection reset after data")}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &email.SendResult{MessageID: uuid.NewString(), Provider: "fake"}, nil
+}
+
+// fakeBatchSender accepts a whole batch in one side effect. Like the unified sender
+// after splitting a rejected batch, it fails only the rejected address.
+type fakeBatchSender struct {
+	fakeSender
+}
+
+func (f *fakeBatchSender) MaxBatchSize() int {
+	return 3
+}
+
+func (f *fakeBatchSender) SendBatch(reqs []*email.EmailRequest) ([]email.BatchResult, error) {
+	results := make([]email.BatchResult, len(reqs))
+	err := f.p.apply(func(s *store) error {
+		for i, req := range reqs {
+			if req.To == s.rejected {
+				results[i].Err = &email.SendError{Permanent: true, Err: errors.New("invalid recipient")}
+				continue
+			}
+			s.mailbox[req.To]++
+			results[i].Result = &email.SendResult{MessageID: uuid.NewString(), Provider: "fake"}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// newTestWorker builds a worker whose repositories and sender belong to process p
+func newTestWorker(p *process, batching bool, concurrency int) *SendContentWorker {
+	var sender email.EmailSender = &fakeSender{p: p}
+	if batching {
+		sender = &fakeBatchSender{fakeSender{p: p}}
+	}
+
+	return NewSendContentWorker(
+		&fakeWorkspaceRepo{p: p},
+		&fakeContentRepo{p: p},
+		&fakeTopicRepo{p: p},
+		&fakeSubscriberRepo{p: p},
+		&fakeJobRepo{p: p},
+		&fakeDeliveryRepo{p: p},
+		&fakeSuppressionRepo{p: p},
+		sender,
+		links.NewBuilder("https://newsletter.example.com", token.NewSigner("secret")),
+		SendContentOptions{
+			Concurrency:       concurrency,
+			BatchSize:         2,
+			FailureThreshold:  constants.DefaultSendFailureThreshold,
+			RetryMaxAttempts:  constants.DefaultDeliveryRetryMaxAttempts,
+			RetryBaseDelay:    constants.DefaultDeliveryRetryBaseDelay,
+			RetryMaxDelay:     constants.DefaultDeliveryRetryMaxDelay,
+			HeartbeatInterval: time.Hour,
+		},
+		zap.NewNop(),
+	)
+}
+
+// runSend runs one send content task against the store; crashAfter < 0 never crashes
+func runSend(t *testing.T, s *store, crashAfter int, batching bool, concurrency int) error {
+	t.Helper()
+
+	payload, err := json.Marshal(map[string]string{
+		"content_id": s.content.ID.String(),
+		"job_id":     s.job.ID.String(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &process{store: s, crashAfter: crashAfter}
+	return newTestWorker(p, batching, concurrency).HandleSendContent(context.Background(), asynq.NewTask("send_newsletter", payload))
+}
+
+func TestHandleSendContentResumesAfterCrash(t *testing.T) {
+	const subscribers = 5
+	// Every claim, send and status update is a side effect; crash before each of them
+	const maxEffects = 3*subscribers + 4
+
+	for _, batching := range []bool{false, true} {
+		for crashAfter := 0; crashAfter <= maxEffects; crashAfter++ {
+			t.Run(fmt.Sprintf("batching=%t/crash_after=%d", batching, crashAfter), func(t *testing.T) {
+				s := newStore(subscribers)
+
+				runSend(t, s, crashAfter, batching, 1)
+				inDoubt := s.inDoubt()
+
+				// A redelivered task runs while the crashed run's claims still hold
+				runSend(t, s, -1, batching, 1)
+				for address, count := range s.mailed() {
+					if count > 1 {
+						t.Fatalf("%s mailed %d times before the claims expired", address, count)
+					}
+				}
+
+				s.expireClaims()
+				if err := runSend(t, s, -1, batching, 1); err != nil {
+					t.Fatalf("run after claims expired: %v", err)
+				}
+
+				mailed := s.mailed()
+				for _, subscriber := range s.subscribers {
+					count := mailed[subscriber.Email]
+					switch {
+					case count == 0:
+						t.Errorf("%s was never mailed", subscriber.Email)
+					case count > 2, count == 2 && !inDoubt[subscriber.Email]:
+						t.Errorf("%s mailed %d times", subscriber.Email, count)
+					}
+				}
+				if s.content.Status != constants.ContentStatusSent {
+					t.Errorf("content status = %q, want %q", s.content.Status, constants.ContentStatusSent)
+				}
+				if s.content.SentCount != subscribers {
+					t.Errorf("content sent count = %d, want %d", s.content.SentCount, subscribers)
+				}
+			})
+		}
+	}
+}
+
+func TestHandleSendContentConcurrentRunsMailOnce(t *testing.T) {
+	for _, batching := range []bool{false, true} {
+		t.Run(fmt.Sprintf("batching=%t", batching), func(t *testing.T) {
+			s := newStore(50)
+
+			var wg sync.WaitGroup
+			errs := make([]error, 2)
+			for i := range errs {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					errs[i] = runSend(t, s, -1, batching, 4)
+				}(i)
+			}
+			wg.Wait()
+
+			// A run that met deliveries held by the other one asks to be retried
+			if errs[0] != nil || errs[1] != nil {
+				if err := runSend(t, s, -1, batching, 4); err != nil {
+					t.Fatalf("retried run: %v", err)
+				}
+			}
+
+			mailed := s.mailed()
+			for _, subscriber := range s.subscribers {
+				if count := mailed[subscriber.Email]; count != 1 {
+					t.Errorf("%s mailed %d times, want 1", subscriber.Email, count)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleSendContentRejectedEmailFailsAlone(t *testing.T) {
+	s := newStore(6)
+	s.rejected = s.subscribers[2].Email
+
+	if err := runSend(t, s, -1, true, 1); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	mailed := s.mailed()
+	for _, subscriber := range s.subscribers {
+		want := 1
+		if subscriber.Email == s.rejected {
+			want = 0
+		}
+		if count := mailed[subscriber.Email]; count != want {
+			t.Errorf("%s mailed %d times, want %d", subscriber.Email, count, want)
+		}
+	}
+
+	for _, d := range s.deliveries {
+		want := constants.DeliveryStatusSent
+		if d.Email == s.rejected {
+			want = constants.DeliveryStatusUndeliverable
+		}
+		if d.Status != want {
+			t.Errorf("delivery to %s is %q, want %q", d.Email, d.Status, want)
+		}
+	}
+	if s.content.Status != constants.ContentStatusPartiallySent {
+		t.Errorf("content status = %q, want %q", s.content.Status, constants.ContentStatusPartiallySent)
+	}
+}
+
+func TestHandleSendContentCountsSuppressedAsSkipped(t *testing.T) {
+	s := newStore(4)
+	s.suppressed = map[string]string{s.subscribers[1].Email: constants.SuppressionReasonHardBounce}
+
+	if err := runSend(t, s, -1, false, 1); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if count := s.mailed()[s.subscribers[1].Email]; count != 0 {
+		t.Errorf("suppressed address mailed %d times", count)
+	}
+	if s.content.Status != constants.ContentStatusSent {
+		t.Errorf("content status = %q, want %q", s.content.Status, constants.ContentStatusSent)
+	}
+	if s.content.SentCount != 3 || s.content.SkippedCount != 1 {
+		t.Errorf("content counts sent=%d skipped=%d, want 3 and 1", s.content.SentCount, s.content.SkippedCount)
+	}
+}
+
+func TestHandleSendContentDoesNotResendWhenOutcomeUnknown(t *testing.T) {
+	s := newStore(3)
+	s.unconfirmed = s.subscribers[0].Email
+
+	if err := runSend(t, s, -1, false, 1); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	// Neither a delivery retry nor a rerun of the content may mail the address again
+	s.expireClaims()
+	if err := runSend(t, s, -1, false, 1); err != nil {
+		t.Fatalf("rerun: %v", err)
+	}
+
+	if count := s.mailed()[s.unconfirmed]; count != 1 {
+		t.Errorf("%s mailed %d times, want 1", s.unconfirmed, count)
+	}
+	d := s.deliveries[s.subscribers[0].ID]
+	if d.Status != constants.DeliveryStatusUndeliverable || d.NextRetryAt != nil {
+		t.Errorf("delivery is %q with next retry %v, want %q without retry", d.Status, d.NextRetryAt, constants.DeliveryStatusUndeliverable)
+	}
+}
+
+func TestHandleSendContentThresholdFailureIsNotRerun(t *testing.T) {
+	s := newStore(4)
+	s.unavailable = map[string]bool{
+		s.subscribers[0].Email: true,
+		s.subscribers[1].Email: true,
+		s.subscribers[2].Email: true,
+	}
+
+	err := runSend(t, s, -1, false, 1)
+	if !errors.Is(err, asynq.SkipRetry) {
+		t.Fatalf("run error = %v, want SkipRetry", err)
+	}
+	if s.job.Status != constants.JobStatusFailed || s.job.Attempts != s.job.MaxAttempts {
+		t.Errorf("job is %q after %d of %d attempts, want %q with no attempts left",
+			s.job.Status, s.job.Attempts, s.job.MaxAttempts, constants.JobStatusFailed)
+	}
+	for _, subscriber := range s.subscribers[:3] {
+		d := s.deliveries[subscriber.ID]
+		if d.Status != constants.DeliveryStatusFailed || d.NextRetryAt == nil {
+			t.Errorf("delivery to %s is %q with next retry %v, want a pending retry", d.Email, d.Status, d.NextRetryAt)
+		}
+	}
+	if s.content.Status != constants.ContentStatusPartiallySent {
+		t.Errorf("content status = %q, want %q", s.content.Status, constants.ContentStatusPartiallySent)
+	}
+
+	// A redelivered task neither mails the deliveries pending retry nor completes the job
+	s.unavailable = nil
+	s.expireClaims()
+	if err := runSend(t, s, -1, false, 1); err != nil {
+		t.Fatalf("rerun: %v", err)
+	}
+	mailed := s.mailed()
+	for _, subscriber := range s.subscribers {
+		want := 0
+		if subscriber == s.subscribers[3] {
+			want = 1
+		}
+		if count := mailed[subscriber.Email]; count != want {
+			t.Errorf("%s mailed %d times, want %d", subscriber.Email, count, want)
+		}
+	}
+	if s.job.Status != constants.JobStatusFailed {
+		t.Errorf("job status after rerun = %q, want %q", s.job.Status, constants.JobStatusFailed)
+	}
+}
+
+func TestHandleSendContentSkipsDeliveryOfAnotherWorkspace(t *testing.T) {
+	s := newStore(3)
+	foreign := s.subscribers[1]
+	s.deliveries[foreign.ID] = &storedDelivery{Delivery: models.Delivery{
+		ID:           uuid.New(),
+		WorkspaceID:  uuid.New(),
+		ContentID:    s.content.ID,
+		SubscriberID: foreign.ID,
+		Email:        foreign.Email,
+		Status:       constants.DeliveryStatusPending,
+	}}
+
+	if err := runSend(t, s, -1, false, 1); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	mailed := s.mailed()
+	for _, subscriber := range s.subscribers {
+		want := 1
+		if subscriber == foreign {
+			want = 0
+		}
+		if count := mailed[subscriber.Email]; count != want {
+			t.Errorf("%s mailed %d times, want %d", subscriber.Email, count, want)
+		}
+	}
+	if s.deliveries[foreign.ID].Status != constants.DeliveryStatusPending {
+		t.Errorf("delivery of another workspace was changed to %q", s.deliveries[foreign.ID].Status)
+	}
+}
@@ -0,0 +1,4 @@
+-- Migration 002: Index supporting keyset-paginated audience resolution
+
+-- Covers the join from a topic's active subscriptions to subscribers ordered by subscriber ID
+CREATE INDEX idx_subscriptions_topic_active_subscriber ON subscriptions(topic_id, subscriber_id) WHERE is_active = true;
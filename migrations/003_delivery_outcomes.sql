@@ -0,0 +1,18 @@
+-- Migration 003: Track delivery outcomes on content and jobs
+
+-- Allow content that was only partially delivered
+ALTER TABLE content DROP CONSTRAINT content_status_check;
+ALTER TABLE content ADD CONSTRAINT content_status_check
+    CHECK (status IN ('scheduled', 'sent', 'partially_sent', 'failed', 'cancelled'));
+
+-- Content totals across all deliveries
+ALTER TABLE content
+    ADD COLUMN sent_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN failed_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN skipped_count INTEGER NOT NULL DEFAULT 0;
+
+-- Outcome counts of the latest job run
+ALTER TABLE job_scheduler
+    ADD COLUMN sent_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN failed_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN skipped_count INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,16 @@
+-- Migration 004: Automatic retry of failed deliveries
+
+-- Failed deliveries are retried; undeliverable is terminal (permanent error or retries exhausted)
+ALTER TABLE deliveries DROP CONSTRAINT deliveries_status_check;
+ALTER TABLE deliveries ADD CONSTRAINT deliveries_status_check
+    CHECK (status IN ('pending', 'sent', 'failed', 'undeliverable', 'bounced'));
+
+ALTER TABLE deliveries
+    ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN next_retry_at TIMESTAMP WITH TIME ZONE;
+
+-- Retry jobs reference the delivery they retry
+ALTER TABLE job_scheduler
+    ADD COLUMN delivery_id UUID REFERENCES deliveries(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_job_scheduler_delivery_id ON job_scheduler(delivery_id);
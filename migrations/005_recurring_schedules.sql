@@ -0,0 +1,31 @@
+-- Migration 005: Recurring newsletter schedules
+
+-- A schedule either repeats an existing content item (content_id) or carries
+-- its own subject and body for a topic
+CREATE TABLE recurring_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    topic_id UUID NOT NULL REFERENCES topics(id) ON DELETE CASCADE,
+    content_id UUID REFERENCES content(id) ON DELETE CASCADE,
+    subject VARCHAR(500),
+    body TEXT,
+    cron_expression VARCHAR(255) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    status VARCHAR(50) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'paused')),
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (content_id IS NOT NULL OR (subject IS NOT NULL AND body IS NOT NULL))
+);
+
+CREATE INDEX idx_recurring_schedules_topic_id ON recurring_schedules(topic_id);
+CREATE INDEX idx_recurring_schedules_due ON recurring_schedules(next_run_at) WHERE status = 'active';
+
+CREATE TRIGGER update_recurring_schedules_updated_at BEFORE UPDATE ON recurring_schedules FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Content generated by a schedule; one occurrence per schedule and send time
+ALTER TABLE content
+    ADD COLUMN recurring_schedule_id UUID REFERENCES recurring_schedules(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX idx_content_recurring_occurrence ON content(recurring_schedule_id, send_at)
+    WHERE recurring_schedule_id IS NOT NULL;
@@ -0,0 +1,16 @@
+-- Migration 006: Subscriber time-zone aware delivery
+
+-- Optional IANA time zone of the subscriber, e.g. 'America/New_York'
+ALTER TABLE subscribers ADD COLUMN timezone VARCHAR(64);
+
+CREATE INDEX idx_subscribers_timezone ON subscribers(timezone);
+
+-- 'absolute' sends at send_at; 'local_time' sends at the wall-clock time of send_at
+-- in each subscriber's time zone
+ALTER TABLE content
+    ADD COLUMN delivery_mode VARCHAR(20) NOT NULL DEFAULT 'absolute'
+    CHECK (delivery_mode IN ('absolute', 'local_time'));
+
+-- Send jobs of local_time content target the subscribers of one time zone;
+-- NULL is the default bucket for everyone else
+ALTER TABLE job_scheduler ADD COLUMN timezone VARCHAR(64);
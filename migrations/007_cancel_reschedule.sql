@@ -0,0 +1,11 @@
+-- Migration 007: Cancel and reschedule content
+
+-- Jobs of cancelled content are cancelled rather than deleted, for auditing
+ALTER TABLE job_scheduler DROP CONSTRAINT job_scheduler_status_check;
+ALTER TABLE job_scheduler ADD CONSTRAINT job_scheduler_status_check
+    CHECK (status IN ('pending', 'enqueued', 'completed', 'failed', 'cancelled'));
+
+-- Asynq task ID of an enqueued job, used to revoke it
+ALTER TABLE job_scheduler ADD COLUMN task_id VARCHAR(255);
+
+CREATE INDEX idx_job_scheduler_content_id ON job_scheduler(content_id);
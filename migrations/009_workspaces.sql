@@ -0,0 +1,76 @@
+-- Migration 009: Workspaces
+
+-- A workspace is one tenant (brand). It scopes topics, subscribers, content,
+-- deliveries, jobs, schedules and API keys, and carries its own sender identity.
+CREATE TABLE workspaces (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(64) NOT NULL UNIQUE,
+    from_email VARCHAR(255),
+    from_name VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER update_workspaces_updated_at BEFORE UPDATE ON workspaces FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Existing data moves into a default workspace that uses the global sender identity.
+-- Its ID is fixed so that unsubscribe links issued before workspaces keep working.
+INSERT INTO workspaces (id, name, slug) VALUES ('00000000-0000-0000-0000-000000000001', 'Default', 'default');
+
+ALTER TABLE topics ADD COLUMN workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE subscribers ADD COLUMN workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE subscriptions ADD COLUMN workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE content ADD COLUMN workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE deliveries ADD COLUMN workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE job_scheduler ADD COLUMN workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE recurring_schedules ADD COLUMN workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE api_keys ADD COLUMN workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+
+UPDATE topics SET workspace_id = '00000000-0000-0000-0000-000000000001';
+UPDATE subscribers SET workspace_id = '00000000-0000-0000-0000-000000000001';
+UPDATE subscriptions SET workspace_id = '00000000-0000-0000-0000-000000000001';
+UPDATE content SET workspace_id = '00000000-0000-0000-0000-000000000001';
+UPDATE deliveries SET workspace_id = '00000000-0000-0000-0000-000000000001';
+UPDATE job_scheduler SET workspace_id = '00000000-0000-0000-0000-000000000001';
+UPDATE recurring_schedules SET workspace_id = '00000000-0000-0000-0000-000000000001';
+UPDATE api_keys SET workspace_id = '00000000-0000-0000-0000-000000000001';
+
+ALTER TABLE topics ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE subscribers ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE subscriptions ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE content ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE deliveries ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE job_scheduler ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE recurring_schedules ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE api_keys ALTER COLUMN workspace_id SET NOT NULL;
+
+-- Names and email addresses are unique per workspace instead of globally
+ALTER TABLE topics DROP CONSTRAINT topics_name_key;
+ALTER TABLE topics ADD CONSTRAINT topics_workspace_id_name_key UNIQUE (workspace_id, name);
+ALTER TABLE subscribers DROP CONSTRAINT subscribers_email_key;
+ALTER TABLE subscribers ADD CONSTRAINT subscribers_workspace_id_email_key UNIQUE (workspace_id, email);
+
+-- Composite keys let child rows reference a parent in the same workspace only
+ALTER TABLE topics ADD CONSTRAINT topics_workspace_id_id_key UNIQUE (workspace_id, id);
+ALTER TABLE subscribers ADD CONSTRAINT subscribers_workspace_id_id_key UNIQUE (workspace_id, id);
+ALTER TABLE content ADD CONSTRAINT content_workspace_id_id_key UNIQUE (workspace_id, id);
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_workspace_subscriber_fkey FOREIGN KEY (workspace_id, subscriber_id) REFERENCES subscribers(workspace_id, id) ON DELETE CASCADE,
+    ADD CONSTRAINT subscriptions_workspace_topic_fkey FOREIGN KEY (workspace_id, topic_id) REFERENCES topics(workspace_id, id) ON DELETE CASCADE;
+ALTER TABLE content
+    ADD CONSTRAINT content_workspace_topic_fkey FOREIGN KEY (workspace_id, topic_id) REFERENCES topics(workspace_id, id) ON DELETE CASCADE;
+ALTER TABLE deliveries
+    ADD CONSTRAINT deliveries_workspace_content_fkey FOREIGN KEY (workspace_id, content_id) REFERENCES content(workspace_id, id) ON DELETE CASCADE,
+    ADD CONSTRAINT deliveries_workspace_subscriber_fkey FOREIGN KEY (workspace_id, subscriber_id) REFERENCES subscribers(workspace_id, id) ON DELETE CASCADE;
+ALTER TABLE job_scheduler
+    ADD CONSTRAINT job_scheduler_workspace_content_fkey FOREIGN KEY (workspace_id, content_id) REFERENCES content(workspace_id, id) ON DELETE CASCADE;
+ALTER TABLE recurring_schedules
+    ADD CONSTRAINT recurring_schedules_workspace_topic_fkey FOREIGN KEY (workspace_id, topic_id) REFERENCES topics(workspace_id, id) ON DELETE CASCADE;
+
+CREATE INDEX idx_content_workspace_id ON content(workspace_id, created_at);
+CREATE INDEX idx_deliveries_workspace_id ON deliveries(workspace_id);
+CREATE INDEX idx_job_scheduler_workspace_id ON job_scheduler(workspace_id);
+CREATE INDEX idx_recurring_schedules_workspace_id ON recurring_schedules(workspace_id);
+CREATE INDEX idx_api_keys_workspace_id ON api_keys(workspace_id);
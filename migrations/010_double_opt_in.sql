@@ -0,0 +1,20 @@
+-- Migration 010: Double opt-in subscriptions
+
+-- Topics with double opt-in require new subscribers to confirm by email
+ALTER TABLE topics ADD COLUMN double_opt_in BOOLEAN NOT NULL DEFAULT false;
+
+-- A subscription is active, awaiting confirmation, expired unconfirmed, or unsubscribed.
+-- is_active stays true only for active subscriptions so that audience queries and
+-- their partial index are unchanged.
+ALTER TABLE subscriptions
+    ADD COLUMN status VARCHAR(32) NOT NULL DEFAULT 'active',
+    ADD COLUMN confirmation_expires_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN confirmed_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE subscriptions SET status = 'unsubscribed' WHERE is_active = false;
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_status_check CHECK (status IN ('active', 'pending_confirmation', 'expired', 'unsubscribed')),
+    ADD CONSTRAINT subscriptions_status_is_active_check CHECK (is_active = (status = 'active'));
+
+CREATE INDEX idx_subscriptions_pending_confirmation ON subscriptions(confirmation_expires_at) WHERE status = 'pending_confirmation';
@@ -0,0 +1,18 @@
+-- Migration 011: Suppression list
+
+-- Suppressed addresses are never mailed again, whatever topic they subscribe to.
+-- Addresses are stored lowercased, like subscriber emails.
+CREATE TABLE suppressions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    reason VARCHAR(32) NOT NULL CHECK (reason IN ('hard_bounce', 'complaint', 'unsubscribe', 'manual')),
+    source VARCHAR(32) NOT NULL CHECK (source IN ('api', 'import', 'provider')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT suppressions_workspace_id_email_key UNIQUE (workspace_id, email)
+);
+
+-- Deliveries skipped because the address is suppressed
+ALTER TABLE deliveries DROP CONSTRAINT deliveries_status_check;
+ALTER TABLE deliveries ADD CONSTRAINT deliveries_status_check
+    CHECK (status IN ('pending', 'sent', 'failed', 'undeliverable', 'bounced', 'suppressed'));
@@ -0,0 +1,10 @@
+-- Migration 012: Provider delivery events
+
+-- The message ID the email provider assigned on send lets bounce, complaint and
+-- engagement webhooks be matched back to the delivery they concern
+ALTER TABLE deliveries
+    ADD COLUMN provider_message_id VARCHAR(255),
+    ADD COLUMN delivered_at TIMESTAMP WITH TIME ZONE;
+
+CREATE UNIQUE INDEX idx_deliveries_provider_message_id ON deliveries(provider_message_id)
+    WHERE provider_message_id IS NOT NULL;
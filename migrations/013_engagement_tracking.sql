@@ -0,0 +1,18 @@
+-- Migration 013: Open and click tracking
+
+-- Tracking is off unless a topic opts in; content can override its topic either way
+ALTER TABLE topics ADD COLUMN tracking_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE content ADD COLUMN tracking_enabled BOOLEAN;
+
+-- Opens and clicks recorded by the tracking pixel and link redirects
+CREATE TABLE delivery_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    delivery_id UUID NOT NULL REFERENCES deliveries(id) ON DELETE CASCADE,
+    event_type VARCHAR(16) NOT NULL CHECK (event_type IN ('open', 'click')),
+    url TEXT,
+    user_agent TEXT,
+    occurred_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_delivery_events_delivery_id ON delivery_events(delivery_id, occurred_at);
@@ -0,0 +1,28 @@
+-- Migration 014: Job administration
+
+-- Every error a job records is kept, not only the latest one on the job itself
+CREATE TABLE job_errors (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_id UUID NOT NULL REFERENCES job_scheduler(id) ON DELETE CASCADE,
+    attempt INTEGER NOT NULL,
+    error_message TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_job_errors_job_id ON job_errors(job_id, created_at);
+
+-- Manual interventions through the jobs API. Entries outlive the jobs they
+-- describe, so job_id is not a foreign key.
+CREATE TABLE job_audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    job_id UUID NOT NULL,
+    action VARCHAR(32) NOT NULL CHECK (action IN ('retry', 'fail', 'delete')),
+    api_key_id UUID REFERENCES api_keys(id) ON DELETE SET NULL,
+    previous_status VARCHAR(50) NOT NULL,
+    new_status VARCHAR(50),
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_job_audit_log_job_id ON job_audit_log(job_id, created_at);
@@ -0,0 +1,7 @@
+-- Migration 015: Recovery of stuck enqueued jobs
+
+-- Workers record a heartbeat while they process a job so that the scheduler can
+-- tell long running sends from jobs whose task was lost
+ALTER TABLE job_scheduler ADD COLUMN heartbeat_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_job_scheduler_enqueued ON job_scheduler(updated_at) WHERE status = 'enqueued';
@@ -0,0 +1,6 @@
+-- Migration 016: Delivery claim leases
+
+-- A run records when it claimed a delivery. Another run of the same content only
+-- takes over a pending delivery once that claim has expired, so overlapping runs
+-- cannot mail the same subscriber.
+ALTER TABLE deliveries ADD COLUMN claimed_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,4 @@
+-- Migration 017: Count bounced deliveries in content totals
+
+-- Bounces arrive after an email was sent and move its delivery out of the sent total
+ALTER TABLE content ADD COLUMN bounced_count INTEGER NOT NULL DEFAULT 0;